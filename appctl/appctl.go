@@ -0,0 +1,223 @@
+// Package appctl talks to the control socket of OVS daemons, e.g. ovsdb-server, like ovs-appctl does,
+// so Go tooling can fetch cluster status or manage compaction with the same library used for data access
+package appctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Client is a client of the control socket (unixctl) of an OVS daemon
+type Client struct {
+	conn    io.ReadWriteCloser
+	encoder *json.Encoder
+	decoder *json.Decoder
+
+	// mu serializes commands, the protocol doesn't pipeline requests
+	mu sync.Mutex
+	id int
+}
+
+// Dial connects to the control socket at path, e.g. /var/run/openvswitch/ovsdb-server.1234.ctl
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial: %v", err)
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient creates a Client over an established connection to a control socket
+func NewClient(conn io.ReadWriteCloser) *Client {
+	return &Client{
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		decoder: json.NewDecoder(conn),
+	}
+}
+
+// ControlSocketPath returns the path of the control socket of program in rundir, from its pid file,
+// e.g. ControlSocketPath("/var/run/openvswitch", "ovsdb-server")
+func ControlSocketPath(rundir, program string) (string, error) {
+	pidFile := filepath.Join(rundir, program+".pid")
+	content, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		return "", err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return "", fmt.Errorf("invalid pid file %s: %v", pidFile, err)
+	}
+	return filepath.Join(rundir, fmt.Sprintf("%s.%d.ctl", program, pid)), nil
+}
+
+// Call executes command with args, like "ovs-appctl <command> <args>...", and returns its output
+func (c *Client) Call(command string, args ...string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if args == nil {
+		args = []string{}
+	}
+	c.id++
+	request := struct {
+		ID     int      `json:"id"`
+		Method string   `json:"method"`
+		Params []string `json:"params"`
+	}{c.id, command, args}
+	if err := c.encoder.Encode(request); err != nil {
+		return "", err
+	}
+
+	var response struct {
+		ID     int         `json:"id"`
+		Result *string     `json:"result"`
+		Error  interface{} `json:"error"`
+	}
+	if err := c.decoder.Decode(&response); err != nil {
+		return "", err
+	}
+	if response.ID != c.id {
+		return "", fmt.Errorf("unexpected response id %d, want %d", response.ID, c.id)
+	}
+	if response.Error != nil {
+		return "", fmt.Errorf("%s: %v", command, strings.TrimSpace(fmt.Sprint(response.Error)))
+	}
+	if response.Result == nil {
+		return "", errors.New("response without result")
+	}
+	return *response.Result, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// LogLevels returns the log levels of the daemon's modules, the output of "vlog/list"
+func (c *Client) LogLevels() (string, error) {
+	return c.Call("vlog/list")
+}
+
+// SetLogLevel changes log levels with a "vlog/set" spec, e.g. "raft:file:dbg"
+func (c *Client) SetLogLevel(spec string) error {
+	_, err := c.Call("vlog/set", spec)
+	return err
+}
+
+// ClusterStatus is the status of a clustered database, parsed from the output of "cluster/status"
+type ClusterStatus struct {
+	Name      string
+	ClusterID string
+	ServerID  string
+	Address   string
+	Status    string
+	Role      string
+	Term      int64
+	Leader    string
+	Vote      string
+	// Servers are the lines describing the servers of the cluster
+	Servers []string
+	// Fields holds all "<key>: <value>" lines of the output
+	Fields map[string]string
+}
+
+// ClusterStatus returns the status of the clustered database db
+func (c *Client) ClusterStatus(db string) (*ClusterStatus, error) {
+	output, err := c.Call("cluster/status", db)
+	if err != nil {
+		return nil, err
+	}
+	return ParseClusterStatus(output)
+}
+
+// ParseClusterStatus parses the output of "cluster/status"
+func ParseClusterStatus(output string) (*ClusterStatus, error) {
+	status := &ClusterStatus{Fields: make(map[string]string)}
+	inServers := false
+	for _, line := range strings.Split(output, "\n") {
+		if inServers {
+			if trimmed := strings.TrimSpace(line); trimmed != "" {
+				status.Servers = append(status.Servers, trimmed)
+			}
+			continue
+		}
+		if strings.TrimSpace(line) == "Servers:" {
+			inServers = true
+			continue
+		}
+		i := strings.Index(line, ": ")
+		if i < 0 {
+			continue
+		}
+		status.Fields[line[:i]] = strings.TrimSpace(line[i+2:])
+	}
+
+	status.Name = status.Fields["Name"]
+	status.ClusterID = status.Fields["Cluster ID"]
+	status.ServerID = status.Fields["Server ID"]
+	status.Address = status.Fields["Address"]
+	status.Status = status.Fields["Status"]
+	status.Role = status.Fields["Role"]
+	status.Leader = status.Fields["Leader"]
+	status.Vote = status.Fields["Vote"]
+	if term, ok := status.Fields["Term"]; ok {
+		var err error
+		if status.Term, err = strconv.ParseInt(term, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid term %q: %v", term, err)
+		}
+	}
+	if status.Name == "" {
+		return nil, errors.New("not a cluster/status output")
+	}
+	return status, nil
+}
+
+// TriggerCompaction compacts database db of ovsdb-server, or all its databases if db is empty.
+// A clustered database is compacted by taking a snapshot of its log.
+func (c *Client) TriggerCompaction(db string) error {
+	var args []string
+	if db != "" {
+		args = append(args, db)
+	}
+	_, err := c.Call("ovsdb-server/compact", args...)
+	return err
+}
+
+// CompactionStatus is the state of the log of a clustered database, which compaction shrinks
+type CompactionStatus struct {
+	// LogStart and LogEnd are the first and the next log indexes, entries before LogStart are in the snapshot
+	LogStart int64
+	LogEnd   int64
+}
+
+// Entries returns the number of log entries since the last snapshot
+func (s *CompactionStatus) Entries() int64 {
+	return s.LogEnd - s.LogStart
+}
+
+// CompactionStatus returns the state of the log of the clustered database db,
+// taken from the "Log: [<start>, <end>]" line of "cluster/status"
+func (c *Client) CompactionStatus(db string) (*CompactionStatus, error) {
+	status, err := c.ClusterStatus(db)
+	if err != nil {
+		return nil, err
+	}
+	log, ok := status.Fields["Log"]
+	if !ok {
+		return nil, fmt.Errorf("no log in the status of database %s", db)
+	}
+	var compaction CompactionStatus
+	if _, err := fmt.Sscanf(log, "[%d, %d]", &compaction.LogStart, &compaction.LogEnd); err != nil {
+		return nil, fmt.Errorf("invalid log %q in the status of database %s: %v", log, db, err)
+	}
+	return &compaction, nil
+}
@@ -0,0 +1,110 @@
+package appctl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const clusterStatusOutput = `a8d0
+Name: OVN_Northbound
+Cluster ID: 5b1c (5b1c8a1e-7a2f-4d5e-9c3b-2f1e0d9c8b7a)
+Server ID: a8d0 (a8d0c6f2-1b3a-4c5d-8e7f-6a5b4c3d2e1f)
+Address: tcp:10.0.0.1:6643
+Status: cluster member
+Role: leader
+Term: 3
+Leader: self
+Vote: self
+
+Log: [2, 10]
+Entries not yet committed: 0
+Entries not yet applied: 0
+Servers:
+    a8d0 (a8d0 at tcp:10.0.0.1:6643) (self) next_index=9 match_index=9
+    b9e1 (b9e1 at tcp:10.0.0.2:6643) next_index=10 match_index=9
+`
+
+// serve answers requests on conn with responses
+func serve(conn net.Conn, responses map[string]interface{}) {
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		var request struct {
+			ID     int      `json:"id"`
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+		}
+		if err := decoder.Decode(&request); err != nil {
+			return
+		}
+		response := map[string]interface{}{"id": request.ID, "result": nil, "error": nil}
+		if result, ok := responses[request.Method]; ok {
+			response["result"] = result
+		} else {
+			response["error"] = "\"" + request.Method + "\" is not a valid command\n"
+		}
+		encoder.Encode(response)
+	}
+}
+
+func TestClient(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	go serve(serverConn, map[string]interface{}{
+		"cluster/status":       clusterStatusOutput,
+		"vlog/set":             "",
+		"ovsdb-server/compact": "",
+	})
+	client := NewClient(conn)
+	defer client.Close()
+
+	status, err := client.ClusterStatus("OVN_Northbound")
+	if err != nil {
+		t.Fatalf("ClusterStatus failed: %v", err)
+	}
+	if status.Name != "OVN_Northbound" || status.Role != "leader" || status.Term != 3 || status.Leader != "self" {
+		t.Errorf("ClusterStatus = %+v", status)
+	}
+	if len(status.Servers) != 2 || status.Fields["Log"] != "[2, 10]" {
+		t.Errorf("ClusterStatus servers %v, fields %v", status.Servers, status.Fields)
+	}
+
+	compaction, err := client.CompactionStatus("OVN_Northbound")
+	if err != nil {
+		t.Fatalf("CompactionStatus failed: %v", err)
+	}
+	if compaction.LogStart != 2 || compaction.LogEnd != 10 || compaction.Entries() != 8 {
+		t.Errorf("CompactionStatus = %+v", compaction)
+	}
+	if err := client.TriggerCompaction("OVN_Northbound"); err != nil {
+		t.Errorf("TriggerCompaction failed: %v", err)
+	}
+
+	if err := client.SetLogLevel("raft:file:dbg"); err != nil {
+		t.Errorf("SetLogLevel failed: %v", err)
+	}
+	if _, err := client.Call("bogus/command"); err == nil {
+		t.Error("expect Call of an invalid command failed, but got nil")
+	}
+}
+
+func TestControlSocketPath(t *testing.T) {
+	rundir, err := ioutil.TempDir("", "appctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rundir)
+	if err := ioutil.WriteFile(filepath.Join(rundir, "ovsdb-server.pid"), []byte("1234\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path, err := ControlSocketPath(rundir, "ovsdb-server")
+	if err != nil {
+		t.Fatalf("ControlSocketPath failed: %v", err)
+	}
+	if want := filepath.Join(rundir, "ovsdb-server.1234.ctl"); path != want {
+		t.Errorf("ControlSocketPath = %s, want %s", path, want)
+	}
+}
@@ -0,0 +1,139 @@
+package ovsdb
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+)
+
+// OrderedUpdate is a row update of TableUpdates, see ApplyOrder
+type OrderedUpdate struct {
+	Table  ID
+	UUID   UUID
+	Update RowUpdate
+}
+
+// ApplyOrder orders the row updates of a batch, e.g. a monitor update, for consumers applying them to systems
+// enforcing references between rows, like a SQL mirror with foreign keys.
+// A row holding a reference to another row of the batch is its parent, and the referred row its child.
+// Deletions come first, children before parents, followed by insertions and modifications, parents before
+// children. References are those of the old rows for deletions and of the new rows otherwise.
+// Rows referring to each other in a cycle, which OVSDB allows, are ordered by table and UUID.
+// Apart from that, updates are also ordered by table and UUID, so the order is stable.
+func ApplyOrder(dbSchema *DatabaseSchema, updates TableUpdates) ([]OrderedUpdate, error) {
+	deleted := make(map[ID]map[UUID]map[ID]interface{})
+	written := make(map[ID]map[UUID]map[ID]interface{})
+	for table, tableUpdate := range updates {
+		for uuid, rowUpdate := range tableUpdate {
+			rows, raw := written, rowUpdate.New
+			if rowUpdate.New == nil {
+				rows, raw = deleted, rowUpdate.Old
+			}
+			var row map[ID]interface{}
+			if raw != nil {
+				if err := json.Unmarshal(*raw, &row); err != nil {
+					return nil, fmt.Errorf("invalid update of row %s in table %s: %v", uuid, table, err)
+				}
+			}
+			if rows[table] == nil {
+				rows[table] = make(map[UUID]map[ID]interface{})
+			}
+			rows[table][uuid] = row
+		}
+	}
+
+	deletions, err := parentsFirst(dbSchema, deleted)
+	if err != nil {
+		return nil, err
+	}
+	writes, err := parentsFirst(dbSchema, written)
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([]OrderedUpdate, 0, len(deletions)+len(writes))
+	for i := len(deletions) - 1; i >= 0; i-- {
+		key := deletions[i]
+		ordered = append(ordered, OrderedUpdate{key.table, key.uuid, updates[key.table][key.uuid]})
+	}
+	for _, key := range writes {
+		ordered = append(ordered, OrderedUpdate{key.table, key.uuid, updates[key.table][key.uuid]})
+	}
+	return ordered, nil
+}
+
+// parentsFirst sorts rows topologically so rows come before the rows they refer to, cycles are broken
+// by taking the first remaining row in table and UUID order
+func parentsFirst(dbSchema *DatabaseSchema, rows map[ID]map[UUID]map[ID]interface{}) ([]rowKey, error) {
+	refs, err := findReferences(dbSchema, rows)
+	if err != nil {
+		return nil, err
+	}
+	var keys []rowKey
+	for table, tableRows := range rows {
+		for uuid := range tableRows {
+			keys = append(keys, rowKey{table, uuid})
+		}
+	}
+	sortRowKeys(keys)
+
+	// parents counts the references to a row from other remaining rows
+	parents := make(map[rowKey]int)
+	children := make(map[rowKey][]rowKey)
+	for _, ref := range refs {
+		if _, ok := rows[ref.to.table][ref.to.uuid]; !ok || ref.from == ref.to {
+			continue
+		}
+		parents[ref.to]++
+		children[ref.from] = append(children[ref.from], ref.to)
+	}
+
+	// ready holds the indexes in keys of the rows without remaining parents
+	index := make(map[rowKey]int, len(keys))
+	ready := &intHeap{}
+	for i, key := range keys {
+		index[key] = i
+		if parents[key] == 0 {
+			heap.Push(ready, i)
+		}
+	}
+	sorted := make([]rowKey, 0, len(keys))
+	done := make([]bool, len(keys))
+	// first is the first row which may not be done yet, taken if all remaining rows are in cycles
+	first := 0
+	for len(sorted) < len(keys) {
+		var next int
+		if ready.Len() != 0 {
+			next = heap.Pop(ready).(int)
+			if done[next] {
+				continue
+			}
+		} else {
+			for done[first] {
+				first++
+			}
+			next = first
+		}
+		done[next] = true
+		sorted = append(sorted, keys[next])
+		for _, child := range children[keys[next]] {
+			if parents[child]--; parents[child] == 0 {
+				heap.Push(ready, index[child])
+			}
+		}
+	}
+	return sorted, nil
+}
+
+// intHeap is a min-heap of ints
+type intHeap []int
+
+func (h intHeap) Len() int            { return len(h) }
+func (h intHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h intHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *intHeap) Push(x interface{}) { *h = append(*h, x.(int)) }
+func (h *intHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
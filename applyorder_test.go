@@ -0,0 +1,51 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyOrder(t *testing.T) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(cascadeSchema), &dbSchema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	raw := func(s string) *json.RawMessage {
+		r := json.RawMessage(s)
+		return &r
+	}
+	updates := TableUpdates{
+		"Logical_Switch": {
+			ls1: {New: raw(`{"name":"ls1","ports":["uuid","` + lsp1 + `"]}`)},
+			ls2: {Old: raw(`{"name":"ls2","ports":["uuid","` + lsp2 + `"]}`)},
+		},
+		"Logical_Switch_Port": {
+			lsp1: {New: raw(`{"name":"lsp1"}`)},
+			lsp2: {Old: raw(`{"name":"lsp2"}`)},
+		},
+		"Switch_Group": {
+			sg1: {Old: raw(`{"primary":["uuid","` + ls2 + `"]}`), New: raw(`{"primary":["uuid","` + ls1 + `"]}`)},
+		},
+		"ACL": {
+			acl1: {New: raw(`{"priority":1}`)},
+		},
+	}
+
+	ordered, err := ApplyOrder(&dbSchema, updates)
+	if err != nil {
+		t.Fatalf("ApplyOrder failed: %v", err)
+	}
+	var got []UUID
+	for _, update := range ordered {
+		if !reflect.DeepEqual(update.Update, updates[update.Table][update.UUID]) {
+			t.Errorf("update of %s in %s differs", update.UUID, update.Table)
+		}
+		got = append(got, update.UUID)
+	}
+	// deletions children first, then writes parents first, otherwise by table and UUID
+	want := []UUID{lsp2, ls2, acl1, sg1, ls1, lsp1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got order %v, want %v", got, want)
+	}
+}
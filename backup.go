@@ -0,0 +1,196 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// BackupFormat is the portable format written by Backup and read by Restore.
+// It's a JSON object of the following form, with tables and rows keyed by name and UUID:
+//
+//	{"name": <db-name>, "version": <version>, "cksum": <checksum>,
+//	 "tables": {<table>: {<uuid>: <row>, ...}, ...}}
+//
+// Rows don't contain the "_uuid" and "_version" columns.
+type BackupFormat struct {
+	Name     ID                                 `json:"name"`
+	Version  Version                            `json:"version"`
+	Checksum string                             `json:"cksum,omitempty"`
+	Tables   map[ID]map[UUID]map[ID]interface{} `json:"tables"`
+}
+
+// Backup dumps the content of all tables of database db into w in the BackupFormat
+func (c *Client) Backup(db ID, w io.Writer) error {
+	dbSchema, err := c.GetSchema(db)
+	if err != nil {
+		return err
+	}
+
+	tables, err := c.selectAllRows(db, dbSchema)
+	if err != nil {
+		return err
+	}
+	backup := BackupFormat{
+		Name:     dbSchema.Name,
+		Version:  dbSchema.Version,
+		Checksum: dbSchema.Checksum,
+		Tables:   tables,
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(backup)
+}
+
+// selectAllRows selects all rows of all tables of dbSchema in database db in one transaction,
+// which gives a consistent snapshot of the database with rows keyed by table and UUID
+func (c *Client) selectAllRows(db ID, dbSchema *DatabaseSchema) (map[ID]map[UUID]map[ID]interface{}, error) {
+	var tables []ID
+	var ops []Operation
+	for table := range dbSchema.Tables {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i] < tables[j] })
+	for _, table := range tables {
+		ops = append(ops, &SelectOperation{Table: table, Where: MatchAll()})
+	}
+
+	result, err := c.Transact(db, ops...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) != 0 {
+		return nil, result.Errors
+	}
+	snapshot := make(map[ID]map[UUID]map[ID]interface{})
+	for i, table := range tables {
+		rows, err := decodeBackupRows(result.Results[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to select table %s: %v", table, err)
+		}
+		snapshot[table] = rows
+	}
+	return snapshot, nil
+}
+
+// decodeBackupRows decodes the result of a select operation into rows keyed by UUID
+func decodeBackupRows(result interface{}) (map[UUID]map[ID]interface{}, error) {
+	raw, ok := result.(json.RawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected select result: %v", result)
+	}
+	var selected struct {
+		Rows []map[ID]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal(raw, &selected); err != nil {
+		return nil, err
+	}
+
+	rows := make(map[UUID]map[ID]interface{}, len(selected.Rows))
+	for _, row := range selected.Rows {
+		uuid, ok := atomUUID(row["_uuid"])
+		if !ok {
+			return nil, errNotUUID
+		}
+		delete(row, "_uuid")
+		delete(row, "_version")
+		rows[uuid] = row
+	}
+	return rows, nil
+}
+
+// Restore replaces the content of database db with a backup read from r in the BackupFormat.
+// All rows are deleted and the backup is inserted in one transaction, rows get new UUIDs
+// and references between restored rows are kept.
+func (c *Client) Restore(db ID, r io.Reader) error {
+	var backup BackupFormat
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return fmt.Errorf("failed to decode backup: %v", err)
+	}
+	if backup.Name != db {
+		return fmt.Errorf("backup of database %s can't be restored to %s", backup.Name, db)
+	}
+
+	result, err := c.Transact(db, restoreOperations(&backup)...)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) != 0 {
+		return result.Errors
+	}
+	return nil
+}
+
+// restoreOperations returns the operations which delete all rows of the backed up tables and insert
+// the backed up rows, references to backed up rows are replaced with the named UUIDs of new rows
+func restoreOperations(backup *BackupFormat) []Operation {
+	var tables []ID
+	for table := range backup.Tables {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i] < tables[j] })
+
+	names := make(map[UUID]NamedUUID)
+	for _, rows := range backup.Tables {
+		for uuid := range rows {
+			names[uuid] = NamedUUID("row_" + strings.Replace(string(uuid), "-", "_", -1))
+		}
+	}
+
+	var ops []Operation
+	for _, table := range tables {
+		ops = append(ops, &DeleteOperation{Table: table, Where: MatchAll()})
+	}
+	for _, table := range tables {
+		rows := backup.Tables[table]
+		var uuids []UUID
+		for uuid := range rows {
+			uuids = append(uuids, uuid)
+		}
+		sort.Slice(uuids, func(i, j int) bool { return uuids[i] < uuids[j] })
+		for _, uuid := range uuids {
+			row := make(map[ID]interface{}, len(rows[uuid]))
+			for column, value := range rows[uuid] {
+				row[column] = renameReferences(value, names)
+			}
+			ops = append(ops, &InsertOperation{
+				Table:    table,
+				Row:      row,
+				UUIDName: ID(names[uuid]),
+			})
+		}
+	}
+	return ops
+}
+
+// renameReferences replaces <uuid>s in a JSON value with the <named-uuid>s in names
+func renameReferences(v interface{}, names map[UUID]NamedUUID) interface{} {
+	array, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	if uuid, ok := atomUUID(array); ok {
+		if name, ok := names[uuid]; ok {
+			return name
+		}
+		return uuid
+	}
+	renamed := make([]interface{}, len(array))
+	for i, element := range array {
+		renamed[i] = renameReferences(element, names)
+	}
+	return renamed
+}
+
+// atomUUID returns the UUID of a <uuid> decoded from JSON
+func atomUUID(v interface{}) (UUID, bool) {
+	array, ok := v.([]interface{})
+	if !ok || len(array) != 2 || array[0] != uuidMagic {
+		return "", false
+	}
+	uuid, ok := array[1].(string)
+	return UUID(uuid), ok
+}
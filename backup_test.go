@@ -0,0 +1,55 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRestoreOperations(t *testing.T) {
+	var backup BackupFormat
+	err := json.Unmarshal([]byte(`{
+		"name": "Open_vSwitch",
+		"version": "7.15.1",
+		"tables": {
+			"Bridge": {
+				"00000000-0000-0000-0000-000000000001": {
+					"name": "br0",
+					"ports": ["set", [["uuid", "00000000-0000-0000-0000-000000000002"], ["uuid", "00000000-0000-0000-0000-00000000ffff"]]]
+				}
+			},
+			"Port": {
+				"00000000-0000-0000-0000-000000000002": {"name": "p0", "external_ids": ["map", [["k", "v"]]]}
+			}
+		}
+	}`), &backup)
+	if err != nil {
+		t.Fatalf("failed to decode backup: %v", err)
+	}
+
+	bytes, err := json.Marshal(restoreOperations(&backup))
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	want := `[{"op":"delete","table":"Bridge","where":[["_uuid","!=",["uuid","00000000-0000-0000-0000-000000000000"]]]},` +
+		`{"op":"delete","table":"Port","where":[["_uuid","!=",["uuid","00000000-0000-0000-0000-000000000000"]]]},` +
+		`{"op":"insert","table":"Bridge","row":{"name":"br0","ports":["set",[["named-uuid","row_00000000_0000_0000_0000_000000000002"],["uuid","00000000-0000-0000-0000-00000000ffff"]]]},"uuid-name":"row_00000000_0000_0000_0000_000000000001"},` +
+		`{"op":"insert","table":"Port","row":{"external_ids":["map",[["k","v"]]],"name":"p0"},"uuid-name":"row_00000000_0000_0000_0000_000000000002"}]`
+	if string(bytes) != want {
+		t.Errorf("restore operations = %s, want %s", bytes, want)
+	}
+}
+
+func TestDecodeBackupRows(t *testing.T) {
+	rows, err := decodeBackupRows(json.RawMessage(`{"rows":[{"_uuid":["uuid","00000000-0000-0000-0000-000000000001"],"_version":["uuid","00000000-0000-0000-0000-000000000009"],"name":"br0"}]}`))
+	if err != nil {
+		t.Fatalf("decodeBackupRows failed: %v", err)
+	}
+	row, ok := rows["00000000-0000-0000-0000-000000000001"]
+	if !ok || len(row) != 1 || row["name"] != "br0" {
+		t.Errorf("decodeBackupRows = %v, want one row with only name", rows)
+	}
+
+	if _, err := decodeBackupRows(json.RawMessage(`{"rows":[{"name":"br0"}]}`)); err == nil {
+		t.Error("expect error for row without _uuid, but got nil")
+	}
+}
@@ -0,0 +1,172 @@
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+)
+
+// ErrCircuitOpen is returned by RPCs of a client while its circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int
+
+// Supported BreakerStates
+const (
+	// BreakerClosed lets RPCs through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails RPCs fast with ErrCircuitOpen
+	BreakerOpen
+)
+
+// String implements fmt.Stringer interface
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "Closed"
+	case BreakerOpen:
+		return "Open"
+	}
+	return "Unknown"
+}
+
+// BreakerStats are statistics of a CircuitBreaker, e.g. for exporting as metrics
+type BreakerStats struct {
+	State BreakerState
+	// ConsecutiveFailures is the number of RPCs failed in a row
+	ConsecutiveFailures int
+	// Trips counts the times the breaker opened, Rejected the RPCs failed fast while it was open
+	Trips    uint64
+	Rejected uint64
+}
+
+// CircuitBreaker protects callers of a client from piling up on a server which doesn't respond:
+// after a number of RPCs failed in a row it opens and RPCs fail fast with ErrCircuitOpen, instead of
+// blocking until they time out. While open, the server is probed with "echo" in the background every
+// cool-down period, and the breaker closes again once the server responds.
+// Errors reported by the server, e.g. a failed operation or an unknown database, are not failures,
+// timeouts and lost connections are. Install it with Client.UseCircuitBreaker.
+type CircuitBreaker struct {
+	threshold int
+	coolDown  time.Duration
+
+	mu    sync.Mutex
+	stats BreakerStats
+}
+
+// NewCircuitBreaker creates a CircuitBreaker opening after threshold consecutive failures,
+// and probing the server every coolDown while it's open
+func NewCircuitBreaker(threshold int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold, coolDown: coolDown}
+}
+
+// UseCircuitBreaker appends an interceptor failing RPCs fast while breaker is open to the chain of the client,
+// see Use. The connection is Degraded while breaker is open, with ErrCircuitOpen as the cause.
+// A breaker must be used by one client only.
+func (c *Client) UseCircuitBreaker(breaker *CircuitBreaker) {
+	c.Use(func(next CallFunc) CallFunc {
+		return func(ctx context.Context, method string, args interface{}, reply interface{}) error {
+			if !breaker.allow() {
+				return ErrCircuitOpen
+			}
+			err := next(ctx, method, args, reply)
+			if breaker.record(err) {
+				c.setState(StateDegraded, ErrCircuitOpen)
+				go breaker.probe(c)
+			}
+			return err
+		}
+	})
+}
+
+// Stats returns the statistics of the breaker
+func (b *CircuitBreaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// WriteMetrics writes the statistics of the breaker in the Prometheus text exposition format
+func (b *CircuitBreaker) WriteMetrics(w io.Writer) error {
+	stats := b.Stats()
+	_, err := fmt.Fprintf(w, `# HELP ovsdb_circuit_breaker_open Whether the circuit breaker is open.
+# TYPE ovsdb_circuit_breaker_open gauge
+ovsdb_circuit_breaker_open %d
+# HELP ovsdb_circuit_breaker_trips_total Times the circuit breaker opened.
+# TYPE ovsdb_circuit_breaker_trips_total counter
+ovsdb_circuit_breaker_trips_total %d
+# HELP ovsdb_circuit_breaker_rejected_total RPCs failed fast while the circuit breaker was open.
+# TYPE ovsdb_circuit_breaker_rejected_total counter
+ovsdb_circuit_breaker_rejected_total %d
+`, stats.State, stats.Trips, stats.Rejected)
+	return err
+}
+
+// allow returns true if a RPC may go through, it counts rejected RPCs otherwise
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stats.State == BreakerOpen {
+		b.stats.Rejected++
+		return false
+	}
+	return true
+}
+
+// record records the outcome of a RPC, it returns true if the breaker just opened
+func (b *CircuitBreaker) record(err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !isBreakerFailure(err) {
+		b.stats.ConsecutiveFailures = 0
+		return false
+	}
+	b.stats.ConsecutiveFailures++
+	if b.stats.State == BreakerOpen || b.stats.ConsecutiveFailures < b.threshold {
+		return false
+	}
+	b.stats.State = BreakerOpen
+	b.stats.Trips++
+	return true
+}
+
+// probe sends "echo" to the server every cool-down period until it responds and closes the breaker,
+// or the connection is closed
+func (b *CircuitBreaker) probe(c *Client) {
+	for {
+		time.Sleep(b.coolDown)
+		if c.State() == StateClosed {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), b.coolDown)
+		var reply []interface{}
+		err := c.rpcCall(ctx, "echo", []interface{}{"circuit-breaker"}, &reply)
+		cancel()
+		if !isBreakerFailure(err) {
+			b.mu.Lock()
+			b.stats.State = BreakerClosed
+			b.stats.ConsecutiveFailures = 0
+			b.mu.Unlock()
+			return
+		}
+	}
+}
+
+// isBreakerFailure returns true if err shows the server didn't respond to a RPC,
+// errors reported by the server and canceled RPCs are not failures
+func isBreakerFailure(err error) bool {
+	if err == nil || err == context.Canceled {
+		return false
+	}
+	switch err.(type) {
+	case rpc2.ServerError, *Error:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package ovsdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+	server.Handle("list_dbs", func(params []json.RawMessage) (interface{}, error) {
+		return []string{"Open_vSwitch"}, nil
+	})
+	server.Handle("echo", func(params []json.RawMessage) (interface{}, error) {
+		return params, nil
+	})
+
+	breaker := NewCircuitBreaker(2, 20*time.Millisecond)
+	client.UseCircuitBreaker(breaker)
+
+	// errors reported by the server are not failures
+	for i := 0; i < 3; i++ {
+		if err := client.Call(context.Background(), "get_schema", "Unknown", nil); err == nil || err == ErrCircuitOpen {
+			t.Fatalf("get_schema of an unknown database = %v", err)
+		}
+	}
+	if stats := breaker.Stats(); stats.State != BreakerClosed || stats.ConsecutiveFailures != 0 {
+		t.Fatalf("Stats = %+v after server errors", stats)
+	}
+
+	server.SetDelay(50 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		if err := client.Call(ctx, "list_dbs", nil, nil); err != context.DeadlineExceeded {
+			t.Errorf("list_dbs = %v, want a timeout", err)
+		}
+		cancel()
+	}
+	if _, err := client.ListDbs(); err != ErrCircuitOpen {
+		t.Errorf("ListDbs = %v while the breaker is open", err)
+	}
+	if state := client.State(); state != StateDegraded {
+		t.Errorf("connection %v while the breaker is open", state)
+	}
+	stats := breaker.Stats()
+	if stats.State != BreakerOpen || stats.Trips != 1 || stats.Rejected != 1 {
+		t.Errorf("Stats = %+v after opening", stats)
+	}
+	var metrics bytes.Buffer
+	breaker.WriteMetrics(&metrics)
+	if !strings.Contains(metrics.String(), "ovsdb_circuit_breaker_open 1\n") {
+		t.Errorf("metrics:\n%s", metrics.String())
+	}
+
+	server.SetDelay(0)
+	deadline := time.Now().Add(2 * time.Second)
+	for breaker.Stats().State == BreakerOpen {
+		if time.Now().After(deadline) {
+			t.Fatal("breaker not closed after the server recovered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if dbs, err := client.ListDbs(); err != nil || len(dbs) != 1 {
+		t.Errorf("ListDbs = %v, %v after closing", dbs, err)
+	}
+	if state := client.State(); state != StateActive {
+		t.Errorf("connection %v after closing", state)
+	}
+}
@@ -0,0 +1,226 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// DefaultBulkChunkSize is the number of rows inserted per transaction by a BulkLoader, unless set with SetChunkSize
+const DefaultBulkChunkSize = 500
+
+// BulkRow is a row inserted by a BulkLoader
+type BulkRow struct {
+	Table ID
+	// Name, if not empty, is the uuid-name of the row, other rows of the load refer to it with NamedUUID(Name)
+	// no matter they are inserted in the same transaction or not
+	Name ID
+	Row  map[ID]Value
+}
+
+// BulkProgress is the progress of a BulkLoader
+type BulkProgress struct {
+	// Inserted is the number of rows inserted so far out of Total
+	Inserted int
+	Total    int
+	// Transactions is the number of committed transactions
+	Transactions int
+}
+
+// BulkLoader inserts a large number of rows, e.g. to seed a fresh database, in transactions of a limited size.
+// References between rows are written as NamedUUIDs of the rows' names, references to rows inserted by
+// earlier transactions are replaced with the UUIDs of those rows.
+// A transaction holds at least the chunk size of rows, and is extended to hold the rows referred to by
+// its rows which come later in the load, so list a row of a non-root table before or right after the
+// row referring to it, or it's garbage collected at the end of its transaction.
+// If Load fails, the failed transaction is not applied and calling Load again resumes from it.
+type BulkLoader struct {
+	client *Client
+	db     ID
+	rows   []BulkRow
+
+	mu         sync.Mutex
+	chunkSize  int
+	onProgress func(BulkProgress)
+	names      map[ID]int
+	uuids      map[ID]UUID
+	progress   BulkProgress
+}
+
+// NewBulkLoader creates a BulkLoader inserting rows into database db in order
+func NewBulkLoader(client *Client, db ID, rows []BulkRow) *BulkLoader {
+	return &BulkLoader{
+		client:    client,
+		db:        db,
+		rows:      rows,
+		chunkSize: DefaultBulkChunkSize,
+		uuids:     make(map[ID]UUID),
+		progress:  BulkProgress{Total: len(rows)},
+	}
+}
+
+// SetChunkSize sets the number of rows inserted per transaction
+func (l *BulkLoader) SetChunkSize(size int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.chunkSize = size
+}
+
+// OnProgress registers fn to be called after each committed transaction
+func (l *BulkLoader) OnProgress(fn func(BulkProgress)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onProgress = fn
+}
+
+// Progress returns the progress of the load
+func (l *BulkLoader) Progress() BulkProgress {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.progress
+}
+
+// UUIDs returns the UUIDs of the named rows inserted so far
+func (l *BulkLoader) UUIDs() map[ID]UUID {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	uuids := make(map[ID]UUID, len(l.uuids))
+	for name, uuid := range l.uuids {
+		uuids[name] = uuid
+	}
+	return uuids
+}
+
+// Load inserts the rows not inserted yet, it returns on the first failed transaction
+func (l *BulkLoader) Load() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.names == nil {
+		names := make(map[ID]int)
+		for i, row := range l.rows {
+			if row.Name == "" {
+				continue
+			}
+			if _, ok := names[row.Name]; ok {
+				return fmt.Errorf("duplicate row name %s", row.Name)
+			}
+			names[row.Name] = i
+		}
+		l.names = names
+	}
+
+	for l.progress.Inserted < len(l.rows) {
+		start := l.progress.Inserted
+		ops, err := l.chunk(start)
+		if err != nil {
+			return err
+		}
+		result, err := l.client.Transact(l.db, ops...)
+		if err != nil {
+			return fmt.Errorf("failed to insert rows %d-%d: %v", start, start+len(ops)-1, err)
+		}
+		if len(result.Errors) != 0 {
+			return fmt.Errorf("failed to insert rows %d-%d: %v", start, start+len(ops)-1, result.Errors)
+		}
+		for i := range ops {
+			row := l.rows[start+i]
+			if row.Name == "" {
+				continue
+			}
+			insert, err := result.InsertAt(i)
+			if err != nil {
+				return fmt.Errorf("invalid result for row %s: %v", row.Name, err)
+			}
+			l.uuids[row.Name] = insert.UUID
+		}
+		l.progress.Inserted += len(ops)
+		l.progress.Transactions++
+		if l.onProgress != nil {
+			l.onProgress(l.progress)
+		}
+	}
+	return nil
+}
+
+// chunk returns the insert operations of the next transaction starting from row start
+func (l *BulkLoader) chunk(start int) ([]Operation, error) {
+	size := l.chunkSize
+	if size <= 0 {
+		size = DefaultBulkChunkSize
+	}
+	end := start + size
+	if end > len(l.rows) {
+		end = len(l.rows)
+	}
+
+	var ops []Operation
+	for i := start; i < end; i++ {
+		row, refs, err := l.resolveRow(l.rows[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range refs {
+			index, ok := l.names[name]
+			if !ok {
+				return nil, fmt.Errorf("row %d of table %s refers to unknown row %s", i, l.rows[i].Table, name)
+			}
+			// extend the chunk to the rows referred to which come later
+			if index >= end {
+				end = index + 1
+			}
+		}
+		ops = append(ops, &InsertOperation{Table: l.rows[i].Table, Row: row, UUIDName: l.rows[i].Name})
+	}
+	return ops, nil
+}
+
+// resolveRow replaces the NamedUUIDs of inserted rows in the columns of row with their UUIDs,
+// it returns the names of rows referred to which are not inserted yet
+func (l *BulkLoader) resolveRow(row BulkRow) (map[ID]interface{}, []ID, error) {
+	columns, refs, err := resolveColumns(row.Row, l.uuids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid row %s of table %s: %v", row.Name, row.Table, err)
+	}
+	return columns, refs, nil
+}
+
+// resolveColumns encodes row into JSON values and replaces the NamedUUIDs in uuids with their UUIDs,
+// it returns the names of the other NamedUUIDs
+func resolveColumns(row map[ID]Value, uuids map[ID]UUID) (map[ID]interface{}, []ID, error) {
+	bytes, err := json.Marshal(row)
+	if err != nil {
+		return nil, nil, err
+	}
+	var columns map[ID]interface{}
+	if err := json.Unmarshal(bytes, &columns); err != nil {
+		return nil, nil, err
+	}
+	var refs []ID
+	for column, value := range columns {
+		columns[column] = resolveNamedUUIDs(value, uuids, &refs)
+	}
+	return columns, refs, nil
+}
+
+// resolveNamedUUIDs replaces <named-uuid>s in a JSON value with the <uuid>s in uuids,
+// names not in uuids are appended to unresolved
+func resolveNamedUUIDs(v interface{}, uuids map[ID]UUID, unresolved *[]ID) interface{} {
+	array, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	if len(array) == 2 && array[0] == namedUUIDMagic {
+		if name, ok := array[1].(string); ok {
+			if uuid, ok := uuids[ID(name)]; ok {
+				return uuid
+			}
+			*unresolved = append(*unresolved, ID(name))
+			return array
+		}
+	}
+	resolved := make([]interface{}, len(array))
+	for i, element := range array {
+		resolved[i] = resolveNamedUUIDs(element, uuids, unresolved)
+	}
+	return resolved
+}
@@ -0,0 +1,89 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestBulkLoader(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	var transactions []string
+	fail := true
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		var ops []string
+		for _, param := range params[1:] {
+			ops = append(ops, string(param))
+		}
+		if len(transactions) == 1 && fail {
+			fail = false
+			return []interface{}{map[string]string{"error": "constraint violation", "details": "index conflict"}}, nil
+		}
+		transactions = append(transactions, strings.Join(ops, ","))
+		var results []interface{}
+		for i := range ops {
+			uuid := fmt.Sprintf("00000000-0000-0000-0000-%012d", len(transactions)*10+i)
+			results = append(results, map[string]interface{}{"uuid": []string{"uuid", uuid}})
+		}
+		return results, nil
+	})
+
+	rows := []BulkRow{
+		{Table: "Logical_Switch", Name: "ls0", Row: map[ID]Value{"name": "ls0", "ports": NamedUUID("lsp0")}},
+		{Table: "Logical_Router", Row: map[ID]Value{"name": "lr0"}},
+		{Table: "Logical_Switch_Port", Name: "lsp0", Row: map[ID]Value{"name": "lsp0"}},
+		{Table: "Logical_Switch", Row: map[ID]Value{"name": "ls1", "other_config": Map{Values: []MapPair{{"peer", NamedUUID("ls0")}}}}},
+	}
+	loader := NewBulkLoader(client, "OVN_Northbound", rows)
+	loader.SetChunkSize(2)
+	var progress []BulkProgress
+	loader.OnProgress(func(p BulkProgress) { progress = append(progress, p) })
+
+	if err := loader.Load(); err == nil {
+		t.Fatal("expect the second transaction to fail, but got nil")
+	}
+	if p := loader.Progress(); p.Inserted != 3 || p.Total != 4 || p.Transactions != 1 {
+		t.Errorf("Progress after failure = %+v", p)
+	}
+	if err := loader.Load(); err != nil {
+		t.Fatalf("resumed Load failed: %v", err)
+	}
+
+	want := []string{
+		`{"op":"insert","table":"Logical_Switch","row":{"name":"ls0","ports":["named-uuid","lsp0"]},"uuid-name":"ls0"},` +
+			`{"op":"insert","table":"Logical_Router","row":{"name":"lr0"}},` +
+			`{"op":"insert","table":"Logical_Switch_Port","row":{"name":"lsp0"},"uuid-name":"lsp0"}`,
+		`{"op":"insert","table":"Logical_Switch","row":{"name":"ls1","other_config":["map",[["peer",["uuid","00000000-0000-0000-0000-000000000010"]]]]}}`,
+	}
+	if len(transactions) != len(want) {
+		t.Fatalf("transactions = %v, want %v", transactions, want)
+	}
+	for i := range want {
+		if transactions[i] != want[i] {
+			t.Errorf("transaction %d = %s, want %s", i, transactions[i], want[i])
+		}
+	}
+	if len(progress) != 2 || progress[1].Inserted != 4 || progress[1].Transactions != 2 {
+		t.Errorf("progress = %+v", progress)
+	}
+	if uuids := loader.UUIDs(); len(uuids) != 2 || uuids["lsp0"] != "00000000-0000-0000-0000-000000000012" {
+		t.Errorf("UUIDs = %v", uuids)
+	}
+}
+
+func TestBulkLoaderUnknownName(t *testing.T) {
+	loader := NewBulkLoader(nil, "OVN_Northbound", []BulkRow{
+		{Table: "Logical_Switch", Row: map[ID]Value{"ports": NamedUUID("missing")}},
+	})
+	if err := loader.Load(); err == nil {
+		t.Error("expect error for reference to an unknown row, but got nil")
+	}
+}
@@ -0,0 +1,63 @@
+package ovsdb
+
+import (
+	"sort"
+)
+
+// LimitPolicy is what a ReadThroughCache does when the cached rows of a table exceed the size limit
+type LimitPolicy int
+
+// Supported LimitPolicies
+const (
+	// LimitDeny refuses to cache rows exceeding the limit, they are selected from the server on every call
+	LimitDeny LimitPolicy = iota
+	// LimitEvictLRU evicts the least recently used rows of the table until it's within the limit
+	LimitEvictLRU
+)
+
+// SetTableLimit limits the size of the cached rows of each table to maxBytes, the size of the JSON encoding
+// of the rows, so one huge table can't exhaust the memory of the program. Rows exceeding the limit are handled
+// by policy. A maxBytes of 0 or less, the default, removes the limit. The sizes of the cached tables are
+// reported by Stats.
+func (cache *ReadThroughCache) SetTableLimit(maxBytes int, policy LimitPolicy) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.maxBytes = maxBytes
+	cache.limitPolicy = policy
+	if maxBytes <= 0 {
+		return
+	}
+	for table := range cache.rows {
+		if policy == LimitEvictLRU {
+			cache.evict(table)
+		} else if cache.bytes[table] > maxBytes {
+			cache.stats.Denials += uint64(len(cache.rows[table]))
+			delete(cache.rows, table)
+			delete(cache.tables, table)
+			delete(cache.bytes, table)
+			cache.changed()
+		}
+	}
+}
+
+// evict drops the least recently used rows of table until it's within the size limit, cache.mu must be held
+func (cache *ReadThroughCache) evict(table ID) {
+	if cache.bytes[table] <= cache.maxBytes {
+		return
+	}
+	rows := cache.rows[table]
+	uuids := make([]UUID, 0, len(rows))
+	for uuid := range rows {
+		uuids = append(uuids, uuid)
+	}
+	sort.Slice(uuids, func(i, j int) bool { return rows[uuids[i]].used < rows[uuids[j]].used })
+	for _, uuid := range uuids {
+		if cache.bytes[table] <= cache.maxBytes {
+			break
+		}
+		cache.remove(table, uuid)
+		cache.stats.Evictions++
+	}
+	// the listing of the table is refreshed by the next List
+	delete(cache.tables, table)
+}
@@ -0,0 +1,70 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestReadThroughCacheLimit(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	uuids := []UUID{
+		"a0000000-0000-0000-0000-000000000001",
+		"a0000000-0000-0000-0000-000000000002",
+		"a0000000-0000-0000-0000-000000000003",
+	}
+	row := func(uuid UUID) map[string]interface{} {
+		return map[string]interface{}{"_uuid": []string{"uuid", string(uuid)}, "name": "br"}
+	}
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		var op struct {
+			Where [][]interface{} `json:"where"`
+		}
+		json.Unmarshal(params[1], &op)
+		if op.Where[0][1] == "==" {
+			uuid := UUID(op.Where[0][2].([]interface{})[1].(string))
+			return []interface{}{map[string]interface{}{"rows": []interface{}{row(uuid)}}}, nil
+		}
+		return []interface{}{map[string]interface{}{"rows": []interface{}{row(uuids[0]), row(uuids[1]), row(uuids[2])}}}, nil
+	})
+	rowSize := len(`{"_uuid":["uuid","a0000000-0000-0000-0000-000000000001"],"name":"br"}`)
+
+	cache := NewReadThroughCache(client, "Open_vSwitch", time.Hour)
+	cache.SetTableLimit(2*rowSize, LimitEvictLRU)
+	for _, uuid := range uuids {
+		if _, err := cache.Get("Bridge", uuid); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+	}
+	stats := cache.Stats()
+	if stats.Rows["Bridge"] != 2 || stats.Bytes["Bridge"] != 2*rowSize || stats.Evictions != 1 {
+		t.Errorf("Stats = %+v after LRU eviction", stats)
+	}
+	if _, ok := cache.rows["Bridge"][uuids[0]]; ok {
+		t.Error("least recently used row not evicted")
+	}
+
+	cache.SetTableLimit(2*rowSize, LimitDeny)
+	if rows, err := cache.List("Bridge"); err != nil || len(rows) != 3 {
+		t.Fatalf("List = %d rows, %v", len(rows), err)
+	}
+	stats = cache.Stats()
+	if stats.Rows["Bridge"] != 2 || stats.Denials != 3 {
+		t.Errorf("Stats = %+v after denial", stats)
+	}
+	if _, ok := cache.tables["Bridge"]; ok {
+		t.Error("denied table listed in the cache")
+	}
+
+	cache.SetTableLimit(rowSize, LimitDeny)
+	if stats := cache.Stats(); stats.Rows["Bridge"] != 0 || stats.Bytes["Bridge"] != 0 {
+		t.Errorf("Stats = %+v after lowering the limit", stats)
+	}
+}
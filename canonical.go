@@ -0,0 +1,104 @@
+package ovsdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// MarshalCanonical returns the canonical JSON encoding of v.
+// The output is deterministic for equal inputs: object members (e.g. columns of a Row) are sorted by name,
+// map pairs are sorted by key, set elements are sorted and one-element sets are encoded as the element.
+// It's intended for golden-file tests and reproducible transaction logs.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewCanonicalEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	// strip the newline appended by Encode
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+// CanonicalEncoder writes canonical JSON encoding of values to an output stream
+type CanonicalEncoder struct {
+	w      io.Writer
+	prefix string
+	indent string
+}
+
+// NewCanonicalEncoder returns a new CanonicalEncoder that writes to w
+func NewCanonicalEncoder(w io.Writer) *CanonicalEncoder {
+	return &CanonicalEncoder{w: w}
+}
+
+// SetIndent instructs the encoder to format each subsequent encoded value as if indented by json.Indent
+func (enc *CanonicalEncoder) SetIndent(prefix, indent string) {
+	enc.prefix = prefix
+	enc.indent = indent
+}
+
+// Encode writes the canonical JSON encoding of v to the stream, followed by a newline character
+func (enc *CanonicalEncoder) Encode(v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// decode into generic JSON values, numbers are kept as is
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return err
+	}
+	generic, err = canonicalJSON(generic)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(enc.w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(enc.prefix, enc.indent)
+	return encoder.Encode(generic)
+}
+
+// canonicalJSON walks a generic JSON value and canonicalizes every <set> and <map> in it.
+// JSON objects need no work here, since encoding/json marshals map keys in sorted order.
+func canonicalJSON(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, member := range value {
+			canonical, err := canonicalJSON(member)
+			if err != nil {
+				return nil, err
+			}
+			value[key] = canonical
+		}
+		return value, nil
+	case []interface{}:
+		if isSetOrMap(value) {
+			return CanonicalValue(value)
+		}
+		for i, element := range value {
+			canonical, err := canonicalJSON(element)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = canonical
+		}
+		return value, nil
+	}
+	return v, nil
+}
+
+// isSetOrMap returns true if array is the JSON encoding of a <set> or a <map>
+func isSetOrMap(array []interface{}) bool {
+	if len(array) != 2 {
+		return false
+	}
+	magic, ok := array[0].(string)
+	if !ok || (magic != setMagic && magic != mapMagic) {
+		return false
+	}
+	_, ok = array[1].([]interface{})
+	return ok
+}
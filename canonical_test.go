@@ -0,0 +1,61 @@
+package ovsdb
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	tests := []struct {
+		v       interface{}
+		jsonStr string
+	}{
+		{
+			v: map[ID]Value{
+				"name":         "br0",
+				"external_ids": Map{Values: []MapPair{{"b", "2"}, {"a", "1"}}},
+				"ports":        Set{Values: []Value{UUID("uuid2"), UUID("uuid1")}},
+				"datapath":     Set{Values: []Value{"system"}},
+			},
+			jsonStr: `{"datapath":"system","external_ids":["map",[["a","1"],["b","2"]]],"name":"br0","ports":["set",[["uuid","uuid1"],["uuid","uuid2"]]]}`,
+		},
+		{
+			v: []Operation{
+				&InsertOperation{
+					Table: "TestTable",
+					Row:   map[ID]Value{"TestColumn": Set{Values: []Value{3, 1, 2}}},
+				},
+				&MutateOperation{
+					Table:     "TestTable",
+					Where:     []Condition{{"TestColumn", FuncInc, Set{Values: []Value{"b", "a"}}}},
+					Mutations: []Mutation{{"TestColumn", MutatorPluEq, 1.5}},
+				},
+			},
+			jsonStr: `[{"op":"insert","row":{"TestColumn":["set",[1,2,3]]},"table":"TestTable"},{"mutations":[["TestColumn","+=",1.5]],"op":"mutate","table":"TestTable","where":[["TestColumn","includes",["set",["a","b"]]]]}]`,
+		},
+	}
+
+	for _, test := range tests {
+		bytes, err := MarshalCanonical(test.v)
+		if err != nil {
+			t.Errorf("MarshalCanonical(%+v) failed: %v", test.v, err)
+			continue
+		}
+		if string(bytes) != test.jsonStr {
+			t.Errorf("MarshalCanonical(%+v) = %s, want %s", test.v, bytes, test.jsonStr)
+		}
+	}
+}
+
+func TestCanonicalEncoderIndent(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := NewCanonicalEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(map[ID]Value{"b": 1, "a": Set{Values: []Value{"y", "x"}}}); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	want := "{\n  \"a\": [\n    \"set\",\n    [\n      \"x\",\n      \"y\"\n    ]\n  ],\n  \"b\": 1\n}\n"
+	if buf.String() != want {
+		t.Errorf("Encode got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,284 @@
+package ovsdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DeletePlan is a transaction removing a row together with the rows it owns, see PlanDelete.
+// It's meant to be reviewed before it's executed with Transact(db, plan.Operations...).
+type DeletePlan struct {
+	// Deletes are the deleted rows by table: the row to delete and its owned children
+	Deletes map[ID][]UUID
+	// Operations remove the references to deleted rows held by other rows, then delete the rows
+	Operations []Operation
+}
+
+// String summarizes the plan, one operation per line
+func (plan *DeletePlan) String() string {
+	var lines []string
+	for _, op := range plan.Operations {
+		switch op := op.(type) {
+		case *MutateOperation:
+			lines = append(lines, fmt.Sprintf("mutate %s %v: %v", op.Table, op.Where[0].Value, op.Mutations))
+		case *DeleteOperation:
+			lines = append(lines, fmt.Sprintf("delete %s %v", op.Table, op.Where[0].Value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PlanDelete plans the deletion of the row uuid of table in database db, see the PlanDelete function.
+// The plan is computed from a snapshot of all tables and may be outdated by the time it's executed.
+func (c *Client) PlanDelete(db, table ID, uuid UUID) (*DeletePlan, error) {
+	dbSchema, err := c.GetSchema(db)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.selectAllRows(db, dbSchema)
+	if err != nil {
+		return nil, err
+	}
+	return PlanDelete(dbSchema, rows, table, uuid)
+}
+
+// PlanDelete computes the transaction fully removing the row uuid of table and the rows it owns,
+// e.g. a Logical_Switch with its ports, from rows, the content of the database by table and UUID
+// (see BackupFormat).
+// A row of a non-root table is owned if all rows holding strong references to it are removed,
+// it would be garbage collected by the server anyway and is deleted explicitly for clarity.
+// Strong references to removed rows held by other rows are removed with mutations, it fails if
+// a reference can't be removed without violating the column's minimum number of elements.
+// Weak references are removed by the server and are left alone.
+func PlanDelete(dbSchema *DatabaseSchema, rows map[ID]map[UUID]map[ID]interface{}, table ID, uuid UUID) (*DeletePlan, error) {
+	if _, ok := rows[table][uuid]; !ok {
+		return nil, fmt.Errorf("row %s not found in table %s", uuid, table)
+	}
+	refs, err := findReferences(dbSchema, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// collect the owned children until no more row is found
+	deleted := map[rowKey]bool{{table, uuid}: true}
+	for changed := true; changed; {
+		changed = false
+		for _, ref := range refs {
+			if !ref.strong || !deleted[ref.from] || deleted[ref.to] {
+				continue
+			}
+			if toSchema := dbSchema.Tables[ref.to.table]; toSchema == nil || toSchema.IsRoot {
+				continue
+			}
+			if ownedBy(refs, ref.to, deleted) {
+				deleted[ref.to] = true
+				changed = true
+			}
+		}
+	}
+
+	plan := &DeletePlan{Deletes: make(map[ID][]UUID)}
+	mutations, err := removeReferences(dbSchema, rows, refs, deleted)
+	if err != nil {
+		return nil, err
+	}
+	plan.Operations = append(plan.Operations, mutations...)
+
+	var keys []rowKey
+	for key := range deleted {
+		keys = append(keys, key)
+	}
+	sortRowKeys(keys)
+	for _, key := range keys {
+		plan.Deletes[key.table] = append(plan.Deletes[key.table], key.uuid)
+		plan.Operations = append(plan.Operations, &DeleteOperation{
+			Table: key.table,
+			Where: []Condition{{"_uuid", FuncEq, key.uuid}},
+		})
+	}
+	return plan, nil
+}
+
+// rowKey identifies a row in a database
+type rowKey struct {
+	table ID
+	uuid  UUID
+}
+
+func sortRowKeys(keys []rowKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].table != keys[j].table {
+			return keys[i].table < keys[j].table
+		}
+		return keys[i].uuid < keys[j].uuid
+	})
+}
+
+// reference is a reference from a column of a row to another row
+type reference struct {
+	from   rowKey
+	column ID
+	to     rowKey
+	strong bool
+	// pair is the map pair holding the reference in a map column
+	pair *MapPair
+	// key is true if the reference is the key of pair
+	key bool
+}
+
+// findReferences returns all references between rows, in a stable order
+func findReferences(dbSchema *DatabaseSchema, rows map[ID]map[UUID]map[ID]interface{}) ([]reference, error) {
+	var refs []reference
+	var keys []rowKey
+	for table, tableRows := range rows {
+		for uuid := range tableRows {
+			keys = append(keys, rowKey{table, uuid})
+		}
+	}
+	sortRowKeys(keys)
+
+	for _, from := range keys {
+		tableSchema := dbSchema.Tables[from.table]
+		if tableSchema == nil {
+			continue
+		}
+		row := rows[from.table][from.uuid]
+		var columns []ID
+		for column := range row {
+			columns = append(columns, column)
+		}
+		sort.Slice(columns, func(i, j int) bool { return columns[i] < columns[j] })
+
+		for _, column := range columns {
+			columnSchema := tableSchema.Columns[column]
+			if columnSchema == nil {
+				continue
+			}
+			value, err := CanonicalValue(row[column])
+			if err != nil {
+				return nil, fmt.Errorf("column %s of row %s in table %s: %v", column, from.uuid, from.table, err)
+			}
+			keyType, valueType := columnSchema.Type.JSON.Key, columnSchema.Type.JSON.Value
+			switch v := value.(type) {
+			case Map:
+				for i := range v.Values {
+					pair := &v.Values[i]
+					if ref, ok := newReference(from, column, keyType, pair[0]); ok {
+						ref.pair, ref.key = pair, true
+						refs = append(refs, ref)
+					}
+					if ref, ok := newReference(from, column, valueType, pair[1]); ok {
+						ref.pair = pair
+						refs = append(refs, ref)
+					}
+				}
+			case Set:
+				for _, atom := range v.Values {
+					if ref, ok := newReference(from, column, keyType, atom); ok {
+						refs = append(refs, ref)
+					}
+				}
+			default:
+				if ref, ok := newReference(from, column, keyType, v); ok {
+					refs = append(refs, ref)
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+// newReference returns the reference held by atom if baseType refers to a table
+func newReference(from rowKey, column ID, baseType AtomicOrJSONBaseType, atom Atomic) (reference, bool) {
+	if baseType.IsAtomic || baseType.JSON.RefTable == "" {
+		return reference{}, false
+	}
+	uuid, ok := atom.(UUID)
+	if !ok {
+		return reference{}, false
+	}
+	return reference{
+		from:   from,
+		column: column,
+		to:     rowKey{baseType.JSON.RefTable, uuid},
+		strong: baseType.JSON.RefType != "weak",
+	}, true
+}
+
+// ownedBy returns true if all strong references to row come from deleted rows
+func ownedBy(refs []reference, row rowKey, deleted map[rowKey]bool) bool {
+	for _, ref := range refs {
+		if ref.strong && ref.to == row && !deleted[ref.from] {
+			return false
+		}
+	}
+	return true
+}
+
+// removeReferences returns the mutations removing strong references to deleted rows from the other rows
+func removeReferences(dbSchema *DatabaseSchema, rows map[ID]map[UUID]map[ID]interface{}, refs []reference, deleted map[rowKey]bool) ([]Operation, error) {
+	type columnKey struct {
+		row    rowKey
+		column ID
+	}
+	removed := make(map[columnKey][]reference)
+	var columnKeys []columnKey
+	for _, ref := range refs {
+		if !ref.strong || deleted[ref.from] || !deleted[ref.to] {
+			continue
+		}
+		key := columnKey{ref.from, ref.column}
+		if _, ok := removed[key]; !ok {
+			columnKeys = append(columnKeys, key)
+		}
+		removed[key] = append(removed[key], ref)
+	}
+
+	var ops []Operation
+	for _, key := range columnKeys {
+		columnSchema := dbSchema.Tables[key.row.table].Columns[key.column]
+		value, _ := CanonicalValue(rows[key.row.table][key.row.uuid][key.column])
+		var n int
+		switch v := value.(type) {
+		case Map:
+			n = len(v.Values)
+		case Set:
+			n = len(v.Values)
+		default:
+			n = 1
+		}
+
+		var mutation Mutation
+		if columnSchema.IsMap() {
+			// remove each pair holding a reference once
+			pairs := make(map[*MapPair]bool)
+			m := Map{}
+			for _, ref := range removed[key] {
+				if !pairs[ref.pair] {
+					pairs[ref.pair] = true
+					m.Values = append(m.Values, *ref.pair)
+				}
+			}
+			n -= len(m.Values)
+			mutation = Mutation{key.column, MutatorDelete, m}
+		} else {
+			set := Set{}
+			for _, ref := range removed[key] {
+				set.Values = append(set.Values, ref.to.uuid)
+			}
+			n -= len(set.Values)
+			mutation = Mutation{key.column, MutatorDelete, set}
+		}
+		if n < columnSchema.MinElements() {
+			return nil, fmt.Errorf("can't remove the reference to a deleted row from column %s of row %s in table %s: at least %d elements required",
+				key.column, key.row.uuid, key.row.table, columnSchema.MinElements())
+		}
+		ops = append(ops, &MutateOperation{
+			Table:     key.row.table,
+			Where:     []Condition{{"_uuid", FuncEq, key.row.uuid}},
+			Mutations: []Mutation{mutation},
+		})
+	}
+	return ops, nil
+}
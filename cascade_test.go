@@ -0,0 +1,126 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const cascadeSchema = `{
+	"name": "OVN_Northbound",
+	"version": "5.10.0",
+	"tables": {
+		"Logical_Switch": {
+			"columns": {
+				"name": {"type": "string"},
+				"ports": {"type": {"key": {"type": "uuid", "refTable": "Logical_Switch_Port"}, "min": 0, "max": "unlimited"}},
+				"acls": {"type": {"key": {"type": "uuid", "refTable": "ACL"}, "min": 0, "max": "unlimited"}},
+				"load_balancer": {"type": {"key": {"type": "uuid", "refTable": "Load_Balancer"}, "min": 0, "max": "unlimited"}}
+			},
+			"isRoot": true
+		},
+		"Logical_Switch_Port": {
+			"columns": {"name": {"type": "string"}}
+		},
+		"ACL": {
+			"columns": {"priority": {"type": "integer"}}
+		},
+		"Load_Balancer": {
+			"columns": {"name": {"type": "string"}},
+			"isRoot": true
+		},
+		"Port_Group": {
+			"columns": {
+				"ports": {"type": {"key": {"type": "uuid", "refTable": "Logical_Switch_Port", "refType": "weak"}, "min": 0, "max": "unlimited"}}
+			},
+			"isRoot": true
+		},
+		"Switch_Group": {
+			"columns": {
+				"switches": {"type": {"key": {"type": "string"}, "value": {"type": "uuid", "refTable": "Logical_Switch"}, "min": 0, "max": "unlimited"}},
+				"primary": {"type": {"key": {"type": "uuid", "refTable": "Logical_Switch"}}}
+			},
+			"isRoot": true
+		}
+	}
+}`
+
+const (
+	ls1  = "10000000-0000-0000-0000-000000000001"
+	ls2  = "10000000-0000-0000-0000-000000000002"
+	lsp1 = "20000000-0000-0000-0000-000000000001"
+	lsp2 = "20000000-0000-0000-0000-000000000002"
+	acl1 = "30000000-0000-0000-0000-000000000001"
+	acl2 = "30000000-0000-0000-0000-000000000002"
+	lb1  = "40000000-0000-0000-0000-000000000001"
+	pg1  = "50000000-0000-0000-0000-000000000001"
+	sg1  = "60000000-0000-0000-0000-000000000001"
+)
+
+func cascadeRows(t *testing.T, primary string) (*DatabaseSchema, map[ID]map[UUID]map[ID]interface{}) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(cascadeSchema), &dbSchema); err != nil {
+		t.Fatalf("json unmarshal schema failed: %v", err)
+	}
+	var rows map[ID]map[UUID]map[ID]interface{}
+	data := `{
+		"Logical_Switch": {
+			"` + ls1 + `": {"name": "ls1",
+				"ports": ["set", [["uuid", "` + lsp1 + `"], ["uuid", "` + lsp2 + `"]]],
+				"acls": ["set", [["uuid", "` + acl1 + `"], ["uuid", "` + acl2 + `"]]],
+				"load_balancer": ["uuid", "` + lb1 + `"]},
+			"` + ls2 + `": {"name": "ls2", "ports": ["set", []], "acls": ["uuid", "` + acl2 + `"], "load_balancer": ["set", []]}
+		},
+		"Logical_Switch_Port": {"` + lsp1 + `": {"name": "lsp1"}, "` + lsp2 + `": {"name": "lsp2"}},
+		"ACL": {"` + acl1 + `": {"priority": 1}, "` + acl2 + `": {"priority": 2}},
+		"Load_Balancer": {"` + lb1 + `": {"name": "lb1"}},
+		"Port_Group": {"` + pg1 + `": {"ports": ["uuid", "` + lsp1 + `"]}},
+		"Switch_Group": {"` + sg1 + `": {
+			"switches": ["map", [["a", ["uuid", "` + ls1 + `"]], ["b", ["uuid", "` + ls2 + `"]]]],
+			"primary": ["uuid", "` + primary + `"]}}
+	}`
+	if err := json.Unmarshal([]byte(data), &rows); err != nil {
+		t.Fatalf("json unmarshal rows failed: %v", err)
+	}
+	return &dbSchema, rows
+}
+
+func TestPlanDelete(t *testing.T) {
+	dbSchema, rows := cascadeRows(t, ls2)
+	plan, err := PlanDelete(dbSchema, rows, "Logical_Switch", ls1)
+	if err != nil {
+		t.Fatalf("PlanDelete failed: %v", err)
+	}
+	// the ports and the unshared ACL are owned, the load balancer is a root row
+	wantDeletes := map[ID][]UUID{
+		"ACL":                 {acl1},
+		"Logical_Switch":      {ls1},
+		"Logical_Switch_Port": {lsp1, lsp2},
+	}
+	if !reflect.DeepEqual(plan.Deletes, wantDeletes) {
+		t.Errorf("Deletes = %v, want %v", plan.Deletes, wantDeletes)
+	}
+
+	bytes, err := json.Marshal(plan.Operations)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	want := `[{"op":"mutate","table":"Switch_Group","where":[["_uuid","==",["uuid","` + sg1 + `"]]],` +
+		`"mutations":[["switches","delete",["map",[["a",["uuid","` + ls1 + `"]]]]]]},` +
+		`{"op":"delete","table":"ACL","where":[["_uuid","==",["uuid","` + acl1 + `"]]]},` +
+		`{"op":"delete","table":"Logical_Switch","where":[["_uuid","==",["uuid","` + ls1 + `"]]]},` +
+		`{"op":"delete","table":"Logical_Switch_Port","where":[["_uuid","==",["uuid","` + lsp1 + `"]]]},` +
+		`{"op":"delete","table":"Logical_Switch_Port","where":[["_uuid","==",["uuid","` + lsp2 + `"]]]}]`
+	if string(bytes) != want {
+		t.Errorf("Operations = %s, want %s", bytes, want)
+	}
+
+	// a required reference can't be removed
+	dbSchema, rows = cascadeRows(t, ls1)
+	if _, err := PlanDelete(dbSchema, rows, "Logical_Switch", ls1); err == nil {
+		t.Error("expect PlanDelete failed with a required reference, but got nil")
+	}
+	if _, err := PlanDelete(dbSchema, rows, "Logical_Switch", lsp1); err == nil {
+		t.Error("expect PlanDelete failed with an unknown row, but got nil")
+	}
+}
@@ -1,65 +1,229 @@
 package ovsdb
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/cenkalti/rpc2"
-	"github.com/cenkalti/rpc2/jsonrpc"
 )
 
 // Client is a OVSDB client
 type Client struct {
-	rpc     *rpc2.Client
+	// address and options are the configuration of the connection, connectMu protects rpc and codec,
+	// which are set once connected, the message size limits and the limit of pending notifications
+	address     string
+	options     dialOptions
+	connectMu   sync.Mutex
+	rpc         *rpc2.Client
+	codec       *codec
+	maxIncoming int
+	maxOutgoing int
+	maxPending  int
+	// schemas caches schemas needed by the client itself, it's protected by mu
 	schemas map[string]*DatabaseSchema
 	handler NotificationHandler
+
+	// mu protects the fields below
+	mu                      sync.Mutex
+	expectedSchemas         map[ID]ExpectedSchema
+	mismatchPolicy          SchemaMismatchPolicy
+	lockWatchers            map[ID]func(locked bool)
+	monitorWatchers         map[string]func(updates TableUpdates)
+	monitor2Watchers        map[string]func(updates TableUpdates2)
+	monitorCanceledWatchers map[string]func()
+	monitorSeq              int
+	interceptors            []Interceptor
+	chain                   CallFunc
+	transactHooks           []*registeredHook
+	policies                []OperationPolicy
+	updateFilters           []UpdateFilter
+	commentFunc             CommentFunc
+	stampFunc               StampFunc
+	databases               map[ID]bool
+	state                   ConnectionState
+	stateSubscribers        map[chan StateChange]bool
+	decodeErrorPolicy       DecodeErrorPolicy
+	onDecodeError           DecodeErrorFunc
+	sessionResetFuncs       []SessionResetFunc
+	sessionMonitors         map[string]Value
+	sessionLocks            map[ID]bool
+	// closed is closed with the change to StateClosed, closeErr is its cause,
+	// closing is set by Close so the connection closed by the client isn't reported as lost
+	closed   chan struct{}
+	closeErr error
+	closing  bool
+	// notifications runs the handlers of notifications of the connection in order
+	notifications *notificationQueue
 }
 
-// Dial create a ovsdb.Client and connect to OVSDB server at address
-func Dial(address string) (*Client, error) {
-	var conn net.Conn
-	var err error
+// Dial create a ovsdb.Client and connect to OVSDB server at address, which is "tcp:<host>:<port>",
+// "ssl:<host>:<port>" (see WithTLS) or "unix:<path>". If <host> is a name resolving to several addresses, they are dialed in parallel
+// with staggered starts (see WithAttemptDelay) and the first established connection is used.
+// Names are resolved on every call and never cached, so dialing again after a lost connection
+// follows DNS changes, e.g. a rescheduled ovn-central behind a Kubernetes service.
+// Connections are made with the dialer set by WithNetDialer, address is ignored if WithConn is used.
+func Dial(address string, opts ...DialOption) (*Client, error) {
+	client := New(address, opts...)
+	if err := client.Connect(context.Background()); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
 
-	segs := strings.SplitN(address, ":", 2)
-	switch segs[0] {
-	case "tcp":
-		conn, err = net.Dial("tcp", segs[1])
-	case "unix":
-		conn, err = net.Dial("unix", segs[1])
-	default:
-		return nil, fmt.Errorf("unknown protocol: %q", segs[0])
+// New create a ovsdb.Client for the OVSDB server at address like Dial, without connecting.
+// The client is in StateConnecting until it connects, with Connect or on the first RPC, so
+// dependency injection frameworks can wire clients at startup but control when network activity begins.
+func New(address string, opts ...DialOption) *Client {
+	var options dialOptions
+	for _, opt := range opts {
+		opt(&options)
 	}
+	client := newClient()
+	client.address = address
+	client.options = options
+	client.maxIncoming = options.maxIncoming
+	client.maxOutgoing = options.maxOutgoing
+	return client
+}
+
+// Connect connects a client created by New to the server, it does nothing if the client is connected.
+// If connecting fails, the client stays in StateConnecting and Connect can be called again.
+// RPCs connect the client on first use, Connect lets the caller choose when and handle errors early.
+func (c *Client) Connect(ctx context.Context) error {
+	_, err := c.connection(ctx)
+	return err
+}
+
+// connection returns the JSON-RPC client of the connection, connecting first if needed
+func (c *Client) connection(ctx context.Context) (*rpc2.Client, error) {
+	c.connectMu.Lock()
+	defer c.connectMu.Unlock()
+	if c.rpc != nil {
+		return c.rpc, nil
+	}
+	if c.State() == StateClosed {
+		return nil, errDisconnected
+	}
+	conn, err := c.options.dial(ctx, c.address)
 	if err != nil {
-		return nil, fmt.Errorf("failed to dial: %v", err)
+		return nil, err
+	}
+	c.start(conn)
+	return c.rpc, nil
+}
+
+// dial establishes a connection to the server at address like Dial
+func (options *dialOptions) dial(ctx context.Context, address string) (net.Conn, error) {
+	segs := strings.SplitN(address, ":", 2)
+	if segs[0] == "ssl" && options.tlsErr != nil {
+		return nil, fmt.Errorf("bad TLS configuration: %v", options.tlsErr)
 	}
+	conn := options.conn
+	if conn == nil {
+		dialer := options.dialer
+		if dialer == nil {
+			dialer = &net.Dialer{}
+		}
+		var err error
+		switch segs[0] {
+		case "tcp", "ssl":
+			var proxy *url.URL
+			if proxy, err = options.proxyFor(segs[1]); err != nil {
+				return nil, err
+			}
+			if proxy != nil {
+				conn, err = dialProxy(ctx, dialer, proxy, segs[1])
+			} else {
+				conn, err = dialTCP(ctx, dialer, segs[1], options.attemptDelay)
+			}
+		case "unix":
+			conn, err = dialer.DialContext(ctx, "unix", segs[1])
+		default:
+			return nil, fmt.Errorf("unknown protocol: %q", segs[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial: %v", err)
+		}
+	}
+	if err := options.setSockopts(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set socket options: %v", err)
+	}
+	if segs[0] == "ssl" {
+		tlsConn, err := options.handshake(ctx, conn, segs[1])
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake failed: %v", err)
+		}
+		conn = tlsConn
+	}
+	if options.preamble != nil {
+		if err := options.preamble(conn); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("connection preamble failed: %v", err)
+		}
+	}
+	return conn, nil
+}
+
+// NewClient create a ovsdb.Client over an established connection to OVSDB server,
+// e.g. a connection wrapped for testing
+func NewClient(conn io.ReadWriteCloser) *Client {
+	client := newClient()
+	client.connectMu.Lock()
+	client.start(conn)
+	client.connectMu.Unlock()
+	return client
+}
 
-	client := &Client{
-		rpc:     rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn)),
+// newClient creates a client without connection
+func newClient() *Client {
+	return &Client{
 		schemas: make(map[string]*DatabaseSchema),
 		handler: &defaultNotificationHandler,
+		state:   StateConnecting,
+		closed:  make(chan struct{}),
 	}
+}
+
+// start serves the JSON-RPC connection conn, c.connectMu must be held
+func (c *Client) start(conn io.ReadWriteCloser) {
+	c.codec = newCodec(conn)
+	c.codec.setMaxMessageSize(c.maxIncoming, c.maxOutgoing)
+	c.rpc = rpc2.NewClientWithCodec(c.codec)
 
 	// insert this client to clientsMap
 	clientsLock.Lock()
 	if clientsMap == nil {
 		clientsMap = make(map[*rpc2.Client]*Client)
 	}
-	clientsMap[client.rpc] = client
+	clientsMap[c.rpc] = c
 	clientsLock.Unlock()
 
+	// requests of the server are handled as they are read, so notifications are queued in order
+	c.rpc.SetBlocking(true)
+	c.notifications = newNotificationQueue(c.maxPending)
+	go c.notifications.run()
 	// handle "echo" request from ovsdb-server, otherwise connection will be closed by server
-	client.rpc.Handle("echo", echoHandler)
+	c.rpc.Handle("echo", echoHandler)
 	// register notification handlers
-	client.rpc.Handle("update", updateHandler)
-	client.rpc.Handle("locked", lockedHandler)
-	client.rpc.Handle("stolen", stolenHandler)
+	c.rpc.Handle("update", c.ordered(updateHandler))
+	c.rpc.Handle("update2", c.ordered(update2Handler))
+	c.rpc.Handle("monitor_canceled", c.ordered(monitorCanceledHandler))
+	c.rpc.Handle("locked", c.ordered(lockedHandler))
+	c.rpc.Handle("stolen", c.ordered(stolenHandler))
 
+	c.setState(StateActive, nil)
 	// start rpc handling thread
-	go client.rpc.Run()
-
-	return client, nil
+	go c.rpc.Run()
+	go c.trackDisconnect()
 }
 
 func echoHandler(client *rpc2.Client, args []interface{}, reply *[]interface{}) error {
@@ -67,32 +231,60 @@ func echoHandler(client *rpc2.Client, args []interface{}, reply *[]interface{})
 	return nil
 }
 
-// ListDbs list databases in the connected OVSDB server
+// Call invokes an arbitrary JSON-RPC method on the connection, e.g. vendor-specific or experimental
+// methods not wrapped by this package. params is sent as the "params" array if it's a []interface{},
+// otherwise as its only element. The "result" of the response is decoded into reply, unless reply is nil.
+// The call goes through the interceptors of the client like all other RPCs.
+// If ctx is done before the response arrives, Call returns ctx.Err() and reply must not be used.
+func (c *Client) Call(ctx context.Context, method string, params interface{}, reply interface{}) error {
+	return c.call(ctx, method, params, reply)
+}
+
+// ListDbs list databases in the connected OVSDB server, from the cache if CacheDatabases is used
 func (c *Client) ListDbs() ([]ID, error) {
+	if dbs, ok := c.cachedDatabases(); ok {
+		return dbs, nil
+	}
 	var dbs []ID
-	if err := c.rpc.Call("list_dbs", nil, &dbs); err != nil {
+	if err := c.call(context.Background(), "list_dbs", nil, &dbs); err != nil {
 		return nil, err
 	}
 	return dbs, nil
 }
 
-// GetSchema get the schema of a OVSDB database
+// GetSchema get the schema of a OVSDB database, it fails with ErrUnknownDatabase if there's no such database.
+// If an expected schema is registered for db with ExpectSchema, the schema is checked against it.
 func (c *Client) GetSchema(db ID) (*DatabaseSchema, error) {
 	var dbSchema DatabaseSchema
-	if err := c.rpc.Call("get_schema", db, &dbSchema); err != nil {
-		return nil, err
+	if err := c.call(context.Background(), "get_schema", db, &dbSchema); err != nil {
+		return nil, databaseError(err)
 	}
-	return &dbSchema, nil
+	return c.checkSchema(db, &dbSchema)
 }
 
 // Transact do operations as a transact on OVSDB
 // https://tools.ietf.org/html/rfc7047#section-4.1.3
 func (c *Client) Transact(db ID, ops ...Operation) (*TransactResult, error) {
 	var result TransactResult
+	err := c.transact(db, ops, &result, &result)
+	return &result, err
+}
+
+// transact sends ops as a transaction on db and decodes the response into reply,
+// result is the outcome of the transaction passed to transaction hooks, which reply fills in
+func (c *Client) transact(db ID, ops []Operation, reply interface{}, result *TransactResult) error {
 	// no operations supplied, return
 	if len(ops) == 0 {
-		return &result, nil
+		return nil
+	}
+	ops, err := c.stampInserts(db, ops)
+	if err != nil {
+		return err
+	}
+	if err := c.checkPolicies(db, ops); err != nil {
+		return err
 	}
+	ops = c.appendComment(db, ops)
 	// construct rpc call parameters
 	var params []interface{}
 	params = append(params, db)
@@ -100,14 +292,17 @@ func (c *Client) Transact(db ID, ops ...Operation) (*TransactResult, error) {
 		params = append(params, op)
 	}
 
-	err := c.rpc.Call("transact", params, &result)
-	return &result, err
+	start := time.Now()
+	err = databaseError(c.call(context.Background(), "transact", params, reply))
+	c.runTransactHooks(db, ops, result, time.Since(start), err)
+	return err
 }
 
 // TransactResult contains results for each operations in a transaction.
 // See https://tools.ietf.org/html/rfc7047#section-4.1.3 for detailed explaination of the result array.
 // For a failed operation, we decode the erorr message into ovsdb.Error, otherwise we keep the result
-// as a json.RawMessage for user to decode it as proper operation result type.
+// as a json.RawMessage for user to decode it as proper operation result type,
+// with DecodeAt or the typed accessors, e.g. InsertAt.
 type TransactResult struct {
 	// Results contain operations' result
 	Results []interface{}
@@ -146,10 +341,13 @@ func (tr *TransactResult) UnmarshalJSON(value []byte) error {
 			// the operation was not attempted because a prior operation failed
 			tr.Results = append(tr.Results, nil)
 		} else if _, ok := temp["error"]; ok {
-			// the operation completed with an error
+			// the operation completed with an error, ovsdb-server leaves out empty details,
+			// e.g. of a failed wait
+			errMsg, _ := temp["error"].(string)
+			details, _ := temp["details"].(string)
 			opError := &Error{
-				Err:     temp["error"].(string),
-				Details: temp["details"].(string),
+				Err:     errMsg,
+				Details: details,
 			}
 			tr.Errors = append(tr.Errors, opError)
 			tr.Results = append(tr.Results, opError)
@@ -162,7 +360,11 @@ func (tr *TransactResult) UnmarshalJSON(value []byte) error {
 	return nil
 }
 
-// SetNotificationHandler set handler as the notification handler
+// SetNotificationHandler set handler as the notification handler. Its methods are called one at a time,
+// in the order the server sent the notifications, and may call the client. The reply of Monitor is
+// not ordered with the notifications, the first updates may be delivered before Monitor returns.
+// Notifications have no response, the errors returned by handler are dropped. Notifications wait in
+// a queue while handler is busy, use SetMaxPendingNotifications to bound it.
 // FIXME: not thread-safe
 func (c *Client) SetNotificationHandler(handler NotificationHandler) {
 	c.handler = handler
@@ -171,13 +373,20 @@ func (c *Client) SetNotificationHandler(handler NotificationHandler) {
 // Monitor enables a client to replicate tables or subsets
 // of tables within an OVSDB database by requesting notifications of
 // changes to those tables and by receiving the complete initial state
-// of a table or a subset of a table
+// of a table or a subset of a table.
+// It fails with ErrUnknownDatabase if there's no database db, so does Transact.
 func (c *Client) Monitor(db ID, jsonValue Value, requests MonitorRequests) (TableUpdates, error) {
+	return c.monitor(context.Background(), db, jsonValue, requests)
+}
+
+// monitor is Monitor waiting for the response within ctx
+func (c *Client) monitor(ctx context.Context, db ID, jsonValue Value, requests MonitorRequests) (TableUpdates, error) {
 	var updates TableUpdates
 	params := []interface{}{db, jsonValue, requests}
-	if err := c.rpc.Call("monitor", params, &updates); err != nil {
-		return nil, err
+	if err := c.call(ctx, "monitor", params, &updates); err != nil {
+		return nil, databaseError(err)
 	}
+	c.trackMonitor(jsonValue, true)
 	return updates, nil
 }
 
@@ -208,15 +417,27 @@ const (
 
 // MonitorCancel cancels a previously issued monitor request
 func (c *Client) MonitorCancel(jsonValue Value) error {
-	return c.rpc.Call("monitor_cancel", []interface{}{jsonValue}, nil)
+	return c.monitorCancel(context.Background(), jsonValue)
+}
+
+// monitorCancel is MonitorCancel waiting for the response within ctx
+func (c *Client) monitorCancel(ctx context.Context, jsonValue Value) error {
+	if err := c.call(ctx, "monitor_cancel", []interface{}{jsonValue}, nil); err != nil {
+		return err
+	}
+	c.trackMonitor(jsonValue, false)
+	return nil
 }
 
 // Lock acquire a lock named lockID from OVSDB server
 func (c *Client) Lock(lockID ID) (bool, error) {
 	var result LockResult
-	if err := c.rpc.Call("lock", []interface{}{lockID}, &result); err != nil {
+	if err := c.call(context.Background(), "lock", []interface{}{lockID}, &result); err != nil {
 		return false, err
 	}
+	if result.Locked {
+		c.trackLock(lockID, true)
+	}
 	return result.Locked, nil
 }
 
@@ -228,10 +449,18 @@ type LockResult struct {
 // Steal acquire a lock named lockID from OVSDB server.
 // If there is an existing owner, it loses ownership.
 func (c *Client) Steal(lockID ID) error {
-	return c.rpc.Call("steal", []interface{}{lockID}, nil)
+	if err := c.call(context.Background(), "steal", []interface{}{lockID}, nil); err != nil {
+		return err
+	}
+	c.trackLock(lockID, true)
+	return nil
 }
 
 // Unlock release a lock named lockID
 func (c *Client) Unlock(lockID ID) error {
-	return c.rpc.Call("unlock", []interface{}{lockID}, nil)
+	if err := c.call(context.Background(), "unlock", []interface{}{lockID}, nil); err != nil {
+		return err
+	}
+	c.trackLock(lockID, false)
+	return nil
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	ovsdb "github.com/liwei/go-ovsdb"
+)
+
+func init() {
+	commands["apply"] = &command{
+		usage: "apply [-address <address>] [-format <format>] [-dry-run] -f <file> <db>",
+		short: "converge tables to the rows declared in a file",
+		run:   runApply,
+	}
+}
+
+// resources is the content of a file read by apply:
+//
+//	{"keys": {<table>: [<column>...], ...},
+//	 "owner": {"column": <column>, "key": <string>, "value": <string>},
+//	 "rows": [{"table": <table>, "name": <name>, "row": <row>}, ...]}
+//
+// The tables of keys and rows are managed, rows of managed tables not declared are deleted.
+// The key columns of a table default to its first index. Rows refer to each other with
+// ["named-uuid", <name>]. If owner is set, only rows tagged with it are deleted, see Reconciler.SetOwner.
+type resources struct {
+	Keys  map[ovsdb.ID][]ovsdb.ID `json:"keys"`
+	Owner *struct {
+		Column ovsdb.ID `json:"column"`
+		Key    string   `json:"key"`
+		Value  string   `json:"value"`
+	} `json:"owner"`
+	Rows []struct {
+		Table ovsdb.ID                 `json:"table"`
+		Name  ovsdb.ID                 `json:"name"`
+		Row   map[ovsdb.ID]interface{} `json:"row"`
+	} `json:"rows"`
+}
+
+func runApply(args []string) error {
+	var address string
+	flags := newFlagSet("apply", &address)
+	format := formatFlag(flags, formatTable)
+	file := flags.String("f", "", "JSON file declaring the rows, - for stdin")
+	dryRun := flags.Bool("dry-run", false, "print the changes without applying them")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *file == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	db := ovsdb.ID(flags.Arg(0))
+
+	var r io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	var declared resources
+	if err := json.NewDecoder(r).Decode(&declared); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", *file, err)
+	}
+	keys := make(map[ovsdb.ID][]ovsdb.ID)
+	for table, columns := range declared.Keys {
+		keys[table] = columns
+	}
+	var desired []ovsdb.DesiredRow
+	for _, row := range declared.Rows {
+		if _, ok := keys[row.Table]; !ok {
+			keys[row.Table] = nil
+		}
+		columns := make(map[ovsdb.ID]ovsdb.Value, len(row.Row))
+		for column, value := range row.Row {
+			columns[column] = value
+		}
+		desired = append(desired, ovsdb.DesiredRow{Table: row.Table, Name: row.Name, Row: columns})
+	}
+
+	client, err := dial(address)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	dbSchema, err := client.GetSchema(db)
+	if err != nil {
+		return err
+	}
+	reconciler, err := ovsdb.NewReconciler(dbSchema, keys)
+	if err != nil {
+		return err
+	}
+	if owner := declared.Owner; owner != nil {
+		if err := reconciler.SetOwner(owner.Column, owner.Key, owner.Value); err != nil {
+			return err
+		}
+	}
+
+	var plan *ovsdb.SyncPlan
+	if *dryRun {
+		plan, err = client.PlanSync(db, reconciler, desired)
+	} else {
+		plan, err = client.Sync(db, reconciler, desired)
+	}
+	if err != nil {
+		return err
+	}
+	return writeValue(os.Stdout, *format, plan, func(w io.Writer) error {
+		_, err := io.WriteString(w, plan.String())
+		return err
+	})
+}
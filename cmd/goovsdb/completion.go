@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func init() {
+	commands["completion"] = &command{
+		usage: "completion bash|zsh",
+		short: "print a shell completion script, e.g. source <(goovsdb completion bash)",
+		run:   runCompletion,
+	}
+}
+
+// bashCompletion completes commands, and databases and tables fetched from the server
+// at $GOOVSDB_ADDRESS, or the default address
+const bashCompletion = `_goovsdb() {
+	local cur=${COMP_WORDS[COMP_CWORD]} args=() i
+	for ((i = 2; i < COMP_CWORD; i++)); do
+		case ${COMP_WORDS[i]} in
+		-address|-format|-f|-private-key|-certificate|-ca-cert) ((i++)) ;;
+		-*) ;;
+		*) args+=("${COMP_WORDS[i]}") ;;
+		esac
+	done
+	if ((COMP_CWORD == 1)); then
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		return
+	fi
+	case ${COMP_WORDS[COMP_CWORD-1]} in
+	-format) COMPREPLY=($(compgen -W "json yaml table" -- "$cur")); return ;;
+	-address) return ;;
+	-f|-private-key|-certificate|-ca-cert) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	esac
+	case ${COMP_WORDS[1]} in
+	list-tables|dump|watch|shell|apply)
+		if ((${#args[@]} == 0)); then
+			COMPREPLY=($(compgen -W "$(goovsdb list-dbs 2>/dev/null)" -- "$cur"))
+		elif ((${#args[@]} == 1)) && [[ ${COMP_WORDS[1]} == dump ]]; then
+			COMPREPLY=($(compgen -W "$(goovsdb list-tables "${args[0]}" 2>/dev/null)" -- "$cur"))
+		fi
+		;;
+	completion) COMPREPLY=($(compgen -W "bash zsh" -- "$cur")) ;;
+	esac
+}
+complete -F _goovsdb goovsdb
+`
+
+func runCompletion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: goovsdb %s", commands["completion"].usage)
+	}
+	script := fmt.Sprintf(bashCompletion, commandNames())
+	switch args[0] {
+	case "bash":
+	case "zsh":
+		// zsh runs bash completion functions with bashcompinit
+		script = "autoload -U +X bashcompinit && bashcompinit\n" + script
+	default:
+		return fmt.Errorf("unknown shell %q: must be bash or zsh", args[0])
+	}
+	_, err := os.Stdout.WriteString(script)
+	return err
+}
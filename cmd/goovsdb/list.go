@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	ovsdb "github.com/liwei/go-ovsdb"
+)
+
+func init() {
+	commands["list-dbs"] = &command{
+		usage: "list-dbs [-address <address>] [-format <format>]",
+		short: "list the databases of the server",
+		run:   runListDbs,
+	}
+	commands["list-tables"] = &command{
+		usage: "list-tables [-address <address>] [-format <format>] <db>",
+		short: "list the tables of a database",
+		run:   runListTables,
+	}
+	commands["dump"] = &command{
+		usage: "dump [-address <address>] [-format <format>] <db> <table> [<column>...]",
+		short: "print the rows of a table",
+		run:   runDump,
+	}
+}
+
+func runListDbs(args []string) error {
+	var address string
+	flags := newFlagSet("list-dbs", &address)
+	format := formatFlag(flags, formatTable)
+	flags.Parse(args)
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	client, err := dial(address)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	dbs, err := client.ListDbs()
+	if err != nil {
+		return err
+	}
+	return writeValue(os.Stdout, *format, dbs, func(w io.Writer) error {
+		for _, db := range dbs {
+			fmt.Fprintln(w, db)
+		}
+		return nil
+	})
+}
+
+func runListTables(args []string) error {
+	var address string
+	flags := newFlagSet("list-tables", &address)
+	format := formatFlag(flags, formatTable)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	client, err := dial(address)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	dbSchema, err := client.GetSchema(ovsdb.ID(flags.Arg(0)))
+	if err != nil {
+		return err
+	}
+	var tables []ovsdb.ID
+	for table := range dbSchema.Tables {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i] < tables[j] })
+	return writeValue(os.Stdout, *format, tables, func(w io.Writer) error {
+		for _, table := range tables {
+			fmt.Fprintln(w, table)
+		}
+		return nil
+	})
+}
+
+func runDump(args []string) error {
+	var address string
+	flags := newFlagSet("dump", &address)
+	format := formatFlag(flags, formatTable)
+	flags.Parse(args)
+	if flags.NArg() < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	client, err := dial(address)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	var columns []ovsdb.ID
+	for _, column := range flags.Args()[2:] {
+		columns = append(columns, ovsdb.ID(column))
+	}
+	dump, err := client.Dump(ovsdb.ID(flags.Arg(0)), ovsdb.ID(flags.Arg(1)), columns...)
+	if err != nil {
+		return err
+	}
+	rows := make([]map[ovsdb.ID]ovsdb.Value, 0, len(dump.Rows))
+	for _, values := range dump.Rows {
+		row := make(map[ovsdb.ID]ovsdb.Value, len(values))
+		for i, value := range values {
+			row[dump.Columns[i]] = value
+		}
+		rows = append(rows, row)
+	}
+	return writeValue(os.Stdout, *format, rows, dump.WriteText)
+}
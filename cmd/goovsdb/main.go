@@ -0,0 +1,97 @@
+// Command goovsdb is a command line client of OVSDB servers built on the go-ovsdb package.
+//
+// Usage:
+//
+//	goovsdb <command> [flags] [args]
+//
+// Run "goovsdb help" for the list of commands.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	ovsdb "github.com/liwei/go-ovsdb"
+)
+
+// DefaultAddress is the address of the local ovsdb-server of Open vSwitch
+const DefaultAddress = "unix:/var/run/openvswitch/db.sock"
+
+// command is a subcommand of goovsdb
+type command struct {
+	usage string
+	short string
+	run   func(args []string) error
+}
+
+var commands = map[string]*command{}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage()
+		return
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "goovsdb: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "goovsdb %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: goovsdb <command> [flags] [args]\n\nCommands:\n")
+	for _, name := range strings.Fields(commandNames()) {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].short)
+	}
+}
+
+// commandNames returns the names of all commands separated by spaces
+func commandNames() string {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// defaultAddress returns the address in $GOOVSDB_ADDRESS, or DefaultAddress
+func defaultAddress() string {
+	if address := os.Getenv("GOOVSDB_ADDRESS"); address != "" {
+		return address
+	}
+	return DefaultAddress
+}
+
+// tlsFiles are set by the TLS flags of the command, for ssl addresses
+var tlsFiles ovsdb.TLSFiles
+
+// newFlagSet creates the flag set of a command with the -address flag and the TLS flags
+func newFlagSet(name string, address *string) *flag.FlagSet {
+	flags := flag.NewFlagSet("goovsdb "+name, flag.ExitOnError)
+	flags.StringVar(address, "address", defaultAddress(), "OVSDB server address, $GOOVSDB_ADDRESS if set")
+	flags.StringVar(&tlsFiles.PrivateKey, "private-key", "", "private key `file` of ssl addresses")
+	flags.StringVar(&tlsFiles.Certificate, "certificate", "", "certificate `file` of ssl addresses")
+	flags.StringVar(&tlsFiles.CACert, "ca-cert", "", "CA certificate `file` verifying the server of ssl addresses")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: goovsdb %s\n\n", commands[name].usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// dial connects to the OVSDB server at address, with the TLS flags if it's an ssl address
+func dial(address string) (*ovsdb.Client, error) {
+	if strings.HasPrefix(address, "ssl:") {
+		return ovsdb.Dial(address, ovsdb.WithTLSFiles(tlsFiles))
+	}
+	return ovsdb.Dial(address)
+}
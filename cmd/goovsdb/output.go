@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Output formats of the -format flag
+const (
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatTable = "table"
+)
+
+// formatFlag adds the -format flag to flags
+func formatFlag(flags *flag.FlagSet, def string) *string {
+	return flags.String("format", def, "output format: json, yaml or table")
+}
+
+// checkFormat returns an error if format is unknown
+func checkFormat(format string) error {
+	switch format {
+	case formatJSON, formatYAML, formatTable:
+		return nil
+	}
+	return fmt.Errorf("unknown format %q: must be json, yaml or table", format)
+}
+
+// writeValue writes v as a JSON or YAML document, or calls table to write it as a table
+func writeValue(w io.Writer, format string, v interface{}, table func(w io.Writer) error) error {
+	switch format {
+	case formatJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case formatYAML:
+		// encode in JSON first to honor the JSON encoding of OVSDB values
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		writeYAML(&b, decoded, 0)
+		_, err = io.WriteString(w, b.String())
+		return err
+	}
+	return table(w)
+}
+
+// writeYAML writes a value decoded from JSON in YAML block style, strings are double-quoted
+func writeYAML(b *bytes.Buffer, v interface{}, indent int) {
+	prefix := strings.Repeat("  ", indent)
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			b.WriteString(prefix + "{}\n")
+			return
+		}
+		var keys []string
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			b.WriteString(prefix + yamlScalar(key) + ":")
+			writeYAMLChild(b, value[key], indent)
+		}
+	case []interface{}:
+		if len(value) == 0 {
+			b.WriteString(prefix + "[]\n")
+			return
+		}
+		for _, element := range value {
+			b.WriteString(prefix + "-")
+			writeYAMLChild(b, element, indent)
+		}
+	default:
+		b.WriteString(prefix + yamlScalar(value) + "\n")
+	}
+}
+
+// writeYAMLChild writes the value of a mapping key or sequence entry
+func writeYAMLChild(b *bytes.Buffer, v interface{}, indent int) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) != 0 {
+			b.WriteString("\n")
+			writeYAML(b, value, indent+1)
+			return
+		}
+		b.WriteString(" {}\n")
+	case []interface{}:
+		if len(value) != 0 {
+			b.WriteString("\n")
+			writeYAML(b, value, indent+1)
+			return
+		}
+		b.WriteString(" []\n")
+	default:
+		b.WriteString(" " + yamlScalar(value) + "\n")
+	}
+}
+
+// yamlScalar formats a scalar decoded from JSON, JSON strings are valid double-quoted YAML strings
+func yamlScalar(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	bytes, _ := json.Marshal(v)
+	return string(bytes)
+}
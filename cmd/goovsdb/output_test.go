@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteYAML(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+	}{
+		{`"br0"`, "\"br0\"\n"},
+		{`null`, "null\n"},
+		{`{}`, "{}\n"},
+		{`[]`, "[]\n"},
+		{`{"name":"br0","mtu":1500,"up":true}`, "\"mtu\": 1500\n\"name\": \"br0\"\n\"up\": true\n"},
+		{`["uuid","a0000000-0000-0000-0000-000000000000"]`, "- \"uuid\"\n- \"a0000000-0000-0000-0000-000000000000\"\n"},
+		{
+			`{"ports":["set",[]],"external_ids":{},"options":{"a":["x",1]}}`,
+			"\"external_ids\": {}\n\"options\":\n  \"a\":\n    - \"x\"\n    - 1\n\"ports\":\n  - \"set\"\n  - []\n",
+		},
+		{`[{"a":1},[]]`, "-\n  \"a\": 1\n- []\n"},
+	}
+	for _, test := range tests {
+		var v interface{}
+		if err := json.Unmarshal([]byte(test.json), &v); err != nil {
+			t.Fatalf("failed to decode %s: %v", test.json, err)
+		}
+		var b bytes.Buffer
+		writeYAML(&b, v, 0)
+		if b.String() != test.want {
+			t.Errorf("writeYAML(%s) = %q, want %q", test.json, b.String(), test.want)
+		}
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range []string{formatJSON, formatYAML, formatTable} {
+		if err := checkFormat(format); err != nil {
+			t.Errorf("checkFormat(%q) failed: %v", format, err)
+		}
+	}
+	if err := checkFormat("xml"); err == nil {
+		t.Error("expect error for format xml, but got nil")
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	ovsdb "github.com/liwei/go-ovsdb"
+)
+
+func init() {
+	commands["shell"] = &command{
+		usage: "shell [-address <address>] [-format <format>] <db>",
+		short: "run an interactive prompt to select and modify rows",
+		run:   runShell,
+	}
+}
+
+const shellHelp = `Commands:
+  select <table> [<column>...] [where <condition>...]
+  insert <table> <column>=<value>...
+  update <table> <column>=<value>... [where <condition>...]
+  delete <table> [where <condition>...]
+  begin               queue the following insert, update and delete commands
+  show                print the queued operations
+  commit              send the queued operations as one transaction
+  abort               drop the queued operations
+  tables              list the tables
+  columns <table>     list the columns of a table
+  help, quit
+Conditions are <column><op><value> with op ==, !=, <, <=, > or >=.
+Values are in the syntax of ovs-vsctl, e.g. {k=v} or [a, b], or JSON. End a line with ? to list the completions of its last word.
+`
+
+func runShell(args []string) error {
+	var address string
+	flags := newFlagSet("shell", &address)
+	format := formatFlag(flags, formatTable)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	client, err := dial(address)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	db := ovsdb.ID(flags.Arg(0))
+	dbSchema, err := client.GetSchema(db)
+	if err != nil {
+		return err
+	}
+	s := &shell{client: client, db: db, schema: dbSchema, out: os.Stdout, format: *format}
+	return s.run(os.Stdin)
+}
+
+// shell is an interactive session on a database
+type shell struct {
+	client *ovsdb.Client
+	db     ovsdb.ID
+	schema *ovsdb.DatabaseSchema
+	out    io.Writer
+	format string
+
+	// inTxn is true between begin and commit or abort, txn holds the queued operations
+	inTxn bool
+	txn   []ovsdb.Operation
+}
+
+// errQuit ends the session
+var errQuit = errors.New("quit")
+
+// run reads commands from in until it's closed or the quit command
+func (s *shell) run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		if s.inTxn {
+			fmt.Fprintf(s.out, "%s(%d)> ", s.db, len(s.txn))
+		} else {
+			fmt.Fprintf(s.out, "%s> ", s.db)
+		}
+		if !scanner.Scan() {
+			fmt.Fprintln(s.out)
+			return scanner.Err()
+		}
+		err := s.exec(scanner.Text())
+		if err == errQuit {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintf(s.out, "error: %v\n", err)
+		}
+	}
+}
+
+// exec runs a command line
+func (s *shell) exec(line string) error {
+	line = strings.TrimSpace(line)
+	if strings.HasSuffix(line, "?") {
+		words := tokenize(strings.TrimSuffix(line, "?"))
+		prefix := ""
+		if len(words) != 0 && !strings.HasSuffix(line, " ?") {
+			prefix, words = words[len(words)-1], words[:len(words)-1]
+		}
+		fmt.Fprintln(s.out, strings.Join(s.complete(words, prefix), " "))
+		return nil
+	}
+	words := tokenize(line)
+	if len(words) == 0 {
+		return nil
+	}
+
+	switch words[0] {
+	case "help":
+		io.WriteString(s.out, shellHelp)
+	case "quit", "exit":
+		return errQuit
+	case "tables":
+		fmt.Fprintln(s.out, strings.Join(s.tables(), " "))
+	case "columns":
+		if len(words) != 2 {
+			return errors.New("usage: columns <table>")
+		}
+		columns, err := s.columns(words[1])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(s.out, strings.Join(columns, " "))
+	case "begin":
+		if s.inTxn {
+			return errors.New("already in a transaction")
+		}
+		s.inTxn = true
+	case "show":
+		return writeValue(s.out, formatJSON, s.txn, nil)
+	case "abort":
+		s.inTxn, s.txn = false, nil
+	case "commit":
+		if !s.inTxn {
+			return errors.New("not in a transaction")
+		}
+		ops := s.txn
+		s.inTxn, s.txn = false, nil
+		return s.transact(ops...)
+	case "select", "insert", "update", "delete":
+		op, err := s.operation(words)
+		if err != nil {
+			return err
+		}
+		if s.inTxn && words[0] != "select" {
+			s.txn = append(s.txn, op)
+			return nil
+		}
+		return s.transact(op)
+	default:
+		return fmt.Errorf("unknown command %q, try help", words[0])
+	}
+	return nil
+}
+
+// operation builds the operation of a select, insert, update or delete command
+func (s *shell) operation(words []string) (ovsdb.Operation, error) {
+	if len(words) < 2 {
+		return nil, fmt.Errorf("usage: %s <table> ...", words[0])
+	}
+	table := ovsdb.ID(words[1])
+	tableSchema, ok := s.schema.Tables[table]
+	if !ok {
+		return nil, fmt.Errorf("unknown table %s", table)
+	}
+	args, conditions := words[2:], []string(nil)
+	for i, word := range args {
+		if word == "where" {
+			args, conditions = args[:i], args[i+1:]
+			break
+		}
+	}
+	where := ovsdb.MatchAll()
+	if len(conditions) != 0 {
+		where = nil
+		for _, condition := range conditions {
+			parsed, err := parseCondition(tableSchema, condition)
+			if err != nil {
+				return nil, err
+			}
+			where = append(where, parsed)
+		}
+	}
+
+	switch words[0] {
+	case "select":
+		var columns []ovsdb.ID
+		for _, column := range args {
+			columns = append(columns, ovsdb.ID(column))
+		}
+		return &ovsdb.SelectOperation{Table: table, Where: where, Columns: columns}, nil
+	case "delete":
+		if len(args) != 0 {
+			return nil, errors.New("usage: delete <table> [where <condition>...]")
+		}
+		return &ovsdb.DeleteOperation{Table: table, Where: where}, nil
+	}
+
+	row := make(map[ovsdb.ID]ovsdb.Value)
+	for _, assignment := range args {
+		i := strings.Index(assignment, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid assignment %q: must be <column>=<value>", assignment)
+		}
+		column := ovsdb.ID(assignment[:i])
+		value, err := parseShellValue(tableSchema, column, assignment[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		row[column] = value
+	}
+	if words[0] == "insert" {
+		if len(conditions) != 0 {
+			return nil, errors.New("usage: insert <table> <column>=<value>...")
+		}
+		return &ovsdb.InsertOperation{Table: table, Row: row}, nil
+	}
+	if len(row) == 0 {
+		return nil, errors.New("usage: update <table> <column>=<value>... [where <condition>...]")
+	}
+	return &ovsdb.UpdateOperation{Table: table, Where: where, Row: row}, nil
+}
+
+// transact sends ops in a transaction and prints the results
+func (s *shell) transact(ops ...ovsdb.Operation) error {
+	result, err := s.client.Transact(s.db, ops...)
+	if err != nil {
+		return err
+	}
+	for i, r := range result.Results {
+		if i >= len(ops) {
+			break
+		}
+		raw, ok := r.(json.RawMessage)
+		if !ok {
+			if r != nil {
+				fmt.Fprintf(s.out, "operation %d: %v\n", i, r)
+			}
+			continue
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return err
+		}
+		rows, isSelect := decoded["rows"].([]interface{})
+		var v interface{} = decoded
+		if isSelect {
+			v = rows
+		}
+		err := writeValue(s.out, s.format, v, func(w io.Writer) error {
+			if !isSelect {
+				for key, value := range decoded {
+					fmt.Fprintf(w, "%s: %s\n", key, ovsdb.FormatValue(value))
+				}
+				return nil
+			}
+			for _, row := range rows {
+				fmt.Fprintln(w, formatRow(row.(map[string]interface{})))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(result.Errors) != 0 {
+		return result.Errors
+	}
+	return nil
+}
+
+// formatRow formats a row as <column>=<value> pairs, sorted with _uuid first
+func formatRow(row map[string]interface{}) string {
+	var columns []string
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if (columns[i] == "_uuid") != (columns[j] == "_uuid") {
+			return columns[i] == "_uuid"
+		}
+		return columns[i] < columns[j]
+	})
+	pairs := make([]string, len(columns))
+	for i, column := range columns {
+		pairs[i] = column + "=" + ovsdb.FormatValue(row[column])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// conditionOps are the functions of conditions, the ones of two characters first so "<=" isn't taken for "<"
+var conditionOps = []ovsdb.Function{ovsdb.FuncEq, ovsdb.FuncNe, ovsdb.FuncLe, ovsdb.FuncGe, ovsdb.FuncLt, ovsdb.FuncGt}
+
+// parseCondition parses <column><op><value>, the condition is split at the leftmost operator
+// since the value may contain operators too
+func parseCondition(tableSchema *ovsdb.TableSchema, condition string) (ovsdb.Condition, error) {
+	for i := 1; i < len(condition); i++ {
+		for _, function := range conditionOps {
+			if !strings.HasPrefix(condition[i:], string(function)) {
+				continue
+			}
+			column := ovsdb.ID(condition[:i])
+			value, err := parseShellValue(tableSchema, column, condition[i+len(function):])
+			if err != nil {
+				return ovsdb.Condition{}, err
+			}
+			return ovsdb.Condition{Column: column, Function: function, Value: value}, nil
+		}
+	}
+	return ovsdb.Condition{}, fmt.Errorf("invalid condition %q: must be <column><op><value>", condition)
+}
+
+// parseShellValue parses the value of column in the syntax of ovs-vsctl (see ovsdb.ParseValue),
+// or in JSON if it starts with ["
+func parseShellValue(tableSchema *ovsdb.TableSchema, column ovsdb.ID, text string) (ovsdb.Value, error) {
+	if strings.HasPrefix(text, `["`) {
+		var value interface{}
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return nil, fmt.Errorf("invalid value %s: %v", text, err)
+		}
+		return value, nil
+	}
+	if column == "_uuid" {
+		return ovsdb.UUID(text), nil
+	}
+	columnSchema, ok := tableSchema.Columns[column]
+	if !ok {
+		return nil, fmt.Errorf("unknown column %s", column)
+	}
+	return ovsdb.ParseValue(columnSchema, text)
+}
+
+// tokenize splits a line into words separated by spaces outside of quotes, brackets and braces
+func tokenize(line string) []string {
+	var words []string
+	var word bytes.Buffer
+	depth, quoted, escaped := 0, false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[' || r == '{':
+			depth++
+		case r == ']' || r == '}':
+			depth--
+		case depth <= 0 && (r == ' ' || r == '\t'):
+			if word.Len() != 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			continue
+		}
+		word.WriteRune(r)
+	}
+	if word.Len() != 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// complete returns the completions of prefix following words
+func (s *shell) complete(words []string, prefix string) []string {
+	var candidates []string
+	switch {
+	case len(words) == 0:
+		candidates = strings.Fields("select insert update delete begin show commit abort tables columns help quit")
+	case len(words) == 1:
+		switch words[0] {
+		case "select", "insert", "update", "delete", "columns":
+			candidates = s.tables()
+		}
+	default:
+		switch words[0] {
+		case "select", "insert", "update", "delete":
+			candidates, _ = s.columns(words[1])
+			if words[0] != "insert" {
+				candidates = append(candidates, "where")
+			}
+		}
+	}
+	var completions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			completions = append(completions, candidate)
+		}
+	}
+	return completions
+}
+
+// tables returns the sorted tables of the database
+func (s *shell) tables() []string {
+	var tables []string
+	for table := range s.schema.Tables {
+		tables = append(tables, string(table))
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// columns returns the sorted columns of table
+func (s *shell) columns(table string) ([]string, error) {
+	tableSchema, ok := s.schema.Tables[ovsdb.ID(table)]
+	if !ok {
+		return nil, fmt.Errorf("unknown table %s", table)
+	}
+	var columns []string
+	for column := range tableSchema.Columns {
+		columns = append(columns, string(column))
+	}
+	sort.Strings(columns)
+	return columns, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	ovsdb "github.com/liwei/go-ovsdb"
+)
+
+const shellSchema = `{
+	"name": "Open_vSwitch",
+	"version": "8.0.0",
+	"tables": {
+		"Bridge": {
+			"columns": {
+				"name": {"type": "string"},
+				"external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+			}
+		},
+		"Interface": {
+			"columns": {
+				"name": {"type": "string"},
+				"mtu": {"type": {"key": "integer", "min": 0, "max": 1}}
+			}
+		}
+	}
+}`
+
+func testShell(t *testing.T) *shell {
+	var dbSchema ovsdb.DatabaseSchema
+	if err := json.Unmarshal([]byte(shellSchema), &dbSchema); err != nil {
+		t.Fatalf("failed to decode schema: %v", err)
+	}
+	return &shell{db: "Open_vSwitch", schema: &dbSchema, format: formatTable}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"  select\tBridge  name ", []string{"select", "Bridge", "name"}},
+		{`insert Bridge name="br 0"`, []string{"insert", "Bridge", `name="br 0"`}},
+		{`update Bridge external_ids={a=1, b=2} where name==br0`, []string{"update", "Bridge", "external_ids={a=1, b=2}", "where", "name==br0"}},
+		{`update Interface options=[a, [b, c]]`, []string{"update", "Interface", "options=[a, [b, c]]"}},
+		{`insert Bridge name="a \" b"`, []string{"insert", "Bridge", `name="a \" b"`}},
+	}
+	for _, test := range tests {
+		if got := tokenize(test.line); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", test.line, got, test.want)
+		}
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	s := testShell(t)
+	tests := []struct {
+		table     ovsdb.ID
+		condition string
+		want      ovsdb.Condition
+	}{
+		{"Bridge", "name==br0", ovsdb.Condition{Column: "name", Function: ovsdb.FuncEq, Value: "br0"}},
+		{"Bridge", "name!=br0", ovsdb.Condition{Column: "name", Function: ovsdb.FuncNe, Value: "br0"}},
+		{"Interface", "mtu<=1500", ovsdb.Condition{Column: "mtu", Function: ovsdb.FuncLe, Value: ovsdb.Set{Values: []ovsdb.Value{int64(1500)}}}},
+		{"Interface", "mtu>1500", ovsdb.Condition{Column: "mtu", Function: ovsdb.FuncGt, Value: ovsdb.Set{Values: []ovsdb.Value{int64(1500)}}}},
+		// the condition is split at the leftmost operator
+		{"Bridge", "name<a==b", ovsdb.Condition{Column: "name", Function: ovsdb.FuncLt, Value: "a==b"}},
+		{"Bridge", "name==a<b", ovsdb.Condition{Column: "name", Function: ovsdb.FuncEq, Value: "a<b"}},
+		{"Bridge", "_uuid==a0000000-0000-0000-0000-000000000000", ovsdb.Condition{Column: "_uuid", Function: ovsdb.FuncEq, Value: ovsdb.UUID("a0000000-0000-0000-0000-000000000000")}},
+	}
+	for _, test := range tests {
+		got, err := parseCondition(s.schema.Tables[test.table], test.condition)
+		if err != nil {
+			t.Errorf("parseCondition(%q) failed: %v", test.condition, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseCondition(%q) = %#v, want %#v", test.condition, got, test.want)
+		}
+	}
+
+	for _, condition := range []string{"name", "==br0", "unknown==x"} {
+		if _, err := parseCondition(s.schema.Tables["Bridge"], condition); err == nil {
+			t.Errorf("expect error for %q, but got nil", condition)
+		}
+	}
+}
+
+func TestShellComplete(t *testing.T) {
+	s := testShell(t)
+	tests := []struct {
+		words  []string
+		prefix string
+		want   []string
+	}{
+		{nil, "s", []string{"select", "show"}},
+		{nil, "x", nil},
+		{[]string{"select"}, "", []string{"Bridge", "Interface"}},
+		{[]string{"columns"}, "I", []string{"Interface"}},
+		{[]string{"begin"}, "", nil},
+		{[]string{"update", "Interface"}, "", []string{"mtu", "name", "where"}},
+		{[]string{"insert", "Bridge"}, "", []string{"external_ids", "name"}},
+		{[]string{"select", "Bridge", "name"}, "w", []string{"where"}},
+		{[]string{"select", "Unknown"}, "", []string{"where"}},
+	}
+	for _, test := range tests {
+		if got := s.complete(test.words, test.prefix); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("complete(%q, %q) = %q, want %q", test.words, test.prefix, got, test.want)
+		}
+	}
+}
+
+func TestShellOperation(t *testing.T) {
+	s := testShell(t)
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"select Bridge", `{"op":"select","table":"Bridge","where":[["_uuid","!=",["uuid","00000000-0000-0000-0000-000000000000"]]]}`},
+		{"select Bridge name where name==br0", `{"op":"select","table":"Bridge","where":[["name","==","br0"]],"columns":["name"]}`},
+		{"insert Bridge name=br0", `{"op":"insert","table":"Bridge","row":{"name":"br0"}}`},
+		{"update Interface mtu=1500 where name==eth0", `{"op":"update","table":"Interface","where":[["name","==","eth0"]],"row":{"mtu":1500}}`},
+		{"delete Bridge where name!=br0", `{"op":"delete","table":"Bridge","where":[["name","!=","br0"]]}`},
+	}
+	for _, test := range tests {
+		op, err := s.operation(tokenize(test.line))
+		if err != nil {
+			t.Errorf("operation of %q failed: %v", test.line, err)
+			continue
+		}
+		var got, want interface{}
+		data, err := json.Marshal(op)
+		if err != nil {
+			t.Errorf("operation of %q can't be encoded: %v", test.line, err)
+			continue
+		}
+		json.Unmarshal(data, &got)
+		json.Unmarshal([]byte(test.want), &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("operation of %q = %s, want %s", test.line, data, test.want)
+		}
+	}
+
+	for _, line := range []string{"select", "select Unknown", "delete Bridge name", "insert Bridge name=br0 where name==br1", "update Bridge where name==br0", "insert Bridge =br0"} {
+		if _, err := s.operation(tokenize(line)); err == nil {
+			t.Errorf("expect error for %q, but got nil", line)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	ovsdb "github.com/liwei/go-ovsdb"
+)
+
+func init() {
+	commands["watch"] = &command{
+		usage: "watch [-address <address>] [-format <format>] [-initial] <db> <filter>",
+		short: "print the changes of rows matching a filter, e.g. 'Logical_Switch_Port:name==lsp1'",
+		run:   runWatch,
+	}
+}
+
+func runWatch(args []string) error {
+	var address string
+	flags := newFlagSet("watch", &address)
+	initial := flags.Bool("initial", false, "print existing rows as inserts")
+	format := formatFlag(flags, formatJSON)
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	db := ovsdb.ID(flags.Arg(0))
+	filter, err := ovsdb.ParseEventFilter(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	client, err := dial(address)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	tables := []ovsdb.ID{filter.Table}
+	if filter.Table == "" {
+		dbSchema, err := client.GetSchema(db)
+		if err != nil {
+			return err
+		}
+		tables = tables[:0]
+		for table := range dbSchema.Tables {
+			tables = append(tables, table)
+		}
+	}
+
+	var sink ovsdb.ChangeSink = ovsdb.NewWriterSink(os.Stdout)
+	if *format != formatJSON {
+		sink = ovsdb.ChangeSinkFunc(func(events []ovsdb.ChangeEvent) error {
+			return writeEvents(os.Stdout, *format, events)
+		})
+	}
+	publish := func(updates ovsdb.TableUpdates) error {
+		var matched []ovsdb.ChangeEvent
+		for _, event := range ovsdb.ChangeEvents(db, updates) {
+			if filter.Match(event) {
+				matched = append(matched, event)
+			}
+		}
+		return sink.Publish(matched)
+	}
+	failed := make(chan error, 1)
+	// updates may be delivered before Monitor returns, they wait for the existing rows to be published
+	initialized := make(chan struct{})
+	client.SetNotificationHandler(&ovsdb.NotificationHandlerFuncs{
+		UpdateFunc: func(jsonValue ovsdb.Value, updates ovsdb.TableUpdates) error {
+			<-initialized
+			if err := publish(updates); err != nil {
+				select {
+				case failed <- err:
+				default:
+				}
+			}
+			return nil
+		},
+	})
+
+	requests := make(ovsdb.MonitorRequests)
+	for _, table := range tables {
+		requests[table] = ovsdb.MonitorRequest{Select: &ovsdb.MonitorSelect{
+			ovsdb.SelectInitial: *initial,
+			ovsdb.SelectInsert:  true,
+			ovsdb.SelectDelete:  true,
+			ovsdb.SelectModify:  true,
+		}}
+	}
+	updates, err := client.Monitor(db, "goovsdb-watch", requests)
+	if err == nil {
+		err = publish(updates)
+	}
+	close(initialized)
+	if err != nil {
+		return err
+	}
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	changes, cancel := client.StateChanges()
+	defer cancel()
+	for {
+		select {
+		case <-interrupted:
+			return nil
+		case err := <-failed:
+			return err
+		case change, ok := <-changes:
+			if !ok {
+				return errors.New("connection closed")
+			}
+			if change.To == ovsdb.StateClosed {
+				if change.Err != nil {
+					return change.Err
+				}
+				return errors.New("connection closed")
+			}
+		}
+	}
+}
+
+// writeEvents writes events as YAML documents or one table line per event
+func writeEvents(w io.Writer, format string, events []ovsdb.ChangeEvent) error {
+	for _, event := range events {
+		if format == formatYAML {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		err := writeValue(w, format, event, func(w io.Writer) error {
+			row := event.New
+			if row == nil {
+				row = event.Old
+			}
+			_, err := fmt.Fprintf(w, "%-6s %s %s %s\n", event.Op, event.Table, event.UUID, row)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	ovsdb "github.com/liwei/go-ovsdb"
+)
+
+func TestRunWatchArguments(t *testing.T) {
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"-format", "xml", "OVN_Northbound", "Logical_Switch"}, "unknown format"},
+		{[]string{"OVN_Northbound", "Bad-Table"}, "Bad-Table"},
+		{[]string{"-format", "yaml", "OVN_Northbound", "*:==x"}, "=="},
+	}
+	for _, test := range tests {
+		// invalid arguments are reported before connecting
+		err := runWatch(test.args)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("runWatch(%q) = %v, want error containing %q", test.args, err, test.err)
+		}
+	}
+}
+
+func TestWriteEvents(t *testing.T) {
+	events := []ovsdb.ChangeEvent{
+		{Database: "OVN_Northbound", Table: "Logical_Switch", UUID: "a0000000-0000-0000-0000-000000000000", Op: ovsdb.ChangeInsert, New: json.RawMessage(`{"name":"sw0"}`)},
+		{Database: "OVN_Northbound", Table: "Logical_Switch", UUID: "a0000000-0000-0000-0000-000000000001", Op: ovsdb.ChangeDelete, Old: json.RawMessage(`{"name":"sw1"}`), Revision: 2},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{formatTable, `insert Logical_Switch a0000000-0000-0000-0000-000000000000 {"name":"sw0"}
+delete Logical_Switch a0000000-0000-0000-0000-000000000001 {"name":"sw1"}
+`},
+		{formatYAML, `---
+"database": "OVN_Northbound"
+"new":
+  "name": "sw0"
+"op": "insert"
+"table": "Logical_Switch"
+"uuid": "a0000000-0000-0000-0000-000000000000"
+---
+"database": "OVN_Northbound"
+"old":
+  "name": "sw1"
+"op": "delete"
+"revision": 2
+"table": "Logical_Switch"
+"uuid": "a0000000-0000-0000-0000-000000000001"
+`},
+	}
+	for _, test := range tests {
+		var b bytes.Buffer
+		if err := writeEvents(&b, test.format, events); err != nil {
+			t.Fatalf("writeEvents in %s failed: %v", test.format, err)
+		}
+		if b.String() != test.want {
+			t.Errorf("writeEvents in %s:\n%s\nwant:\n%s", test.format, b.String(), test.want)
+		}
+	}
+}
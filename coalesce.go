@@ -0,0 +1,160 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// CoalescingHandler is a NotificationHandler which merges the updates of a monitor received within
+// a time window and delivers them to the wrapped handler as a single update, reducing reconcile churn
+// for handlers that only care about the eventual state of rows.
+// Successive updates of the same row are merged: "old" holds the columns as they were before the first
+// update and "new" the row after the last one, a row inserted and deleted within the window disappears.
+// The "old" of a merged modification may hold columns that didn't change in the end.
+// Locked and Stolen notifications are delivered to the wrapped handler immediately.
+type CoalescingHandler struct {
+	NotificationHandler
+
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*pendingUpdate
+}
+
+// pendingUpdate holds the merged updates of a monitor waiting for delivery
+type pendingUpdate struct {
+	jsonValue Value
+	updates   TableUpdates
+}
+
+// NewCoalescingHandler wraps handler into a CoalescingHandler, updates are delivered window after
+// the first update of a batch is received
+func NewCoalescingHandler(handler NotificationHandler, window time.Duration) *CoalescingHandler {
+	return &CoalescingHandler{
+		NotificationHandler: handler,
+		window:              window,
+		pending:             make(map[string]*pendingUpdate),
+	}
+}
+
+// Update implements NotificationHandler interface
+func (h *CoalescingHandler) Update(jsonValue Value, updates TableUpdates) error {
+	key, err := monitorKey(jsonValue)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	pending, ok := h.pending[key]
+	if !ok {
+		pending = &pendingUpdate{jsonValue: jsonValue, updates: make(TableUpdates)}
+		h.pending[key] = pending
+		time.AfterFunc(h.window, func() { h.flush(key) })
+	}
+	mergeTableUpdates(pending.updates, updates)
+	return nil
+}
+
+// MonitorCanceled implements MonitorCanceledHandler interface, updates of the canceled monitor
+// are delivered before the notification is forwarded to the wrapped handler
+func (h *CoalescingHandler) MonitorCanceled(jsonValue Value) error {
+	if key, err := monitorKey(jsonValue); err == nil {
+		h.flush(key)
+	}
+	if handler, ok := h.NotificationHandler.(MonitorCanceledHandler); ok {
+		return handler.MonitorCanceled(jsonValue)
+	}
+	return nil
+}
+
+// Flush delivers all pending updates now
+func (h *CoalescingHandler) Flush() {
+	h.mu.Lock()
+	var keys []string
+	for key := range h.pending {
+		keys = append(keys, key)
+	}
+	h.mu.Unlock()
+	for _, key := range keys {
+		h.flush(key)
+	}
+}
+
+// flush delivers the pending updates of a monitor
+func (h *CoalescingHandler) flush(key string) {
+	h.mu.Lock()
+	pending, ok := h.pending[key]
+	delete(h.pending, key)
+	h.mu.Unlock()
+	if !ok || len(pending.updates) == 0 {
+		return
+	}
+	if err := h.NotificationHandler.Update(pending.jsonValue, pending.updates); err != nil {
+		log.Printf("ovsdb: failed to handle coalesced update of monitor %v: %v", pending.jsonValue, err)
+	}
+}
+
+// monitorKey returns a comparable key of the <json-value> of a monitor
+func monitorKey(jsonValue Value) (string, error) {
+	key, err := json.Marshal(jsonValue)
+	if err != nil {
+		return "", fmt.Errorf("invalid monitor id %v: %v", jsonValue, err)
+	}
+	return string(key), nil
+}
+
+// mergeTableUpdates merges updates received later into merged
+func mergeTableUpdates(merged, updates TableUpdates) {
+	for table, tableUpdate := range updates {
+		mergedTable, ok := merged[table]
+		if !ok {
+			mergedTable = make(TableUpdate)
+			merged[table] = mergedTable
+		}
+		for uuid, rowUpdate := range tableUpdate {
+			previous, ok := mergedTable[uuid]
+			if !ok {
+				mergedTable[uuid] = rowUpdate
+				continue
+			}
+			if previous.Old == nil && rowUpdate.New == nil {
+				// inserted then deleted
+				delete(mergedTable, uuid)
+				continue
+			}
+			mergedTable[uuid] = RowUpdate{
+				Old: mergeOldRows(previous, rowUpdate),
+				New: rowUpdate.New,
+			}
+		}
+		if len(mergedTable) == 0 {
+			delete(merged, table)
+		}
+	}
+}
+
+// mergeOldRows returns the "old" of two successive updates of a row: the columns of the first "old",
+// plus the columns of the second "old" unchanged by the first update
+func mergeOldRows(first, second RowUpdate) *json.RawMessage {
+	if first.Old == nil || second.Old == nil {
+		// nothing to merge for an insert, or after a delete followed by an insert
+		return first.Old
+	}
+	var firstColumns, secondColumns map[ID]json.RawMessage
+	if json.Unmarshal(*first.Old, &firstColumns) != nil || json.Unmarshal(*second.Old, &secondColumns) != nil {
+		return first.Old
+	}
+	for column, value := range firstColumns {
+		secondColumns[column] = value
+	}
+	raw, err := json.Marshal(secondColumns)
+	if err != nil {
+		return first.Old
+	}
+	old := json.RawMessage(raw)
+	return &old
+}
@@ -0,0 +1,76 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func rawRow(s string) *json.RawMessage {
+	raw := json.RawMessage(s)
+	return &raw
+}
+
+func TestMergeTableUpdates(t *testing.T) {
+	merged := make(TableUpdates)
+	mergeTableUpdates(merged, TableUpdates{"Bridge": {
+		"a": {New: rawRow(`{"name":"a","stp_enable":false}`)},
+		"b": {Old: rawRow(`{"stp_enable":false}`), New: rawRow(`{"name":"b","stp_enable":true}`)},
+		"c": {Old: rawRow(`{"name":"c"}`)},
+	}})
+	mergeTableUpdates(merged, TableUpdates{"Bridge": {
+		"a": {},
+		"b": {Old: rawRow(`{"name":"b","stp_enable":true}`), New: rawRow(`{"name":"b2","stp_enable":true}`)},
+		"c": {New: rawRow(`{"name":"c2"}`)},
+	}})
+
+	bridges := merged["Bridge"]
+	if _, ok := bridges["a"]; ok {
+		t.Error("row inserted then deleted is still in the merged updates")
+	}
+	if got := string(*bridges["b"].Old); got != `{"name":"b","stp_enable":false}` {
+		t.Errorf("old of b = %s", got)
+	}
+	if got := string(*bridges["b"].New); got != `{"name":"b2","stp_enable":true}` {
+		t.Errorf("new of b = %s", got)
+	}
+	if bridges["c"].Old == nil || bridges["c"].New == nil {
+		t.Errorf("row deleted then inserted is not a modification: %v", bridges["c"])
+	}
+}
+
+func TestCoalescingHandler(t *testing.T) {
+	delivered := make(chan TableUpdates, 2)
+	handler := NewCoalescingHandler(&NotificationHandlerFuncs{
+		UpdateFunc: func(jsonValue Value, updates TableUpdates) error {
+			delivered <- updates
+			return nil
+		},
+	}, 20*time.Millisecond)
+
+	handler.Update("m", TableUpdates{"Bridge": {"a": {New: rawRow(`{"name":"a"}`)}}})
+	handler.Update("m", TableUpdates{"Bridge": {"a": {Old: rawRow(`{"name":"a"}`), New: rawRow(`{"name":"a2"}`)}}})
+	select {
+	case updates := <-delivered:
+		t.Fatalf("updates delivered before the window: %v", updates)
+	default:
+	}
+
+	select {
+	case updates := <-delivered:
+		rowUpdate := updates["Bridge"]["a"]
+		if rowUpdate.Old != nil || string(*rowUpdate.New) != `{"name":"a2"}` {
+			t.Errorf("coalesced update = %v", updates)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("updates not delivered after the window")
+	}
+
+	handler.Update("m", TableUpdates{"Bridge": {"b": {New: rawRow(`{"name":"b"}`)}}})
+	handler.Flush()
+	select {
+	case <-delivered:
+	default:
+		t.Error("Flush didn't deliver pending updates")
+	}
+}
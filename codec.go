@@ -0,0 +1,285 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/cenkalti/rpc2"
+)
+
+// codec is the JSON-RPC codec of connections to OVSDB servers. It's the codec of github.com/cenkalti/rpc2/jsonrpc,
+// except that it accepts any JSON value as the "error" of a response: ovsdb-server reports request errors,
+// e.g. of an unknown database, as <error> objects, on which the original codec fails and the connection breaks.
+// Such errors are turned into "<error>: <details>" strings, which ClassifyError understands.
+// It also enforces the message size limits set with Client.SetMaxMessageSize.
+type codec struct {
+	dec    *json.Decoder
+	enc    *json.Encoder
+	c      io.Closer
+	reader *limitedReader
+
+	// msg is the message being read
+	msg codecMessage
+	// params and result are of the message being read
+	params *json.RawMessage
+	result *json.RawMessage
+
+	// requests of the server have arbitrary JSON ids, rpc2 needs uint64 ones,
+	// seq and pending map the latter to the former
+	mu          sync.Mutex
+	seq         uint64
+	pending     map[uint64]*json.RawMessage
+	maxIncoming int
+	maxOutgoing int
+	// readErr is the error which ended reading, if it's not the end of the connection
+	readErr error
+}
+
+// codecMessage is a request, notification or response
+type codecMessage struct {
+	Method string           `json:"method"`
+	Params *json.RawMessage `json:"params"`
+	ID     *json.RawMessage `json:"id"`
+	Result *json.RawMessage `json:"result"`
+	Error  interface{}      `json:"error"`
+}
+
+// newCodec creates a codec on conn
+func newCodec(conn io.ReadWriteCloser) *codec {
+	reader := &limitedReader{r: conn, limit: -1}
+	return &codec{
+		dec:     json.NewDecoder(reader),
+		enc:     json.NewEncoder(conn),
+		c:       conn,
+		reader:  reader,
+		pending: make(map[uint64]*json.RawMessage),
+	}
+}
+
+// MessageSizeError is the error of a JSON-RPC message exceeding the size limit set with Client.SetMaxMessageSize
+type MessageSizeError struct {
+	// Incoming is true for a message of the server, false for a message of the client
+	Incoming bool
+	// Limit is the exceeded limit in bytes
+	Limit int
+}
+
+// Error implements error interface
+func (err *MessageSizeError) Error() string {
+	if err.Incoming {
+		return fmt.Sprintf("incoming message exceeds the size limit of %d bytes", err.Limit)
+	}
+	return fmt.Sprintf("outgoing message exceeds the size limit of %d bytes", err.Limit)
+}
+
+// limitedReader fails reads past an absolute offset in the stream, so a message exceeding the size limit
+// is never buffered whole
+type limitedReader struct {
+	r io.Reader
+	// offset is the number of bytes read, limit the offset reads fail at, -1 for no limit
+	offset int64
+	limit  int64
+	err    error
+}
+
+// Read implements io.Reader interface
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if lr.limit >= 0 {
+		if lr.offset >= lr.limit {
+			return 0, lr.err
+		}
+		if remaining := lr.limit - lr.offset; int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+	n, err := lr.r.Read(p)
+	lr.offset += int64(n)
+	return n, err
+}
+
+// setMaxMessageSize sets the size limits of messages, 0 or less for no limit
+func (c *codec) setMaxMessageSize(incoming, outgoing int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxIncoming = incoming
+	c.maxOutgoing = outgoing
+}
+
+// err returns the error which ended reading, if it's not the end of the connection
+func (c *codec) err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.readErr
+}
+
+// inputOffset returns the offset in the stream of the next message: the bytes read by the reader,
+// less those buffered by the decoder past the previous message
+func (c *codec) inputOffset() int64 {
+	buffered, _ := io.Copy(ioutil.Discard, c.dec.Buffered())
+	return c.reader.offset - buffered
+}
+
+// ReadHeader implements rpc2.Codec interface
+func (c *codec) ReadHeader(req *rpc2.Request, resp *rpc2.Response) error {
+	c.msg = codecMessage{}
+	c.mu.Lock()
+	maxIncoming := c.maxIncoming
+	c.mu.Unlock()
+	c.reader.limit = -1
+	if maxIncoming > 0 {
+		// the message starts at the input offset of the decoder, bytes past it may be buffered already
+		c.reader.limit = c.inputOffset() + int64(maxIncoming)
+		c.reader.err = &MessageSizeError{Incoming: true, Limit: maxIncoming}
+	}
+	if err := c.dec.Decode(&c.msg); err != nil {
+		if _, ok := err.(*MessageSizeError); ok {
+			c.mu.Lock()
+			c.readErr = err
+			c.mu.Unlock()
+		}
+		return err
+	}
+
+	if c.msg.Method != "" {
+		// a request or notification of the server
+		req.Method = c.msg.Method
+		c.params = c.msg.Params
+		if c.msg.ID != nil {
+			c.mu.Lock()
+			c.seq++
+			c.pending[c.seq] = c.msg.ID
+			req.Seq = c.seq
+			c.mu.Unlock()
+		}
+		return nil
+	}
+
+	// a response of the server
+	if c.msg.ID == nil {
+		return errors.New("response without id")
+	}
+	if err := json.Unmarshal(*c.msg.ID, &resp.Seq); err != nil {
+		return err
+	}
+	c.result = c.msg.Result
+	resp.Error = ""
+	if c.msg.Error != nil || c.msg.Result == nil {
+		resp.Error = responseError(c.msg.Error)
+	}
+	return nil
+}
+
+// responseError formats the "error" of a response
+func responseError(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		if value != "" {
+			return value
+		}
+	case map[string]interface{}:
+		// an <error> object
+		if err, ok := value["error"].(string); ok {
+			if details, ok := value["details"].(string); ok && details != "" {
+				return err + ": " + details
+			}
+			return err
+		}
+	}
+	if value == nil {
+		return "unspecified error"
+	}
+	bytes, _ := json.Marshal(value)
+	return string(bytes)
+}
+
+// ReadRequestBody implements rpc2.Codec interface
+func (c *codec) ReadRequestBody(x interface{}) error {
+	if x == nil {
+		return nil
+	}
+	if c.params == nil {
+		return errors.New("request without params")
+	}
+	params, ok := x.(*[]interface{})
+	if !ok {
+		params = &[]interface{}{x}
+	}
+	return json.Unmarshal(*c.params, params)
+}
+
+// ReadResponseBody implements rpc2.Codec interface
+func (c *codec) ReadResponseBody(x interface{}) error {
+	if x == nil {
+		return nil
+	}
+	return json.Unmarshal(*c.result, x)
+}
+
+// WriteRequest implements rpc2.Codec interface
+func (c *codec) WriteRequest(r *rpc2.Request, param interface{}) error {
+	req := struct {
+		Method string        `json:"method"`
+		Params []interface{} `json:"params"`
+		ID     *uint64       `json:"id"`
+	}{Method: r.Method}
+	if params, ok := param.([]interface{}); ok {
+		req.Params = params
+	} else {
+		req.Params = []interface{}{param}
+	}
+	if r.Seq != 0 {
+		seq := r.Seq
+		req.ID = &seq
+	}
+	return c.encode(req)
+}
+
+// WriteResponse implements rpc2.Codec interface
+func (c *codec) WriteResponse(r *rpc2.Response, x interface{}) error {
+	c.mu.Lock()
+	id, ok := c.pending[r.Seq]
+	delete(c.pending, r.Seq)
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("invalid sequence number %d in response", r.Seq)
+	}
+
+	resp := struct {
+		ID     *json.RawMessage `json:"id"`
+		Result interface{}      `json:"result"`
+		Error  interface{}      `json:"error"`
+	}{ID: id}
+	if r.Error == "" {
+		resp.Result = x
+	} else {
+		resp.Error = r.Error
+	}
+	return c.encode(resp)
+}
+
+// encode writes a message, unless it exceeds the size limit of outgoing messages
+func (c *codec) encode(message interface{}) error {
+	c.mu.Lock()
+	maxOutgoing := c.maxOutgoing
+	c.mu.Unlock()
+	if maxOutgoing <= 0 {
+		return c.enc.Encode(message)
+	}
+	bytes, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if len(bytes) > maxOutgoing {
+		return &MessageSizeError{Limit: maxOutgoing}
+	}
+	return c.enc.Encode(json.RawMessage(bytes))
+}
+
+// Close implements rpc2.Codec interface
+func (c *codec) Close() error {
+	return c.c.Close()
+}
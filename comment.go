@@ -0,0 +1,63 @@
+package ovsdb
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// CommentFunc returns the comment appended to a transaction on db, no comment is appended if it's empty
+type CommentFunc func(db ID, ops []Operation) string
+
+// SetCommentFunc makes the client append a CommentOperation with the comment returned by fn to every
+// transaction, so ovsdb-server logs attribute changes to the originating component.
+// The result of the comment operation is the last one in TransactResult.Results.
+// A nil fn turns it off.
+func (c *Client) SetCommentFunc(fn CommentFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commentFunc = fn
+}
+
+// CommentData is the data a comment template is executed with
+type CommentData struct {
+	// Component is the name of the component making the transaction
+	Component string
+	// Database of the transaction
+	Database ID
+	// Summary describes the operations by their types and tables, see SummarizeOperations
+	Summary string
+}
+
+// CommentTemplate returns a CommentFunc executing the text/template text with CommentData,
+// e.g. CommentTemplate("{{.Component}}: {{.Summary}}", "my-controller").
+// It panics if text is not a valid template.
+func CommentTemplate(text string, component string) CommentFunc {
+	tmpl := template.Must(template.New("comment").Parse(text))
+	return func(db ID, ops []Operation) string {
+		var buf bytes.Buffer
+		data := CommentData{
+			Component: component,
+			Database:  db,
+			Summary:   SummarizeOperations(ops),
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return ""
+		}
+		return buf.String()
+	}
+}
+
+// appendComment appends the comment operation to ops if a CommentFunc is set
+func (c *Client) appendComment(db ID, ops []Operation) []Operation {
+	c.mu.Lock()
+	fn := c.commentFunc
+	c.mu.Unlock()
+	if fn == nil {
+		return ops
+	}
+	comment := fn(db, ops)
+	if comment == "" {
+		return ops
+	}
+	return append(ops[:len(ops):len(ops)], &CommentOperation{Comment: comment})
+}
@@ -0,0 +1,46 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCommentOperation(t *testing.T) {
+	comment := &CommentOperation{}
+	if op := comment.Op(); op != OpComment {
+		t.Errorf("Op() returned %q, want %q", op, OpComment)
+	}
+	bytes, err := json.Marshal(CommentOperation{Comment: "TestComment"})
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	if want := `{"op":"comment","comment":"TestComment"}`; string(bytes) != want {
+		t.Errorf("json marshal got %s, want %s", bytes, want)
+	}
+}
+
+func TestAppendComment(t *testing.T) {
+	c := &Client{}
+	ops := []Operation{&InsertOperation{Table: "Bridge", Row: map[ID]Value{"name": "br0"}}}
+	if got := c.appendComment("Open_vSwitch", ops); len(got) != 1 {
+		t.Errorf("got %d operations without CommentFunc, want 1", len(got))
+	}
+
+	c.SetCommentFunc(CommentTemplate("{{.Component}} on {{.Database}}: {{.Summary}}", "test-controller"))
+	got := c.appendComment("Open_vSwitch", ops)
+	if len(got) != 2 || len(ops) != 1 {
+		t.Fatalf("got %d operations, want 2, and the original untouched", len(got))
+	}
+	comment, ok := got[1].(*CommentOperation)
+	if !ok {
+		t.Fatalf("last operation is %T, want *CommentOperation", got[1])
+	}
+	if want := "test-controller on Open_vSwitch: insert Bridge"; comment.Comment != want {
+		t.Errorf("comment = %q, want %q", comment.Comment, want)
+	}
+
+	c.SetCommentFunc(func(db ID, ops []Operation) string { return "" })
+	if got := c.appendComment("Open_vSwitch", ops); len(got) != 1 {
+		t.Errorf("got %d operations with empty comment, want 1", len(got))
+	}
+}
@@ -0,0 +1,133 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// validate returns an error describing why the condition is invalid, nil if it's valid
+func (c Condition) validate() error {
+	if err := validateID("column name", c.Column); err != nil {
+		return err
+	}
+	switch c.Function {
+	case FuncLt, FuncLe, FuncEq, FuncNe, FuncGt, FuncGe, FuncInc, FuncExc:
+		return nil
+	}
+	return fmt.Errorf("unknown function %q", c.Function)
+}
+
+// validateConditions checks the conditions of where, it's shared by operations and conditional monitors,
+// so a condition is rejected for the same reasons no matter it selects rows of a transaction or of a monitor
+func validateConditions(where []Condition) error {
+	for _, cond := range where {
+		if err := cond.validate(); err != nil {
+			return fmt.Errorf("Invalid condition: %v: %v", cond, err)
+		}
+	}
+	return nil
+}
+
+// ValidateConditions checks the conditions of where against the schema of the table they are applied to:
+// the columns must exist, "_uuid" and "_version" included, values must be of the types of the columns,
+// and "<", "<=", ">" and ">=" are only allowed on integer and real columns holding at most one value.
+// Conditions are checked the same way for operations and conditional monitors.
+func ValidateConditions(tableSchema *TableSchema, where []Condition) error {
+	if err := validateConditions(where); err != nil {
+		return err
+	}
+	for _, cond := range where {
+		if err := validateCondition(tableSchema, cond); err != nil {
+			return fmt.Errorf("Invalid condition: %v: %v", cond, err)
+		}
+	}
+	return nil
+}
+
+// validateCondition checks a valid condition against the schema of its table
+func validateCondition(tableSchema *TableSchema, cond Condition) error {
+	var columnSchema *ColumnSchema
+	switch cond.Column {
+	case "_uuid", "_version":
+		columnSchema = &ColumnSchema{Type: AtomicOrJSONColumnType{IsAtomic: true, Atomic: TypeUUID}}
+	default:
+		var ok bool
+		if columnSchema, ok = tableSchema.Columns[cond.Column]; !ok {
+			return fmt.Errorf("unknown column %s", cond.Column)
+		}
+	}
+	switch cond.Function {
+	case FuncLt, FuncLe, FuncGt, FuncGe:
+		keyType := columnSchema.KeyType()
+		if columnSchema.IsMap() || columnSchema.MaxElements() != 1 || keyType != TypeInteger && keyType != TypeReal {
+			return fmt.Errorf("function %s is only allowed on integer and real columns holding at most one value", cond.Function)
+		}
+	}
+	value, err := CanonicalValue(cond.Value)
+	if err != nil {
+		return err
+	}
+	return checkValueType(columnSchema, value)
+}
+
+// checkValueType returns an error if the canonical value doesn't fit the type of column
+func checkValueType(column *ColumnSchema, value Value) error {
+	if column.IsMap() {
+		m, ok := value.(Map)
+		if !ok {
+			return errNotMap
+		}
+		for _, pair := range m.Values {
+			if err := checkAtomType(column.KeyType(), pair[0]); err != nil {
+				return err
+			}
+			if err := checkAtomType(column.ValueType(), pair[1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, element := range setElements(value) {
+		if err := checkAtomType(column.KeyType(), element); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAtomType returns an error if the canonical atom is not of type atomicType
+func checkAtomType(atomicType AtomicType, atom Atomic) error {
+	ok := false
+	switch atom.(type) {
+	case int64:
+		// a real column accepts integers, they are indistinguishable in JSON
+		ok = atomicType == TypeInteger || atomicType == TypeReal
+	case float64:
+		ok = atomicType == TypeReal
+	case bool:
+		ok = atomicType == TypeBoolean
+	case string:
+		ok = atomicType == TypeString
+	case UUID, NamedUUID:
+		ok = atomicType == TypeUUID
+	}
+	if !ok {
+		bytes, _ := json.Marshal(atom)
+		return fmt.Errorf("%s is not of type %s", bytes, atomicType)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface
+func (r MonitorCondRequest) MarshalJSON() ([]byte, error) {
+	for _, column := range r.Columns {
+		if err := validateID("column name", column); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateConditions(r.Where); err != nil {
+		return nil, err
+	}
+	type alias MonitorCondRequest
+	return json.Marshal(alias(r))
+}
@@ -0,0 +1,79 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestConditionsParity(t *testing.T) {
+	tests := []struct {
+		where []Condition
+		valid bool
+	}{
+		{[]Condition{{"name", FuncEq, "ls1"}}, true},
+		{[]Condition{{"_uuid", FuncNe, UUID(zeroUUID)}, {"tag", FuncGe, 10}}, true},
+		{[]Condition{{"name", "~=", "ls1"}}, false},
+		{[]Condition{{"1name", FuncEq, "ls1"}}, false},
+	}
+	for _, test := range tests {
+		selectJSON, selectErr := json.Marshal(&SelectOperation{Table: "Logical_Switch", Where: test.where})
+		monitorJSON, monitorErr := json.Marshal(MonitorCondRequest{Where: test.where})
+		if (selectErr == nil) != test.valid || (monitorErr == nil) != test.valid {
+			t.Errorf("%v: select error %v, monitor error %v, want valid %v", test.where, selectErr, monitorErr, test.valid)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		var selectOp, monitorRequest struct {
+			Where json.RawMessage `json:"where"`
+		}
+		json.Unmarshal(selectJSON, &selectOp)
+		json.Unmarshal(monitorJSON, &monitorRequest)
+		if string(selectOp.Where) != string(monitorRequest.Where) {
+			t.Errorf("%v: select where %s, monitor where %s", test.where, selectOp.Where, monitorRequest.Where)
+		}
+	}
+}
+
+func TestValidateConditions(t *testing.T) {
+	var dbSchema DatabaseSchema
+	err := json.Unmarshal([]byte(`{"name": "OVN_Northbound", "version": "5.0.0", "tables": {
+		"Logical_Switch_Port": {"columns": {
+			"name": {"type": "string"},
+			"tag": {"type": {"key": {"type": "integer", "minInteger": 1, "maxInteger": 4095}, "min": 0, "max": 1}},
+			"addresses": {"type": {"key": "string", "min": 0, "max": "unlimited"}},
+			"options": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}}}}}`), &dbSchema)
+	if err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	tableSchema := dbSchema.Tables["Logical_Switch_Port"]
+
+	tests := []struct {
+		where []Condition
+		err   string
+	}{
+		{[]Condition{{"name", FuncEq, "lsp1"}, {"tag", FuncLt, 100}, {"_uuid", FuncNe, UUID(zeroUUID)}}, ""},
+		{[]Condition{{"addresses", FuncInc, []interface{}{"set", []interface{}{"router"}}}}, ""},
+		{[]Condition{{"options", FuncInc, Map{Values: []MapPair{{"router-port", "lrp1"}}}}}, ""},
+		{[]Condition{{"type", FuncEq, "router"}}, "unknown column type"},
+		{[]Condition{{"name", FuncGt, "lsp1"}}, "only allowed on integer and real columns"},
+		{[]Condition{{"addresses", FuncLe, "router"}}, "only allowed on integer and real columns"},
+		{[]Condition{{"tag", FuncEq, "10"}}, `"10" is not of type integer`},
+		{[]Condition{{"options", FuncEq, "lrp1"}}, "Not an OVSDB map"},
+		{[]Condition{{"name", "~=", "lsp1"}}, "unknown function"},
+	}
+	for _, test := range tests {
+		err := ValidateConditions(tableSchema, test.where)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("ValidateConditions(%v) failed: %v", test.where, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("ValidateConditions(%v) = %v, want error containing %q", test.where, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package ovsdb
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// conflictClasses are the errors counted by a ConflictTracker: failed waits of optimistic concurrency
+// and constraint violations, e.g. of an index, both caused by concurrent writers
+var conflictClasses = []*Error{ErrTimedOut, ErrConstraintViolation}
+
+// ConflictTracker counts the transactions failed by conflicts, by table and row, to find the contention
+// hotspots of a fleet of controllers, e.g. everyone mutating NB_Global. Install it with
+// client.AddTransactHook(tracker.Hook()), a tracker may be shared by several clients.
+type ConflictTracker struct {
+	mu     sync.Mutex
+	counts map[ConflictHotspot]uint64
+}
+
+// ConflictHotspot identifies where conflicts happen
+type ConflictHotspot struct {
+	Database ID
+	Table    ID
+	// Row is the row the failed operation was on, if its conditions select a row by "_uuid",
+	// empty otherwise and for conflicts detected at commit
+	Row UUID
+	// Class is the class of the error, "timed out" for failed waits or "constraint violation"
+	Class string
+}
+
+// ConflictCount is the number of conflicts of a hotspot
+type ConflictCount struct {
+	ConflictHotspot
+	Count uint64
+}
+
+// NewConflictTracker creates a ConflictTracker
+func NewConflictTracker() *ConflictTracker {
+	return &ConflictTracker{counts: make(map[ConflictHotspot]uint64)}
+}
+
+// Hook returns the TransactHook counting the conflicts of transactions
+func (t *ConflictTracker) Hook() TransactHook {
+	return func(db ID, ops []Operation, result *TransactResult, duration time.Duration, err error) {
+		if err != nil || result == nil {
+			return
+		}
+		for i, r := range result.Results {
+			opErr, ok := r.(*Error)
+			if !ok {
+				continue
+			}
+			class := conflictClass(opErr)
+			if class == nil {
+				continue
+			}
+			if i < len(ops) {
+				t.count(ConflictHotspot{db, OperationTable(ops[i]), operationRow(ops[i]), class.Err})
+				continue
+			}
+			// an error past the operations is detected at commit, it's on the written tables
+			for _, table := range writtenTables(ops) {
+				t.count(ConflictHotspot{db, table, "", class.Err})
+			}
+		}
+	}
+}
+
+// Report returns the n hotspots with the most conflicts, all if n <= 0, ordered by decreasing count
+func (t *ConflictTracker) Report(n int) []ConflictCount {
+	t.mu.Lock()
+	report := make([]ConflictCount, 0, len(t.counts))
+	for hotspot, count := range t.counts {
+		report = append(report, ConflictCount{hotspot, count})
+	}
+	t.mu.Unlock()
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return fmt.Sprint(a.ConflictHotspot) < fmt.Sprint(b.ConflictHotspot)
+	})
+	if n > 0 && len(report) > n {
+		report = report[:n]
+	}
+	return report
+}
+
+// Reset forgets all counted conflicts
+func (t *ConflictTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts = make(map[ConflictHotspot]uint64)
+}
+
+// WriteMetrics writes the conflicts by database, table and error class in the Prometheus text exposition format,
+// rows are left out to bound the number of series
+func (t *ConflictTracker) WriteMetrics(w io.Writer) error {
+	totals := make(map[ConflictHotspot]uint64)
+	for _, count := range t.Report(0) {
+		count.Row = ""
+		totals[count.ConflictHotspot] += count.Count
+	}
+	hotspots := make([]ConflictHotspot, 0, len(totals))
+	for hotspot := range totals {
+		hotspots = append(hotspots, hotspot)
+	}
+	sort.Slice(hotspots, func(i, j int) bool { return fmt.Sprint(hotspots[i]) < fmt.Sprint(hotspots[j]) })
+
+	if _, err := fmt.Fprint(w, `# HELP ovsdb_transaction_conflicts_total Operations failed by conflicts with other writers.
+# TYPE ovsdb_transaction_conflicts_total counter
+`); err != nil {
+		return err
+	}
+	for _, hotspot := range hotspots {
+		labels := formatLabels([]ID{"database", "table", "error"}, map[ID]string{
+			"database": string(hotspot.Database),
+			"table":    string(hotspot.Table),
+			"error":    hotspot.Class,
+		})
+		if _, err := fmt.Fprintf(w, "ovsdb_transaction_conflicts_total%s %d\n", labels, totals[hotspot]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count counts a conflict of hotspot
+func (t *ConflictTracker) count(hotspot ConflictHotspot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts[hotspot]++
+}
+
+// conflictClass returns the class of err if it's a conflict, nil otherwise
+func conflictClass(err *Error) *Error {
+	for _, class := range conflictClasses {
+		if err.Is(class) {
+			return class
+		}
+	}
+	return nil
+}
+
+// operationRow returns the row selected by the "_uuid" condition of op, empty if there's none
+func operationRow(op Operation) UUID {
+	where, _ := operationWhere(op)
+	for _, cond := range where {
+		if cond.Column != "_uuid" || cond.Function != FuncEq {
+			continue
+		}
+		switch uuid := cond.Value.(type) {
+		case UUID:
+			return uuid
+		case string:
+			return UUID(uuid)
+		}
+	}
+	return ""
+}
+
+// writtenTables returns the tables written by ops, in order
+func writtenTables(ops []Operation) []ID {
+	var tables []ID
+	seen := make(map[ID]bool)
+	for _, op := range ops {
+		switch op.Op() {
+		case OpInsert, OpUpdate, OpMutate, OpDelete:
+		default:
+			continue
+		}
+		if table := OperationTable(op); table != "" && !seen[table] {
+			seen[table] = true
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
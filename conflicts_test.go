@@ -0,0 +1,58 @@
+package ovsdb
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConflictTracker(t *testing.T) {
+	tracker := NewConflictTracker()
+	hook := tracker.Hook()
+	wait := &WaitOperation{Table: "NB_Global", Where: []Condition{{Column: "_uuid", Function: FuncEq, Value: UUID(ls1)}}, Until: WaitEqual}
+	mutate := &MutateOperation{Table: "NB_Global", Where: MatchAll(), Mutations: []Mutation{{"nb_cfg", MutatorPluEq, 1}}}
+	insert := &InsertOperation{Table: "Logical_Switch", Row: map[ID]Value{"name": "ls1"}}
+
+	timedOut := &TransactResult{Results: []interface{}{&Error{Err: ErrTimedOut.Err}, nil}}
+	hook("OVN_Northbound", []Operation{wait, mutate}, timedOut, time.Millisecond, nil)
+	hook("OVN_Northbound", []Operation{wait, mutate}, timedOut, time.Millisecond, nil)
+	// a constraint violation detected at commit is on the written tables
+	violation := &TransactResult{Results: []interface{}{nil, nil, &Error{Err: ErrConstraintViolation.Err}}}
+	hook("OVN_Northbound", []Operation{mutate, insert}, violation, time.Millisecond, nil)
+	// other errors and failed calls are not conflicts
+	hook("OVN_Northbound", []Operation{insert}, &TransactResult{Results: []interface{}{&Error{Err: ErrSyntaxError.Err}}}, time.Millisecond, nil)
+	hook("OVN_Northbound", []Operation{insert}, &TransactResult{}, time.Millisecond, errors.New("broken"))
+
+	want := []ConflictCount{
+		{ConflictHotspot{"OVN_Northbound", "NB_Global", UUID(ls1), ErrTimedOut.Err}, 2},
+		{ConflictHotspot{"OVN_Northbound", "Logical_Switch", "", ErrConstraintViolation.Err}, 1},
+		{ConflictHotspot{"OVN_Northbound", "NB_Global", "", ErrConstraintViolation.Err}, 1},
+	}
+	if report := tracker.Report(0); !reflect.DeepEqual(report, want) {
+		t.Errorf("Report = %+v, want %+v", report, want)
+	}
+	if report := tracker.Report(1); !reflect.DeepEqual(report, want[:1]) {
+		t.Errorf("Report(1) = %+v, want %+v", report, want[:1])
+	}
+
+	var buf bytes.Buffer
+	if err := tracker.WriteMetrics(&buf); err != nil {
+		t.Fatalf("WriteMetrics failed: %v", err)
+	}
+	wantMetrics := `# HELP ovsdb_transaction_conflicts_total Operations failed by conflicts with other writers.
+# TYPE ovsdb_transaction_conflicts_total counter
+ovsdb_transaction_conflicts_total{database="OVN_Northbound",table="Logical_Switch",error="constraint violation"} 1
+ovsdb_transaction_conflicts_total{database="OVN_Northbound",table="NB_Global",error="constraint violation"} 1
+ovsdb_transaction_conflicts_total{database="OVN_Northbound",table="NB_Global",error="timed out"} 2
+`
+	if buf.String() != wantMetrics {
+		t.Errorf("WriteMetrics wrote\n%s\nwant\n%s", buf.String(), wantMetrics)
+	}
+
+	tracker.Reset()
+	if report := tracker.Report(0); len(report) != 0 {
+		t.Errorf("Report after Reset = %+v", report)
+	}
+}
@@ -0,0 +1,235 @@
+package ovsdb
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Converter converts values of a custom Go type to and from OVSDB atoms,
+// e.g. net.IP to and from the string stored in an OVN column
+type Converter struct {
+	// ToAtom converts a value of the Go type into an atom
+	ToAtom func(v interface{}) (Atomic, error)
+	// FromAtom converts a canonical atom (see CanonicalValue) into a value of the Go type
+	FromAtom func(atom Atomic) (interface{}, error)
+}
+
+var (
+	convertersLock sync.RWMutex
+	converters     = make(map[reflect.Type]Converter)
+)
+
+// RegisterConverter registers conv as the converter of Go type typ, replacing any previous one.
+// Converters are used by ConvertToValue, ConvertFromValue and CanonicalValue.
+// Converters of net.IP, net.IPNet, net.HardwareAddr and time.Time are registered by default.
+func RegisterConverter(typ reflect.Type, conv Converter) {
+	convertersLock.Lock()
+	defer convertersLock.Unlock()
+	converters[typ] = conv
+}
+
+// lookupConverter returns the converter registered for typ
+func lookupConverter(typ reflect.Type) (Converter, bool) {
+	convertersLock.RLock()
+	defer convertersLock.RUnlock()
+	conv, ok := converters[typ]
+	return conv, ok
+}
+
+// ConvertToValue converts v into an OVSDB value with the registered converters:
+// a value of a registered type becomes an atom, a slice or array becomes a Set,
+// and a Go map becomes a Map, with their elements converted the same way.
+// Other values are returned as is.
+func ConvertToValue(v interface{}) (Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(v)
+	if conv, ok := lookupConverter(rv.Type()); ok {
+		return conv.ToAtom(v)
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		set := Set{Values: []Value{}}
+		for i := 0; i < rv.Len(); i++ {
+			atom, err := ConvertToValue(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			set.Values = append(set.Values, atom)
+		}
+		return set, nil
+	case reflect.Map:
+		m := Map{Values: []MapPair{}}
+		for _, key := range rv.MapKeys() {
+			k, err := ConvertToValue(key.Interface())
+			if err != nil {
+				return nil, err
+			}
+			value, err := ConvertToValue(rv.MapIndex(key).Interface())
+			if err != nil {
+				return nil, err
+			}
+			m.Values = append(m.Values, MapPair{k, value})
+		}
+		return m, nil
+	}
+	return v, nil
+}
+
+// ConvertFromValue converts an OVSDB value into dst, which must be a pointer.
+// Atoms are converted with the converter registered for the type dst points to, or assigned if they are
+// convertible to it; sets are converted into slices and maps into Go maps, element by element.
+// value may be in any form accepted by CanonicalValue, e.g. a column decoded from JSON.
+func ConvertFromValue(value Value, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("ConvertFromValue: non-pointer or nil destination %T", dst)
+	}
+	canonical, err := CanonicalValue(value)
+	if err != nil {
+		return err
+	}
+	return convertFromValue(canonical, rv.Elem())
+}
+
+// convertFromValue converts a canonical value into dst
+func convertFromValue(value Value, dst reflect.Value) error {
+	typ := dst.Type()
+	if _, ok := lookupConverter(typ); ok {
+		return convertFromAtom(value, dst)
+	}
+	switch typ.Kind() {
+	case reflect.Slice:
+		var values []Value
+		switch v := value.(type) {
+		case Set:
+			values = v.Values
+		default:
+			// a set with exactly one element
+			values = []Value{v}
+		}
+		slice := reflect.MakeSlice(typ, len(values), len(values))
+		for i, v := range values {
+			if err := convertFromAtom(v, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+	case reflect.Map:
+		m, ok := value.(Map)
+		if !ok {
+			return errNotMap
+		}
+		goMap := reflect.MakeMapWithSize(typ, len(m.Values))
+		for _, pair := range m.Values {
+			key := reflect.New(typ.Key()).Elem()
+			if err := convertFromAtom(pair[0], key); err != nil {
+				return err
+			}
+			elem := reflect.New(typ.Elem()).Elem()
+			if err := convertFromAtom(pair[1], elem); err != nil {
+				return err
+			}
+			goMap.SetMapIndex(key, elem)
+		}
+		dst.Set(goMap)
+		return nil
+	}
+	return convertFromAtom(value, dst)
+}
+
+// convertFromAtom converts a canonical atom into dst
+func convertFromAtom(atom Atomic, dst reflect.Value) error {
+	if conv, ok := lookupConverter(dst.Type()); ok {
+		v, err := conv.FromAtom(atom)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(v))
+		return nil
+	}
+	rv := reflect.ValueOf(atom)
+	if !rv.IsValid() || !rv.Type().ConvertibleTo(dst.Type()) {
+		return fmt.Errorf("can't convert %#v into %s", atom, dst.Type())
+	}
+	// reject conversions of numbers into strings
+	if dst.Kind() == reflect.String && rv.Kind() != reflect.String {
+		return fmt.Errorf("can't convert %#v into %s", atom, dst.Type())
+	}
+	dst.Set(rv.Convert(dst.Type()))
+	return nil
+}
+
+// stringAtom returns atom as a string, converters of string columns use it
+func stringAtom(atom Atomic) (string, error) {
+	s, ok := atom.(string)
+	if !ok {
+		return "", fmt.Errorf("Not a string atom: %#v", atom)
+	}
+	return s, nil
+}
+
+func init() {
+	RegisterConverter(reflect.TypeOf(net.IP{}), Converter{
+		ToAtom: func(v interface{}) (Atomic, error) {
+			return v.(net.IP).String(), nil
+		},
+		FromAtom: func(atom Atomic) (interface{}, error) {
+			s, err := stringAtom(atom)
+			if err != nil {
+				return nil, err
+			}
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", s)
+			}
+			return ip, nil
+		},
+	})
+	RegisterConverter(reflect.TypeOf(net.IPNet{}), Converter{
+		ToAtom: func(v interface{}) (Atomic, error) {
+			ipNet := v.(net.IPNet)
+			return ipNet.String(), nil
+		},
+		FromAtom: func(atom Atomic) (interface{}, error) {
+			s, err := stringAtom(atom)
+			if err != nil {
+				return nil, err
+			}
+			_, ipNet, err := net.ParseCIDR(s)
+			if err != nil {
+				return nil, err
+			}
+			return *ipNet, nil
+		},
+	})
+	RegisterConverter(reflect.TypeOf(net.HardwareAddr{}), Converter{
+		ToAtom: func(v interface{}) (Atomic, error) {
+			return v.(net.HardwareAddr).String(), nil
+		},
+		FromAtom: func(atom Atomic) (interface{}, error) {
+			s, err := stringAtom(atom)
+			if err != nil {
+				return nil, err
+			}
+			return net.ParseMAC(s)
+		},
+	})
+	RegisterConverter(reflect.TypeOf(time.Time{}), Converter{
+		ToAtom: func(v interface{}) (Atomic, error) {
+			return v.(time.Time).Format(time.RFC3339Nano), nil
+		},
+		FromAtom: func(atom Atomic) (interface{}, error) {
+			s, err := stringAtom(atom)
+			if err != nil {
+				return nil, err
+			}
+			return time.Parse(time.RFC3339Nano, s)
+		},
+	})
+}
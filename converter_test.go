@@ -0,0 +1,85 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConvertToValue(t *testing.T) {
+	mac, _ := net.ParseMAC("0a:00:00:00:00:01")
+	now := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		v    interface{}
+		want Value
+	}{
+		{net.ParseIP("10.0.0.1"), "10.0.0.1"},
+		{mac, "0a:00:00:00:00:01"},
+		{now, "2018-01-02T03:04:05Z"},
+		{[]net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("::1")}, Set{Values: []Value{"10.0.0.1", "::1"}}},
+		{map[string]net.IP{"gw": net.ParseIP("10.0.0.1")}, Map{Values: []MapPair{{"gw", "10.0.0.1"}}}},
+		{"plain", "plain"},
+		{42, 42},
+	}
+	for _, test := range tests {
+		got, err := ConvertToValue(test.v)
+		if err != nil {
+			t.Errorf("ConvertToValue(%v) failed: %v", test.v, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ConvertToValue(%v) = %#v, want %#v", test.v, got, test.want)
+		}
+	}
+
+	// registered types are canonicalized by their converters
+	if !ValueEqual(net.ParseIP("10.0.0.1"), "10.0.0.1") {
+		t.Error("ValueEqual(net.IP, string) = false, want true")
+	}
+}
+
+func TestConvertFromValue(t *testing.T) {
+	var ip net.IP
+	if err := ConvertFromValue("10.0.0.1", &ip); err != nil || !ip.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("ConvertFromValue into net.IP = %v, %v", ip, err)
+	}
+	if err := ConvertFromValue("not-an-ip", &ip); err == nil {
+		t.Error("expect ConvertFromValue failed with invalid IP, but got nil")
+	}
+
+	var cidr net.IPNet
+	if err := ConvertFromValue("10.0.0.0/24", &cidr); err != nil || cidr.String() != "10.0.0.0/24" {
+		t.Errorf("ConvertFromValue into net.IPNet = %v, %v", cidr, err)
+	}
+
+	var macs []net.HardwareAddr
+	raw := json.RawMessage(`["set",["0a:00:00:00:00:02","0a:00:00:00:00:01"]]`)
+	if err := ConvertFromValue(raw, &macs); err != nil {
+		t.Fatalf("ConvertFromValue into []net.HardwareAddr failed: %v", err)
+	}
+	if len(macs) != 2 || macs[0].String() != "0a:00:00:00:00:01" {
+		t.Errorf("ConvertFromValue into []net.HardwareAddr = %v", macs)
+	}
+
+	// a one-element set is its atom
+	var single []net.IP
+	if err := ConvertFromValue("::1", &single); err != nil || len(single) != 1 {
+		t.Errorf("ConvertFromValue into []net.IP = %v, %v", single, err)
+	}
+
+	var ids map[string]int
+	raw = json.RawMessage(`["map",[["a",1],["b",2]]]`)
+	if err := ConvertFromValue(raw, &ids); err != nil || !reflect.DeepEqual(ids, map[string]int{"a": 1, "b": 2}) {
+		t.Errorf("ConvertFromValue into map[string]int = %v, %v", ids, err)
+	}
+
+	var s string
+	if err := ConvertFromValue(1, &s); err == nil {
+		t.Error("expect ConvertFromValue of a number into string failed, but got nil")
+	}
+	if err := ConvertFromValue("x", s); err == nil {
+		t.Error("expect ConvertFromValue into non-pointer failed, but got nil")
+	}
+}
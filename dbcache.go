@@ -0,0 +1,77 @@
+package ovsdb
+
+import (
+	"sort"
+)
+
+// CacheDatabases makes ListDbs and HasDatabase answer from a cache of the databases of the server,
+// kept up to date with WatchDatabases, instead of asking the server on every call, e.g. for tools
+// repeatedly probing for optional databases. The returned stop function stops caching.
+func (c *Client) CacheDatabases() (stop func() error, err error) {
+	stopWatch, err := c.WatchDatabases(func(added, removed []ID) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.databases == nil {
+			c.databases = make(map[ID]bool)
+		}
+		for _, db := range added {
+			c.databases[db] = true
+		}
+		for _, db := range removed {
+			delete(c.databases, db)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.databases == nil {
+		// the server has no database
+		c.databases = make(map[ID]bool)
+	}
+	c.mu.Unlock()
+	stop = func() error {
+		err := stopWatch()
+		c.mu.Lock()
+		c.databases = nil
+		c.mu.Unlock()
+		return err
+	}
+	return stop, nil
+}
+
+// HasDatabase returns true if the server has database db
+func (c *Client) HasDatabase(db ID) (bool, error) {
+	c.mu.Lock()
+	databases := c.databases
+	has := databases[db]
+	c.mu.Unlock()
+	if databases != nil {
+		return has, nil
+	}
+	dbs, err := c.ListDbs()
+	if err != nil {
+		return false, err
+	}
+	for _, name := range dbs {
+		if name == db {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// cachedDatabases returns the databases cached by CacheDatabases, ok is false if they are not cached
+func (c *Client) cachedDatabases() (dbs []ID, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.databases == nil {
+		return nil, false
+	}
+	dbs = make([]ID, 0, len(c.databases))
+	for db := range c.databases {
+		dbs = append(dbs, db)
+	}
+	sort.Slice(dbs, func(i, j int) bool { return dbs[i] < dbs[j] })
+	return dbs, true
+}
@@ -0,0 +1,88 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestCacheDatabases(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	var mu sync.Mutex
+	listed := 0
+	server.Handle("list_dbs", func(params []json.RawMessage) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		listed++
+		return []string{"_Server", "OVN_Southbound"}, nil
+	})
+	server.Handle("set_db_change_aware", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+	var monitorID string
+	server.Handle("monitor", func(params []json.RawMessage) (interface{}, error) {
+		json.Unmarshal(params[1], &monitorID)
+		return map[string]interface{}{
+			"Database": map[string]interface{}{
+				"d0000000-0000-0000-0000-000000000000": map[string]interface{}{"new": map[string]interface{}{"name": "_Server"}},
+				"d0000000-0000-0000-0000-000000000001": map[string]interface{}{"new": map[string]interface{}{"name": "OVN_Southbound"}},
+			},
+		}, nil
+	})
+	server.Handle("monitor_cancel", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+
+	if has, err := client.HasDatabase("OVN_Northbound"); err != nil || has {
+		t.Errorf("HasDatabase = %v, %v without cache", has, err)
+	}
+	stop, err := client.CacheDatabases()
+	if err != nil {
+		t.Fatalf("CacheDatabases failed: %v", err)
+	}
+	dbs, err := client.ListDbs()
+	if err != nil || !reflect.DeepEqual(dbs, []ID{"OVN_Southbound", "_Server"}) {
+		t.Errorf("ListDbs = %v, %v", dbs, err)
+	}
+
+	server.Notify("update", monitorID, map[string]interface{}{
+		"Database": map[string]interface{}{
+			"d0000000-0000-0000-0000-000000000002": map[string]interface{}{"new": map[string]interface{}{"name": "OVN_Northbound"}},
+		},
+	})
+	deadline := time.Now().Add(time.Second)
+	for {
+		has, err := client.HasDatabase("OVN_Northbound")
+		if err != nil {
+			t.Fatalf("HasDatabase failed: %v", err)
+		}
+		if has {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("added database not seen")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	if listed != 1 {
+		t.Errorf("list_dbs called %d times, want once before caching", listed)
+	}
+	mu.Unlock()
+
+	if err := stop(); err != nil {
+		t.Errorf("stop failed: %v", err)
+	}
+	if has, _ := client.HasDatabase("OVN_Northbound"); has {
+		t.Error("HasDatabase should ask the server after stop")
+	}
+}
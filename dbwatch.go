@@ -0,0 +1,117 @@
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// the database of ovsdb-server describing the databases it serves
+const (
+	serverDatabase = "_Server"
+	databaseTable  = "Database"
+)
+
+// DatabasesChangedFunc is invoked with the names of databases added to or removed from the server
+type DatabasesChangedFunc func(added, removed []ID)
+
+// WatchDatabases tells the server the client is aware of database changes ("set_db_change_aware"),
+// and monitors the _Server database to invoke changed when databases are added or removed,
+// e.g. when ovsdb-server serves OVN_Northbound and OVN_Southbound on one socket and one is added later.
+// changed is first invoked with all existing databases as added. The returned stop function
+// cancels the watch. The server must support the _Server database (OVS 2.9+).
+func (c *Client) WatchDatabases(changed DatabasesChangedFunc) (stop func() error, err error) {
+	if err := c.call(context.Background(), "set_db_change_aware", []interface{}{true}, nil); err != nil {
+		return nil, err
+	}
+
+	watcher := &databaseWatcher{
+		changed:   changed,
+		databases: make(map[UUID]ID),
+	}
+	monitorID := c.watchMonitor(watcher.update)
+	initial, err := c.Monitor(serverDatabase, monitorID, MonitorRequests{
+		databaseTable: MonitorRequest{Columns: []ID{"name"}},
+	})
+	if err != nil {
+		c.unwatchMonitor(monitorID)
+		return nil, err
+	}
+	watcher.initialize(initial)
+
+	stop = func() error {
+		c.unwatchMonitor(monitorID)
+		return c.MonitorCancel(monitorID)
+	}
+	return stop, nil
+}
+
+// databaseWatcher tracks the databases of the server from updates of the _Server Database table
+type databaseWatcher struct {
+	changed DatabasesChangedFunc
+
+	// updates are delivered in order, but may arrive before the initial contents are applied,
+	// they are buffered in pending until then
+	mu          sync.Mutex
+	initialized bool
+	pending     []TableUpdates
+	databases   map[UUID]ID
+}
+
+// update applies updates of the Database table once the initial contents are applied, buffers them before
+func (w *databaseWatcher) update(updates TableUpdates) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.initialized {
+		w.pending = append(w.pending, updates)
+		return
+	}
+	w.apply(updates)
+}
+
+// initialize applies the initial contents of the Database table, then the updates buffered before
+func (w *databaseWatcher) initialize(initial TableUpdates) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.initialized = true
+	w.apply(initial)
+	for _, updates := range w.pending {
+		w.apply(updates)
+	}
+	w.pending = nil
+}
+
+// apply applies updates of the Database table, and invokes changed if any database is added or removed
+func (w *databaseWatcher) apply(updates TableUpdates) {
+	added, removed := applyDatabaseUpdates(w.databases, updates[databaseTable])
+	if len(added) != 0 || len(removed) != 0 {
+		w.changed(added, removed)
+	}
+}
+
+// applyDatabaseUpdates applies updates of the Database table to databases, returns added and removed names
+func applyDatabaseUpdates(databases map[UUID]ID, updates TableUpdate) (added, removed []ID) {
+	for uuid, rowUpdate := range updates {
+		if rowUpdate.New == nil {
+			if name, ok := databases[uuid]; ok {
+				removed = append(removed, name)
+				delete(databases, uuid)
+			}
+			continue
+		}
+		var row struct {
+			Name ID `json:"name"`
+		}
+		if err := json.Unmarshal(*rowUpdate.New, &row); err != nil || row.Name == "" {
+			continue
+		}
+		if _, ok := databases[uuid]; !ok {
+			added = append(added, row.Name)
+		}
+		databases[uuid] = row.Name
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i] < added[j] })
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	return added, removed
+}
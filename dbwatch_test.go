@@ -0,0 +1,76 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func databaseRowUpdate(old, new string) RowUpdate {
+	var update RowUpdate
+	if old != "" {
+		raw := json.RawMessage(old)
+		update.Old = &raw
+	}
+	if new != "" {
+		raw := json.RawMessage(new)
+		update.New = &raw
+	}
+	return update
+}
+
+func TestApplyDatabaseUpdates(t *testing.T) {
+	databases := make(map[UUID]ID)
+
+	added, removed := applyDatabaseUpdates(databases, TableUpdate{
+		"uuid-server": databaseRowUpdate("", `{"name":"_Server"}`),
+		"uuid-nb":     databaseRowUpdate("", `{"name":"OVN_Northbound"}`),
+	})
+	if want := []ID{"OVN_Northbound", "_Server"}; !reflect.DeepEqual(added, want) || len(removed) != 0 {
+		t.Errorf("initial updates: added %v removed %v, want added %v", added, removed, want)
+	}
+
+	added, removed = applyDatabaseUpdates(databases, TableUpdate{
+		"uuid-nb": databaseRowUpdate(`{"name":"OVN_Northbound"}`, ""),
+		"uuid-sb": databaseRowUpdate("", `{"name":"OVN_Southbound"}`),
+	})
+	if !reflect.DeepEqual(added, []ID{"OVN_Southbound"}) || !reflect.DeepEqual(removed, []ID{"OVN_Northbound"}) {
+		t.Errorf("changes: added %v removed %v, want added [OVN_Southbound] removed [OVN_Northbound]", added, removed)
+	}
+
+	// modification of other columns is not a change
+	added, removed = applyDatabaseUpdates(databases, TableUpdate{
+		"uuid-sb": databaseRowUpdate(`{"name":"OVN_Southbound"}`, `{"name":"OVN_Southbound"}`),
+	})
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("modification: added %v removed %v, want nothing", added, removed)
+	}
+}
+
+func TestDatabaseWatcherBuffersUpdates(t *testing.T) {
+	var added, removed []ID
+	watcher := &databaseWatcher{
+		changed: func(a, r []ID) {
+			added = append(added, a...)
+			removed = append(removed, r...)
+		},
+		databases: make(map[UUID]ID),
+	}
+
+	// the removal is delivered before the reply of the monitor holding the database
+	watcher.update(TableUpdates{"Database": {
+		"uuid-nb": databaseRowUpdate(`{"name":"OVN_Northbound"}`, ""),
+	}})
+	if len(added) != 0 || len(removed) != 0 {
+		t.Fatalf("update before initial contents: added %v removed %v, want nothing", added, removed)
+	}
+	watcher.initialize(TableUpdates{"Database": {
+		"uuid-nb": databaseRowUpdate("", `{"name":"OVN_Northbound"}`),
+	}})
+	if !reflect.DeepEqual(added, []ID{"OVN_Northbound"}) || !reflect.DeepEqual(removed, []ID{"OVN_Northbound"}) {
+		t.Errorf("added %v removed %v, want OVN_Northbound added then removed", added, removed)
+	}
+	if len(watcher.databases) != 0 {
+		t.Errorf("databases %v, want none", watcher.databases)
+	}
+}
@@ -0,0 +1,59 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// DecodeErrorPolicy defines how the client behaves when a notification from the server can't be decoded,
+// e.g. a malformed "update" notification of a monitor, which means updates are lost
+type DecodeErrorPolicy int
+
+// Supported DecodeErrorPolicies
+const (
+	// DecodeErrorLog logs and drops the notification
+	DecodeErrorLog DecodeErrorPolicy = iota
+	// DecodeErrorDrop silently drops the notification, e.g. when a DecodeErrorFunc counts errors in a metric
+	DecodeErrorDrop
+	// DecodeErrorFail closes the connection, so monitors never go on after missing updates
+	DecodeErrorFail
+)
+
+// DecodeErrorFunc is called with the method and the raw params of a notification that can't be decoded
+type DecodeErrorFunc func(method string, payload json.RawMessage, err error)
+
+// SetDecodeErrorPolicy set policy as the behavior on notification decode errors, default to DecodeErrorLog
+func (c *Client) SetDecodeErrorPolicy(policy DecodeErrorPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decodeErrorPolicy = policy
+}
+
+// OnDecodeError registers fn to be called on notification decode errors before the policy is applied
+func (c *Client) OnDecodeError(fn DecodeErrorFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onDecodeError = fn
+}
+
+// decodeError handles the decode error of a notification according to the DecodeErrorPolicy, it returns err
+func (c *Client) decodeError(method string, params []interface{}, err error) error {
+	c.mu.Lock()
+	policy := c.decodeErrorPolicy
+	fn := c.onDecodeError
+	c.mu.Unlock()
+
+	if fn != nil {
+		payload, _ := json.Marshal(params)
+		fn(method, payload, err)
+	}
+	switch policy {
+	case DecodeErrorLog:
+		log.Printf("ovsdb: dropped %s notification: %v", method, err)
+	case DecodeErrorFail:
+		log.Printf("ovsdb: closing connection after %s notification decode error: %v", method, err)
+		c.setState(StateClosed, err)
+		c.rpc.Close()
+	}
+	return err
+}
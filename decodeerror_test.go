@@ -0,0 +1,51 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestDecodeErrorPolicy(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	type decodeError struct {
+		method  string
+		payload string
+	}
+	errs := make(chan decodeError, 1)
+	client.OnDecodeError(func(method string, payload json.RawMessage, err error) {
+		errs <- decodeError{method, string(payload)}
+	})
+	client.SetDecodeErrorPolicy(DecodeErrorDrop)
+
+	server.Notify("update", "m", "not table updates")
+	select {
+	case got := <-errs:
+		if want := (decodeError{"update", `["m","not table updates"]`}); got != want {
+			t.Errorf("decode error = %v, want %v", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnDecodeError not called")
+	}
+	if state := client.State(); state != StateActive {
+		t.Errorf("State = %v after a dropped notification, want %v", state, StateActive)
+	}
+
+	client.SetDecodeErrorPolicy(DecodeErrorFail)
+	server.Notify("locked", 1)
+	<-errs
+	// the policy is applied after OnDecodeError returns
+	for deadline := time.Now().Add(time.Second); client.State() != StateClosed && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if state := client.State(); state != StateClosed {
+		t.Errorf("State = %v after a failed notification, want %v", state, StateClosed)
+	}
+}
@@ -0,0 +1,66 @@
+package ovsdb
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/url"
+	"time"
+)
+
+// DialOption configures how Dial establishes the connection
+type DialOption func(*dialOptions)
+
+// dialOptions is the configuration of Dial
+type dialOptions struct {
+	preamble func(conn net.Conn) error
+	// proxy is set by WithProxy if proxySet, otherwise it's taken from the environment
+	proxy    *url.URL
+	proxySet bool
+	// attemptDelay is set by WithAttemptDelay
+	attemptDelay time.Duration
+	dialer       ContextDialer
+	conn         net.Conn
+	// socket options of tcp connections, see sockopt.go
+	keepAlive   time.Duration
+	userTimeout time.Duration
+	noDelay     *bool
+	// message size limits set by WithMaxMessageSize
+	maxIncoming int
+	maxOutgoing int
+	// TLS configuration of ssl connections set by WithTLS or WithTLSFiles, tlsErr is the error loading it
+	tlsConfig *tls.Config
+	tlsErr    error
+}
+
+// ContextDialer makes network connections, it's implemented by *net.Dialer
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// WithNetDialer sets the dialer making the connections of Dial, to the server or to a proxy,
+// e.g. a *net.Dialer with a Control function binding the socket to a VRF, setting SO_MARK
+// or entering a network namespace. Without it, a zero net.Dialer is used.
+func WithNetDialer(dialer ContextDialer) DialOption {
+	return func(o *dialOptions) {
+		o.dialer = dialer
+	}
+}
+
+// WithConn makes Dial use conn, an already established connection to the server, instead of dialing.
+// Unlike NewClient, the other options, e.g. WithPreamble, still apply to conn.
+func WithConn(conn net.Conn) DialOption {
+	return func(o *dialOptions) {
+		o.conn = conn
+	}
+}
+
+// WithPreamble sets fn to run on the connection once it's established and before JSON-RPC starts,
+// e.g. to send a token to or perform the handshake of an authenticating proxy in front of the server.
+// fn must not read past its handshake, e.g. with a buffered reader, since JSON-RPC starts right after.
+// If fn fails, the connection is closed and Dial returns the error.
+func WithPreamble(fn func(conn net.Conn) error) DialOption {
+	return func(o *dialOptions) {
+		o.preamble = fn
+	}
+}
@@ -0,0 +1,235 @@
+package ovsdb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+// listen accepts one connection on a local TCP port and passes it to serve
+func listen(t *testing.T, serve func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		serve(conn)
+	}()
+	return "tcp:" + listener.Addr().String()
+}
+
+func TestDialWithPreamble(t *testing.T) {
+	var server *ovsdbtest.Server
+	served := make(chan struct{})
+	address := listen(t, func(conn net.Conn) {
+		// an authenticating proxy expecting a token line before JSON-RPC
+		reader := bufio.NewReader(conn)
+		line, err := reader.ReadString('\n')
+		if err != nil || line != "TOKEN secret\n" {
+			conn.Close()
+			return
+		}
+		conn.Write([]byte("OK\n"))
+		server = ovsdbtest.NewServer(conn)
+		close(served)
+	})
+
+	client, err := Dial(address, WithPreamble(func(conn net.Conn) error {
+		fmt.Fprintf(conn, "TOKEN secret\n")
+		reply, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(reply) != "OK" {
+			return errors.New(reply)
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+	<-served
+	defer server.Close()
+	if _, err := client.ListDbs(); err != nil {
+		t.Errorf("ListDbs failed: %v", err)
+	}
+
+	address = listen(t, func(conn net.Conn) { conn.Close() })
+	_, err = Dial(address, WithPreamble(func(conn net.Conn) error { return errors.New("denied") }))
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("Dial = %v, want preamble error", err)
+	}
+}
+
+func TestDialHappyEyeballs(t *testing.T) {
+	served := make(chan struct{})
+	address := listen(t, func(conn net.Conn) {
+		ovsdbtest.NewServer(conn)
+		close(served)
+	})
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(address, "tcp:"))
+
+	defer func(lookup func(context.Context, string) ([]net.IPAddr, error)) { lookupIPAddr = lookup }(lookupIPAddr)
+	lookupIPAddr = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		if host != "ovsdb.example" {
+			return nil, fmt.Errorf("unknown host %s", host)
+		}
+		// the first address is a blackhole of TEST-NET-1
+		return []net.IPAddr{{IP: net.ParseIP("192.0.2.1")}, {IP: net.ParseIP("127.0.0.1")}}, nil
+	}
+
+	start := time.Now()
+	client, err := Dial("tcp:ovsdb.example:"+port, WithAttemptDelay(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Dial took %v", elapsed)
+	}
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not served")
+	}
+}
+
+func TestInterleaveFamilies(t *testing.T) {
+	var ips []net.IPAddr
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "fd00::1", "fd00::2"} {
+		ips = append(ips, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	var got []string
+	for _, ip := range interleaveFamilies(ips) {
+		got = append(got, ip.IP.String())
+	}
+	want := []string{"fd00::1", "10.0.0.1", "fd00::2", "10.0.0.2", "10.0.0.3"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDialResolvesEachTime(t *testing.T) {
+	first := listen(t, func(conn net.Conn) { ovsdbtest.NewServer(conn) })
+	second := listen(t, func(conn net.Conn) { ovsdbtest.NewServer(conn) })
+
+	defer func(lookup func(context.Context, string) ([]net.IPAddr, error)) { lookupIPAddr = lookup }(lookupIPAddr)
+	var lookups int
+	lookupIPAddr = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		lookups++
+		return []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}, nil
+	}
+
+	// the service moved from first to second between the dials, with its new port
+	for _, address := range []string{first, second} {
+		_, port, _ := net.SplitHostPort(strings.TrimPrefix(address, "tcp:"))
+		client, err := Dial("tcp:ovsdb.example:" + port)
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		client.Close()
+	}
+	if lookups != 2 {
+		t.Errorf("got %d lookups, want 2", lookups)
+	}
+}
+
+// recordingDialer is a ContextDialer recording the addresses it dials
+type recordingDialer struct {
+	addresses []string
+}
+
+func (d *recordingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	d.addresses = append(d.addresses, network+":"+address)
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, address)
+}
+
+func TestDialWithNetDialer(t *testing.T) {
+	address := listen(t, func(conn net.Conn) { ovsdbtest.NewServer(conn) })
+	dialer := &recordingDialer{}
+	client, err := Dial(address, WithNetDialer(dialer), WithProxy(nil))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+	if len(dialer.addresses) != 1 || dialer.addresses[0] != address {
+		t.Errorf("dialed %v, want %s", dialer.addresses, address)
+	}
+}
+
+func TestDialWithConn(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	ovsdbtest.NewServer(serverConn)
+	var preamble bool
+	client, err := Dial("", WithConn(clientConn), WithPreamble(func(conn net.Conn) error {
+		preamble = conn == clientConn
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+	if !preamble {
+		t.Error("preamble not run on the connection")
+	}
+	if _, err := client.ListDbs(); err != nil {
+		t.Errorf("ListDbs failed: %v", err)
+	}
+}
+
+func TestNewConnectsLazily(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	ovsdbtest.NewServer(serverConn)
+	var dials int
+	client := New("unix:/ovsdb.sock", WithNetDialer(dialerFunc(func(network, address string) (net.Conn, error) {
+		dials++
+		if dials == 1 {
+			return nil, errors.New("refused")
+		}
+		return clientConn, nil
+	})))
+	defer client.Close()
+	if dials != 0 || client.State() != StateConnecting {
+		t.Fatalf("New dialed %d times, state %v, want no dial before use", dials, client.State())
+	}
+	if err := client.Connect(context.Background()); err == nil || client.State() != StateConnecting {
+		t.Fatalf("failed Connect returned %v, state %v, want an error and %v", err, client.State(), StateConnecting)
+	}
+	// the first RPC connects
+	if _, err := client.ListDbs(); err != nil {
+		t.Fatalf("ListDbs failed: %v", err)
+	}
+	if err := client.Connect(context.Background()); err != nil {
+		t.Errorf("Connect of a connected client failed: %v", err)
+	}
+	if dials != 2 || client.State() != StateActive {
+		t.Errorf("dialed %d times, state %v, want 2 and %v", dials, client.State(), StateActive)
+	}
+
+	closed := New("unix:/ovsdb.sock")
+	closed.Close()
+	if err := closed.Connect(context.Background()); err != errDisconnected {
+		t.Errorf("Connect of a closed client returned %v, want %v", err, errDisconnected)
+	}
+}
+
+// dialerFunc adapts a function to a ContextDialer
+type dialerFunc func(network, address string) (net.Conn, error)
+
+func (fn dialerFunc) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return fn(network, address)
+}
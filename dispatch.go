@@ -0,0 +1,139 @@
+package ovsdb
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cenkalti/rpc2"
+)
+
+// NotificationBacklogError is the error closing the connection when more notifications than the limit set
+// with Client.SetMaxPendingNotifications wait for the notification handler
+type NotificationBacklogError struct {
+	// Limit is the exceeded number of pending notifications
+	Limit int
+}
+
+// Error implements error interface
+func (err *NotificationBacklogError) Error() string {
+	return fmt.Sprintf("more than %d notifications wait for the notification handler", err.Limit)
+}
+
+// SetMaxPendingNotifications limits the number of notifications received from the server and waiting for the
+// notification handler, 0 for no limit, the default. Notifications are read as they arrive and queued for the
+// handler, so without a limit a handler which doesn't keep up makes the queue grow without bound.
+// A notification exceeding the limit closes the connection, the client changes to StateClosed with
+// a *NotificationBacklogError as the error, so monitors never go on after missing updates.
+func (c *Client) SetMaxPendingNotifications(limit int) {
+	c.connectMu.Lock()
+	defer c.connectMu.Unlock()
+	c.maxPending = limit
+	if c.notifications != nil {
+		c.notifications.setLimit(limit)
+	}
+}
+
+// notificationQueue runs the handlers of notifications one at a time, in the order the notifications
+// were received from the server. Handlers run on the goroutine of the queue, not on the one reading
+// the connection, so they may call the client, e.g. to resubscribe; a slow handler delays the
+// following notifications but not the responses of calls.
+// Pushing never blocks, reading the connection would stop otherwise and a handler waiting for the
+// response of a call would never get it, the queue fails once it holds limit handlers instead.
+type notificationQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []func()
+	closed  bool
+	// limit is the maximum number of pending handlers, 0 for no limit, err is set once it's exceeded
+	limit int
+	err   error
+}
+
+// newNotificationQueue creates a notificationQueue holding at most limit handlers, its handlers run once run
+// is started
+func newNotificationQueue(limit int) *notificationQueue {
+	q := &notificationQueue{limit: limit}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// setLimit sets the maximum number of pending handlers, 0 for no limit
+func (q *notificationQueue) setLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.limit = limit
+}
+
+// push appends the handler of a notification to the queue, it never blocks. It fails with
+// a *NotificationBacklogError if the queue holds limit handlers already, fn is dropped then.
+func (q *notificationQueue) push(fn func()) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	if q.limit > 0 && len(q.pending) >= q.limit {
+		if q.err == nil {
+			q.err = &NotificationBacklogError{Limit: q.limit}
+		}
+		return q.err
+	}
+	q.pending = append(q.pending, fn)
+	q.cond.Signal()
+	return nil
+}
+
+// backlogError returns the *NotificationBacklogError of the queue if its limit was exceeded, nil otherwise
+func (q *notificationQueue) backlogError() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.err
+}
+
+// close makes run return once the pending handlers have run
+func (q *notificationQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Signal()
+}
+
+// run runs the handlers in order until the queue is closed
+func (q *notificationQueue) run() {
+	for {
+		q.mu.Lock()
+		for len(q.pending) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.pending) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		fn := q.pending[0]
+		q.pending[0] = nil
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+		fn()
+	}
+}
+
+// notificationFunc is the type of the rpc2 handlers of notifications
+type notificationFunc func(client *rpc2.Client, params []interface{}, reply *[]interface{}) error
+
+// ordered returns a handler queuing notifications to run handler in order on the queue of the client.
+// The connection must be read in blocking mode, so the notifications are queued in the order they arrive.
+// The connection is closed if the queue is full, trackDisconnect reports the error of the queue then.
+func (c *Client) ordered(handler notificationFunc) notificationFunc {
+	queue := c.notifications
+	return func(client *rpc2.Client, params []interface{}, reply *[]interface{}) error {
+		err := queue.push(func() {
+			// notifications have no response, so the errors of handlers are dropped, decode errors are
+			// handled by the handlers according to the DecodeErrorPolicy
+			handler(client, params, new([]interface{}))
+		})
+		if err != nil {
+			client.Close()
+		}
+		return err
+	}
+}
@@ -0,0 +1,88 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestNotificationsInOrder(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+	defer client.Close()
+
+	const n = 100
+	received := make(chan string, n)
+	client.SetNotificationHandler(&NotificationHandlerFuncs{UpdateFunc: func(jsonValue Value, updates TableUpdates) error {
+		var row struct {
+			Name string `json:"name"`
+		}
+		json.Unmarshal(*updates["Bridge"][ls1].New, &row)
+		if row.Name == "br0" {
+			// handlers may call the client
+			if _, err := client.ListDbs(); err != nil {
+				t.Errorf("ListDbs in a handler failed: %v", err)
+			}
+		}
+		received <- row.Name
+		return nil
+	}})
+
+	go func() {
+		for i := 0; i < n; i++ {
+			row := map[string]interface{}{"name": fmt.Sprintf("br%d", i)}
+			server.Notify("update", "m", map[string]interface{}{"Bridge": map[string]interface{}{ls1: map[string]interface{}{"new": row}}})
+		}
+	}()
+	for i := 0; i < n; i++ {
+		select {
+		case name := <-received:
+			if want := fmt.Sprintf("br%d", i); name != want {
+				t.Fatalf("update %d is %s, want %s", i, name, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("update %d not received", i)
+		}
+	}
+}
+
+func TestMaxPendingNotifications(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+	client.SetMaxPendingNotifications(2)
+	changes, cancel := client.StateChanges()
+	defer cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+	client.SetNotificationHandler(&NotificationHandlerFuncs{UpdateFunc: func(jsonValue Value, updates TableUpdates) error {
+		<-release
+		return nil
+	}})
+
+	// the first notification is handled and blocks the handler, the next two are pending
+	go func() {
+		for i := 0; i < 4; i++ {
+			if err := server.Notify("update", "m", map[string]interface{}{}); err != nil {
+				return
+			}
+		}
+	}()
+	select {
+	case change := <-changes:
+		backlogErr, ok := change.Err.(*NotificationBacklogError)
+		if change.To != StateClosed || !ok || backlogErr.Limit != 2 {
+			t.Errorf("state change = %+v after too many notifications, want StateClosed with a *NotificationBacklogError", change)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connection not closed by too many pending notifications")
+	}
+}
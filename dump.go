@@ -0,0 +1,206 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TableDump is the content of a table, like the output of "ovsdb-client dump"
+type TableDump struct {
+	// Table is the name of the dumped table
+	Table ID
+	// Columns are the dumped columns
+	Columns []ID
+	// Rows hold the values of Columns for each row, in canonical form (see CanonicalValue)
+	Rows [][]Value
+}
+
+// Dump selects columns of all rows in table of database db.
+// All columns are dumped if none is specified, with "_uuid" first and others sorted by name.
+// Rows are sorted by their formatted values for a stable output.
+func (c *Client) Dump(db, table ID, columns ...ID) (*TableDump, error) {
+	if len(columns) == 0 {
+		dbSchema, err := c.GetSchema(db)
+		if err != nil {
+			return nil, err
+		}
+		tableSchema, ok := dbSchema.Tables[table]
+		if !ok {
+			return nil, fmt.Errorf("table %s not found in database %s", table, db)
+		}
+		columns = dumpColumns(tableSchema)
+	}
+
+	result, err := c.Transact(db, &SelectOperation{Table: table, Where: MatchAll(), Columns: columns})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) != 0 {
+		return nil, result.Errors
+	}
+	var selected struct {
+		Rows []map[ID]json.RawMessage `json:"rows"`
+	}
+	if err := result.DecodeAt(0, &selected); err != nil {
+		return nil, err
+	}
+	return newTableDump(table, columns, selected.Rows)
+}
+
+// dumpColumns returns all columns of a table in the dump order
+func dumpColumns(tableSchema *TableSchema) []ID {
+	var columns []ID
+	for column := range tableSchema.Columns {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i] < columns[j] })
+	return append([]ID{"_uuid"}, columns...)
+}
+
+// newTableDump creates a TableDump from selected rows
+func newTableDump(table ID, columns []ID, rows []map[ID]json.RawMessage) (*TableDump, error) {
+	dump := &TableDump{Table: table, Columns: columns}
+	for _, row := range rows {
+		values := make([]Value, len(columns))
+		for i, column := range columns {
+			raw, ok := row[column]
+			if !ok {
+				continue
+			}
+			value, err := CanonicalValue(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of column %s: %v", column, err)
+			}
+			values[i] = value
+		}
+		dump.Rows = append(dump.Rows, values)
+	}
+
+	sort.SliceStable(dump.Rows, func(i, j int) bool {
+		for k := range columns {
+			a, b := FormatValue(dump.Rows[i][k]), FormatValue(dump.Rows[j][k])
+			if a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+	return dump, nil
+}
+
+// WriteText writes the dump as an aligned text table in the format printed by "ovsdb-client dump":
+//
+//	Bridge table
+//	_uuid                                name
+//	------------------------------------ ----
+//	4b6b1ec6-2d2d-4a6b-9a0d-5e8d7b3f7f10 br0
+func (dump *TableDump) WriteText(w io.Writer) error {
+	cells := make([][]string, 0, len(dump.Rows)+1)
+	header := make([]string, len(dump.Columns))
+	for i, column := range dump.Columns {
+		header[i] = string(column)
+	}
+	cells = append(cells, header)
+	for _, row := range dump.Rows {
+		line := make([]string, len(row))
+		for i, value := range row {
+			line[i] = FormatValue(value)
+		}
+		cells = append(cells, line)
+	}
+
+	widths := make([]int, len(dump.Columns))
+	for _, line := range cells {
+		for i, cell := range line {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	separator := make([]string, len(widths))
+	for i, width := range widths {
+		separator[i] = strings.Repeat("-", width)
+	}
+	cells = append(cells[:1], append([][]string{separator}, cells[1:]...)...)
+
+	if _, err := fmt.Fprintf(w, "%s table\n", dump.Table); err != nil {
+		return err
+	}
+	for _, line := range cells {
+		padded := make([]string, len(line))
+		for i, cell := range line {
+			if i == len(line)-1 {
+				padded[i] = cell
+			} else {
+				padded[i] = cell + strings.Repeat(" ", widths[i]-len(cell))
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(padded, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bareString matches strings which are printed without quotes
+var bareString = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_./:-]*$`)
+
+// FormatValue formats a value in the syntax used by ovsdb-client and ovs-vsctl:
+// strings are quoted only if needed, sets are printed as [a, b] and maps as {k=v, k2=v2}.
+// Named UUIDs are printed as @name. See ParseValue for the reverse.
+// An empty set is printed as [] and a nil value as an empty string.
+func FormatValue(v Value) string {
+	if v == nil {
+		return ""
+	}
+	canonical, err := CanonicalValue(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	switch value := canonical.(type) {
+	case Set:
+		elements := make([]string, len(value.Values))
+		for i, element := range value.Values {
+			elements[i] = formatAtom(element)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case Map:
+		pairs := make([]string, len(value.Values))
+		for i, pair := range value.Values {
+			pairs[i] = formatAtom(pair[0]) + "=" + formatAtom(pair[1])
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+	return formatAtom(canonical)
+}
+
+// formatAtom formats a canonical atom
+func formatAtom(atom Atomic) string {
+	switch value := atom.(type) {
+	case string:
+		// keywords and strings looking like other atoms must be quoted
+		if bareString.MatchString(value) && value != "true" && value != "false" && !isUUIDString(value) {
+			return value
+		}
+		return strconv.Quote(value)
+	case UUID:
+		return string(value)
+	case NamedUUID:
+		return "@" + string(value)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return fmt.Sprint(atom)
+}
+
+// uuidString matches strings in the format of a UUID
+var uuidString = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func isUUIDString(s string) bool {
+	return uuidString.MatchString(s)
+}
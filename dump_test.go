@@ -0,0 +1,62 @@
+package ovsdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value Value
+		text  string
+	}{
+		{nil, ``},
+		{"br0", `br0`},
+		{"", `""`},
+		{"hello world", `"hello world"`},
+		{"true", `"true"`},
+		{"550e8400-e29b-41d4-a716-446655440000", `"550e8400-e29b-41d4-a716-446655440000"`},
+		{true, `true`},
+		{float64(1500), `1500`},
+		{1.5, `1.5`},
+		{UUID("550e8400-e29b-41d4-a716-446655440000"), `550e8400-e29b-41d4-a716-446655440000`},
+		{Set{Values: []Value{}}, `[]`},
+		{Set{Values: []Value{"b", "a"}}, `[a, b]`},
+		{Map{Values: []MapPair{{"b", "2"}, {"a", "x y"}}}, `{a="x y", b="2"}`},
+	}
+	for _, test := range tests {
+		if text := FormatValue(test.value); text != test.text {
+			t.Errorf("FormatValue(%#v) = %s, want %s", test.value, text, test.text)
+		}
+	}
+}
+
+func TestTableDumpWriteText(t *testing.T) {
+	var rows []map[ID]json.RawMessage
+	err := json.Unmarshal([]byte(`[
+		{"_uuid":["uuid","00000000-0000-0000-0000-000000000002"],"name":"br1","ports":["set",[]]},
+		{"_uuid":["uuid","00000000-0000-0000-0000-000000000001"],"name":"br0","ports":["uuid","00000000-0000-0000-0000-000000000003"]}
+	]`), &rows)
+	if err != nil {
+		t.Fatalf("failed to decode rows: %v", err)
+	}
+	dump, err := newTableDump("Bridge", []ID{"_uuid", "name", "ports"}, rows)
+	if err != nil {
+		t.Fatalf("newTableDump failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := dump.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	want := `Bridge table
+_uuid                                name ports
+------------------------------------ ---- ------------------------------------
+00000000-0000-0000-0000-000000000001 br0  00000000-0000-0000-0000-000000000003
+00000000-0000-0000-0000-000000000002 br1  []
+`
+	if buf.String() != want {
+		t.Errorf("WriteText got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,103 @@
+package ovsdb
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cenkalti/rpc2"
+)
+
+// Classes of errors reported by OVSDB servers in the "error" member of a failed operation or RPC.
+// See https://tools.ietf.org/html/rfc7047#section-3.1 and the operations in section 5.2.
+// Match errors against them with ClassifyError, e.g. ClassifyError(err) == ErrConstraintViolation,
+// their Details are ignored.
+var (
+	ErrReferentialIntegrity = &Error{Err: "referential integrity violation"}
+	ErrConstraintViolation  = &Error{Err: "constraint violation"}
+	ErrResourcesExhausted   = &Error{Err: "resources exhausted"}
+	ErrIOError              = &Error{Err: "I/O error"}
+	ErrDuplicateUUIDName    = &Error{Err: "duplicate uuid-name"}
+	ErrDomainError          = &Error{Err: "domain error"}
+	ErrRangeError           = &Error{Err: "range error"}
+	ErrTimedOut             = &Error{Err: "timed out"}
+	ErrNotSupported         = &Error{Err: "not supported"}
+	ErrAborted              = &Error{Err: "aborted"}
+	ErrNotOwner             = &Error{Err: "not owner"}
+	// ErrSyntaxError is reported by ovsdb-server for malformed requests, it's not in RFC 7047
+	ErrSyntaxError = &Error{Err: "syntax error"}
+	// ErrUnknownDatabase is returned by GetSchema, Monitor and Transact if the database doesn't exist (yet),
+	// e.g. while waiting for ovn-northd to create it. It's not in RFC 7047.
+	ErrUnknownDatabase = &Error{Err: "unknown database"}
+)
+
+// errDisconnected is returned by calls of a client whose connection is closed
+var errDisconnected = errors.New("connection to OVSDB server is closed")
+
+// errorClasses are all known error classes
+var errorClasses = []*Error{
+	ErrReferentialIntegrity,
+	ErrConstraintViolation,
+	ErrResourcesExhausted,
+	ErrIOError,
+	ErrDuplicateUUIDName,
+	ErrDomainError,
+	ErrRangeError,
+	ErrTimedOut,
+	ErrNotSupported,
+	ErrAborted,
+	ErrNotOwner,
+	ErrSyntaxError,
+	ErrUnknownDatabase,
+}
+
+// Is reports whether err is of the same class as target, errors.Is uses it on Go 1.13 and later
+func (err *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Err == err.Err
+}
+
+// Is reports whether any of the errors is of the same class as target
+func (re ResultErrors) Is(target error) bool {
+	for _, err := range re {
+		if err.Is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyError returns the class of err, which is one of the Err* error classes of this package,
+// or nil if err is not a known OVSDB error. For ResultErrors the class of the first error is returned.
+func ClassifyError(err error) *Error {
+	var class string
+	switch err := err.(type) {
+	case ResultErrors:
+		if len(err) == 0 {
+			return nil
+		}
+		return ClassifyError(err[0])
+	case *Error:
+		class = err.Err
+	case rpc2.ServerError:
+		class = string(err)
+	default:
+		return nil
+	}
+	for _, known := range errorClasses {
+		if class == known.Err || strings.HasPrefix(class, known.Err+":") {
+			return known
+		}
+	}
+	return nil
+}
+
+// databaseError turns the response error of a request on an unknown database into an *Error
+// with the details of the server, other errors are returned as they are
+func databaseError(err error) error {
+	serverErr, ok := err.(rpc2.ServerError)
+	if !ok || ClassifyError(err) != ErrUnknownDatabase {
+		return err
+	}
+	details := strings.TrimPrefix(strings.TrimPrefix(string(serverErr), ErrUnknownDatabase.Err), ":")
+	return &Error{Err: ErrUnknownDatabase.Err, Details: strings.TrimSpace(details)}
+}
@@ -0,0 +1,88 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/cenkalti/rpc2"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err   error
+		class *Error
+	}{
+		{nil, nil},
+		{errors.New("some error"), nil},
+		{&Error{Err: "constraint violation", Details: "duplicate name"}, ErrConstraintViolation},
+		{&Error{Err: "unknown class"}, nil},
+		{ResultErrors{&Error{Err: "referential integrity violation"}, &Error{Err: "aborted"}}, ErrReferentialIntegrity},
+		{rpc2.ServerError("not owner"), ErrNotOwner},
+	}
+	for _, test := range tests {
+		if class := ClassifyError(test.err); class != test.class {
+			t.Errorf("ClassifyError(%v) = %v, want %v", test.err, class, test.class)
+		}
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	err := &Error{Err: "constraint violation", Details: "duplicate name"}
+	if !err.Is(ErrConstraintViolation) {
+		t.Error("error should be a constraint violation")
+	}
+	if err.Is(ErrTimedOut) {
+		t.Error("error should not be timed out")
+	}
+	results := ResultErrors{&Error{Err: "aborted"}, err}
+	if !results.Is(ErrConstraintViolation) {
+		t.Error("result errors should include a constraint violation")
+	}
+}
+
+func TestUnknownDatabase(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	client := NewClient(conn)
+	defer client.Close()
+	go func() {
+		// ovsdb-server reports an unknown database with an <error> object
+		decoder, encoder := json.NewDecoder(serverConn), json.NewEncoder(serverConn)
+		for {
+			var request struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+				ID     interface{}       `json:"id"`
+			}
+			if err := decoder.Decode(&request); err != nil {
+				return
+			}
+			var db string
+			json.Unmarshal(request.Params[0], &db)
+			encoder.Encode(map[string]interface{}{
+				"id":     request.ID,
+				"result": nil,
+				"error": map[string]interface{}{
+					"error":   "unknown database",
+					"details": fmt.Sprintf("%s request specifies unknown database %s", request.Method, db),
+				},
+			})
+		}
+	}()
+
+	_, err := client.GetSchema("OVN_Northbound")
+	if ClassifyError(err) != ErrUnknownDatabase {
+		t.Fatalf("GetSchema error = %v, want unknown database", err)
+	}
+	if details := err.(*Error).Details; details != "get_schema request specifies unknown database OVN_Northbound" {
+		t.Errorf("got details %q", details)
+	}
+	if _, err := client.Monitor("OVN_Northbound", nil, MonitorRequests{}); ClassifyError(err) != ErrUnknownDatabase {
+		t.Errorf("Monitor error = %v, want unknown database", err)
+	}
+	if _, err := client.Transact("OVN_Northbound", &CommentOperation{Comment: "x"}); ClassifyError(err) != ErrUnknownDatabase {
+		t.Errorf("Transact error = %v, want unknown database", err)
+	}
+}
@@ -0,0 +1,136 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EventFilter selects ChangeEvents, it's parsed from an expression by ParseEventFilter
+type EventFilter struct {
+	// Table is the table of selected events, all tables if it's empty
+	Table ID
+	// Predicates must all hold for selected events
+	Predicates []EventPredicate
+}
+
+// EventPredicate compares a field of a ChangeEvent with a value
+type EventPredicate struct {
+	// Path is the field, see ParseEventFilter
+	Path string
+	// Op is one of "==", "!=" or "~=" (contains)
+	Op    string
+	Value string
+}
+
+// eventFilterOps are the operators of predicates
+var eventFilterOps = []string{"==", "!=", "~="}
+
+// ParseEventFilter parses a filter expression of the form "<table>[:<predicate>[,<predicate>...]]",
+// where the table may be "*" for all tables and a predicate is "<path><op><value>", e.g.
+// "Logical_Switch_Port:name==lsp1,.op!=delete".
+// A path is either a column name, which is the value of the column in the new row or in the old one for
+// deleted rows, or a jq-like path: ".op", ".uuid", ".table", ".old.<column>" or ".new.<column>".
+// Values are compared with the fields formatted by EventField.
+func ParseEventFilter(expr string) (*EventFilter, error) {
+	var filter EventFilter
+	parts := strings.SplitN(expr, ":", 2)
+	table := strings.TrimSpace(parts[0])
+	if table != "*" && table != "" {
+		if err := validateID("table name", ID(table)); err != nil {
+			return nil, err
+		}
+		filter.Table = ID(table)
+	}
+	if len(parts) == 1 {
+		return &filter, nil
+	}
+	for _, predicate := range strings.Split(parts[1], ",") {
+		predicate = strings.TrimSpace(predicate)
+		parsed := parsePredicate(predicate)
+		if parsed == nil {
+			return nil, fmt.Errorf("invalid predicate %q: must be <path><op><value> with op ==, != or ~=", predicate)
+		}
+		filter.Predicates = append(filter.Predicates, *parsed)
+	}
+	return &filter, nil
+}
+
+// parsePredicate parses "<path><op><value>", it's split at the leftmost operator since the value
+// may contain operators too. It returns nil if there's no operator after a path.
+func parsePredicate(predicate string) *EventPredicate {
+	for i := 1; i < len(predicate); i++ {
+		for _, op := range eventFilterOps {
+			if strings.HasPrefix(predicate[i:], op) {
+				return &EventPredicate{Path: strings.TrimSpace(predicate[:i]), Op: op, Value: strings.TrimSpace(predicate[i+len(op):])}
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns true if event is selected by the filter
+func (f *EventFilter) Match(event ChangeEvent) bool {
+	if f.Table != "" && f.Table != event.Table {
+		return false
+	}
+	for _, predicate := range f.Predicates {
+		value, ok := EventField(event, predicate.Path)
+		switch predicate.Op {
+		case "==":
+			if !ok || value != predicate.Value {
+				return false
+			}
+		case "!=":
+			if ok && value == predicate.Value {
+				return false
+			}
+		case "~=":
+			if !ok || !strings.Contains(value, predicate.Value) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// EventField returns the field of event at path (see ParseEventFilter), ok is false if it's absent.
+// Strings and UUIDs are formatted as is, other values as the JSON encoding of their canonical form.
+func EventField(event ChangeEvent, path string) (value string, ok bool) {
+	var row json.RawMessage
+	column := path
+	switch {
+	case path == ".op":
+		return string(event.Op), true
+	case path == ".uuid":
+		return event.UUID, true
+	case path == ".table":
+		return string(event.Table), true
+	case strings.HasPrefix(path, ".old."):
+		row, column = event.Old, strings.TrimPrefix(path, ".old.")
+	case strings.HasPrefix(path, ".new."):
+		row, column = event.New, strings.TrimPrefix(path, ".new.")
+	case event.Op == ChangeDelete:
+		row = event.Old
+	default:
+		row = event.New
+	}
+	if row == nil {
+		return "", false
+	}
+	var columns map[ID]json.RawMessage
+	if err := json.Unmarshal(row, &columns); err != nil {
+		return "", false
+	}
+	raw, ok := columns[ID(column)]
+	if !ok {
+		return "", false
+	}
+	canonical, err := CanonicalValue(raw)
+	if err != nil {
+		return "", false
+	}
+	return formatLabelValue(canonical), true
+}
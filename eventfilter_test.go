@@ -0,0 +1,115 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseEventFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want EventFilter
+	}{
+		{"*", EventFilter{}},
+		{"Logical_Switch_Port", EventFilter{Table: "Logical_Switch_Port"}},
+		{"Logical_Switch_Port: name==lsp1, .op!=delete", EventFilter{
+			Table:      "Logical_Switch_Port",
+			Predicates: []EventPredicate{{Path: "name", Op: "==", Value: "lsp1"}, {Path: ".op", Op: "!=", Value: "delete"}},
+		}},
+		{"*:.new.name~=lsp", EventFilter{Predicates: []EventPredicate{{Path: ".new.name", Op: "~=", Value: "lsp"}}}},
+		// predicates are split at the leftmost operator
+		{"*:name~=a==b", EventFilter{Predicates: []EventPredicate{{Path: "name", Op: "~=", Value: "a==b"}}}},
+		{"*:name==a!=b", EventFilter{Predicates: []EventPredicate{{Path: "name", Op: "==", Value: "a!=b"}}}},
+		{"*:name!==", EventFilter{Predicates: []EventPredicate{{Path: "name", Op: "!=", Value: "="}}}},
+	}
+	for _, test := range tests {
+		filter, err := ParseEventFilter(test.expr)
+		if err != nil {
+			t.Errorf("ParseEventFilter(%q) failed: %v", test.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(*filter, test.want) {
+			t.Errorf("ParseEventFilter(%q) = %+v, want %+v", test.expr, *filter, test.want)
+		}
+	}
+
+	for _, expr := range []string{"Bad-Table", "*:name", "*:==x", "*:name==x,"} {
+		if _, err := ParseEventFilter(expr); err == nil {
+			t.Errorf("expect error for %q, but got nil", expr)
+		}
+	}
+}
+
+func TestEventFilterMatch(t *testing.T) {
+	events := []ChangeEvent{
+		{Table: "Logical_Switch_Port", UUID: lsp1, Op: ChangeInsert, New: json.RawMessage(`{"name":"lsp1","up":true}`)},
+		{Table: "Logical_Switch_Port", UUID: lsp2, Op: ChangeModify, Old: json.RawMessage(`{"up":false}`), New: json.RawMessage(`{"name":"lsp2","up":true}`)},
+		{Table: "Logical_Switch_Port", UUID: lsp1, Op: ChangeDelete, Old: json.RawMessage(`{"name":"lsp1","up":true}`)},
+		{Table: "Logical_Switch", UUID: ls1, Op: ChangeInsert, New: json.RawMessage(`{"name":"lsp1"}`)},
+	}
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{"*", []int{0, 1, 2, 3}},
+		{"Logical_Switch_Port", []int{0, 1, 2}},
+		{"Logical_Switch_Port:name==lsp1", []int{0, 2}},
+		{"Logical_Switch_Port: name==lsp1, .op!=delete", []int{0}},
+		{"*:.old.up==false", []int{1}},
+		{"*:.new.name~=lsp", []int{0, 1, 3}},
+		{"*:.uuid==" + ls1, []int{3}},
+		// an absent field doesn't equal a value
+		{"*:mtu!=1500", []int{0, 1, 2, 3}},
+		{"*:mtu==1500", nil},
+	}
+	for _, test := range tests {
+		filter, err := ParseEventFilter(test.expr)
+		if err != nil {
+			t.Errorf("ParseEventFilter(%q) failed: %v", test.expr, err)
+			continue
+		}
+		var matched []int
+		for i, event := range events {
+			if filter.Match(event) {
+				matched = append(matched, i)
+			}
+		}
+		if !reflect.DeepEqual(matched, test.want) {
+			t.Errorf("%q matched %v, want %v", test.expr, matched, test.want)
+		}
+	}
+}
+
+func TestEventField(t *testing.T) {
+	modify := ChangeEvent{
+		Table: "Logical_Switch_Port", UUID: lsp1, Op: ChangeModify,
+		Old: json.RawMessage(`{"up":false}`),
+		New: json.RawMessage(`{"name":"lsp1","up":true,"tag":["set",[]],"options":["map",[["a","1"]]]}`),
+	}
+	deleted := ChangeEvent{Table: "Logical_Switch_Port", UUID: lsp2, Op: ChangeDelete, Old: json.RawMessage(`{"name":"lsp2"}`)}
+	tests := []struct {
+		event ChangeEvent
+		path  string
+		value string
+		ok    bool
+	}{
+		{modify, ".op", "modify", true},
+		{modify, ".uuid", lsp1, true},
+		{modify, ".table", "Logical_Switch_Port", true},
+		{modify, ".old.up", "false", true},
+		{modify, ".new.up", "true", true},
+		{modify, "name", "lsp1", true},
+		{modify, ".old.name", "", false},
+		{modify, "unknown", "", false},
+		// columns of deleted rows are the old values
+		{deleted, "name", "lsp2", true},
+		{deleted, ".new.name", "", false},
+	}
+	for _, test := range tests {
+		value, ok := EventField(test.event, test.path)
+		if value != test.value || ok != test.ok {
+			t.Errorf("EventField of %s at %s = %q, %v, want %q, %v", test.event.Op, test.path, value, ok, test.value, test.ok)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+// Command bindingwatcher prints the binding state transitions of the logical ports of the local hypervisor.
+// It monitors the interfaces of the local Open vSwitch having an external_ids:iface-id, and the Port_Binding
+// rows of the OVN southbound database bound to the local chassis or of the logical ports of the interfaces,
+// with a conditional monitor updated as interfaces come and go. Chassis names are read through a cache.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"time"
+
+	ovsdb "github.com/liwei/go-ovsdb"
+)
+
+var (
+	ovsAddress string
+	sbAddress  string
+	chassis    string
+)
+
+const (
+	DefaultOVSAddress = "unix:/var/run/openvswitch/db.sock"
+	DefaultSBAddress  = "unix:/var/run/ovn/ovnsb_db.sock"
+)
+
+// binding is a Port_Binding row
+type binding struct {
+	logicalPort string
+	chassis     ovsdb.UUID
+}
+
+// watcher correlates local interfaces with port bindings
+type watcher struct {
+	sb      *ovsdb.Client
+	sbCache *ovsdb.ReadThroughCache
+	chassis string
+
+	// each client delivers its notifications in order on its own goroutine, and the initial contents
+	// of monitors are applied from others, mu serializes them
+	mu sync.Mutex
+	// localChassis is the UUID of the Chassis row of chassis, empty until it's registered
+	localChassis ovsdb.UUID
+	// interfaces maps the UUIDs of local interfaces to their iface-id
+	interfaces map[ovsdb.UUID]string
+	bindings   map[ovsdb.UUID]binding
+	// monitor is the id of the current monitor of Port_Binding, seq numbers the monitors
+	monitor string
+	seq     int
+	// conditions are the conditions of the current monitor
+	conditions []ovsdb.Condition
+	// states are the last printed states of logical ports
+	states map[string]string
+}
+
+func main() {
+	flag.StringVar(&ovsAddress, "ovs", DefaultOVSAddress, "Open vSwitch database server address")
+	flag.StringVar(&sbAddress, "sb", DefaultSBAddress, "OVN southbound database server address")
+	flag.StringVar(&chassis, "chassis", "", "name of the local chassis, default to external_ids:system-id of Open_vSwitch")
+	flag.Parse()
+
+	// ctx is canceled on interrupt
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	go func() {
+		<-interrupted
+		cancel()
+	}()
+
+	// clients are created without connecting, connections are made below with a deadline
+	ovs := ovsdb.New(ovsAddress)
+	sb := ovsdb.New(sbAddress, ovsdb.WithKeepAlive(10*time.Second))
+	connectCtx, cancelConnect := context.WithTimeout(ctx, 10*time.Second)
+	defer cancelConnect()
+	if err := ovs.Connect(connectCtx); err != nil {
+		log.Fatalf("failed to connect to Open vSwitch: %v", err)
+	}
+	defer ovs.Close()
+	if err := sb.Connect(connectCtx); err != nil {
+		log.Fatalf("failed to connect to OVN southbound: %v", err)
+	}
+	defer sb.Close()
+
+	w := &watcher{
+		sb:         sb,
+		sbCache:    ovsdb.NewReadThroughCache(sb, "OVN_Southbound", 30*time.Second),
+		chassis:    chassis,
+		interfaces: make(map[ovsdb.UUID]string),
+		bindings:   make(map[ovsdb.UUID]binding),
+		states:     make(map[string]string),
+	}
+	if w.chassis == "" {
+		var err error
+		if w.chassis, err = systemID(ovs); err != nil {
+			log.Fatalf("failed to get the local chassis name: %v", err)
+		}
+	}
+
+	// cached rows of a lost monitor would be stale, exit and let the supervisor restart the watcher
+	lost := make(chan ovsdb.SessionReset, 2)
+	for _, client := range []*ovsdb.Client{ovs, sb} {
+		client.OnSessionReset(func(reset ovsdb.SessionReset) {
+			lost <- reset
+		})
+	}
+	sb.SetNotificationHandler(w)
+	ovs.SetNotificationHandler(&ovsdb.NotificationHandlerFuncs{
+		UpdateFunc: func(jsonValue ovsdb.Value, updates ovsdb.TableUpdates) error {
+			w.updateInterfaces(updates)
+			return w.resubscribe()
+		},
+	})
+
+	initial, err := ovs.Monitor("Open_vSwitch", "interfaces", ovsdb.MonitorRequests{
+		"Interface": {Columns: []ovsdb.ID{"name", "external_ids"}},
+	})
+	if err != nil {
+		log.Fatalf("failed to monitor interfaces: %v", err)
+	}
+	w.updateInterfaces(initial)
+	if err := w.resubscribe(); err != nil {
+		log.Fatalf("failed to monitor port bindings: %v", err)
+	}
+
+	errs := make(chan error, 2)
+	go func() { errs <- ovs.Run(ctx) }()
+	go func() { errs <- sb.Run(ctx) }()
+	select {
+	case err = <-errs:
+	case reset := <-lost:
+		err = fmt.Errorf("session reset (%v): %v", reset.Reason, reset.Err)
+	}
+	if err != nil && err != context.Canceled {
+		log.Fatalf("watcher stopped: %v", err)
+	}
+}
+
+// systemID returns external_ids:system-id of the Open_vSwitch table, the name of the local chassis
+func systemID(ovs *ovsdb.Client) (string, error) {
+	result, err := ovs.Transact("Open_vSwitch", &ovsdb.SelectOperation{
+		Table:   "Open_vSwitch",
+		Where:   ovsdb.MatchAll(),
+		Columns: []ovsdb.ID{"external_ids"},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(result.Errors) != 0 {
+		return "", result.Errors
+	}
+	var selected ovsdb.SelectResult
+	if err := json.Unmarshal(result.Results[0].(json.RawMessage), &selected); err != nil {
+		return "", err
+	}
+	if len(selected.Rows) == 0 {
+		return "", fmt.Errorf("no Open_vSwitch row")
+	}
+	var row struct {
+		ExternalIDs ovsdb.Value `json:"external_ids"`
+	}
+	if err := json.Unmarshal(*selected.Rows[0], &row); err != nil {
+		return "", err
+	}
+	var externalIDs map[string]string
+	if err := ovsdb.ConvertFromValue(row.ExternalIDs, &externalIDs); err != nil {
+		return "", err
+	}
+	if externalIDs["system-id"] == "" {
+		return "", fmt.Errorf("external_ids:system-id not set")
+	}
+	return externalIDs["system-id"], nil
+}
+
+// updateInterfaces applies updates of the Interface table
+func (w *watcher) updateInterfaces(updates ovsdb.TableUpdates) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for uuid, update := range updates["Interface"] {
+		if update.New == nil {
+			delete(w.interfaces, uuid)
+			continue
+		}
+		var row struct {
+			ExternalIDs ovsdb.Value `json:"external_ids"`
+		}
+		var externalIDs map[string]string
+		if err := json.Unmarshal(*update.New, &row); err == nil {
+			ovsdb.ConvertFromValue(row.ExternalIDs, &externalIDs)
+		}
+		if id := externalIDs["iface-id"]; id != "" {
+			w.interfaces[uuid] = id
+		} else {
+			delete(w.interfaces, uuid)
+		}
+	}
+	w.report()
+}
+
+// resubscribe replaces the monitor of Port_Binding if the local chassis or the logical ports of the local
+// interfaces changed. A row is monitored if it matches any of the conditions of a monitor.
+func (w *watcher) resubscribe() error {
+	chassisUUID, err := w.chassisUUID()
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.localChassis = chassisUUID
+	var conditions []ovsdb.Condition
+	if chassisUUID != "" {
+		conditions = append(conditions, ovsdb.Condition{Column: "chassis", Function: ovsdb.FuncEq, Value: chassisUUID})
+	}
+	var ports []string
+	for _, port := range w.interfaces {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		conditions = append(conditions, ovsdb.Condition{Column: "logical_port", Function: ovsdb.FuncEq, Value: port})
+	}
+	if len(conditions) == 0 || fmt.Sprint(conditions) == fmt.Sprint(w.conditions) {
+		w.mu.Unlock()
+		return nil
+	}
+	old := w.monitor
+	w.seq++
+	w.monitor = fmt.Sprintf("bindings-%d", w.seq)
+	w.conditions = conditions
+	monitor := w.monitor
+	w.mu.Unlock()
+
+	initial, err := w.sb.MonitorCond("OVN_Southbound", monitor, ovsdb.MonitorCondRequests{
+		"Port_Binding": {Columns: []ovsdb.ID{"logical_port", "chassis"}, Where: conditions},
+	})
+	if err != nil {
+		return err
+	}
+	if old != "" {
+		if err := w.sb.MonitorCancel(old); err != nil {
+			return err
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.monitor != monitor {
+		// replaced meanwhile
+		return nil
+	}
+	w.bindings = make(map[ovsdb.UUID]binding)
+	for uuid, update := range initial["Port_Binding"] {
+		if update.Initial != nil {
+			w.bindings[uuid] = decodeBinding(*update.Initial)
+		}
+	}
+	w.report()
+	return nil
+}
+
+// chassisUUID returns the UUID of the Chassis row of the local chassis, empty if it's not registered
+func (w *watcher) chassisUUID() (ovsdb.UUID, error) {
+	rows, err := w.sbCache.List("Chassis")
+	if err != nil {
+		return "", err
+	}
+	for _, row := range rows {
+		var chassis struct {
+			UUID ovsdb.UUID `json:"_uuid"`
+			Name string     `json:"name"`
+		}
+		if err := json.Unmarshal(row, &chassis); err == nil && chassis.Name == w.chassis {
+			return chassis.UUID, nil
+		}
+	}
+	return "", nil
+}
+
+// chassisName returns the name of the chassis uuid
+func (w *watcher) chassisName(uuid ovsdb.UUID) string {
+	row, err := w.sbCache.Get("Chassis", uuid)
+	if err != nil {
+		return string(uuid)
+	}
+	var chassis struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(row, &chassis)
+	return chassis.Name
+}
+
+// Update implements ovsdb.NotificationHandler interface, the southbound database is monitored with MonitorCond
+func (w *watcher) Update(jsonValue ovsdb.Value, updates ovsdb.TableUpdates) error {
+	return nil
+}
+
+// Locked implements ovsdb.NotificationHandler interface
+func (w *watcher) Locked(lock ovsdb.ID) error {
+	return nil
+}
+
+// Stolen implements ovsdb.NotificationHandler interface
+func (w *watcher) Stolen(lock ovsdb.ID) error {
+	return nil
+}
+
+// Update2 implements ovsdb.Update2Handler interface, it applies updates of the Port_Binding monitor
+func (w *watcher) Update2(jsonValue ovsdb.Value, updates ovsdb.TableUpdates2) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if jsonValue != w.monitor {
+		// an update of a replaced monitor
+		return nil
+	}
+	for uuid, update := range updates["Port_Binding"] {
+		switch {
+		case update.Delete:
+			delete(w.bindings, uuid)
+		case update.Insert != nil:
+			w.bindings[uuid] = decodeBinding(*update.Insert)
+		case update.Modify != nil:
+			// modified rows hold the differences of the columns, read the row again instead of applying them
+			w.sbCache.Invalidate("Port_Binding", uuid)
+			row, err := w.sbCache.Get("Port_Binding", uuid)
+			if err == ovsdb.ErrRowNotFound {
+				delete(w.bindings, uuid)
+			} else if err == nil {
+				w.bindings[uuid] = decodeBinding(row)
+			}
+		}
+	}
+	w.report()
+	return nil
+}
+
+// decodeBinding decodes a Port_Binding row, columns with default values may be omitted
+func decodeBinding(row json.RawMessage) binding {
+	var columns struct {
+		LogicalPort string      `json:"logical_port"`
+		Chassis     ovsdb.Value `json:"chassis"`
+	}
+	json.Unmarshal(row, &columns)
+	var chassis []ovsdb.UUID
+	if columns.Chassis != nil {
+		ovsdb.ConvertFromValue(columns.Chassis, &chassis)
+	}
+	b := binding{logicalPort: columns.LogicalPort}
+	if len(chassis) != 0 {
+		b.chassis = chassis[0]
+	}
+	return b
+}
+
+// report prints the state transitions of logical ports, w.mu must be held
+func (w *watcher) report() {
+	states := make(map[string]string)
+	for _, port := range w.interfaces {
+		states[port] = "unbound"
+	}
+	for _, b := range w.bindings {
+		_, local := states[b.logicalPort]
+		switch {
+		case b.chassis == "":
+		case b.chassis == w.localChassis && local:
+			states[b.logicalPort] = "bound here"
+		case b.chassis == w.localChassis:
+			states[b.logicalPort] = "bound here without local interface"
+		case local:
+			states[b.logicalPort] = "bound to " + w.chassisName(b.chassis)
+		}
+	}
+	for port := range w.states {
+		if _, ok := states[port]; !ok {
+			states[port] = "gone"
+		}
+	}
+
+	var ports []string
+	for port := range states {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		previous, ok := w.states[port]
+		if !ok {
+			previous = "new"
+		}
+		if states[port] != previous {
+			fmt.Printf("%s %s: %s -> %s\n", time.Now().Format(time.RFC3339), port, previous, states[port])
+		}
+		if states[port] == "gone" {
+			delete(states, port)
+		}
+	}
+	w.states = states
+}
@@ -0,0 +1,92 @@
+package ovsdb
+
+import (
+	"sort"
+)
+
+// ColumnExclusion selects columns left out of monitors and caches, it's a bitmask
+type ColumnExclusion int
+
+// Supported ColumnExclusions
+const (
+	// ExcludeEphemeral leaves out the ephemeral columns of the schema, which hold runtime state
+	ExcludeEphemeral ColumnExclusion = 1 << iota
+	// ExcludeStatistics leaves out the columns in StatisticsColumns, e.g. the statistics of Interface
+	ExcludeStatistics
+)
+
+// StatisticsColumns are the names of the columns holding statistics in OVS and OVN databases, in any table.
+// They change constantly and are of no interest to consumers of configuration.
+var StatisticsColumns = map[ID]bool{
+	"statistics": true,
+}
+
+// excluded returns true if exclude leaves out column
+func (exclude ColumnExclusion) excluded(column ID, columnSchema *ColumnSchema) bool {
+	return exclude&ExcludeEphemeral != 0 && columnSchema.Ephemeral ||
+		exclude&ExcludeStatistics != 0 && StatisticsColumns[column]
+}
+
+// Columns returns the sorted columns of table in dbSchema which are not left out by exclude,
+// nil if there's no such table
+func (exclude ColumnExclusion) Columns(dbSchema *DatabaseSchema, table ID) []ID {
+	tableSchema, ok := dbSchema.Tables[table]
+	if !ok {
+		return nil
+	}
+	columns := []ID{}
+	for column, columnSchema := range tableSchema.Columns {
+		if !exclude.excluded(column, columnSchema) {
+			columns = append(columns, column)
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i] < columns[j] })
+	return columns
+}
+
+// NewMonitorRequests returns MonitorRequests of tables of dbSchema, all tables if none is given,
+// monitoring the columns not left out by exclude, e.g. ExcludeEphemeral|ExcludeStatistics to only
+// monitor the configuration of Interface, sparing the updates of its statistics.
+// Unknown tables are left out.
+func NewMonitorRequests(dbSchema *DatabaseSchema, exclude ColumnExclusion, tables ...ID) MonitorRequests {
+	if len(tables) == 0 {
+		for table := range dbSchema.Tables {
+			tables = append(tables, table)
+		}
+	}
+	requests := make(MonitorRequests, len(tables))
+	for _, table := range tables {
+		if columns := exclude.Columns(dbSchema, table); columns != nil {
+			requests[table] = MonitorRequest{Columns: columns}
+		}
+	}
+	return requests
+}
+
+// ExcludeColumns makes the cache select the columns of tables in dbSchema not left out by exclude,
+// instead of all columns, e.g. to spare the memory for statistics. Cached rows are dropped.
+// A zero exclude selects all columns again.
+func (cache *ReadThroughCache) ExcludeColumns(dbSchema *DatabaseSchema, exclude ColumnExclusion) {
+	var columns map[ID][]ID
+	if exclude != 0 {
+		columns = make(map[ID][]ID, len(dbSchema.Tables))
+		for table := range dbSchema.Tables {
+			// "_uuid" identifies cached rows
+			columns[table] = append([]ID{"_uuid"}, exclude.Columns(dbSchema, table)...)
+		}
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	// rows of tables being selected with the previous columns are not cached either
+	for table := range cache.columns {
+		cache.drop(table)
+	}
+	for table := range dbSchema.Tables {
+		cache.drop(table)
+	}
+	for table := range cache.rows {
+		cache.drop(table)
+	}
+	cache.columns = columns
+	cache.changed()
+}
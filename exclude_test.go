@@ -0,0 +1,83 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+const interfaceSchema = `{"name": "Open_vSwitch", "version": "8.3.0", "tables": {
+	"Interface": {"columns": {
+		"name": {"type": "string"},
+		"mtu_request": {"type": {"key": "integer", "min": 0, "max": 1}},
+		"link_state": {"type": {"key": "string", "min": 0, "max": 1}, "ephemeral": true},
+		"statistics": {"type": {"key": "string", "value": "integer", "min": 0, "max": "unlimited"}}}},
+	"Bridge": {"columns": {"name": {"type": "string"}}}}}`
+
+func TestNewMonitorRequests(t *testing.T) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(interfaceSchema), &dbSchema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	tests := []struct {
+		exclude ColumnExclusion
+		want    []ID
+	}{
+		{0, []ID{"link_state", "mtu_request", "name", "statistics"}},
+		{ExcludeEphemeral, []ID{"mtu_request", "name", "statistics"}},
+		{ExcludeStatistics, []ID{"link_state", "mtu_request", "name"}},
+		{ExcludeEphemeral | ExcludeStatistics, []ID{"mtu_request", "name"}},
+	}
+	for _, test := range tests {
+		requests := NewMonitorRequests(&dbSchema, test.exclude, "Interface", "Port")
+		want := MonitorRequests{"Interface": {Columns: test.want}}
+		if !reflect.DeepEqual(requests, want) {
+			t.Errorf("NewMonitorRequests(%d) = %v, want %v", test.exclude, requests, want)
+		}
+	}
+	if requests := NewMonitorRequests(&dbSchema, ExcludeStatistics); len(requests) != 2 {
+		t.Errorf("NewMonitorRequests of all tables = %v", requests)
+	}
+}
+
+func TestReadThroughCacheExcludeColumns(t *testing.T) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(interfaceSchema), &dbSchema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	selected := make(chan []ID, 1)
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		var op struct {
+			Columns []ID `json:"columns"`
+		}
+		json.Unmarshal(params[1], &op)
+		selected <- op.Columns
+		row := map[string]interface{}{"_uuid": []string{"uuid", "a0000000-0000-0000-0000-000000000000"}, "name": "eth0"}
+		return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}, nil
+	})
+
+	cache := NewReadThroughCache(client, "Open_vSwitch", time.Hour)
+	cache.ExcludeColumns(&dbSchema, ExcludeEphemeral|ExcludeStatistics)
+	if _, err := cache.List("Interface"); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if columns := <-selected; !reflect.DeepEqual(columns, []ID{"_uuid", "mtu_request", "name"}) {
+		t.Errorf("selected columns %v", columns)
+	}
+	cache.ExcludeColumns(&dbSchema, 0)
+	if _, err := cache.List("Interface"); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if columns := <-selected; columns != nil {
+		t.Errorf("selected columns %v, want all", columns)
+	}
+}
@@ -0,0 +1,147 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+)
+
+// ChangeOp is the kind of change of a ChangeEvent
+type ChangeOp string
+
+// ChangeOps of ChangeEvents
+const (
+	ChangeInsert ChangeOp = "insert"
+	ChangeModify ChangeOp = "modify"
+	ChangeDelete ChangeOp = "delete"
+)
+
+// ChangeEvent is the change of a row normalized from a TableUpdates, for exporting to other systems.
+// For a modification Old holds the previous values of the modified columns only, as sent by the server,
+// and New the whole row.
+type ChangeEvent struct {
+	Database ID              `json:"database"`
+	Table    ID              `json:"table"`
+	UUID     string          `json:"uuid"`
+	Op       ChangeOp        `json:"op"`
+	Old      json.RawMessage `json:"old,omitempty"`
+	New      json.RawMessage `json:"new,omitempty"`
+	// Revision increases with every event published by an ExportingHandler, in the order of the update
+	// notifications, so consumers can drop events older than the last one they applied to a row.
+	// It's 0 for events not published by an ExportingHandler.
+	Revision uint64 `json:"revision,omitempty"`
+}
+
+// ChangeEvents normalizes the updates of database db into events, sorted by table and UUID
+func ChangeEvents(db ID, updates TableUpdates) []ChangeEvent {
+	var events []ChangeEvent
+	for table, tableUpdate := range updates {
+		for uuid, rowUpdate := range tableUpdate {
+			event := ChangeEvent{Database: db, Table: table, UUID: string(uuid)}
+			switch {
+			case rowUpdate.Old == nil:
+				event.Op = ChangeInsert
+			case rowUpdate.New == nil:
+				event.Op = ChangeDelete
+			default:
+				event.Op = ChangeModify
+			}
+			if rowUpdate.Old != nil {
+				event.Old = *rowUpdate.Old
+			}
+			if rowUpdate.New != nil {
+				event.New = *rowUpdate.New
+			}
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Table != events[j].Table {
+			return events[i].Table < events[j].Table
+		}
+		return events[i].UUID < events[j].UUID
+	})
+	return events
+}
+
+// ChangeSink receives the change events of an exporter, e.g. a producer of a message bus.
+// A batch holds the events of one update notification.
+type ChangeSink interface {
+	Publish(events []ChangeEvent) error
+}
+
+// ChangeSinkFunc is an adapter to use a function as a ChangeSink
+type ChangeSinkFunc func(events []ChangeEvent) error
+
+// Publish implements ChangeSink interface
+func (fn ChangeSinkFunc) Publish(events []ChangeEvent) error {
+	return fn(events)
+}
+
+// WriterSink is a ChangeSink writing events to an io.Writer as JSON, one event per line
+type WriterSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewWriterSink creates a WriterSink writing to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{encoder: json.NewEncoder(w)}
+}
+
+// Publish implements ChangeSink interface
+func (s *WriterSink) Publish(events []ChangeEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		if err := s.encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportingHandler is a NotificationHandler which publishes the updates of monitors on database db
+// to a ChangeSink before delivering them to the wrapped handler, turning the database into a
+// change data capture source. Wrap it in a JournalingHandler to publish the journal after a restart.
+type ExportingHandler struct {
+	NotificationHandler
+
+	db   ID
+	sink ChangeSink
+
+	mu       sync.Mutex
+	revision uint64
+}
+
+// NewExportingHandler wraps handler into an ExportingHandler publishing changes of database db to sink
+func NewExportingHandler(handler NotificationHandler, db ID, sink ChangeSink) *ExportingHandler {
+	return &ExportingHandler{NotificationHandler: handler, db: db, sink: sink}
+}
+
+// Update implements NotificationHandler interface
+func (h *ExportingHandler) Update(jsonValue Value, updates TableUpdates) error {
+	if events := ChangeEvents(h.db, updates); len(events) != 0 {
+		// revisions are published in order
+		h.mu.Lock()
+		for i := range events {
+			h.revision++
+			events[i].Revision = h.revision
+		}
+		err := h.sink.Publish(events)
+		h.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return h.NotificationHandler.Update(jsonValue, updates)
+}
+
+// MonitorCanceled implements MonitorCanceledHandler interface
+func (h *ExportingHandler) MonitorCanceled(jsonValue Value) error {
+	if handler, ok := h.NotificationHandler.(MonitorCanceledHandler); ok {
+		return handler.MonitorCanceled(jsonValue)
+	}
+	return nil
+}
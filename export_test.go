@@ -0,0 +1,60 @@
+package ovsdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestExportingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewExportingHandler(&NotificationHandlerFuncs{}, "OVN_Northbound", NewWriterSink(&buf))
+
+	old := json.RawMessage(`{"name":"sw0"}`)
+	renamed := json.RawMessage(`{"name":"sw1"}`)
+	port := json.RawMessage(`{"name":"p0"}`)
+	err := handler.Update("mon", TableUpdates{
+		"Logical_Switch": {
+			ls1: {Old: &old, New: &renamed},
+			ls2: {Old: &old},
+		},
+		"Logical_Switch_Port": {
+			lsp1: {New: &port},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	want := `{"database":"OVN_Northbound","table":"Logical_Switch","uuid":"` + ls1 + `","op":"modify","old":{"name":"sw0"},"new":{"name":"sw1"},"revision":1}
+{"database":"OVN_Northbound","table":"Logical_Switch","uuid":"` + ls2 + `","op":"delete","old":{"name":"sw0"},"revision":2}
+{"database":"OVN_Northbound","table":"Logical_Switch_Port","uuid":"` + lsp1 + `","op":"insert","new":{"name":"p0"},"revision":3}
+`
+	if buf.String() != want {
+		t.Errorf("exported events:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestExportingHandlerRevisionsInOrder(t *testing.T) {
+	var published []uint64
+	handler := NewExportingHandler(&NotificationHandlerFuncs{}, "OVN_Northbound", ChangeSinkFunc(func(events []ChangeEvent) error {
+		published = append(published, events[0].Revision)
+		return nil
+	}))
+	row := json.RawMessage(`{"name":"sw0"}`)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.Update("mon", TableUpdates{"Logical_Switch": {ls1: {New: &row}}})
+		}()
+	}
+	wg.Wait()
+	for i, revision := range published {
+		if revision != uint64(i+1) {
+			t.Fatalf("published revisions %v, want them in order", published)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package ovsdb
+
+import (
+	"strings"
+)
+
+// Conventional string-to-string map columns present in most OVS and OVN tables
+const (
+	ExternalIDs = "external_ids"
+	OtherConfig = "other_config"
+)
+
+// KeyNamespace namespaces the keys a controller owns in a shared map column such as external_ids,
+// a key "owner" in KeyNamespace("myapp") is stored as "myapp:owner".
+// Keeping to its own namespace lets multiple controllers safely coexist on the same rows.
+type KeyNamespace string
+
+// Key returns the namespaced key of name
+func (ns KeyNamespace) Key(name string) string {
+	return string(ns) + ":" + name
+}
+
+// Name returns the name of a namespaced key, ok is false if key is not in ns
+func (ns KeyNamespace) Name(key string) (name string, ok bool) {
+	prefix := ns.Key("")
+	if !strings.HasPrefix(key, prefix) {
+		return "", false
+	}
+	return key[len(prefix):], true
+}
+
+// Get returns the value of name in the map column content m
+func (ns KeyNamespace) Get(m map[string]string, name string) (string, bool) {
+	value, ok := m[ns.Key(name)]
+	return value, ok
+}
+
+// Set sets the value of name in the map column content m
+func (ns KeyNamespace) Set(m map[string]string, name, value string) {
+	m[ns.Key(name)] = value
+}
+
+// Delete deletes name from the map column content m
+func (ns KeyNamespace) Delete(m map[string]string, name string) {
+	delete(m, ns.Key(name))
+}
+
+// Extract returns the keys of ns in the map column content m, by their names
+func (ns KeyNamespace) Extract(m map[string]string) map[string]string {
+	names := make(map[string]string)
+	for key, value := range m {
+		if name, ok := ns.Name(key); ok {
+			names[name] = value
+		}
+	}
+	return names
+}
+
+// Merge returns the mutations of map column changing the keys of ns from observed, the current content
+// of the column, to desired, which maps names to values. Keys of ns not in desired are deleted,
+// keys outside of ns are never touched. See MapMergeResult.Mutate to build the operation.
+func (ns KeyNamespace) Merge(column ID, observed, desired map[string]string) *MapMergeResult {
+	base := make(map[string]string)
+	for key, value := range observed {
+		if _, ok := ns.Name(key); ok {
+			base[key] = value
+		}
+	}
+	wanted := make(map[string]string, len(desired))
+	for name, value := range desired {
+		wanted[ns.Key(name)] = value
+	}
+	return MergeMap(column, base, observed, wanted)
+}
@@ -0,0 +1,46 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestKeyNamespace(t *testing.T) {
+	ns := KeyNamespace("myapp")
+	ids := map[string]string{"other:owner": "them"}
+	ns.Set(ids, "owner", "me")
+	if ids["myapp:owner"] != "me" {
+		t.Errorf("Set didn't set the namespaced key: %v", ids)
+	}
+	if value, ok := ns.Get(ids, "owner"); !ok || value != "me" {
+		t.Errorf("Get(owner) = %q, %v", value, ok)
+	}
+	if names := ns.Extract(ids); !reflect.DeepEqual(names, map[string]string{"owner": "me"}) {
+		t.Errorf("Extract = %v", names)
+	}
+	ns.Delete(ids, "owner")
+	if !reflect.DeepEqual(ids, map[string]string{"other:owner": "them"}) {
+		t.Errorf("Delete left %v", ids)
+	}
+	if _, ok := ns.Name("myapp2:owner"); ok {
+		t.Error("Name(myapp2:owner) is in namespace myapp")
+	}
+}
+
+func TestKeyNamespaceMerge(t *testing.T) {
+	ns := KeyNamespace("myapp")
+	observed := map[string]string{"other:owner": "them", "myapp:owner": "me", "myapp:stale": "x"}
+	result := ns.Merge(ExternalIDs, observed, map[string]string{"owner": "me", "zone": "a"})
+	if len(result.Conflicts) != 0 {
+		t.Errorf("Conflicts = %v, want none", result.Conflicts)
+	}
+	bytes, err := json.Marshal(result.Mutations)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	want := `[["external_ids","delete","myapp:stale"],["external_ids","insert",["map",[["myapp:zone","a"]]]]]`
+	if string(bytes) != want {
+		t.Errorf("Mutations = %s, want %s", bytes, want)
+	}
+}
@@ -0,0 +1,122 @@
+package ovsdb
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// DefaultAttemptDelay is the delay between connection attempts to the addresses of a host name,
+// the "Connection Attempt Delay" recommended by RFC 8305
+const DefaultAttemptDelay = 250 * time.Millisecond
+
+// WithAttemptDelay sets the delay between connection attempts to the addresses of a host name
+func WithAttemptDelay(delay time.Duration) DialOption {
+	return func(o *dialOptions) {
+		o.attemptDelay = delay
+	}
+}
+
+// lookupIPAddr resolves host names, it's replaced in tests
+var lookupIPAddr = net.DefaultResolver.LookupIPAddr
+
+// dialTCP connects to address. If its host is a name resolving to several addresses, they are
+// dialed as in RFC 8305 (happy eyeballs): addresses are interleaved by family starting with IPv6,
+// a new attempt starts every delay or as soon as the previous one fails, while earlier attempts
+// continue, and the first established connection is returned.
+func dialTCP(ctx context.Context, dialer ContextDialer, address string, delay time.Duration) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+	ips, err := lookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 1 {
+		return dialer.DialContext(ctx, "tcp", net.JoinHostPort(ips[0].String(), port))
+	}
+	if delay <= 0 {
+		delay = DefaultAttemptDelay
+	}
+
+	type attempt struct {
+		conn net.Conn
+		err  error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	addresses := interleaveFamilies(ips)
+	results := make(chan attempt, len(addresses))
+	pending := 0
+	dial := func() {
+		ip := addresses[0]
+		addresses = addresses[1:]
+		pending++
+		go func() {
+			conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip.String(), port))
+			results <- attempt{conn, err}
+		}()
+	}
+
+	var firstErr error
+	dial()
+	for pending != 0 {
+		var next <-chan time.Time
+		if len(addresses) != 0 {
+			next = time.After(delay)
+		}
+		select {
+		case <-next:
+			dial()
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				// canceled attempts may still have connected
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						if late := <-results; late.conn != nil {
+							late.conn.Close()
+						}
+					}
+				}(pending)
+				return result.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			// don't wait for the delay after a failed attempt
+			if len(addresses) != 0 {
+				dial()
+			}
+		}
+	}
+	return nil, firstErr
+}
+
+// interleaveFamilies orders ips alternating IPv6 and IPv4 addresses, starting with IPv6
+func interleaveFamilies(ips []net.IPAddr) []net.IPAddr {
+	var v6, v4 []net.IPAddr
+	for _, ip := range ips {
+		if ip.IP.To4() != nil {
+			v4 = append(v4, ip)
+		} else {
+			v6 = append(v6, ip)
+		}
+	}
+	ordered := make([]net.IPAddr, 0, len(ips))
+	for len(v6) != 0 || len(v4) != 0 {
+		if len(v6) != 0 {
+			ordered = append(ordered, v6[0])
+			v6 = v6[1:]
+		}
+		if len(v4) != 0 {
+			ordered = append(ordered, v4[0])
+			v4 = v4[1:]
+		}
+	}
+	return ordered
+}
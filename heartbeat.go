@@ -0,0 +1,165 @@
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HeartbeatConfig configures a Heartbeat
+type HeartbeatConfig struct {
+	// Database and Table hold the heartbeat rows
+	Database ID
+	Table    ID
+	// Where selects the row of this client
+	Where []Condition
+	// Column is the heartbeat column, it's incremented by 1 every Interval unless Next is set
+	Column ID
+	// Next, if not nil, returns the value written to Column every Interval, e.g. a timestamp
+	Next func() Value
+	// Interval between two heartbeats
+	Interval time.Duration
+	// PeerColumn, if not empty, is the column identifying the rows of peers, e.g. "name".
+	// Heartbeats of peers are observed only if it's set.
+	PeerColumn ID
+	// PeerWhere selects the rows of peers, default to all rows of Table
+	PeerWhere []Condition
+}
+
+// PeerHeartbeat is the last observed heartbeat of a peer
+type PeerHeartbeat struct {
+	// Value is the value of the heartbeat column
+	Value Value
+	// LastChange is the local time when a change of Value was observed
+	LastChange time.Time
+}
+
+// Alive returns true if the heartbeat changed within timeout
+func (peer PeerHeartbeat) Alive(timeout time.Duration) bool {
+	return time.Since(peer.LastChange) <= timeout
+}
+
+// Heartbeat records liveness of a client in a OVSDB row by updating a column periodically,
+// e.g. nb_cfg of a Chassis_Private row, and observes heartbeats of peers in the same table
+type Heartbeat struct {
+	client *Client
+	config HeartbeatConfig
+
+	mu    sync.Mutex
+	peers map[string]PeerHeartbeat
+}
+
+// NewHeartbeat creates a Heartbeat
+func NewHeartbeat(client *Client, config HeartbeatConfig) *Heartbeat {
+	if len(config.PeerWhere) == 0 {
+		config.PeerWhere = MatchAll()
+	}
+	return &Heartbeat{
+		client: client,
+		config: config,
+		peers:  make(map[string]PeerHeartbeat),
+	}
+}
+
+// Run beats every Interval until ctx is done or a heartbeat fails
+func (hb *Heartbeat) Run(ctx context.Context) error {
+	ticker := time.NewTicker(hb.config.Interval)
+	defer ticker.Stop()
+	for {
+		if err := hb.Beat(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Beat updates the heartbeat column of this client and observes heartbeats of peers in one transaction
+func (hb *Heartbeat) Beat() error {
+	var beat Operation
+	if hb.config.Next != nil {
+		beat = &UpdateOperation{
+			Table: hb.config.Table,
+			Where: hb.config.Where,
+			Row:   map[ID]Value{hb.config.Column: hb.config.Next()},
+		}
+	} else {
+		beat = &MutateOperation{
+			Table:     hb.config.Table,
+			Where:     hb.config.Where,
+			Mutations: []Mutation{{hb.config.Column, MutatorPluEq, 1}},
+		}
+	}
+	ops := []Operation{beat}
+	if hb.config.PeerColumn != "" {
+		ops = append(ops, &SelectOperation{
+			Table:   hb.config.Table,
+			Where:   hb.config.PeerWhere,
+			Columns: []ID{hb.config.PeerColumn, hb.config.Column},
+		})
+	}
+
+	result, err := hb.client.Transact(hb.config.Database, ops...)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) != 0 {
+		return result.Errors
+	}
+	if hb.config.PeerColumn == "" {
+		return nil
+	}
+	rows, err := result.SelectAt(1)
+	if err != nil {
+		return err
+	}
+	return hb.observe(rows.Rows, time.Now())
+}
+
+// Peers returns the last observed heartbeats of peers, keyed by the value of PeerColumn
+func (hb *Heartbeat) Peers() map[string]PeerHeartbeat {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	peers := make(map[string]PeerHeartbeat, len(hb.peers))
+	for peer, heartbeat := range hb.peers {
+		peers[peer] = heartbeat
+	}
+	return peers
+}
+
+// observe updates the heartbeats of peers from selected rows
+func (hb *Heartbeat) observe(rows []*json.RawMessage, now time.Time) error {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	seen := make(map[string]bool, len(rows))
+	for _, raw := range rows {
+		if raw == nil {
+			continue
+		}
+		var row map[ID]interface{}
+		if err := json.Unmarshal(*raw, &row); err != nil {
+			return err
+		}
+		peer := fmt.Sprint(row[hb.config.PeerColumn])
+		value := row[hb.config.Column]
+		seen[peer] = true
+
+		last, ok := hb.peers[peer]
+		if ok && ValueEqual(last.Value, value) {
+			continue
+		}
+		hb.peers[peer] = PeerHeartbeat{Value: value, LastChange: now}
+	}
+	// forget peers whose rows are gone
+	for peer := range hb.peers {
+		if !seen[peer] {
+			delete(hb.peers, peer)
+		}
+	}
+	return nil
+}
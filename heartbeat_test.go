@@ -0,0 +1,48 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func heartbeatRows(rows ...string) []*json.RawMessage {
+	var raws []*json.RawMessage
+	for _, row := range rows {
+		raw := json.RawMessage(row)
+		raws = append(raws, &raw)
+	}
+	return raws
+}
+
+func TestHeartbeatObserve(t *testing.T) {
+	hb := NewHeartbeat(nil, HeartbeatConfig{Column: "nb_cfg", PeerColumn: "name"})
+	start := time.Now()
+
+	if err := hb.observe(heartbeatRows(`{"name":"hv1","nb_cfg":1}`, `{"name":"hv2","nb_cfg":5}`), start); err != nil {
+		t.Fatalf("observe failed: %v", err)
+	}
+	later := start.Add(time.Minute)
+	if err := hb.observe(heartbeatRows(`{"name":"hv1","nb_cfg":2}`, `{"name":"hv2","nb_cfg":5}`), later); err != nil {
+		t.Fatalf("observe failed: %v", err)
+	}
+
+	peers := hb.Peers()
+	if len(peers) != 2 {
+		t.Fatalf("got %d peers, want 2", len(peers))
+	}
+	if !peers["hv1"].LastChange.Equal(later) || !ValueEqual(peers["hv1"].Value, 2) {
+		t.Errorf("hv1 = %+v, want value 2 changed at %v", peers["hv1"], later)
+	}
+	if !peers["hv2"].LastChange.Equal(start) {
+		t.Errorf("hv2 = %+v, want unchanged since %v", peers["hv2"], start)
+	}
+
+	// hv2 is gone
+	if err := hb.observe(heartbeatRows(`{"name":"hv1","nb_cfg":2}`), later); err != nil {
+		t.Fatalf("observe failed: %v", err)
+	}
+	if _, ok := hb.Peers()["hv2"]; ok {
+		t.Error("hv2 should be forgotten")
+	}
+}
@@ -0,0 +1,168 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RowVersion is a version of a row recorded by a RowHistory
+type RowVersion struct {
+	// Time is when the version was recorded
+	Time time.Time
+	// Row is the content of the row, nil if the row was deleted
+	Row json.RawMessage
+}
+
+// RowHistory retains past versions of the rows of monitored tables, recorded from monitor updates,
+// to answer questions like "what did this Logical_Switch_Port look like 10 minutes ago" when debugging
+// transient failures. Versions are retained up to a number per row and for a duration, a zero limit
+// means no limit. The version of a row in effect at the start of the retained duration is kept so
+// the row can be looked up at any time of it.
+// Monitors must use the default "monitor" semantics where "new" holds all monitored columns.
+type RowHistory struct {
+	maxVersions int
+	maxAge      time.Duration
+	now         func() time.Time
+
+	mu        sync.Mutex
+	rows      map[ID]map[UUID][]RowVersion
+	lastSweep time.Time
+}
+
+// NewRowHistory creates a RowHistory retaining at most maxVersions versions of each row for at most maxAge
+func NewRowHistory(maxVersions int, maxAge time.Duration) *RowHistory {
+	return &RowHistory{
+		maxVersions: maxVersions,
+		maxAge:      maxAge,
+		now:         time.Now,
+		rows:        make(map[ID]map[UUID][]RowVersion),
+	}
+}
+
+// Record records the versions of rows in updates
+func (h *RowHistory) Record(updates TableUpdates) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// the clock is read under the lock so the versions of a row are recorded in time order
+	now := h.now()
+	for table, tableUpdate := range updates {
+		tableRows, ok := h.rows[table]
+		if !ok {
+			tableRows = make(map[UUID][]RowVersion)
+			h.rows[table] = tableRows
+		}
+		for uuid, rowUpdate := range tableUpdate {
+			version := RowVersion{Time: now}
+			if rowUpdate.New != nil {
+				version.Row = append(json.RawMessage{}, *rowUpdate.New...)
+			}
+			tableRows[uuid] = h.prune(append(tableRows[uuid], version), now)
+		}
+	}
+	// versions of rows which are not updated anymore expire too
+	if h.maxAge > 0 && now.Sub(h.lastSweep) >= h.maxAge/2 {
+		h.sweep(now)
+	}
+}
+
+// Versions returns the retained versions of the row uuid of table, oldest first
+func (h *RowHistory) Versions(table ID, uuid UUID) []RowVersion {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]RowVersion{}, h.rows[table][uuid]...)
+}
+
+// At returns the content of the row uuid of table at time t, ok is false if the row didn't exist
+// at t or its version at t is not retained
+func (h *RowHistory) At(table ID, uuid UUID, t time.Time) (row json.RawMessage, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	versions := h.rows[table][uuid]
+	// the number of versions recorded up to t
+	i := sort.Search(len(versions), func(i int) bool { return versions[i].Time.After(t) })
+	if i == 0 || versions[i-1].Row == nil {
+		return nil, false
+	}
+	return versions[i-1].Row, true
+}
+
+// Prune drops the versions which are not retained anymore
+func (h *RowHistory) Prune() {
+	now := h.now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sweep(now)
+}
+
+// sweep prunes the versions of all rows, h.mu must be held
+func (h *RowHistory) sweep(now time.Time) {
+	for table, tableRows := range h.rows {
+		for uuid, versions := range tableRows {
+			if versions = h.prune(versions, now); len(versions) == 0 {
+				delete(tableRows, uuid)
+			} else {
+				tableRows[uuid] = versions
+			}
+		}
+		if len(tableRows) == 0 {
+			delete(h.rows, table)
+		}
+	}
+	h.lastSweep = now
+}
+
+// prune returns the retained versions of a row
+func (h *RowHistory) prune(versions []RowVersion, now time.Time) []RowVersion {
+	start := 0
+	if h.maxVersions > 0 && len(versions) > h.maxVersions {
+		start = len(versions) - h.maxVersions
+	}
+	if h.maxAge > 0 {
+		cutoff := now.Add(-h.maxAge)
+		// keep the last version before the cutoff, it's in effect at the cutoff, unless it's a deletion
+		i := sort.Search(len(versions), func(i int) bool { return versions[i].Time.After(cutoff) })
+		if i > 0 && versions[i-1].Row == nil {
+			i++
+		}
+		if i-1 > start {
+			start = i - 1
+		}
+	}
+	if start == 0 {
+		return versions
+	}
+	if start >= len(versions) {
+		return nil
+	}
+	// don't retain the dropped versions in the underlying array
+	return append([]RowVersion(nil), versions[start:]...)
+}
+
+// HistoryHandler is a NotificationHandler which records updates to a RowHistory before
+// delivering them to the wrapped handler
+type HistoryHandler struct {
+	NotificationHandler
+
+	history *RowHistory
+}
+
+// NewHistoryHandler wraps handler into a HistoryHandler recording updates to history
+func NewHistoryHandler(handler NotificationHandler, history *RowHistory) *HistoryHandler {
+	return &HistoryHandler{NotificationHandler: handler, history: history}
+}
+
+// Update implements NotificationHandler interface
+func (h *HistoryHandler) Update(jsonValue Value, updates TableUpdates) error {
+	h.history.Record(updates)
+	return h.NotificationHandler.Update(jsonValue, updates)
+}
+
+// MonitorCanceled implements MonitorCanceledHandler interface
+func (h *HistoryHandler) MonitorCanceled(jsonValue Value) error {
+	if handler, ok := h.NotificationHandler.(MonitorCanceledHandler); ok {
+		return handler.MonitorCanceled(jsonValue)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRowHistory(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	history := NewRowHistory(3, 10*time.Minute)
+	history.now = func() time.Time { return now }
+	record := func(old, new string) {
+		history.Record(TableUpdates{"Logical_Switch_Port": {lsp1: databaseRowUpdate(old, new)}})
+	}
+	at := func(minutes int) string {
+		row, ok := history.At("Logical_Switch_Port", lsp1, start.Add(time.Duration(minutes)*time.Minute))
+		if !ok {
+			return ""
+		}
+		var columns struct{ Up bool }
+		json.Unmarshal(row, &columns)
+		if columns.Up {
+			return "up"
+		}
+		return "down"
+	}
+
+	record("", `{"up":false}`)
+	now = start.Add(5 * time.Minute)
+	record(`{"up":false}`, `{"up":true}`)
+	now = start.Add(6 * time.Minute)
+	record(`{"up":true}`, `{"up":false}`)
+	for minutes, want := range map[int]string{-1: "", 0: "down", 4: "down", 5: "up", 6: "down", 30: "down"} {
+		if got := at(minutes); got != want {
+			t.Errorf("row at %d minutes is %q, want %q", minutes, got, want)
+		}
+	}
+
+	// at most 3 versions are retained
+	now = start.Add(7 * time.Minute)
+	record(`{"up":false}`, `{"up":true}`)
+	if versions := history.Versions("Logical_Switch_Port", lsp1); len(versions) != 3 {
+		t.Errorf("got %d versions, want 3", len(versions))
+	}
+	if got := at(0); got != "" {
+		t.Errorf("dropped version at 0 minutes is %q", got)
+	}
+
+	// versions older than 10 minutes expire, except the one in effect 10 minutes ago
+	now = start.Add(16*time.Minute + 30*time.Second)
+	history.Prune()
+	if versions := history.Versions("Logical_Switch_Port", lsp1); len(versions) != 2 {
+		t.Errorf("got %d versions, want 2", len(versions))
+	}
+	if got := at(6); got != "down" {
+		t.Errorf("row at 6 minutes is %q, want down", got)
+	}
+
+	// deleted rows are forgotten once the deletion expires
+	record(`{"up":true}`, "")
+	if _, ok := history.At("Logical_Switch_Port", lsp1, now); ok {
+		t.Error("deleted row should not exist")
+	}
+	now = now.Add(11 * time.Minute)
+	history.Prune()
+	if versions := history.Versions("Logical_Switch_Port", lsp1); len(versions) != 0 {
+		t.Errorf("got %d versions of an expired deleted row", len(versions))
+	}
+}
+
+func TestRowHistoryConcurrentRecords(t *testing.T) {
+	history := NewRowHistory(0, 0)
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ticks int64
+	history.now = func() time.Time {
+		return start.Add(time.Duration(atomic.AddInt64(&ticks, 1)) * time.Second)
+	}
+
+	row := json.RawMessage(`{"name":"p0"}`)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			history.Record(TableUpdates{"Logical_Switch_Port": {lsp1: {New: &row}}})
+		}()
+	}
+	wg.Wait()
+
+	versions := history.Versions("Logical_Switch_Port", lsp1)
+	for i := 1; i < len(versions); i++ {
+		if versions[i].Time.Before(versions[i-1].Time) {
+			t.Fatalf("version %d recorded at %v is older than the previous one at %v", i, versions[i].Time, versions[i-1].Time)
+		}
+	}
+}
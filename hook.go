@@ -0,0 +1,46 @@
+package ovsdb
+
+import (
+	"time"
+)
+
+// TransactHook is invoked after every transaction with its database, operations, result,
+// wall time and error, e.g. for audit logging of all changes made by a controller.
+// result holds per-operation errors, err is the error of the RPC itself.
+type TransactHook func(db ID, ops []Operation, result *TransactResult, duration time.Duration, err error)
+
+// registeredHook is a TransactHook added to a client, it's identified by its address for removal
+type registeredHook struct {
+	hook TransactHook
+}
+
+// AddTransactHook registers hook to be invoked after every transaction, hooks are invoked in the order added.
+// remove unregisters the hook, e.g. when the component it belongs to is discarded while the client lives on.
+func (c *Client) AddTransactHook(hook TransactHook) (remove func()) {
+	registered := &registeredHook{hook}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.transactHooks = append(c.transactHooks, registered)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		// runTransactHooks may iterate over the current slice, a new one is built
+		var hooks []*registeredHook
+		for _, h := range c.transactHooks {
+			if h != registered {
+				hooks = append(hooks, h)
+			}
+		}
+		c.transactHooks = hooks
+	}
+}
+
+// runTransactHooks invokes the registered transaction hooks
+func (c *Client) runTransactHooks(db ID, ops []Operation, result *TransactResult, duration time.Duration, err error) {
+	c.mu.Lock()
+	hooks := c.transactHooks
+	c.mu.Unlock()
+	for _, h := range hooks {
+		h.hook(db, ops, result, duration, err)
+	}
+}
@@ -0,0 +1,110 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+// hookCall is an invocation of a TransactHook
+type hookCall struct {
+	db       ID
+	ops      []Operation
+	result   *TransactResult
+	duration time.Duration
+	err      error
+}
+
+func TestTransactHooks(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+	server.SetDelay(time.Millisecond)
+
+	var calls []hookCall
+	var order []int
+	for i := 0; i < 2; i++ {
+		i := i
+		client.AddTransactHook(func(db ID, ops []Operation, result *TransactResult, duration time.Duration, err error) {
+			order = append(order, i)
+			if i == 0 {
+				calls = append(calls, hookCall{db, ops, result, duration, err})
+			}
+		})
+	}
+
+	op := &UpdateOperation{Table: "Bridge", Where: MatchAll(), Row: map[ID]Value{"name": "br0"}}
+	result, err := client.Transact("Open_vSwitch", op)
+	if err != nil {
+		t.Fatalf("Transact failed: %v", err)
+	}
+	server.FailOperation(0, "constraint violation", "duplicate name")
+	failed, err := client.Transact("Open_vSwitch", op)
+	if err != nil {
+		t.Fatalf("Transact failed: %v", err)
+	}
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		return nil, errors.New("database is read-only")
+	})
+	if _, err := client.Transact("OVN_Northbound", op); err == nil {
+		t.Fatal("Transact should fail with the error of the server")
+	}
+
+	if want := []int{0, 1, 0, 1, 0, 1}; !reflect.DeepEqual(order, want) {
+		t.Errorf("hooks invoked in order %v, want %v", order, want)
+	}
+	if len(calls) != 3 {
+		t.Fatalf("hook invoked %d times, want 3", len(calls))
+	}
+	for i, call := range calls {
+		if len(call.ops) != 1 || call.ops[0] != op {
+			t.Errorf("transaction %d: hook got operations %v, want %v", i, call.ops, op)
+		}
+		if call.duration < time.Millisecond {
+			t.Errorf("transaction %d: hook got duration %v, want at least the delay of the server", i, call.duration)
+		}
+	}
+	if call := calls[0]; call.db != "Open_vSwitch" || call.result != result || call.err != nil || len(call.result.Errors) != 0 {
+		t.Errorf("successful transaction: hook got db %s, result %+v, err %v", call.db, call.result, call.err)
+	}
+	if call := calls[1]; call.result != failed || ClassifyError(call.result.Errors) != ErrConstraintViolation || call.err != nil {
+		t.Errorf("failed operation: hook got result %+v, err %v", call.result, call.err)
+	}
+	if call := calls[2]; call.db != "OVN_Northbound" || call.err == nil || call.err.Error() != "database is read-only" {
+		t.Errorf("failed transaction: hook got db %s, err %v, want the error of the server", call.db, call.err)
+	}
+}
+
+func TestRemoveTransactHook(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	var invoked []string
+	hook := func(name string) TransactHook {
+		return func(db ID, ops []Operation, result *TransactResult, duration time.Duration, err error) {
+			invoked = append(invoked, name)
+		}
+	}
+	removeFirst := client.AddTransactHook(hook("first"))
+	client.AddTransactHook(hook("second"))
+	removeThird := client.AddTransactHook(hook("third"))
+	removeFirst()
+	removeThird()
+	// removing twice is harmless
+	removeThird()
+
+	if _, err := client.Transact("Open_vSwitch", &CommentOperation{Comment: "test"}); err != nil {
+		t.Fatalf("Transact failed: %v", err)
+	}
+	if want := []string{"second"}; !reflect.DeepEqual(invoked, want) {
+		t.Errorf("invoked hooks %v, want %v", invoked, want)
+	}
+}
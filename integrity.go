@@ -0,0 +1,82 @@
+package ovsdb
+
+import (
+	"fmt"
+)
+
+// IntegrityProblem is an anomaly found by CheckIntegrity
+type IntegrityProblem struct {
+	// Table and UUID identify the row with the problem
+	Table ID
+	UUID  UUID
+	// Column holds the dangling reference, it's empty for an unreachable row
+	Column ID
+	// Reference is the UUID of the missing row a dangling reference points to
+	Reference UUID
+}
+
+// String describes the problem
+func (p IntegrityProblem) String() string {
+	if p.Column == "" {
+		return fmt.Sprintf("row %s in non-root table %s is not referenced by any row", p.UUID, p.Table)
+	}
+	return fmt.Sprintf("column %s of row %s in table %s references missing row %s", p.Column, p.UUID, p.Table, p.Reference)
+}
+
+// CheckIntegrity checks the referential integrity of database db, see the CheckIntegrity function
+func (c *Client) CheckIntegrity(db ID) ([]IntegrityProblem, error) {
+	dbSchema, err := c.GetSchema(db)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.selectAllRows(db, dbSchema)
+	if err != nil {
+		return nil, err
+	}
+	return CheckIntegrity(dbSchema, rows)
+}
+
+// CheckIntegrity scans rows, the content of a database by table and UUID (see BackupFormat), for
+// strong references to missing rows and for rows of non-root tables without strong references.
+// ovsdb-server never commits such a state, so problems found in a copy of the database reveal a
+// bug in the code maintaining the copy, e.g. a cache that missed updates.
+func CheckIntegrity(dbSchema *DatabaseSchema, rows map[ID]map[UUID]map[ID]interface{}) ([]IntegrityProblem, error) {
+	refs, err := findReferences(dbSchema, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []IntegrityProblem
+	referenced := make(map[rowKey]bool)
+	for _, ref := range refs {
+		if !ref.strong {
+			continue
+		}
+		referenced[ref.to] = true
+		if _, ok := rows[ref.to.table][ref.to.uuid]; !ok {
+			problems = append(problems, IntegrityProblem{
+				Table:     ref.from.table,
+				UUID:      ref.from.uuid,
+				Column:    ref.column,
+				Reference: ref.to.uuid,
+			})
+		}
+	}
+
+	var keys []rowKey
+	for table, tableRows := range rows {
+		if tableSchema := dbSchema.Tables[table]; tableSchema == nil || tableSchema.IsRoot {
+			continue
+		}
+		for uuid := range tableRows {
+			if key := (rowKey{table, uuid}); !referenced[key] {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sortRowKeys(keys)
+	for _, key := range keys {
+		problems = append(problems, IntegrityProblem{Table: key.table, UUID: key.uuid})
+	}
+	return problems, nil
+}
@@ -0,0 +1,36 @@
+package ovsdb
+
+import (
+	"testing"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	dbSchema, rows := cascadeRows(t, ls2)
+	problems, err := CheckIntegrity(dbSchema, rows)
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("CheckIntegrity found %v, want no problem", problems)
+	}
+
+	// a dangling reference and an unreachable row
+	delete(rows["ACL"], acl1)
+	rows["Logical_Switch_Port"]["20000000-0000-0000-0000-000000000003"] = map[ID]interface{}{"name": "lsp3"}
+	problems, err = CheckIntegrity(dbSchema, rows)
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	want := []IntegrityProblem{
+		{Table: "Logical_Switch", UUID: ls1, Column: "acls", Reference: acl1},
+		{Table: "Logical_Switch_Port", UUID: "20000000-0000-0000-0000-000000000003"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("CheckIntegrity found %v, want %v", problems, want)
+	}
+	for i := range want {
+		if problems[i] != want[i] {
+			t.Errorf("problem %d = %v, want %v", i, problems[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package ovsdb
+
+import (
+	"context"
+
+	"github.com/cenkalti/rpc2"
+)
+
+// CallFunc performs a JSON-RPC call of method with args, and decodes the result into reply
+type CallFunc func(ctx context.Context, method string, args interface{}, reply interface{}) error
+
+// Interceptor wraps a CallFunc to add cross-cutting behaviors to RPCs, e.g. metrics, retries or logging.
+// An interceptor calls next to continue the RPC, or returns without calling it to short-circuit.
+type Interceptor func(next CallFunc) CallFunc
+
+// Use appends interceptors to the chain applied to all RPCs of the client.
+// The first interceptor of the chain is the outermost one, i.e. it's called first.
+func (c *Client) Use(interceptors ...Interceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interceptors = append(c.interceptors, interceptors...)
+	c.chain = chainInterceptors(c.rpcCall, c.interceptors)
+}
+
+// call performs a RPC through the interceptor chain
+func (c *Client) call(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	c.mu.Lock()
+	chain := c.chain
+	c.mu.Unlock()
+	if chain == nil {
+		chain = c.rpcCall
+	}
+	return chain(ctx, method, args, reply)
+}
+
+// rpcCall performs a RPC on the connection, it's the innermost CallFunc of the chain.
+// If ctx is done before the response arrives, it returns ctx.Err() and reply must not be used,
+// the connection is Degraded until a response arrives.
+func (c *Client) rpcCall(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	rpc, err := c.connection(ctx)
+	if err != nil {
+		return err
+	}
+	call := rpc.Go(method, args, reply, nil)
+	select {
+	case <-call.Done:
+		if call.Error != rpc2.ErrShutdown {
+			c.setState(StateActive, nil)
+		}
+		return call.Error
+	case <-ctx.Done():
+		c.setState(StateDegraded, ctx.Err())
+		return ctx.Err()
+	}
+}
+
+// chainInterceptors wraps base with interceptors, the first interceptor is the outermost one
+func chainInterceptors(base CallFunc, interceptors []Interceptor) CallFunc {
+	chain := base
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		chain = interceptors[i](chain)
+	}
+	return chain
+}
@@ -0,0 +1,49 @@
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestChainInterceptors(t *testing.T) {
+	var calls []string
+	base := func(ctx context.Context, method string, args interface{}, reply interface{}) error {
+		calls = append(calls, "base:"+method)
+		return nil
+	}
+	trace := func(name string) Interceptor {
+		return func(next CallFunc) CallFunc {
+			return func(ctx context.Context, method string, args interface{}, reply interface{}) error {
+				calls = append(calls, name)
+				return next(ctx, method, args, reply)
+			}
+		}
+	}
+	errShortCircuit := errors.New("short-circuit")
+	deny := func(next CallFunc) CallFunc {
+		return func(ctx context.Context, method string, args interface{}, reply interface{}) error {
+			if method == "transact" {
+				return errShortCircuit
+			}
+			return next(ctx, method, args, reply)
+		}
+	}
+
+	chain := chainInterceptors(base, []Interceptor{trace("first"), trace("second"), deny})
+	if err := chain(context.Background(), "list_dbs", nil, nil); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if want := []string{"first", "second", "base:list_dbs"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	calls = nil
+	if err := chain(context.Background(), "transact", nil, nil); err != errShortCircuit {
+		t.Errorf("call returned %v, want %v", err, errShortCircuit)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
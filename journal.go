@@ -0,0 +1,160 @@
+package ovsdb
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry is a notification of updates recorded in a Journal
+type JournalEntry struct {
+	// Offset is the position of the entry in the journal, starting from 0
+	Offset int64 `json:"offset"`
+	// Time is when the entry was appended
+	Time time.Time `json:"time"`
+	// Monitor is the <json-value> of the monitor which received the updates
+	Monitor Value        `json:"monitor"`
+	Updates TableUpdates `json:"updates"`
+}
+
+// Journal is an append-only file of received updates, one JSON encoded JournalEntry per line.
+// Consumers exporting changes to other systems record the offset of the last entry they handled
+// and replay the journal from the next one after a restart, which gives at-least-once delivery.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+	next int64
+}
+
+// OpenJournal opens the journal at path, creating it if it doesn't exist.
+// An incomplete entry at the end of the file, e.g. left by a crash, is discarded.
+func OpenJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{file: file}
+	var size int64
+	err = scanJournal(file, func(entry JournalEntry, end int64) error {
+		j.next = entry.Offset + 1
+		size = end
+		return nil
+	})
+	if err == nil {
+		err = file.Truncate(size)
+	}
+	if err == nil {
+		_, err = file.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open journal %s: %v", path, err)
+	}
+	return j, nil
+}
+
+// Append records updates received by monitor, it returns the offset of the entry.
+// The entry is synced to disk before Append returns.
+func (j *Journal) Append(monitor Value, updates TableUpdates) (int64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry := JournalEntry{Offset: j.next, Time: time.Now(), Monitor: monitor, Updates: updates}
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := j.file.Write(append(bytes, '\n')); err != nil {
+		return 0, err
+	}
+	if err := j.file.Sync(); err != nil {
+		return 0, err
+	}
+	j.next++
+	return entry.Offset, nil
+}
+
+// Next returns the offset of the next entry appended
+func (j *Journal) Next() int64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.next
+}
+
+// Replay calls fn with the entries from offset from in order, it stops at the first error returned by fn
+func (j *Journal) Replay(from int64, fn func(entry JournalEntry) error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	reader := io.NewSectionReader(j.file, 0, 1<<63-1)
+	return scanJournal(reader, func(entry JournalEntry, end int64) error {
+		if entry.Offset < from {
+			return nil
+		}
+		return fn(entry)
+	})
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// scanJournal calls fn with each complete entry of the journal read from r and the position of its end,
+// it stops silently at an incomplete entry
+func scanJournal(r io.Reader, fn func(entry JournalEntry, end int64) error) error {
+	reader := bufio.NewReader(r)
+	var pos int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var entry JournalEntry
+		if json.Unmarshal(line, &entry) != nil {
+			return nil
+		}
+		pos += int64(len(line))
+		if err := fn(entry, pos); err != nil {
+			return err
+		}
+	}
+}
+
+// JournalingHandler is a NotificationHandler which appends updates to a Journal before
+// delivering them to the wrapped handler, updates failed to be recorded are not delivered.
+// The client calls it in the order the server sent the notifications, so entries are in that order,
+// except if it's shared by several clients, whose updates are interleaved.
+type JournalingHandler struct {
+	NotificationHandler
+
+	journal *Journal
+}
+
+// NewJournalingHandler wraps handler into a JournalingHandler recording updates to journal
+func NewJournalingHandler(handler NotificationHandler, journal *Journal) *JournalingHandler {
+	return &JournalingHandler{NotificationHandler: handler, journal: journal}
+}
+
+// Update implements NotificationHandler interface
+func (h *JournalingHandler) Update(jsonValue Value, updates TableUpdates) error {
+	if _, err := h.journal.Append(jsonValue, updates); err != nil {
+		return fmt.Errorf("failed to record updates: %v", err)
+	}
+	return h.NotificationHandler.Update(jsonValue, updates)
+}
+
+// MonitorCanceled implements MonitorCanceledHandler interface
+func (h *JournalingHandler) MonitorCanceled(jsonValue Value) error {
+	if handler, ok := h.NotificationHandler.(MonitorCanceledHandler); ok {
+		return handler.MonitorCanceled(jsonValue)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "updates.journal")
+
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+	var delivered int
+	handler := NewJournalingHandler(&NotificationHandlerFuncs{
+		UpdateFunc: func(jsonValue Value, updates TableUpdates) error {
+			delivered++
+			return nil
+		},
+	}, journal)
+	row := json.RawMessage(`{"name":"sw0"}`)
+	for i := 0; i < 3; i++ {
+		updates := TableUpdates{"Logical_Switch": {ls1: {New: &row}}}
+		if err := handler.Update("mon", updates); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	}
+	if delivered != 3 {
+		t.Errorf("delivered %d updates, want 3", delivered)
+	}
+	journal.Close()
+
+	// simulate a crash in the middle of an append
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"offset":3,"upd`)
+	file.Close()
+
+	journal, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+	defer journal.Close()
+	if journal.Next() != 3 {
+		t.Errorf("Next() = %d, want 3", journal.Next())
+	}
+	if offset, err := journal.Append("mon", TableUpdates{}); err != nil || offset != 3 {
+		t.Errorf("Append = %d, %v, want 3", offset, err)
+	}
+
+	var offsets []int64
+	err = journal.Replay(1, func(entry JournalEntry) error {
+		offsets = append(offsets, entry.Offset)
+		if entry.Monitor != "mon" {
+			t.Errorf("monitor of entry %d = %v", entry.Offset, entry.Monitor)
+		}
+		if entry.Offset < 3 && string(*entry.Updates["Logical_Switch"][ls1].New) != `{"name":"sw0"}` {
+			t.Errorf("updates of entry %d = %v", entry.Offset, entry.Updates)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(offsets) != 3 || offsets[0] != 1 || offsets[2] != 3 {
+		t.Errorf("replayed offsets %v, want [1 2 3]", offsets)
+	}
+}
+
+func TestJournalingHandlerOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	journal, err := OpenJournal(filepath.Join(dir, "updates.journal"))
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+	defer journal.Close()
+
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+	defer client.Close()
+	const n = 20
+	delivered := make(chan struct{}, n)
+	client.SetNotificationHandler(NewJournalingHandler(&NotificationHandlerFuncs{
+		UpdateFunc: func(jsonValue Value, updates TableUpdates) error {
+			delivered <- struct{}{}
+			return nil
+		},
+	}, journal))
+	for i := 0; i < n; i++ {
+		row := map[string]interface{}{"name": fmt.Sprintf("sw%d", i)}
+		server.Notify("update", "mon", map[string]interface{}{"Logical_Switch": map[string]interface{}{ls1: map[string]interface{}{"new": row}}})
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case <-delivered:
+		case <-time.After(time.Second):
+			t.Fatalf("update %d not delivered", i)
+		}
+	}
+
+	var names []string
+	journal.Replay(0, func(entry JournalEntry) error {
+		var row struct {
+			Name string `json:"name"`
+		}
+		json.Unmarshal(*entry.Updates["Logical_Switch"][ls1].New, &row)
+		names = append(names, row.Name)
+		return nil
+	})
+	for i, name := range names {
+		if want := fmt.Sprintf("sw%d", i); name != want {
+			t.Fatalf("journal entries %v, want them in the order of the notifications", names)
+		}
+	}
+	if len(names) != n {
+		t.Errorf("%d journal entries, want %d", len(names), n)
+	}
+}
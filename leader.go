@@ -0,0 +1,112 @@
+package ovsdb
+
+import (
+	"context"
+	"sync"
+)
+
+// LeaderCallbacks are invoked by LeaderElector when the leadership changes
+type LeaderCallbacks struct {
+	// OnStartedLeading is called when the lock is granted to this client
+	OnStartedLeading func()
+	// OnStoppedLeading is called when the lock is stolen by another client or released
+	OnStoppedLeading func()
+}
+
+// LeaderElector elects a leader among the clients of a OVSDB server with the lock named Lock.
+// The client owns the lock is the leader, other clients wait in the queue of the lock,
+// and one of them becomes the leader when the lock is released.
+// See https://tools.ietf.org/html/rfc7047#section-4.1.8
+type LeaderElector struct {
+	client    *Client
+	lock      ID
+	callbacks LeaderCallbacks
+
+	mu      sync.Mutex
+	leading bool
+}
+
+// NewLeaderElector creates a LeaderElector electing leader with the lock named lock
+func NewLeaderElector(client *Client, lock ID, callbacks LeaderCallbacks) *LeaderElector {
+	return &LeaderElector{
+		client:    client,
+		lock:      lock,
+		callbacks: callbacks,
+	}
+}
+
+// IsLeader returns true if this client is the leader now
+func (le *LeaderElector) IsLeader() bool {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	return le.leading
+}
+
+// Run requests the lock and tracks its ownership until ctx is done, the lock is released then.
+// Callbacks are invoked from the goroutine calling Run, except the ones caused by Steal.
+// It returns ctx.Err() after ctx is done, or an error if the connection fails.
+func (le *LeaderElector) Run(ctx context.Context) error {
+	events := make(chan bool)
+	done := make(chan struct{})
+	defer close(done)
+	le.client.watchLock(le.lock, func(locked bool) {
+		select {
+		case events <- locked:
+		case <-done:
+		}
+	})
+	defer le.client.unwatchLock(le.lock)
+
+	locked, err := le.client.Lock(le.lock)
+	if err != nil {
+		return err
+	}
+	if locked {
+		le.setLeading(true)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			le.setLeading(false)
+			if err := le.client.Unlock(le.lock); err != nil {
+				return err
+			}
+			return ctx.Err()
+		case <-le.client.closed:
+			le.setLeading(false)
+			return errDisconnected
+		case locked := <-events:
+			// a stolen lock stays requested, so the server grants it to us again when the thief releases it
+			le.setLeading(locked)
+		}
+	}
+}
+
+// Steal takes the lock from the current leader, this client becomes the leader immediately.
+// It should only be called while Run is running.
+func (le *LeaderElector) Steal() error {
+	if err := le.client.Steal(le.lock); err != nil {
+		return err
+	}
+	le.setLeading(true)
+	return nil
+}
+
+// setLeading updates the leadership and invokes callbacks on changes
+func (le *LeaderElector) setLeading(leading bool) {
+	le.mu.Lock()
+	changed := le.leading != leading
+	le.leading = leading
+	le.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	if leading && le.callbacks.OnStartedLeading != nil {
+		le.callbacks.OnStartedLeading()
+	}
+	if !leading && le.callbacks.OnStoppedLeading != nil {
+		le.callbacks.OnStoppedLeading()
+	}
+}
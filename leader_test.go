@@ -0,0 +1,103 @@
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+// leaderFixture runs a LeaderElector of the lock "leader" on a client of server, the lock is granted
+// to the "lock" request if granted is true. The changes of leadership are sent to the returned channel.
+func leaderFixture(granted bool) (*ovsdbtest.Server, *LeaderElector, <-chan string) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	client := NewClient(conn)
+	server.Handle("lock", func(params []json.RawMessage) (interface{}, error) {
+		return LockResult{Locked: granted}, nil
+	})
+
+	changes := make(chan string, 4)
+	elector := NewLeaderElector(client, "leader", LeaderCallbacks{
+		OnStartedLeading: func() { changes <- "started" },
+		OnStoppedLeading: func() { changes <- "stopped" },
+	})
+	return server, elector, changes
+}
+
+func expectLeading(t *testing.T, changes <-chan string, want string) {
+	select {
+	case change := <-changes:
+		if change != want {
+			t.Fatalf("leadership %s, want %s", change, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("leadership not %s", want)
+	}
+}
+
+func TestLeaderElector(t *testing.T) {
+	server, elector, changes := leaderFixture(false)
+	defer server.Close()
+	unlocked := make(chan string, 1)
+	server.Handle("unlock", func(params []json.RawMessage) (interface{}, error) {
+		unlocked <- string(params[0])
+		return map[string]interface{}{}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() { errs <- elector.Run(ctx) }()
+
+	// the lock is granted once the owner releases it
+	server.Notify("locked", "leader")
+	expectLeading(t, changes, "started")
+	if !elector.IsLeader() {
+		t.Error("IsLeader = false after the lock is granted")
+	}
+	server.Notify("stolen", "leader")
+	expectLeading(t, changes, "stopped")
+	if elector.IsLeader() {
+		t.Error("IsLeader = true after the lock is stolen")
+	}
+	// the lock stays requested after it's stolen
+	server.Notify("locked", "leader")
+	expectLeading(t, changes, "started")
+
+	cancel()
+	expectLeading(t, changes, "stopped")
+	select {
+	case lock := <-unlocked:
+		if lock != `"leader"` {
+			t.Errorf("unlocked %s, want \"leader\"", lock)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lock not released after the context is canceled")
+	}
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("Run returned %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestLeaderElectorGranted(t *testing.T) {
+	server, elector, changes := leaderFixture(true)
+	errs := make(chan error, 1)
+	go func() { errs <- elector.Run(context.Background()) }()
+	expectLeading(t, changes, "started")
+
+	// the leadership is lost with the connection
+	server.Close()
+	expectLeading(t, changes, "stopped")
+	select {
+	case err := <-errs:
+		if err != errDisconnected {
+			t.Errorf("Run returned %v, want %v", err, errDisconnected)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return after the connection is lost")
+	}
+}
@@ -0,0 +1,112 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var errRowNotObject = errors.New("Row is not encoded as a JSON object")
+
+// FieldMask is a set of columns, it limits the columns written by an UpdateOperation,
+// so columns owned by others are not clobbered with stale values of a model
+type FieldMask []ID
+
+// Fields creates a FieldMask of columns
+func Fields(columns ...ID) FieldMask {
+	return FieldMask(columns)
+}
+
+// Apply returns a Row which only contains the columns in the mask.
+// row can be any value encoded as a JSON object, e.g. a map[ID]Value or a model struct with json tags.
+// It fails if a column of the mask is missing in row.
+func (mask FieldMask) Apply(row Row) (Row, error) {
+	columns, err := rowColumns(row)
+	if err != nil {
+		return nil, err
+	}
+	masked := make(map[ID]json.RawMessage, len(mask))
+	for _, column := range mask {
+		value, ok := columns[column]
+		if !ok {
+			return nil, fmt.Errorf("column %q of field mask is not in Row", column)
+		}
+		masked[column] = value
+	}
+	return masked, nil
+}
+
+// rowColumns encodes row and splits it into columns
+func rowColumns(row Row) (map[ID]json.RawMessage, error) {
+	bytes, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	var columns map[ID]json.RawMessage
+	if err := json.Unmarshal(bytes, &columns); err != nil || columns == nil {
+		return nil, errRowNotObject
+	}
+	return columns, nil
+}
+
+// TrackedRow is a Row which records the columns modified by Set,
+// so an update of the row only writes the modified columns
+type TrackedRow struct {
+	values map[ID]Value
+	dirty  FieldMask
+}
+
+// NewTrackedRow creates a TrackedRow with the initial column values of row, which are not marked as modified
+func NewTrackedRow(row map[ID]Value) *TrackedRow {
+	values := make(map[ID]Value, len(row))
+	for column, value := range row {
+		values[column] = value
+	}
+	return &TrackedRow{values: values}
+}
+
+// Get returns the value of column
+func (r *TrackedRow) Get(column ID) (Value, bool) {
+	value, ok := r.values[column]
+	return value, ok
+}
+
+// Set sets the value of column and marks it as modified
+func (r *TrackedRow) Set(column ID, value Value) {
+	r.values[column] = value
+	for _, dirty := range r.dirty {
+		if dirty == column {
+			return
+		}
+	}
+	r.dirty = append(r.dirty, column)
+}
+
+// Dirty returns the columns modified since the row was created or last reset
+func (r *TrackedRow) Dirty() FieldMask {
+	return append(FieldMask(nil), r.dirty...)
+}
+
+// Reset clears the modified marks, e.g. after the update was committed
+func (r *TrackedRow) Reset() {
+	r.dirty = nil
+}
+
+// Update returns an UpdateOperation which writes the modified columns to the rows of table matching where.
+// It returns nil if no column is modified, since an UpdateOperation without a mask writes all columns.
+func (r *TrackedRow) Update(table ID, where ...Condition) *UpdateOperation {
+	if len(r.dirty) == 0 {
+		return nil
+	}
+	return &UpdateOperation{
+		Table: table,
+		Where: where,
+		Row:   r,
+		Mask:  r.Dirty(),
+	}
+}
+
+// MarshalJSON implements json.Marshaler interface
+func (r *TrackedRow) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.values)
+}
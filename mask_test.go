@@ -0,0 +1,81 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFieldMaskApply(t *testing.T) {
+	type model struct {
+		Name        string `json:"name"`
+		Addresses   Set    `json:"addresses"`
+		ExternalIDs Map    `json:"external_ids"`
+	}
+	tests := []struct {
+		mask       FieldMask
+		row        Row
+		shouldFail bool
+		json       string
+	}{
+		{Fields("name"), map[ID]Value{"name": "lsp0", "up": true}, false, `{"name":"lsp0"}`},
+		{
+			Fields("addresses", "external_ids"),
+			model{Name: "lsp0", Addresses: Set{Values: []Value{"router"}}, ExternalIDs: Map{Values: []MapPair{}}},
+			false,
+			`{"addresses":"router","external_ids":["map",[]]}`,
+		},
+		{Fields("missing"), map[ID]Value{"name": "lsp0"}, true, ``},
+		{Fields("name"), "not an object", true, ``},
+	}
+
+	for _, test := range tests {
+		row, err := test.mask.Apply(test.row)
+		if test.shouldFail {
+			if err == nil {
+				t.Errorf("expect Apply(%+v) failed, but got nil", test.row)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Apply(%+v) failed: %v", test.row, err)
+			continue
+		}
+		bytes, _ := json.Marshal(row)
+		if string(bytes) != test.json {
+			t.Errorf("Apply(%+v) = %s, want %s", test.row, bytes, test.json)
+		}
+	}
+}
+
+func TestTrackedRow(t *testing.T) {
+	row := NewTrackedRow(map[ID]Value{"name": "lsp0", "addresses": "router"})
+	if dirty := row.Dirty(); len(dirty) != 0 {
+		t.Errorf("Dirty() = %v, want empty", dirty)
+	}
+	if update := row.Update("Logical_Switch_Port", Condition{"name", FuncEq, "lsp0"}); update != nil {
+		t.Errorf("Update of a fresh row = %+v, want nil", update)
+	}
+
+	row.Set("addresses", "dynamic")
+	row.Set("addresses", "unknown")
+	if value, _ := row.Get("addresses"); value != "unknown" {
+		t.Errorf("Get(addresses) = %v, want unknown", value)
+	}
+	update := row.Update("Logical_Switch_Port", Condition{"name", FuncEq, "lsp0"})
+	bytes, err := json.Marshal(update)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	want := `{"op":"update","table":"Logical_Switch_Port","where":[["name","==","lsp0"]],"row":{"addresses":"unknown"}}`
+	if string(bytes) != want {
+		t.Errorf("json marshal got %s, want %s", bytes, want)
+	}
+
+	row.Reset()
+	if dirty := row.Dirty(); len(dirty) != 0 {
+		t.Errorf("Dirty() after Reset = %v, want empty", dirty)
+	}
+	if update := row.Update("Logical_Switch_Port", Condition{"name", FuncEq, "lsp0"}); update != nil {
+		t.Errorf("Update after Reset = %+v, want nil", update)
+	}
+}
@@ -0,0 +1,91 @@
+package ovsdb
+
+import (
+	"sort"
+)
+
+// MapMergeResult is the result of MergeMap
+type MapMergeResult struct {
+	// Mutations change the managed keys of the map column from their observed values to the desired ones
+	Mutations []Mutation
+	// Verify holds conditions which are only true if the managed keys still have their observed values
+	Verify []Condition
+	// Conflicts are the managed keys modified by other writers since base, desired values win over them
+	Conflicts []string
+}
+
+// MergeMap does a three-way merge of a map column shared by multiple writers, e.g. external_ids.
+// The keys managed by this client are the keys in base, its content as last written by this client,
+// and the keys in desired, its content wanted now. Keys not managed by this client are never touched.
+// observed is the current content of the column.
+func MergeMap(column ID, base, observed, desired map[string]string) *MapMergeResult {
+	managed := make(map[string]bool)
+	for key := range base {
+		managed[key] = true
+	}
+	for key := range desired {
+		managed[key] = true
+	}
+	keys := make([]string, 0, len(managed))
+	for key := range managed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := &MapMergeResult{}
+	var deletes []Value
+	var inserts, verifies []MapPair
+	for _, key := range keys {
+		baseValue, inBase := base[key]
+		observedValue, inObserved := observed[key]
+		desiredValue, inDesired := desired[key]
+
+		unchanged := inObserved == inBase && observedValue == baseValue
+		satisfied := inObserved == inDesired && observedValue == desiredValue
+		if !unchanged && !satisfied {
+			result.Conflicts = append(result.Conflicts, key)
+		}
+		if inObserved {
+			verifies = append(verifies, MapPair{key, observedValue})
+		}
+		if satisfied {
+			continue
+		}
+		// map "insert" mutation doesn't overwrite existing keys, so delete them first
+		if inObserved {
+			deletes = append(deletes, key)
+		}
+		if inDesired {
+			inserts = append(inserts, MapPair{key, desiredValue})
+		}
+	}
+
+	if len(deletes) != 0 {
+		result.Mutations = append(result.Mutations, Mutation{column, MutatorDelete, Set{Values: deletes}})
+	}
+	if len(inserts) != 0 {
+		result.Mutations = append(result.Mutations, Mutation{column, MutatorInsert, Map{Values: inserts}})
+	}
+	if len(verifies) != 0 {
+		result.Verify = append(result.Verify, Condition{column, FuncInc, Map{Values: verifies}})
+	}
+	return result
+}
+
+// Mutate returns a MutateOperation applying the merge to the rows of table matching where.
+// The Verify conditions are added to where, so the operation's count is 0 if a concurrent writer
+// changed the managed keys in the meantime, the caller should then observe the row again and retry.
+// It returns nil if there's nothing to change.
+func (result *MapMergeResult) Mutate(table ID, where ...Condition) *MutateOperation {
+	if len(result.Mutations) == 0 {
+		return nil
+	}
+	var conditions []Condition
+	conditions = append(conditions, where...)
+	conditions = append(conditions, result.Verify...)
+	return &MutateOperation{
+		Table:     table,
+		Where:     conditions,
+		Mutations: result.Mutations,
+	}
+}
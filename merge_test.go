@@ -0,0 +1,83 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergeMap(t *testing.T) {
+	tests := []struct {
+		base, observed, desired map[string]string
+		mutations               string
+		verify                  string
+		conflicts               []string
+	}{
+		// nothing to do
+		{
+			base:      map[string]string{"app:owner": "a"},
+			observed:  map[string]string{"app:owner": "a", "other": "x"},
+			desired:   map[string]string{"app:owner": "a"},
+			mutations: `null`,
+			verify:    `[["external_ids","includes",["map",[["app:owner","a"]]]]]`,
+		},
+		// add and change managed keys, other keys untouched
+		{
+			base:      map[string]string{"app:owner": "a"},
+			observed:  map[string]string{"app:owner": "a", "other": "x"},
+			desired:   map[string]string{"app:owner": "b", "app:new": "n"},
+			mutations: `[["external_ids","delete","app:owner"],["external_ids","insert",["map",[["app:new","n"],["app:owner","b"]]]]]`,
+			verify:    `[["external_ids","includes",["map",[["app:owner","a"]]]]]`,
+		},
+		// remove a key no longer desired
+		{
+			base:      map[string]string{"app:owner": "a", "app:old": "o"},
+			observed:  map[string]string{"app:owner": "a", "app:old": "o"},
+			desired:   map[string]string{"app:owner": "a"},
+			mutations: `[["external_ids","delete","app:old"]]`,
+			verify:    `[["external_ids","includes",["map",[["app:old","o"],["app:owner","a"]]]]]`,
+		},
+		// managed key modified by another writer
+		{
+			base:      map[string]string{"app:owner": "a"},
+			observed:  map[string]string{"app:owner": "z"},
+			desired:   map[string]string{"app:owner": "b"},
+			mutations: `[["external_ids","delete","app:owner"],["external_ids","insert",["map",[["app:owner","b"]]]]]`,
+			verify:    `[["external_ids","includes",["map",[["app:owner","z"]]]]]`,
+			conflicts: []string{"app:owner"},
+		},
+	}
+
+	for _, test := range tests {
+		result := MergeMap("external_ids", test.base, test.observed, test.desired)
+		mutations, _ := json.Marshal(result.Mutations)
+		if string(mutations) != test.mutations {
+			t.Errorf("MergeMap(%v, %v, %v) mutations = %s, want %s", test.base, test.observed, test.desired, mutations, test.mutations)
+		}
+		verify, _ := json.Marshal(result.Verify)
+		if string(verify) != test.verify {
+			t.Errorf("MergeMap(%v, %v, %v) verify = %s, want %s", test.base, test.observed, test.desired, verify, test.verify)
+		}
+		if !reflect.DeepEqual(result.Conflicts, test.conflicts) {
+			t.Errorf("MergeMap(%v, %v, %v) conflicts = %v, want %v", test.base, test.observed, test.desired, result.Conflicts, test.conflicts)
+		}
+	}
+}
+
+func TestMapMergeResultMutate(t *testing.T) {
+	result := MergeMap("external_ids", nil, nil, nil)
+	if op := result.Mutate("Logical_Switch", Condition{"name", FuncEq, "ls0"}); op != nil {
+		t.Errorf("Mutate() = %+v, want nil", op)
+	}
+
+	result = MergeMap("external_ids", nil, map[string]string{"app:owner": "a"}, map[string]string{"app:owner": "b"})
+	op := result.Mutate("Logical_Switch", Condition{"name", FuncEq, "ls0"})
+	bytes, err := json.Marshal(op)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	want := `{"op":"mutate","table":"Logical_Switch","where":[["name","==","ls0"],["external_ids","includes",["map",[["app:owner","a"]]]]],"mutations":[["external_ids","delete","app:owner"],["external_ids","insert",["map",[["app:owner","b"]]]]]}`
+	if string(bytes) != want {
+		t.Errorf("json marshal got %s, want %s", bytes, want)
+	}
+}
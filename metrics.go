@@ -0,0 +1,177 @@
+package ovsdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// TableMetric maps the rows of a table to a gauge
+type TableMetric struct {
+	// Name and Help are the name and description of the metric
+	Name string
+	Help string
+	// Table is the table the metric is computed from
+	Table ID
+	// Labels are the columns whose values label the metric, rows with the same values are aggregated
+	Labels []ID
+	// Size, if not empty, is a set or map column whose number of elements is summed,
+	// e.g. "ports" of Logical_Switch labeled by "name" for the number of ports per switch,
+	// otherwise the rows are counted, e.g. Interface labeled by "admin_state"
+	Size ID
+}
+
+// MetricSample is a value of a TableMetric
+type MetricSample struct {
+	Name   string
+	Labels map[ID]string
+	Value  float64
+}
+
+// TableCollector computes TableMetrics from the rows of a ReadThroughCache,
+// so metrics are as fresh as the TTL of the cache. It serves the metrics over HTTP in
+// the Prometheus text exposition format.
+type TableCollector struct {
+	cache   *ReadThroughCache
+	metrics []TableMetric
+}
+
+// NewTableCollector creates a TableCollector computing metrics from cache
+func NewTableCollector(cache *ReadThroughCache, metrics ...TableMetric) *TableCollector {
+	return &TableCollector{cache: cache, metrics: metrics}
+}
+
+// Collect computes the samples of all metrics, sorted by metric and labels
+func (tc *TableCollector) Collect() ([]MetricSample, error) {
+	var samples []MetricSample
+	for _, metric := range tc.metrics {
+		rows, err := tc.cache.List(metric.Table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect %s: %v", metric.Name, err)
+		}
+		collected, err := collectMetric(metric, rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect %s: %v", metric.Name, err)
+		}
+		samples = append(samples, collected...)
+	}
+	return samples, nil
+}
+
+// collectMetric computes the samples of metric from rows
+func collectMetric(metric TableMetric, rows []json.RawMessage) ([]MetricSample, error) {
+	samples := make(map[string]*MetricSample)
+	var keys []string
+	for _, raw := range rows {
+		var row map[ID]interface{}
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return nil, err
+		}
+		labels := make(map[ID]string, len(metric.Labels))
+		for _, column := range metric.Labels {
+			value, err := CanonicalValue(row[column])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of label column %s: %v", column, err)
+			}
+			labels[column] = formatLabelValue(value)
+		}
+		value := 1.0
+		if metric.Size != "" {
+			size, err := valueSize(row[metric.Size])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of column %s: %v", metric.Size, err)
+			}
+			value = float64(size)
+		}
+
+		key := formatLabels(metric.Labels, labels)
+		sample, ok := samples[key]
+		if !ok {
+			sample = &MetricSample{Name: metric.Name, Labels: labels}
+			samples[key] = sample
+			keys = append(keys, key)
+		}
+		sample.Value += value
+	}
+	sort.Strings(keys)
+	collected := make([]MetricSample, 0, len(keys))
+	for _, key := range keys {
+		collected = append(collected, *samples[key])
+	}
+	return collected, nil
+}
+
+// valueSize returns the number of elements of a value
+func valueSize(v interface{}) (int, error) {
+	value, err := CanonicalValue(v)
+	if err != nil {
+		return 0, err
+	}
+	switch value := value.(type) {
+	case Set:
+		return len(value.Values), nil
+	case Map:
+		return len(value.Values), nil
+	}
+	return 1, nil
+}
+
+// formatLabelValue formats a canonical value as a label value, strings are not quoted
+func formatLabelValue(v Value) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case UUID:
+		return string(v)
+	}
+	bytes, _ := json.Marshal(v)
+	return string(bytes)
+}
+
+// formatLabels formats labels in the exposition format, e.g. {name="sw0"}
+func formatLabels(columns []ID, labels map[ID]string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	var pairs []string
+	for _, column := range columns {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[column])
+		pairs = append(pairs, string(column)+`="`+value+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Write writes the metrics in the Prometheus text exposition format
+func (tc *TableCollector) Write(w io.Writer) error {
+	samples, err := tc.Collect()
+	if err != nil {
+		return err
+	}
+	for _, metric := range tc.metrics {
+		if metric.Help != "" {
+			fmt.Fprintf(w, "# HELP %s %s\n", metric.Name, metric.Help)
+		}
+		fmt.Fprintf(w, "# TYPE %s gauge\n", metric.Name)
+		for _, sample := range samples {
+			if sample.Name == metric.Name {
+				fmt.Fprintf(w, "%s%s %v\n", sample.Name, formatLabels(metric.Labels, sample.Labels), sample.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler, e.g. for serving "/metrics"
+func (tc *TableCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var b bytes.Buffer
+	if err := tc.Write(&b); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	io.WriteString(w, b.String())
+}
@@ -0,0 +1,56 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestTableCollector(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	tables := map[string][]interface{}{
+		"Logical_Switch": {
+			map[string]interface{}{"_uuid": []string{"uuid", ls1}, "name": "sw0", "ports": []interface{}{"set", []interface{}{[]string{"uuid", lsp1}, []string{"uuid", lsp2}}}},
+			map[string]interface{}{"_uuid": []string{"uuid", ls2}, "name": "sw\"1", "ports": []interface{}{"set", []interface{}{}}},
+		},
+		"Interface": {
+			map[string]interface{}{"_uuid": []string{"uuid", acl1}, "admin_state": "up"},
+			map[string]interface{}{"_uuid": []string{"uuid", acl2}, "admin_state": "up"},
+			map[string]interface{}{"_uuid": []string{"uuid", lb1}, "admin_state": "down"},
+		},
+	}
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		var op struct {
+			Table string `json:"table"`
+		}
+		json.Unmarshal(params[1], &op)
+		return []interface{}{map[string]interface{}{"rows": tables[op.Table]}}, nil
+	})
+
+	collector := NewTableCollector(NewReadThroughCache(client, "OVN_Northbound", time.Minute),
+		TableMetric{Name: "ovn_switch_ports", Help: "Number of ports per logical switch.", Table: "Logical_Switch", Labels: []ID{"name"}, Size: "ports"},
+		TableMetric{Name: "ovs_interfaces", Table: "Interface", Labels: []ID{"admin_state"}},
+	)
+	recorder := httptest.NewRecorder()
+	collector.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	want := `# HELP ovn_switch_ports Number of ports per logical switch.
+# TYPE ovn_switch_ports gauge
+ovn_switch_ports{name="sw0"} 2
+ovn_switch_ports{name="sw\"1"} 0
+# TYPE ovs_interfaces gauge
+ovs_interfaces{admin_state="down"} 1
+ovs_interfaces{admin_state="up"} 2
+`
+	if recorder.Code != 200 || recorder.Body.String() != want {
+		t.Errorf("metrics = %d %s, want %s", recorder.Code, recorder.Body.String(), want)
+	}
+}
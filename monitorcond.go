@@ -0,0 +1,248 @@
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cenkalti/rpc2"
+)
+
+// MonitorCondRequests maps the name of the table to be monitored to a MonitorCondRequest
+type MonitorCondRequests map[ID]MonitorCondRequest
+
+// MonitorCondRequest selects the contents to monitor in a table with MonitorCond
+type MonitorCondRequest struct {
+	// Columns, if present, define the columns within the table to be monitored,
+	// if omitted, all columns in the table, except for "_uuid", are monitored.
+	Columns []ID `json:"columns,omitempty"`
+	// Where, if present, limits the monitored rows to the ones matching any of the conditions,
+	// unlike the where of operations, an empty Where monitors all rows
+	Where  []Condition    `json:"where,omitempty"`
+	Select *MonitorSelect `json:"select,omitempty"`
+}
+
+// TableUpdates2 is an object that maps from a table name to a TableUpdate2, it's the updates of monitors
+// created with MonitorCond
+type TableUpdates2 map[ID]TableUpdate2
+
+// TableUpdate2 is an object that maps from the row's UUID to a RowUpdate2 object
+type TableUpdate2 map[UUID]RowUpdate2
+
+// RowUpdate2 is an object with exactly one of the following members:
+// "initial": <row>   for a row in the initial contents of the monitor
+// "insert": <row>    for an inserted row
+// "delete": null     for a deleted row
+// "modify": <row>    for a modified row, it holds the differences of the modified columns
+// Columns with default values are omitted from "initial" and "insert" rows.
+// The difference of a column holding exactly one atom is its new value, the difference of a set is
+// the elements added or removed, and the difference of a map is the pairs added, removed, or
+// holding new values of existing keys.
+type RowUpdate2 struct {
+	Initial *json.RawMessage `json:"initial,omitempty"`
+	Insert  *json.RawMessage `json:"insert,omitempty"`
+	Delete  bool             `json:"-"`
+	Modify  *json.RawMessage `json:"modify,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface
+func (ru RowUpdate2) MarshalJSON() ([]byte, error) {
+	update := make(map[string]*json.RawMessage)
+	switch {
+	case ru.Initial != nil:
+		update["initial"] = ru.Initial
+	case ru.Insert != nil:
+		update["insert"] = ru.Insert
+	case ru.Delete:
+		update["delete"] = nil
+	case ru.Modify != nil:
+		update["modify"] = ru.Modify
+	}
+	return json.Marshal(update)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface
+func (ru *RowUpdate2) UnmarshalJSON(value []byte) error {
+	var update map[string]*json.RawMessage
+	if err := json.Unmarshal(value, &update); err != nil {
+		return err
+	}
+	*ru = RowUpdate2{}
+	for member, row := range update {
+		switch member {
+		case "initial":
+			ru.Initial = row
+		case "insert":
+			ru.Insert = row
+		case "delete":
+			ru.Delete = true
+		case "modify":
+			ru.Modify = row
+		default:
+			return fmt.Errorf("unknown <row-update2> member %q", member)
+		}
+	}
+	if len(update) != 1 {
+		return errors.New("<row-update2> must have exactly one member")
+	}
+	return nil
+}
+
+// Update2Handler is implemented by notification handlers interested in the "update2" notifications
+// of monitors created with MonitorCond
+type Update2Handler interface {
+	Update2(jsonValue Value, updates TableUpdates2) error
+}
+
+// MonitorCond is like Monitor, but only the rows matching the conditions of the requests are monitored,
+// which saves the server from sending, and the client from processing, rows it's not interested in.
+// The initial contents are returned and the updates are sent to the notification handler, if it implements
+// Update2Handler, as TableUpdates2 holding the differences of modified rows rather than both versions.
+// The filters registered with AddUpdateFilter don't apply to them.
+// The method was added by Open vSwitch 2.6, older servers fail it with an error.
+func (c *Client) MonitorCond(db ID, jsonValue Value, requests MonitorCondRequests) (TableUpdates2, error) {
+	var updates TableUpdates2
+	params := []interface{}{db, jsonValue, requests}
+	if err := c.call(context.Background(), "monitor_cond", params, &updates); err != nil {
+		return nil, databaseError(err)
+	}
+	c.trackMonitor(jsonValue, true)
+	return updates, nil
+}
+
+// handler function for "update2" notification
+func update2Handler(client *rpc2.Client, params []interface{}, reply *[]interface{}) error {
+	clientsLock.RLock()
+	ovsClient, ok := clientsMap[client]
+	clientsLock.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	// "params": [<json-value>, <table-updates2>]
+	if len(params) != 2 {
+		return ovsClient.decodeError("update2", params, errors.New("invalid update2 notification: wrong number of parameters"))
+	}
+
+	var jsonValue = Value(params[0])
+	var tableUpdates TableUpdates2
+	bytes, _ := json.Marshal(params[1])
+	err := json.Unmarshal(bytes, &tableUpdates)
+	if err != nil {
+		return ovsClient.decodeError("update2", params, fmt.Errorf("failed to decode <table-updates2>: %v", err))
+	}
+
+	// updates of monitors created by helpers of this package are not seen by the handler
+	if ovsClient.notifyMonitor2(jsonValue, tableUpdates) {
+		return nil
+	}
+	// unlike "update", these are not filtered, see AddUpdateFilter
+	if handler, ok := ovsClient.handler.(Update2Handler); ok {
+		return handler.Update2(jsonValue, tableUpdates)
+	}
+	return nil
+}
+
+// watchMonitor2 is watchMonitor for monitors created with MonitorCond
+func (c *Client) watchMonitor2(fn func(updates TableUpdates2)) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.monitor2Watchers == nil {
+		c.monitor2Watchers = make(map[string]func(updates TableUpdates2))
+	}
+	c.monitorSeq++
+	monitorID := fmt.Sprintf("go-ovsdb-monitor-%d", c.monitorSeq)
+	c.monitor2Watchers[monitorID] = fn
+	return monitorID
+}
+
+// notifyMonitor2 delivers updates to the function registered by watchMonitor2 for jsonValue,
+// it returns false if there's no such function
+func (c *Client) notifyMonitor2(jsonValue Value, updates TableUpdates2) bool {
+	monitorID, ok := jsonValue.(string)
+	if !ok {
+		return false
+	}
+	c.mu.Lock()
+	fn, ok := c.monitor2Watchers[monitorID]
+	c.mu.Unlock()
+	if ok {
+		fn(updates)
+	}
+	return ok
+}
+
+// applyDiff applies diff, the difference of column in a "modify" <row-update2>, to the old value of the column,
+// both old and diff must be canonical values, so is the returned value
+func applyDiff(column *ColumnSchema, old, diff Value) (Value, error) {
+	switch {
+	case column.IsMap():
+		oldMap, ok := old.(Map)
+		if !ok {
+			return nil, errNotMap
+		}
+		diffMap, ok := diff.(Map)
+		if !ok {
+			return nil, errNotMap
+		}
+		pairs := append([]MapPair{}, oldMap.Values...)
+		for _, change := range diffMap.Values {
+			found := false
+			for i, pair := range pairs {
+				if compareAtoms(pair[0], change[0]) != 0 {
+					continue
+				}
+				found = true
+				if compareAtoms(pair[1], change[1]) == 0 {
+					// the pair is removed
+					pairs = append(pairs[:i], pairs[i+1:]...)
+				} else {
+					// the key holds a new value
+					pairs[i] = change
+				}
+				break
+			}
+			if !found {
+				pairs = append(pairs, change)
+			}
+		}
+		return canonicalMap(pairs)
+	case column.IsSet():
+		// elements in both old and diff are removed, the others are kept or added
+		var elements []Value
+		for _, element := range setElements(old) {
+			if !containsAtom(setElements(diff), element) {
+				elements = append(elements, element)
+			}
+		}
+		for _, element := range setElements(diff) {
+			if !containsAtom(setElements(old), element) {
+				elements = append(elements, element)
+			}
+		}
+		return canonicalSet(elements)
+	}
+	return diff, nil
+}
+
+// defaultValue returns the canonical default value of column, which is omitted from <row-update2>s
+func defaultValue(column *ColumnSchema) Value {
+	switch {
+	case column.IsMap():
+		return Map{Values: []MapPair{}}
+	case column.IsSet():
+		return Set{Values: []Value{}}
+	}
+	switch column.KeyType() {
+	case TypeInteger:
+		return int64(0)
+	case TypeReal:
+		return float64(0)
+	case TypeBoolean:
+		return false
+	case TypeUUID:
+		return UUID(zeroUUID)
+	}
+	return ""
+}
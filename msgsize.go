@@ -0,0 +1,25 @@
+package ovsdb
+
+// SetMaxMessageSize limits the size in bytes of JSON-RPC messages received from and sent to the server,
+// 0 for no limit, the default. It guards against pathological payloads, e.g. a misbehaving server sending
+// an enormous notification, which would otherwise be buffered whole.
+// An incoming message exceeding the limit closes the connection, the client changes to StateClosed with
+// a *MessageSizeError as the error. An outgoing message exceeding the limit is not sent, the call fails
+// with a *MessageSizeError and the connection is kept.
+func (c *Client) SetMaxMessageSize(incoming, outgoing int) {
+	c.connectMu.Lock()
+	defer c.connectMu.Unlock()
+	c.maxIncoming = incoming
+	c.maxOutgoing = outgoing
+	if c.codec != nil {
+		c.codec.setMaxMessageSize(incoming, outgoing)
+	}
+}
+
+// WithMaxMessageSize sets the message size limits of the client created by Dial, see Client.SetMaxMessageSize
+func WithMaxMessageSize(incoming, outgoing int) DialOption {
+	return func(o *dialOptions) {
+		o.maxIncoming = incoming
+		o.maxOutgoing = outgoing
+	}
+}
@@ -0,0 +1,50 @@
+package ovsdb
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestMaxMessageSize(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+	client.SetMaxMessageSize(1024, 512)
+	changes, cancel := client.StateChanges()
+	defer cancel()
+
+	big := strings.Repeat("x", 1024)
+	err := client.Call(context.Background(), "echo", big, nil)
+	if sizeErr, ok := err.(*MessageSizeError); !ok || sizeErr.Incoming || sizeErr.Limit != 512 {
+		t.Fatalf("oversized request failed with %v, want an outgoing *MessageSizeError", err)
+	}
+	// the connection is kept
+	if _, err := client.ListDbs(); err != nil {
+		t.Fatalf("ListDbs failed after an oversized request: %v", err)
+	}
+
+	// the limit is per message, many messages within it go through
+	medium := strings.Repeat("x", 300)
+	for i := 0; i < 20; i++ {
+		if err := client.Call(context.Background(), "echo", medium, nil); err != nil {
+			t.Fatalf("echo %d failed: %v", i, err)
+		}
+	}
+
+	go server.Notify("update", "m", map[string]interface{}{"Bridge": map[string]interface{}{big: nil}})
+	select {
+	case change := <-changes:
+		sizeErr, ok := change.Err.(*MessageSizeError)
+		if change.To != StateClosed || !ok || !sizeErr.Incoming || sizeErr.Limit != 1024 {
+			t.Errorf("state change = %+v after an oversized notification, want StateClosed with an incoming *MessageSizeError", change)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connection not closed by an oversized notification")
+	}
+}
@@ -0,0 +1,302 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// MonitorMux merges the monitor requests of multiple in-process consumers of a database into one
+// server-side monitor, so the server sends each update only once.
+// When consumers come and go, the union of their requests is recomputed and the server-side monitor
+// is replaced if the union changed. The new monitor is created before the old one is canceled,
+// so no update is lost, but consumers may receive duplicate updates while the monitor is replaced.
+// The merged monitor is a plain "monitor" without conditions, which can't be changed in place,
+// use MonitorCond for a conditional monitor of a single consumer.
+type MonitorMux struct {
+	client *Client
+	db     ID
+
+	mu        sync.Mutex
+	consumers map[int]*muxConsumer
+	nextID    int
+	monitorID string
+	union     MonitorRequests
+}
+
+// muxConsumer is a consumer registered to a MonitorMux
+type muxConsumer struct {
+	requests MonitorRequests
+	handler  func(updates TableUpdates)
+}
+
+// NewMonitorMux creates a MonitorMux of database db
+func NewMonitorMux(client *Client, db ID) *MonitorMux {
+	return &MonitorMux{
+		client:    client,
+		db:        db,
+		consumers: make(map[int]*muxConsumer),
+	}
+}
+
+// Register adds a consumer interested in requests, handler receives the initial contents of the
+// requested tables and updates afterwards, with only the requested columns and kinds of updates.
+// The returned cancel function removes the consumer. handler must not call methods of the MonitorMux.
+func (m *MonitorMux) Register(requests MonitorRequests, handler func(updates TableUpdates)) (cancel func() error, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.nextID
+	m.nextID++
+	consumer := &muxConsumer{requests: requests, handler: handler}
+	m.consumers[id] = consumer
+
+	initial, err := m.remonitor()
+	if err != nil {
+		delete(m.consumers, id)
+		return nil, err
+	}
+	if initial == nil {
+		// the server-side monitor is unchanged, fetch the initial contents for the new consumer
+		if initial, err = m.initialContents(requests); err != nil {
+			delete(m.consumers, id)
+			m.remonitor()
+			return nil, err
+		}
+	}
+	if filtered := filterTableUpdates(requests, initial, true); len(filtered) != 0 {
+		handler(filtered)
+	}
+
+	cancel = func() error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if _, ok := m.consumers[id]; !ok {
+			return nil
+		}
+		delete(m.consumers, id)
+		_, err := m.remonitor()
+		return err
+	}
+	return cancel, nil
+}
+
+// remonitor replaces the server-side monitor if the union of requests changed,
+// it returns the initial contents of the new monitor, or nil if the monitor is unchanged.
+// m.mu must be held.
+func (m *MonitorMux) remonitor() (TableUpdates, error) {
+	union := unionMonitorRequests(m.consumers)
+	if reflect.DeepEqual(union, m.union) {
+		return nil, nil
+	}
+
+	oldMonitorID := m.monitorID
+	var initial TableUpdates
+	if len(union) != 0 {
+		monitorID := m.client.watchMonitor(m.dispatch)
+		var err error
+		if initial, err = m.client.Monitor(m.db, monitorID, union); err != nil {
+			m.client.unwatchMonitor(monitorID)
+			return nil, err
+		}
+		m.monitorID = monitorID
+	} else {
+		m.monitorID = ""
+	}
+	m.union = union
+
+	if oldMonitorID != "" {
+		m.client.unwatchMonitor(oldMonitorID)
+		if err := m.client.MonitorCancel(oldMonitorID); err != nil {
+			return nil, err
+		}
+	}
+	if initial == nil {
+		initial = TableUpdates{}
+	}
+	return initial, nil
+}
+
+// initialContents selects the current contents of the requested tables, in the form of initial updates
+func (m *MonitorMux) initialContents(requests MonitorRequests) (TableUpdates, error) {
+	var tables []ID
+	var ops []Operation
+	for table := range requests {
+		tables = append(tables, table)
+		ops = append(ops, &SelectOperation{Table: table, Where: MatchAll()})
+	}
+	result, err := m.client.Transact(m.db, ops...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) != 0 {
+		return nil, result.Errors
+	}
+
+	updates := make(TableUpdates)
+	for i, table := range tables {
+		rows, err := decodeBackupRows(result.Results[i])
+		if err != nil {
+			return nil, err
+		}
+		tableUpdate := make(TableUpdate, len(rows))
+		for uuid, row := range rows {
+			raw, err := json.Marshal(row)
+			if err != nil {
+				return nil, err
+			}
+			newRow := json.RawMessage(raw)
+			tableUpdate[uuid] = RowUpdate{New: &newRow}
+		}
+		updates[table] = tableUpdate
+	}
+	return updates, nil
+}
+
+// dispatch delivers updates of the server-side monitor to the interested consumers
+func (m *MonitorMux) dispatch(updates TableUpdates) {
+	m.mu.Lock()
+	var consumers []*muxConsumer
+	for _, consumer := range m.consumers {
+		consumers = append(consumers, consumer)
+	}
+	m.mu.Unlock()
+
+	for _, consumer := range consumers {
+		if filtered := filterTableUpdates(consumer.requests, updates, false); len(filtered) != 0 {
+			consumer.handler(filtered)
+		}
+	}
+}
+
+// unionMonitorRequests merges requests of consumers into one MonitorRequests
+func unionMonitorRequests(consumers map[int]*muxConsumer) MonitorRequests {
+	union := make(MonitorRequests)
+	allColumns := make(map[ID]bool)
+	for _, consumer := range consumers {
+		for table, request := range consumer.requests {
+			merged, ok := union[table]
+			if !ok {
+				merged.Select = &MonitorSelect{}
+			}
+			// no columns means all columns
+			if len(request.Columns) == 0 || allColumns[table] {
+				allColumns[table] = true
+				merged.Columns = nil
+			} else {
+				merged.Columns = unionColumns(merged.Columns, request.Columns)
+			}
+			for _, selectType := range []SelectType{SelectInitial, SelectInsert, SelectDelete, SelectModify} {
+				if selects(request.Select, selectType) {
+					(*merged.Select)[selectType] = true
+				}
+			}
+			union[table] = merged
+		}
+	}
+	return union
+}
+
+// unionColumns returns the sorted union of two column lists
+func unionColumns(a, b []ID) []ID {
+	set := make(map[ID]bool)
+	for _, column := range append(append([]ID{}, a...), b...) {
+		set[column] = true
+	}
+	columns := make([]ID, 0, len(set))
+	for column := range set {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i] < columns[j] })
+	return columns
+}
+
+// selects returns true if the select of a monitor request includes selectType, all types are selected by default
+func selects(monitorSelect *MonitorSelect, selectType SelectType) bool {
+	if monitorSelect == nil {
+		return true
+	}
+	selected, ok := (*monitorSelect)[selectType]
+	return !ok || selected
+}
+
+// filterTableUpdates returns the part of updates a consumer with requests is interested in
+func filterTableUpdates(requests MonitorRequests, updates TableUpdates, initial bool) TableUpdates {
+	filtered := make(TableUpdates)
+	for table, tableUpdate := range updates {
+		request, ok := requests[table]
+		if !ok {
+			continue
+		}
+		filteredTable := make(TableUpdate)
+		for uuid, rowUpdate := range tableUpdate {
+			if rowUpdate, ok := filterRowUpdate(request, rowUpdate, initial); ok {
+				filteredTable[uuid] = rowUpdate
+			}
+		}
+		if len(filteredTable) != 0 {
+			filtered[table] = filteredTable
+		}
+	}
+	return filtered
+}
+
+// filterRowUpdate projects rowUpdate to the requested columns, it returns false if the consumer
+// is not interested in the update
+func filterRowUpdate(request MonitorRequest, rowUpdate RowUpdate, initial bool) (RowUpdate, bool) {
+	var selectType SelectType
+	switch {
+	case initial:
+		selectType = SelectInitial
+	case rowUpdate.Old == nil:
+		selectType = SelectInsert
+	case rowUpdate.New == nil:
+		selectType = SelectDelete
+	default:
+		selectType = SelectModify
+	}
+	if !selects(request.Select, selectType) {
+		return rowUpdate, false
+	}
+	if len(request.Columns) == 0 {
+		return rowUpdate, true
+	}
+
+	var err error
+	var filtered RowUpdate
+	if filtered.Old, err = projectRow(rowUpdate.Old, request.Columns); err != nil {
+		return rowUpdate, false
+	}
+	if filtered.New, err = projectRow(rowUpdate.New, request.Columns); err != nil {
+		return rowUpdate, false
+	}
+	// "old" of a modification only holds the changed columns, skip if none is requested
+	if selectType == SelectModify && filtered.Old != nil && string(*filtered.Old) == "{}" {
+		return rowUpdate, false
+	}
+	return filtered, true
+}
+
+// projectRow keeps only columns of a JSON encoded row
+func projectRow(row *json.RawMessage, columns []ID) (*json.RawMessage, error) {
+	if row == nil {
+		return nil, nil
+	}
+	var values map[ID]json.RawMessage
+	if err := json.Unmarshal(*row, &values); err != nil {
+		return nil, err
+	}
+	projected := make(map[ID]json.RawMessage, len(columns))
+	for _, column := range columns {
+		if value, ok := values[column]; ok {
+			projected[column] = value
+		}
+	}
+	raw, err := json.Marshal(projected)
+	if err != nil {
+		return nil, err
+	}
+	result := json.RawMessage(raw)
+	return &result, nil
+}
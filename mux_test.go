@@ -0,0 +1,76 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnionMonitorRequests(t *testing.T) {
+	consumers := map[int]*muxConsumer{
+		0: {requests: MonitorRequests{
+			"Bridge":    {Columns: []ID{"name"}},
+			"Interface": {Columns: []ID{"name"}, Select: &MonitorSelect{SelectInitial: true, SelectModify: false}},
+		}},
+		1: {requests: MonitorRequests{
+			"Bridge":    {Columns: []ID{"ports", "name"}},
+			"Interface": {},
+		}},
+		2: {requests: MonitorRequests{
+			"Port": {Columns: []ID{"name"}, Select: &MonitorSelect{SelectInsert: false, SelectDelete: false, SelectModify: false}},
+		}},
+	}
+
+	union := unionMonitorRequests(consumers)
+	all := &MonitorSelect{SelectInitial: true, SelectInsert: true, SelectDelete: true, SelectModify: true}
+	want := MonitorRequests{
+		"Bridge":    {Columns: []ID{"name", "ports"}, Select: all},
+		"Interface": {Select: all},
+		"Port":      {Columns: []ID{"name"}, Select: &MonitorSelect{SelectInitial: true}},
+	}
+	if !reflect.DeepEqual(union, want) {
+		t.Errorf("unionMonitorRequests = %+v, want %+v", union, want)
+	}
+}
+
+func TestFilterTableUpdates(t *testing.T) {
+	raw := func(s string) *json.RawMessage {
+		r := json.RawMessage(s)
+		return &r
+	}
+	updates := TableUpdates{
+		"Bridge": {
+			"uuid-insert": {New: raw(`{"name":"br0","ports":["set",[]]}`)},
+			"uuid-modify": {Old: raw(`{"ports":["set",[]]}`), New: raw(`{"name":"br1","ports":["uuid","p1"]}`)},
+			"uuid-delete": {Old: raw(`{"name":"br2","ports":["set",[]]}`)},
+		},
+		"Port": {
+			"uuid-port": {New: raw(`{"name":"p1"}`)},
+		},
+	}
+	requests := MonitorRequests{
+		"Bridge": {Columns: []ID{"name"}, Select: &MonitorSelect{SelectDelete: false}},
+	}
+
+	filtered := filterTableUpdates(requests, updates, false)
+	if _, ok := filtered["Port"]; ok {
+		t.Error("unrequested table Port should be filtered")
+	}
+	bridge := filtered["Bridge"]
+	if len(bridge) != 1 {
+		t.Fatalf("got %d Bridge updates, want only the insert: %v", len(bridge), bridge)
+	}
+	if got := string(*bridge["uuid-insert"].New); got != `{"name":"br0"}` {
+		t.Errorf("insert projected to %s, want {\"name\":\"br0\"}", got)
+	}
+
+	// modification of a requested column is kept
+	requests["Bridge"] = MonitorRequest{Columns: []ID{"ports"}}
+	filtered = filterTableUpdates(requests, updates, false)
+	if _, ok := filtered["Bridge"]["uuid-modify"]; !ok {
+		t.Error("modification of ports should be kept")
+	}
+	if _, ok := filtered["Bridge"]["uuid-delete"]; !ok {
+		t.Error("deletion should be kept")
+	}
+}
@@ -30,6 +30,13 @@ type NotificationHandler interface {
 	Stolen(lock ID) error
 }
 
+// MonitorCanceledHandler is implemented by notification handlers interested in "monitor_canceled"
+// notifications, which are sent by servers to clients aware of database changes (see WatchDatabases)
+// when a monitored database is removed
+type MonitorCanceledHandler interface {
+	MonitorCanceled(jsonValue Value) error
+}
+
 // NotificationHandlerFuncs is a adapter which implements NotificationHandler interface
 type NotificationHandlerFuncs struct {
 	UpdateFunc func(jsonValue Value, updates TableUpdates) error
@@ -77,9 +84,16 @@ func (nh *NotificationHandlerFuncs) Stolen(lock ID) error {
 
 // handler function for "update" notification
 func updateHandler(client *rpc2.Client, params []interface{}, reply *[]interface{}) error {
+	clientsLock.RLock()
+	ovsClient, ok := clientsMap[client]
+	clientsLock.RUnlock()
+	if !ok {
+		return nil
+	}
+
 	// "params": [<json-value>, <table-updates>]
 	if len(params) != 2 {
-		return errors.New("invalid update notification: wrong number of parameters")
+		return ovsClient.decodeError("update", params, errors.New("invalid update notification: wrong number of parameters"))
 	}
 
 	var jsonValue = Value(params[0])
@@ -87,56 +101,176 @@ func updateHandler(client *rpc2.Client, params []interface{}, reply *[]interface
 	bytes, _ := json.Marshal(params[1])
 	err := json.Unmarshal(bytes, &tableUpdates)
 	if err != nil {
-		return fmt.Errorf("failed to decode <table-updates>: %v", err)
+		return ovsClient.decodeError("update", params, fmt.Errorf("failed to decode <table-updates>: %v", err))
 	}
 
+	// updates of monitors created by helpers of this package are not seen by the handler
+	if ovsClient.notifyMonitor(jsonValue, tableUpdates) {
+		return nil
+	}
+	tableUpdates, ok = ovsClient.filterUpdates(tableUpdates)
+	if !ok {
+		return nil
+	}
+	return ovsClient.handler.Update(jsonValue, tableUpdates)
+}
+
+// handler function for "monitor_canceled" notification
+func monitorCanceledHandler(client *rpc2.Client, params []interface{}, reply *[]interface{}) error {
 	clientsLock.RLock()
 	ovsClient, ok := clientsMap[client]
 	clientsLock.RUnlock()
-	if ok {
-		return ovsClient.handler.Update(jsonValue, tableUpdates)
+	if !ok {
+		return nil
+	}
+
+	// "params": [<json-value>]
+	if len(params) != 1 {
+		return ovsClient.decodeError("monitor_canceled", params, errors.New("invalid monitor_canceled notification: wrong number of parameters"))
+	}
+	ovsClient.monitorCanceled(Value(params[0]))
+	if monitorID, ok := params[0].(string); ok {
+		// monitors created by helpers of this package are not seen by the handler
+		ovsClient.mu.Lock()
+		_, watched := ovsClient.monitorWatchers[monitorID]
+		_, watched2 := ovsClient.monitor2Watchers[monitorID]
+		fn := ovsClient.monitorCanceledWatchers[monitorID]
+		ovsClient.mu.Unlock()
+		if watched || watched2 {
+			if fn != nil {
+				fn()
+			}
+			return nil
+		}
+	}
+	if handler, ok := ovsClient.handler.(MonitorCanceledHandler); ok {
+		return handler.MonitorCanceled(Value(params[0]))
 	}
 	return nil
 }
 
 // handler function for "locked" notification
 func lockedHandler(client *rpc2.Client, params []interface{}, reply *[]interface{}) error {
+	clientsLock.RLock()
+	ovsClient, ok := clientsMap[client]
+	clientsLock.RUnlock()
+	if !ok {
+		return nil
+	}
+
 	// "params": [<id>]
 	// <id> is the lock name requested with a former lock method
 	if len(params) != 1 {
-		return errors.New("invalid locked notification: wrong number of parameters")
+		return ovsClient.decodeError("locked", params, errors.New("invalid locked notification: wrong number of parameters"))
 	}
 	lock, ok := params[0].(string)
 	if !ok {
-		return errors.New("invalid locked notification: wrong lock name")
+		return ovsClient.decodeError("locked", params, errors.New("invalid locked notification: wrong lock name"))
 	}
 
+	ovsClient.trackLock(ID(lock), true)
+	ovsClient.notifyLock(ID(lock), true)
+	return ovsClient.handler.Locked(ID(lock))
+}
+
+// handler function for "stolen" function
+func stolenHandler(client *rpc2.Client, params []interface{}, reply *[]interface{}) error {
 	clientsLock.RLock()
 	ovsClient, ok := clientsMap[client]
 	clientsLock.RUnlock()
-	if ok {
-		return ovsClient.handler.Locked(ID(lock))
+	if !ok {
+		return nil
 	}
-	return nil
-}
 
-// handler function for "stolen" function
-func stolenHandler(client *rpc2.Client, params []interface{}, reply *[]interface{}) error {
 	// "params": [<id>]
 	// <id> is the lock name which was stolen by another client
 	if len(params) != 1 {
-		return errors.New("invalid stolen notification: wrong number of parameters")
+		return ovsClient.decodeError("stolen", params, errors.New("invalid stolen notification: wrong number of parameters"))
 	}
 	lock, ok := params[0].(string)
 	if !ok {
-		return errors.New("invalid stolen notification: wrong lock name")
+		return ovsClient.decodeError("stolen", params, errors.New("invalid stolen notification: wrong lock name"))
 	}
 
-	clientsLock.RLock()
-	ovsClient, ok := clientsMap[client]
-	clientsLock.RUnlock()
+	ovsClient.lockStolen(ID(lock))
+	ovsClient.notifyLock(ID(lock), false)
+	return ovsClient.handler.Stolen(ID(lock))
+}
+
+// watchLock registers fn to be called on "locked" and "stolen" notifications of lock,
+// besides the notification handler
+func (c *Client) watchLock(lock ID, fn func(locked bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lockWatchers == nil {
+		c.lockWatchers = make(map[ID]func(locked bool))
+	}
+	c.lockWatchers[lock] = fn
+}
+
+// unwatchLock removes the function registered by watchLock
+func (c *Client) unwatchLock(lock ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.lockWatchers, lock)
+}
+
+// watchMonitor registers fn to receive the updates of a monitor created by this package,
+// it returns the <json-value> identifying the monitor
+func (c *Client) watchMonitor(fn func(updates TableUpdates)) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.monitorWatchers == nil {
+		c.monitorWatchers = make(map[string]func(updates TableUpdates))
+	}
+	c.monitorSeq++
+	monitorID := fmt.Sprintf("go-ovsdb-monitor-%d", c.monitorSeq)
+	c.monitorWatchers[monitorID] = fn
+	return monitorID
+}
+
+// watchMonitorCanceled registers fn to be called on the "monitor_canceled" notification of a monitor
+// registered with watchMonitor
+func (c *Client) watchMonitorCanceled(monitorID string, fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.monitorCanceledWatchers == nil {
+		c.monitorCanceledWatchers = make(map[string]func())
+	}
+	c.monitorCanceledWatchers[monitorID] = fn
+}
+
+// unwatchMonitor removes the functions registered by watchMonitor, watchMonitor2 and watchMonitorCanceled
+func (c *Client) unwatchMonitor(monitorID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.monitorWatchers, monitorID)
+	delete(c.monitor2Watchers, monitorID)
+	delete(c.monitorCanceledWatchers, monitorID)
+}
+
+// notifyMonitor delivers updates to the function registered by watchMonitor for jsonValue,
+// it returns false if there's no such function
+func (c *Client) notifyMonitor(jsonValue Value, updates TableUpdates) bool {
+	monitorID, ok := jsonValue.(string)
+	if !ok {
+		return false
+	}
+	c.mu.Lock()
+	fn, ok := c.monitorWatchers[monitorID]
+	c.mu.Unlock()
 	if ok {
-		return ovsClient.handler.Stolen(ID(lock))
+		fn(updates)
+	}
+	return ok
+}
+
+// notifyLock calls the function registered by watchLock for lock, if any
+func (c *Client) notifyLock(lock ID, locked bool) {
+	c.mu.Lock()
+	fn, ok := c.lockWatchers[lock]
+	c.mu.Unlock()
+	if ok {
+		fn(locked)
 	}
-	return nil
 }
@@ -52,6 +52,14 @@ func (insert InsertOperation) MarshalJSON() ([]byte, error) {
 	case insert.Row == nil:
 		return nil, errors.New("Row field is required")
 	}
+	if err := validateID("table name", insert.Table); err != nil {
+		return nil, err
+	}
+	if len(insert.UUIDName) != 0 {
+		if err := validateID("uuid-name", insert.UUIDName); err != nil {
+			return nil, err
+		}
+	}
 
 	var temp = struct {
 		Op       OperationType `json:"op"`
@@ -101,12 +109,18 @@ func (s SelectOperation) MarshalJSON() ([]byte, error) {
 	case len(s.Where) == 0:
 		return nil, errors.New("Where field is required")
 	}
-	// validate contions
-	for _, cond := range s.Where {
-		if !cond.Valid() {
-			return nil, fmt.Errorf("Invalid condition: %v", cond)
+	if err := validateID("table name", s.Table); err != nil {
+		return nil, err
+	}
+	for _, column := range s.Columns {
+		if err := validateID("column name", column); err != nil {
+			return nil, err
 		}
 	}
+	// validate contions
+	if err := validateConditions(s.Where); err != nil {
+		return nil, err
+	}
 
 	var temp = struct {
 		Op      OperationType `json:"op"`
@@ -140,12 +154,14 @@ type SelectResult struct {
 
 // UpdateOperation searches rows that match all the conditions specified in Where and
 // changes the value of each column specified in Row to the value for that column specified in Row
+// If Mask is not empty, only the columns in Mask are written.
 // The corresponding result object contains the following member:
 // "count": <integer>
 type UpdateOperation struct {
 	Table ID
 	Where []Condition
 	Row   Row
+	Mask  FieldMask
 }
 
 // Op implements Operation interface
@@ -164,10 +180,19 @@ func (u UpdateOperation) MarshalJSON() ([]byte, error) {
 	case u.Row == nil:
 		return nil, errors.New("Row field is required")
 	}
+	if err := validateID("table name", u.Table); err != nil {
+		return nil, err
+	}
 	// validate contions
-	for _, cond := range u.Where {
-		if !cond.Valid() {
-			return nil, fmt.Errorf("Invalid condition: %v", cond)
+	if err := validateConditions(u.Where); err != nil {
+		return nil, err
+	}
+	// write only the masked columns
+	row := u.Row
+	if len(u.Mask) != 0 {
+		var err error
+		if row, err = u.Mask.Apply(u.Row); err != nil {
+			return nil, err
 		}
 	}
 
@@ -180,7 +205,7 @@ func (u UpdateOperation) MarshalJSON() ([]byte, error) {
 		Op:    u.Op(),
 		Table: u.Table,
 		Where: u.Where,
-		Row:   u.Row,
+		Row:   row,
 	}
 
 	return json.Marshal(temp)
@@ -216,11 +241,12 @@ func (mutate MutateOperation) MarshalJSON() ([]byte, error) {
 	case len(mutate.Mutations) == 0:
 		return nil, errors.New("Mutations field is required")
 	}
+	if err := validateID("table name", mutate.Table); err != nil {
+		return nil, err
+	}
 	// validate contions
-	for _, cond := range mutate.Where {
-		if !cond.Valid() {
-			return nil, fmt.Errorf("Invalid condition: %v", cond)
-		}
+	if err := validateConditions(mutate.Where); err != nil {
+		return nil, err
 	}
 	// validate mutations
 	for _, mutation := range mutate.Mutations {
@@ -272,14 +298,19 @@ func (c Condition) MarshalJSON() ([]byte, error) {
 	return json.Marshal(temp)
 }
 
-// Valid returns true if condition is valid, otherwise false
+// zeroUUID is the all-zero UUID, it's never used as the UUID of a row
+const zeroUUID = "00000000-0000-0000-0000-000000000000"
+
+// MatchAll returns conditions matched by all rows of a table,
+// since operations in this package require a non-empty Where
+func MatchAll() []Condition {
+	return []Condition{{"_uuid", FuncNe, UUID(zeroUUID)}}
+}
+
+// Valid returns true if condition is valid, otherwise false.
+// Use ValidateConditions to check conditions against the schema of their table.
 func (c Condition) Valid() bool {
-	// TODO: pass in a ColumnSchema and do validation based on it
-	switch c.Function {
-	case FuncLt, FuncLe, FuncEq, FuncNe, FuncGt, FuncGe, FuncInc, FuncExc:
-		return true
-	}
-	return false
+	return c.validate() == nil
 }
 
 // Function is the condition operator
@@ -320,6 +351,9 @@ func (m Mutation) MarshalJSON() ([]byte, error) {
 // Valid returns true if mutation is valid, otherwise false
 func (m Mutation) Valid() bool {
 	// TODO: pass in a ColumnSchema and do validation based on it
+	if validateID("column name", m.Column) != nil {
+		return false
+	}
 	switch m.Mutator {
 	case MutatorPluEq, MutatorMinEq, MutatorMulEq, MutatorDivEq, MutatorModEq, MutatorInsert, MutatorDelete:
 		return true
@@ -365,11 +399,12 @@ func (d DeleteOperation) MarshalJSON() ([]byte, error) {
 	case len(d.Where) == 0:
 		return nil, errors.New("Where field is required")
 	}
+	if err := validateID("table name", d.Table); err != nil {
+		return nil, err
+	}
 	// validate contions
-	for _, cond := range d.Where {
-		if !cond.Valid() {
-			return nil, fmt.Errorf("Invalid condition: %v", cond)
-		}
+	if err := validateConditions(d.Where); err != nil {
+		return nil, err
 	}
 
 	var temp = struct {
@@ -388,3 +423,119 @@ func (d DeleteOperation) MarshalJSON() ([]byte, error) {
 type DeleteResult struct {
 	Count int `json:"count"`
 }
+
+/////////////////////////////////////////////////////////////////////
+// wait operation
+// https://tools.ietf.org/html/rfc7047#section-5.2.6
+/////////////////////////////////////////////////////////////////////
+
+// WaitOperation waits until the rows of Table matching Where, projected on Columns, are equal to Rows
+// if Until is WaitEqual, or differ from Rows if Until is WaitNotEqual. If the condition is not true
+// within Timeout milliseconds, the transaction aborts with a "timed out" error. A Timeout of 0 aborts
+// at once if the condition is false, e.g. for compare-and-swap style transactions which write rows only
+// if they are unchanged since read, a nil Timeout waits with no limit.
+// The corresponding result object is empty
+type WaitOperation struct {
+	Timeout *int
+	Table   ID
+	Where   []Condition
+	Columns []ID
+	Until   WaitUntil
+	Rows    []Row
+}
+
+// WaitUntil is the comparison of a WaitOperation
+type WaitUntil string
+
+// Supported WaitUntils
+const (
+	WaitEqual    WaitUntil = "=="
+	WaitNotEqual WaitUntil = "!="
+)
+
+// Op implements Operation interface
+func (w *WaitOperation) Op() OperationType {
+	return OpWait
+}
+
+// MarshalJSON implements json.Marshaler interface
+func (w WaitOperation) MarshalJSON() ([]byte, error) {
+	// validate required fields
+	switch {
+	case len(w.Table) == 0:
+		return nil, errors.New("Table field is required")
+	case len(w.Where) == 0:
+		return nil, errors.New("Where field is required")
+	case w.Columns == nil:
+		return nil, errors.New("Columns field is required")
+	case w.Until != WaitEqual && w.Until != WaitNotEqual:
+		return nil, fmt.Errorf("Invalid until %q: must be %q or %q", w.Until, WaitEqual, WaitNotEqual)
+	case w.Timeout != nil && *w.Timeout < 0:
+		return nil, fmt.Errorf("Invalid timeout %d: must not be negative", *w.Timeout)
+	}
+	if err := validateID("table name", w.Table); err != nil {
+		return nil, err
+	}
+	for _, column := range w.Columns {
+		if err := validateID("column name", column); err != nil {
+			return nil, err
+		}
+	}
+	// validate contions
+	if err := validateConditions(w.Where); err != nil {
+		return nil, err
+	}
+	// no rows is valid, e.g. to wait until no row matches
+	rows := w.Rows
+	if rows == nil {
+		rows = []Row{}
+	}
+
+	var temp = struct {
+		Op      OperationType `json:"op"`
+		Timeout *int          `json:"timeout,omitempty"`
+		Table   ID            `json:"table"`
+		Where   []Condition   `json:"where"`
+		Columns []ID          `json:"columns"`
+		Until   WaitUntil     `json:"until"`
+		Rows    []Row         `json:"rows"`
+	}{
+		Op:      w.Op(),
+		Timeout: w.Timeout,
+		Table:   w.Table,
+		Where:   w.Where,
+		Columns: w.Columns,
+		Until:   w.Until,
+		Rows:    rows,
+	}
+	return json.Marshal(temp)
+}
+
+/////////////////////////////////////////////////////////////////////
+// comment operation
+// https://tools.ietf.org/html/rfc7047#section-5.2.9
+/////////////////////////////////////////////////////////////////////
+
+// CommentOperation provides information to a database administrator on the purpose of a transaction,
+// the OVSDB server writes Comment to its log
+// The corresponding result object is empty
+type CommentOperation struct {
+	Comment string
+}
+
+// Op implements Operation interface
+func (c *CommentOperation) Op() OperationType {
+	return OpComment
+}
+
+// MarshalJSON implements json.Marshaler interface
+func (c CommentOperation) MarshalJSON() ([]byte, error) {
+	var temp = struct {
+		Op      OperationType `json:"op"`
+		Comment string        `json:"comment"`
+	}{
+		Op:      c.Op(),
+		Comment: c.Comment,
+	}
+	return json.Marshal(temp)
+}
@@ -20,6 +20,9 @@ func TestInsertOperation(t *testing.T) {
 		{InsertOperation{Table: "TestTable"}, true, ``},
 		{InsertOperation{Table: "TestTable", Row: map[ID]Value{"TestColumn": "TestValue"}}, false, `{"op":"insert","table":"TestTable","row":{"TestColumn":"TestValue"}}`},
 		{InsertOperation{Table: "TestTable", Row: map[ID]Value{"TestColumn": "TestValue"}, UUIDName: "TestUUIDName"}, false, `{"op":"insert","table":"TestTable","row":{"TestColumn":"TestValue"},"uuid-name":"TestUUIDName"}`},
+		// invalid IDs
+		{InsertOperation{Table: "Test-Table", Row: map[ID]Value{"TestColumn": "TestValue"}}, true, ``},
+		{InsertOperation{Table: "TestTable", Row: map[ID]Value{"TestColumn": "TestValue"}, UUIDName: "Test UUID Name"}, true, ``},
 	}
 	for _, test := range marshalTests {
 		bytes, err := json.Marshal(test.op)
@@ -79,6 +82,24 @@ func TestSelectOperation(t *testing.T) {
 			shouldFail: true,
 			json:       ``,
 		},
+		// invalid column names
+		{
+			op: SelectOperation{
+				Table: "TestTable",
+				Where: []Condition{Condition{"Test Column", "==", "TestValue"}},
+			},
+			shouldFail: true,
+			json:       ``,
+		},
+		{
+			op: SelectOperation{
+				Table:   "TestTable",
+				Where:   []Condition{Condition{"TestColumn", "==", "TestValue"}},
+				Columns: []ID{"0Column"},
+			},
+			shouldFail: true,
+			json:       ``,
+		},
 	}
 	for _, test := range marshalTests {
 		bytes, err := json.Marshal(test.op)
@@ -149,6 +170,28 @@ func TestUpdateOperation(t *testing.T) {
 			shouldFail: false,
 			json:       `{"op":"update","table":"TestTable","where":[["TestColumn","==","TestValue"]],"row":{"TestColumn":"NewValue"}}`,
 		},
+		// masked columns
+		{
+			op: UpdateOperation{
+				Table: "TestTable",
+				Where: []Condition{Condition{"TestColumn", "==", "TestValue"}},
+				Row:   map[ID]Value{"TestColumn": "NewValue", "OtherColumn": "OtherValue"},
+				Mask:  Fields("TestColumn"),
+			},
+			shouldFail: false,
+			json:       `{"op":"update","table":"TestTable","where":[["TestColumn","==","TestValue"]],"row":{"TestColumn":"NewValue"}}`,
+		},
+		// masked column missing in Row
+		{
+			op: UpdateOperation{
+				Table: "TestTable",
+				Where: []Condition{Condition{"TestColumn", "==", "TestValue"}},
+				Row:   map[ID]Value{"OtherColumn": "OtherValue"},
+				Mask:  Fields("TestColumn"),
+			},
+			shouldFail: true,
+			json:       ``,
+		},
 		// invalid condition
 		{
 			op: UpdateOperation{
@@ -312,3 +355,80 @@ func TestDeleteOperation(t *testing.T) {
 		}
 	}
 }
+
+func TestWaitOperation(t *testing.T) {
+	w := &WaitOperation{}
+	if op := w.Op(); op != OpWait {
+		t.Errorf("Op() returned %q, want %q", op, OpWait)
+	}
+	timeout := 0
+	negative := -1
+	where := []Condition{Condition{"TestColumn", "==", "TestValue"}}
+	marshalTests := []struct {
+		op         WaitOperation
+		shouldFail bool
+		json       string
+	}{
+		// empty
+		{WaitOperation{}, true, ``},
+		// missing Table
+		{WaitOperation{Where: where, Columns: []ID{"TestColumn"}, Until: WaitEqual}, true, ``},
+		// missing Where
+		{WaitOperation{Table: "TestTable", Columns: []ID{"TestColumn"}, Until: WaitEqual}, true, ``},
+		// missing Columns
+		{WaitOperation{Table: "TestTable", Where: where, Until: WaitEqual}, true, ``},
+		// invalid Until
+		{WaitOperation{Table: "TestTable", Where: where, Columns: []ID{"TestColumn"}, Until: "<"}, true, ``},
+		// negative Timeout
+		{WaitOperation{Timeout: &negative, Table: "TestTable", Where: where, Columns: []ID{"TestColumn"}, Until: WaitEqual}, true, ``},
+		// invalid condition
+		{
+			op: WaitOperation{
+				Table:   "TestTable",
+				Where:   []Condition{Condition{"TestColumn", "invalid function", "TestValue"}},
+				Columns: []ID{"TestColumn"},
+				Until:   WaitEqual,
+			},
+			shouldFail: true,
+		},
+		// valid case
+		{
+			op: WaitOperation{
+				Timeout: &timeout,
+				Table:   "TestTable",
+				Where:   where,
+				Columns: []ID{"TestColumn"},
+				Until:   WaitEqual,
+				Rows:    []Row{map[string]interface{}{"TestColumn": "TestValue"}},
+			},
+			shouldFail: false,
+			json:       `{"op":"wait","timeout":0,"table":"TestTable","where":[["TestColumn","==","TestValue"]],"columns":["TestColumn"],"until":"==","rows":[{"TestColumn":"TestValue"}]}`,
+		},
+		// no timeout and no rows
+		{
+			op: WaitOperation{
+				Table:   "TestTable",
+				Where:   where,
+				Columns: []ID{},
+				Until:   WaitNotEqual,
+			},
+			shouldFail: false,
+			json:       `{"op":"wait","table":"TestTable","where":[["TestColumn","==","TestValue"]],"columns":[],"until":"!=","rows":[]}`,
+		},
+	}
+	for _, test := range marshalTests {
+		bytes, err := json.Marshal(test.op)
+		if test.shouldFail {
+			if err == nil {
+				t.Error("expect json marshal failed, but got nil")
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("json marshal failed: %v", err)
+		}
+		if string(bytes) != test.json {
+			t.Errorf("json marshal got %q, want %q", bytes, test.json)
+		}
+	}
+}
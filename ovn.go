@@ -0,0 +1,85 @@
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// OVN database and NB_Global columns used by the config sequence number helpers
+const (
+	ovnNorthbound = "OVN_Northbound"
+	nbGlobal      = "NB_Global"
+	nbCfg         = "nb_cfg"
+	sbCfg         = "sb_cfg"
+	hvCfg         = "hv_cfg"
+)
+
+var errNoNbGlobal = errors.New("NB_Global row not found")
+
+// BumpNbCfg increments NB_Global.nb_cfg in OVN_Northbound and returns the new sequence number.
+// ovn-northd copies it to sb_cfg once the change is in the southbound database, and
+// to hv_cfg once all hypervisors have realized it, see WaitForConfigSync.
+func (c *Client) BumpNbCfg() (int64, error) {
+	result, err := c.Transact(ovnNorthbound,
+		&MutateOperation{
+			Table:     nbGlobal,
+			Where:     MatchAll(),
+			Mutations: []Mutation{{nbCfg, MutatorPluEq, 1}},
+		},
+		&SelectOperation{
+			Table:   nbGlobal,
+			Where:   MatchAll(),
+			Columns: []ID{nbCfg},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	if len(result.Errors) != 0 {
+		return 0, result.Errors
+	}
+
+	selected, err := result.SelectAt(1)
+	if err != nil {
+		return 0, err
+	}
+	if len(selected.Rows) == 0 || selected.Rows[0] == nil {
+		return 0, errNoNbGlobal
+	}
+	return nbGlobalSeq(*selected.Rows[0], nbCfg)
+}
+
+// WaitForConfigSync blocks until NB_Global.hv_cfg reaches seq, i.e. all hypervisors have realized
+// the changes made before nb_cfg was bumped to seq, or ctx is done.
+func (c *Client) WaitForConfigSync(ctx context.Context, seq int64) error {
+	return c.waitForNbGlobal(ctx, hvCfg, seq)
+}
+
+// WaitForSbConfigSync blocks until NB_Global.sb_cfg reaches seq, i.e. ovn-northd has written
+// the changes made before nb_cfg was bumped to seq into the southbound database, or ctx is done.
+func (c *Client) WaitForSbConfigSync(ctx context.Context, seq int64) error {
+	return c.waitForNbGlobal(ctx, sbCfg, seq)
+}
+
+// waitForNbGlobal monitors column of NB_Global until it reaches seq
+func (c *Client) waitForNbGlobal(ctx context.Context, column ID, seq int64) error {
+	_, err := c.waitForRow(ctx, ovnNorthbound, nbGlobal, []ID{column}, func(uuid UUID, row json.RawMessage) (bool, error) {
+		current, err := nbGlobalSeq(row, column)
+		if err != nil {
+			return false, err
+		}
+		return current >= seq, nil
+	})
+	return err
+}
+
+// nbGlobalSeq decodes the sequence number in column of a NB_Global row
+func nbGlobalSeq(row json.RawMessage, column ID) (int64, error) {
+	var columns map[ID]float64
+	if err := json.Unmarshal(row, &columns); err != nil {
+		return 0, fmt.Errorf("failed to decode NB_Global row: %v", err)
+	}
+	return int64(columns[column]), nil
+}
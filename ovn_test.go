@@ -0,0 +1,30 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNbGlobalSeq(t *testing.T) {
+	tests := []struct {
+		row    string
+		column ID
+		seq    int64
+		ok     bool
+	}{
+		{`{"nb_cfg":3,"hv_cfg":2}`, "nb_cfg", 3, true},
+		{`{"nb_cfg":3,"hv_cfg":2}`, "hv_cfg", 2, true},
+		{`{"nb_cfg":3}`, "sb_cfg", 0, true},
+		{`{"nb_cfg":"3"}`, "nb_cfg", 0, false},
+	}
+	for _, test := range tests {
+		seq, err := nbGlobalSeq(json.RawMessage(test.row), test.column)
+		if test.ok != (err == nil) {
+			t.Errorf("nbGlobalSeq(%s, %s) error = %v, want ok %v", test.row, test.column, err, test.ok)
+			continue
+		}
+		if seq != test.seq {
+			t.Errorf("nbGlobalSeq(%s, %s) = %d, want %d", test.row, test.column, seq, test.seq)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package ovsdb
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Special values of Logical_Switch_Port.addresses, which are not parsed by ParsePortAddress
+const (
+	// AddressUnknown lets the port receive packets to unknown destination MACs
+	AddressUnknown = "unknown"
+	// AddressRouter uses the addresses of the router port the switch port is peered with
+	AddressRouter = "router"
+	// AddressDynamic lets ovn-northd allocate the MAC and IP addresses,
+	// "<MAC> dynamic" only allocates the IP addresses
+	AddressDynamic = "dynamic"
+)
+
+// PortAddress is an address string of Logical_Switch_Port.addresses or port_security columns:
+// an Ethernet address followed by zero or more IPv4 or IPv6 addresses, e.g. "0a:00:00:00:00:01 10.0.0.1 fd00::1".
+// IP addresses in port_security may have a prefix length, e.g. "10.0.0.0/24".
+type PortAddress struct {
+	MAC net.HardwareAddr
+	// IPs have a full length mask if no prefix length is given, e.g. 10.0.0.1 is 10.0.0.1/32
+	IPs []net.IPNet
+}
+
+// IsSpecialAddress returns true if s is one of the special values of Logical_Switch_Port.addresses
+// that don't list actual addresses: "unknown", "router", "dynamic" or "<MAC> dynamic"
+func IsSpecialAddress(s string) bool {
+	fields := strings.Fields(s)
+	switch len(fields) {
+	case 1:
+		return fields[0] == AddressUnknown || fields[0] == AddressRouter || fields[0] == AddressDynamic
+	case 2:
+		return fields[1] == AddressDynamic
+	}
+	return false
+}
+
+// ParsePortAddress parses an address string of Logical_Switch_Port.addresses or port_security.
+// The MAC must be a 48-bit Ethernet address, IP addresses must be unique and their host bits are kept.
+func ParsePortAddress(s string) (*PortAddress, error) {
+	if IsSpecialAddress(s) {
+		return nil, fmt.Errorf("special address %q has no addresses to parse", s)
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty address")
+	}
+	mac, err := net.ParseMAC(fields[0])
+	if err != nil || len(mac) != 6 {
+		return nil, fmt.Errorf("invalid Ethernet address %q in %q", fields[0], s)
+	}
+
+	address := &PortAddress{MAC: mac}
+	seen := make(map[string]bool)
+	for _, field := range fields[1:] {
+		ip, err := parsePortIP(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP address %q in %q", field, s)
+		}
+		if seen[ip.String()] {
+			return nil, fmt.Errorf("duplicate IP address %q in %q", field, s)
+		}
+		seen[ip.String()] = true
+		address.IPs = append(address.IPs, ip)
+	}
+	return address, nil
+}
+
+// parsePortIP parses an IP address with an optional prefix length
+func parsePortIP(s string) (net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		ip, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return net.IPNet{}, err
+		}
+		return net.IPNet{IP: normalizeIP(ip), Mask: ipNet.Mask}, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return net.IPNet{}, fmt.Errorf("invalid IP address %q", s)
+	}
+	ip = normalizeIP(ip)
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}, nil
+}
+
+// normalizeIP returns IPv4 addresses in their 4-byte form
+func normalizeIP(ip net.IP) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip
+}
+
+// String formats a in the column format, IP addresses with a full length mask have no prefix length
+func (a *PortAddress) String() string {
+	fields := []string{a.MAC.String()}
+	for _, ip := range a.IPs {
+		if ones, bits := ip.Mask.Size(); ones == bits {
+			fields = append(fields, ip.IP.String())
+		} else {
+			fields = append(fields, fmt.Sprintf("%s/%d", ip.IP, ones))
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// IPv4 returns the IPv4 addresses of a
+func (a *PortAddress) IPv4() []net.IPNet {
+	var ips []net.IPNet
+	for _, ip := range a.IPs {
+		if ip.IP.To4() != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// IPv6 returns the IPv6 addresses of a
+func (a *PortAddress) IPv6() []net.IPNet {
+	var ips []net.IPNet
+	for _, ip := range a.IPs {
+		if ip.IP.To4() == nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// ParsePortAddresses parses all values of an addresses or port_security column, skipping special values
+func ParsePortAddresses(values []string) ([]*PortAddress, error) {
+	var addresses []*PortAddress
+	for _, value := range values {
+		if IsSpecialAddress(value) {
+			continue
+		}
+		address, err := ParsePortAddress(value)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
@@ -0,0 +1,64 @@
+package ovsdb
+
+import (
+	"testing"
+)
+
+func TestParsePortAddress(t *testing.T) {
+	tests := []struct {
+		s          string
+		shouldFail bool
+		str        string
+		ipv4       int
+		ipv6       int
+	}{
+		{"0a:00:00:00:00:01", false, "0a:00:00:00:00:01", 0, 0},
+		{"0A:00:00:00:00:01  10.0.0.1 fd00::1", false, "0a:00:00:00:00:01 10.0.0.1 fd00::1", 1, 1},
+		{"0a:00:00:00:00:01 10.0.0.5/24 fd00::1/64", false, "0a:00:00:00:00:01 10.0.0.5/24 fd00::1/64", 1, 1},
+		{"0a:00:00:00:00:01 10.0.0.1 10.0.0.2", false, "0a:00:00:00:00:01 10.0.0.1 10.0.0.2", 2, 0},
+		// invalid
+		{"", true, "", 0, 0},
+		{"10.0.0.1", true, "", 0, 0},
+		{"0a:00:00:00:00:00:00:01 10.0.0.1", true, "", 0, 0},
+		{"0a:00:00:00:00:01 10.0.0.256", true, "", 0, 0},
+		{"0a:00:00:00:00:01 10.0.0.1/33", true, "", 0, 0},
+		{"0a:00:00:00:00:01 10.0.0.1 10.0.0.1", true, "", 0, 0},
+		// special values
+		{"unknown", true, "", 0, 0},
+		{"router", true, "", 0, 0},
+		{"0a:00:00:00:00:01 dynamic", true, "", 0, 0},
+	}
+	for _, test := range tests {
+		address, err := ParsePortAddress(test.s)
+		if test.shouldFail {
+			if err == nil {
+				t.Errorf("expect ParsePortAddress(%q) failed, but got %v", test.s, address)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePortAddress(%q) failed: %v", test.s, err)
+			continue
+		}
+		if got := address.String(); got != test.str {
+			t.Errorf("ParsePortAddress(%q).String() = %q, want %q", test.s, got, test.str)
+		}
+		if len(address.IPv4()) != test.ipv4 || len(address.IPv6()) != test.ipv6 {
+			t.Errorf("ParsePortAddress(%q) has %d IPv4 and %d IPv6 addresses, want %d and %d",
+				test.s, len(address.IPv4()), len(address.IPv6()), test.ipv4, test.ipv6)
+		}
+	}
+}
+
+func TestParsePortAddresses(t *testing.T) {
+	addresses, err := ParsePortAddresses([]string{"unknown", "0a:00:00:00:00:01 10.0.0.1", "dynamic"})
+	if err != nil {
+		t.Fatalf("ParsePortAddresses failed: %v", err)
+	}
+	if len(addresses) != 1 || addresses[0].String() != "0a:00:00:00:00:01 10.0.0.1" {
+		t.Errorf("ParsePortAddresses = %v", addresses)
+	}
+	if _, err := ParsePortAddresses([]string{"bogus"}); err == nil {
+		t.Error("expect ParsePortAddresses failed, but got nil")
+	}
+}
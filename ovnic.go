@@ -0,0 +1,246 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// OVN interconnect databases and tables used by the ovn-ic helpers
+const (
+	icNorthbound          = "OVN_IC_Northbound"
+	icSouthbound          = "OVN_IC_Southbound"
+	transitSwitchTable    = "Transit_Switch"
+	availabilityZoneTable = "Availability_Zone"
+	gatewayTable          = "Gateway"
+	routeTable            = "Route"
+)
+
+// ErrTransitSwitchExists is returned by AddTransitSwitch if a transit switch of the same name exists
+var ErrTransitSwitchExists = errors.New("transit switch already exists")
+
+// TransitSwitch is a row of the Transit_Switch table of OVN_IC_Northbound, a logical switch
+// shared by the availability zones of an OVN interconnection
+type TransitSwitch struct {
+	UUID        UUID
+	Name        string
+	OtherConfig map[string]string
+	ExternalIDs map[string]string
+}
+
+// AvailabilityZone is a row of the Availability_Zone table of OVN_IC_Southbound, an OVN deployment
+// registered by its ovn-ic daemon
+type AvailabilityZone struct {
+	UUID UUID
+	Name string
+}
+
+// ICGateway is a row of the Gateway table of OVN_IC_Southbound, a chassis of an availability zone
+// carrying traffic of transit switches
+type ICGateway struct {
+	UUID             UUID
+	Name             string
+	AvailabilityZone UUID
+	Hostname         string
+	Encaps           []UUID
+	ExternalIDs      map[string]string
+}
+
+// ICRoute is a row of the Route table of OVN_IC_Southbound, a route advertised by an availability zone
+// through a transit switch
+type ICRoute struct {
+	UUID             UUID
+	TransitSwitch    string
+	AvailabilityZone UUID
+	RouteTable       string
+	IPPrefix         string
+	Nexthop          string
+	Origin           string
+	ExternalIDs      map[string]string
+}
+
+// TransitSwitches returns all transit switches of OVN_IC_Northbound
+func (c *Client) TransitSwitches() ([]TransitSwitch, error) {
+	rows, err := c.selectRows(icNorthbound, transitSwitchTable, MatchAll())
+	if err != nil {
+		return nil, err
+	}
+	switches := make([]TransitSwitch, len(rows))
+	for i, row := range rows {
+		ts := &switches[i]
+		err := decodeColumns(row, map[ID]interface{}{
+			"_uuid":        &ts.UUID,
+			"name":         &ts.Name,
+			"other_config": &ts.OtherConfig,
+			"external_ids": &ts.ExternalIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s row: %v", transitSwitchTable, err)
+		}
+	}
+	return switches, nil
+}
+
+// AddTransitSwitch creates the transit switch name in OVN_IC_Northbound and returns its UUID,
+// or ErrTransitSwitchExists if there's already one. ovn-ic creates the logical switch in every
+// availability zone. The check and the insert are done in one transaction, so concurrent calls
+// never create two transit switches of the same name.
+func (c *Client) AddTransitSwitch(name string, externalIDs map[string]string) (UUID, error) {
+	ids, err := ConvertToValue(externalIDs)
+	if err != nil {
+		return "", err
+	}
+	if ids == nil {
+		ids = Map{Values: []MapPair{}}
+	}
+	noWait := 0
+	result, err := c.Transact(icNorthbound,
+		// abort at once if a transit switch of the same name exists
+		&WaitOperation{
+			Timeout: &noWait,
+			Table:   transitSwitchTable,
+			Where:   []Condition{{"name", FuncEq, name}},
+			Columns: []ID{"name"},
+			Until:   WaitEqual,
+		},
+		&InsertOperation{
+			Table: transitSwitchTable,
+			Row:   map[string]interface{}{"name": name, "external_ids": ids},
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Errors) != 0 {
+		if ClassifyError(result.Errors) == ErrTimedOut {
+			return "", ErrTransitSwitchExists
+		}
+		return "", result.Errors
+	}
+	inserted, err := result.InsertAt(1)
+	if err != nil {
+		return "", err
+	}
+	return inserted.UUID, nil
+}
+
+// DeleteTransitSwitch deletes the transit switch name from OVN_IC_Northbound, it's not an error if there's none
+func (c *Client) DeleteTransitSwitch(name string) error {
+	result, err := c.Transact(icNorthbound, &DeleteOperation{
+		Table: transitSwitchTable,
+		Where: []Condition{{"name", FuncEq, name}},
+	})
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) != 0 {
+		return result.Errors
+	}
+	return nil
+}
+
+// AvailabilityZones returns all availability zones of OVN_IC_Southbound
+func (c *Client) AvailabilityZones() ([]AvailabilityZone, error) {
+	rows, err := c.selectRows(icSouthbound, availabilityZoneTable, MatchAll())
+	if err != nil {
+		return nil, err
+	}
+	zones := make([]AvailabilityZone, len(rows))
+	for i, row := range rows {
+		zone := &zones[i]
+		if err := decodeColumns(row, map[ID]interface{}{"_uuid": &zone.UUID, "name": &zone.Name}); err != nil {
+			return nil, fmt.Errorf("invalid %s row: %v", availabilityZoneTable, err)
+		}
+	}
+	return zones, nil
+}
+
+// ICGateways returns the gateways of OVN_IC_Southbound in availability zone az, or all gateways if az is empty
+func (c *Client) ICGateways(az UUID) ([]ICGateway, error) {
+	where := MatchAll()
+	if az != "" {
+		where = []Condition{{"availability_zone", FuncEq, az}}
+	}
+	rows, err := c.selectRows(icSouthbound, gatewayTable, where)
+	if err != nil {
+		return nil, err
+	}
+	gateways := make([]ICGateway, len(rows))
+	for i, row := range rows {
+		gw := &gateways[i]
+		err := decodeColumns(row, map[ID]interface{}{
+			"_uuid":             &gw.UUID,
+			"name":              &gw.Name,
+			"availability_zone": &gw.AvailabilityZone,
+			"hostname":          &gw.Hostname,
+			"encaps":            &gw.Encaps,
+			"external_ids":      &gw.ExternalIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s row: %v", gatewayTable, err)
+		}
+	}
+	return gateways, nil
+}
+
+// ICRoutes returns the routes of OVN_IC_Southbound advertised through transit switch transitSwitch,
+// or all routes if transitSwitch is empty
+func (c *Client) ICRoutes(transitSwitch string) ([]ICRoute, error) {
+	where := MatchAll()
+	if transitSwitch != "" {
+		where = []Condition{{"transit_switch", FuncEq, transitSwitch}}
+	}
+	rows, err := c.selectRows(icSouthbound, routeTable, where)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]ICRoute, len(rows))
+	for i, row := range rows {
+		route := &routes[i]
+		err := decodeColumns(row, map[ID]interface{}{
+			"_uuid":             &route.UUID,
+			"transit_switch":    &route.TransitSwitch,
+			"availability_zone": &route.AvailabilityZone,
+			"route_table":       &route.RouteTable,
+			"ip_prefix":         &route.IPPrefix,
+			"nexthop":           &route.Nexthop,
+			"origin":            &route.Origin,
+			"external_ids":      &route.ExternalIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s row: %v", routeTable, err)
+		}
+	}
+	return routes, nil
+}
+
+// selectRows selects the rows of table in db matching where
+func (c *Client) selectRows(db, table ID, where []Condition) ([]json.RawMessage, error) {
+	result, err := c.Transact(db, &SelectOperation{Table: table, Where: where})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) != 0 {
+		return nil, result.Errors
+	}
+	return pageRows(result.Results)
+}
+
+// decodeColumns converts the columns of a selected row into the values columns point to,
+// with ConvertFromValue. Columns missing from the row are left as is.
+func decodeColumns(row json.RawMessage, columns map[ID]interface{}) error {
+	var values map[ID]json.RawMessage
+	if err := json.Unmarshal(row, &values); err != nil {
+		return err
+	}
+	for column, dst := range columns {
+		value, ok := values[column]
+		if !ok {
+			continue
+		}
+		if err := ConvertFromValue(value, dst); err != nil {
+			return fmt.Errorf("column %s: %v", column, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestAddTransitSwitch(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	var ops []string
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		ops = nil
+		for _, op := range params[1:] {
+			ops = append(ops, string(op))
+		}
+		return []interface{}{
+			map[string]interface{}{},
+			map[string]interface{}{"uuid": []string{"uuid", ls1}},
+		}, nil
+	})
+	client := NewClient(conn)
+
+	uuid, err := client.AddTransitSwitch("ts1", map[string]string{"owner": "test"})
+	if err != nil {
+		t.Fatalf("AddTransitSwitch failed: %v", err)
+	}
+	if uuid != ls1 {
+		t.Errorf("AddTransitSwitch returned %s, want %s", uuid, ls1)
+	}
+	want := []string{
+		`{"op":"wait","timeout":0,"table":"Transit_Switch","where":[["name","==","ts1"]],"columns":["name"],"until":"==","rows":[]}`,
+		`{"op":"insert","table":"Transit_Switch","row":{"external_ids":["map",[["owner","test"]]],"name":"ts1"}}`,
+	}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("AddTransitSwitch sent\n%v\nwant\n%v", ops, want)
+	}
+
+	// ovsdb-server reports a failed wait without details
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		return json.RawMessage(`[{"error":"timed out"}]`), nil
+	})
+	if _, err := client.AddTransitSwitch("ts1", nil); err != ErrTransitSwitchExists {
+		t.Errorf("AddTransitSwitch of an existing transit switch returned %v, want %v", err, ErrTransitSwitchExists)
+	}
+}
+
+func TestICRoutes(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	var where string
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		var op struct {
+			Where json.RawMessage `json:"where"`
+		}
+		json.Unmarshal(params[1], &op)
+		where = string(op.Where)
+		return []interface{}{map[string]interface{}{"rows": []json.RawMessage{json.RawMessage(`{
+			"_uuid": ["uuid", "` + lsp1 + `"],
+			"transit_switch": "ts1",
+			"availability_zone": ["uuid", "` + ls1 + `"],
+			"route_table": "",
+			"ip_prefix": "10.0.1.0/24",
+			"nexthop": "169.254.100.1",
+			"origin": "connected",
+			"external_ids": ["map", [["ic-learned-route", "` + lsp2 + `"]]]
+		}`)}}}, nil
+	})
+	client := NewClient(conn)
+
+	routes, err := client.ICRoutes("ts1")
+	if err != nil {
+		t.Fatalf("ICRoutes failed: %v", err)
+	}
+	if want := `[["transit_switch","==","ts1"]]`; where != want {
+		t.Errorf("ICRoutes selected %s, want %s", where, want)
+	}
+	want := []ICRoute{{
+		UUID:             lsp1,
+		TransitSwitch:    "ts1",
+		AvailabilityZone: ls1,
+		IPPrefix:         "10.0.1.0/24",
+		Nexthop:          "169.254.100.1",
+		Origin:           "connected",
+		ExternalIDs:      map[string]string{"ic-learned-route": lsp2},
+	}}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("ICRoutes returned %+v, want %+v", routes, want)
+	}
+}
@@ -0,0 +1,145 @@
+// Package ovsdbtest provides utilities for testing code built on go-ovsdb
+package ovsdbtest
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// FaultConn wraps the connection to a OVSDB server and injects faults on demand,
+// so reconnect and consistency logic can be tested against realistic failure modes.
+// Pass it to ovsdb.NewClient to create a client over it.
+// Faults are applied on JSON-RPC message (frame) boundaries of the incoming stream.
+type FaultConn struct {
+	net.Conn
+
+	reader *io.PipeReader
+	writer *io.PipeWriter
+
+	mu                sync.Mutex
+	latency           time.Duration
+	dropNotifications map[string]int
+	corruptFrames     int
+}
+
+// NewFaultConn wraps conn into a FaultConn, no fault is injected until configured
+func NewFaultConn(conn net.Conn) *FaultConn {
+	reader, writer := io.Pipe()
+	fc := &FaultConn{
+		Conn:              conn,
+		reader:            reader,
+		writer:            writer,
+		dropNotifications: make(map[string]int),
+	}
+	go fc.pump()
+	return fc
+}
+
+// SetLatency delays every incoming and outgoing message by latency
+func (fc *FaultConn) SetLatency(latency time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.latency = latency
+}
+
+// DropNotifications silently drops the next n incoming notifications of method, e.g. "update".
+// An empty method matches notifications of all methods.
+func (fc *FaultConn) DropNotifications(method string, n int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.dropNotifications[method] += n
+}
+
+// CorruptFrames replaces the next n incoming messages with malformed JSON
+func (fc *FaultConn) CorruptFrames(n int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.corruptFrames += n
+}
+
+// Disconnect forcibly closes the underlying connection, as if the server or network failed
+func (fc *FaultConn) Disconnect() error {
+	return fc.Conn.Close()
+}
+
+// Read implements net.Conn interface, it reads incoming messages after faults are applied
+func (fc *FaultConn) Read(b []byte) (int, error) {
+	return fc.reader.Read(b)
+}
+
+// Write implements net.Conn interface
+func (fc *FaultConn) Write(b []byte) (int, error) {
+	fc.delay()
+	return fc.Conn.Write(b)
+}
+
+// Close implements net.Conn interface
+func (fc *FaultConn) Close() error {
+	fc.reader.Close()
+	return fc.Conn.Close()
+}
+
+// pump reads messages from the underlying connection, applies faults and forwards them to Read
+func (fc *FaultConn) pump() {
+	decoder := json.NewDecoder(fc.Conn)
+	for {
+		var frame json.RawMessage
+		if err := decoder.Decode(&frame); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			fc.writer.CloseWithError(err)
+			return
+		}
+
+		fc.delay()
+		frame, ok := fc.inject(frame)
+		if !ok {
+			continue
+		}
+		if _, err := fc.writer.Write(append(frame, '\n')); err != nil {
+			fc.Conn.Close()
+			return
+		}
+	}
+}
+
+// inject applies the pending faults to frame, it returns false if frame is dropped
+func (fc *FaultConn) inject(frame json.RawMessage) (json.RawMessage, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if fc.corruptFrames > 0 {
+		fc.corruptFrames--
+		return json.RawMessage(`{"corrupted":`), true
+	}
+
+	var message struct {
+		Method string      `json:"method"`
+		ID     interface{} `json:"id"`
+	}
+	if err := json.Unmarshal(frame, &message); err != nil || message.Method == "" || message.ID != nil {
+		// not a notification
+		return frame, true
+	}
+	for _, method := range []string{message.Method, ""} {
+		if fc.dropNotifications[method] > 0 {
+			fc.dropNotifications[method]--
+			return nil, false
+		}
+	}
+	return frame, true
+}
+
+// delay sleeps for the configured latency
+func (fc *FaultConn) delay() {
+	fc.mu.Lock()
+	latency := fc.latency
+	fc.mu.Unlock()
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+}
@@ -0,0 +1,59 @@
+package ovsdbtest
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFaultConn(t *testing.T) {
+	client, server := net.Pipe()
+	fc := NewFaultConn(client)
+	defer fc.Close()
+
+	fc.DropNotifications("update", 1)
+	fc.CorruptFrames(1)
+	go func() {
+		server.Write([]byte(`{"id":1,"result":[],"error":null}`))
+		server.Write([]byte(`{"id":null,"method":"update","params":[null,{}]}`))
+		server.Write([]byte(`{"id":null,"method":"update","params":["second",{}]}`))
+		server.Write([]byte(`{"id":"echo","method":"echo","params":[]}`))
+	}()
+
+	reader := bufio.NewReader(fc)
+	want := []string{
+		`{"corrupted":`,
+		`{"id":null,"method":"update","params":["second",{}]}`,
+		`{"id":"echo","method":"echo","params":[]}`,
+	}
+	for _, frame := range want {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if line != frame+"\n" {
+			t.Errorf("read %q, want %q", line, frame)
+		}
+	}
+
+	// outgoing messages are delayed
+	fc.SetLatency(20 * time.Millisecond)
+	go func() {
+		buf := make([]byte, 64)
+		server.Read(buf)
+	}()
+	start := time.Now()
+	if _, err := fc.Write([]byte(`{}`)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("write took %v, want at least 20ms", elapsed)
+	}
+
+	// reads fail after disconnect
+	fc.Disconnect()
+	if _, err := reader.ReadString('\n'); err == nil {
+		t.Error("expect read failed after Disconnect, but got nil")
+	}
+}
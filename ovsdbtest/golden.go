@@ -0,0 +1,173 @@
+package ovsdbtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable which, set to a non-empty value,
+// makes AssertGolden write golden files instead of comparing with them
+const UpdateGoldenEnv = "OVSDBTEST_UPDATE_GOLDEN"
+
+// MarshalGolden encodes v, e.g. the []ovsdb.Operation of a transaction or an *ovsdb.TransactResult,
+// into the JSON of golden files: indented, with object members sorted and the elements of
+// OVSDB sets and maps sorted, so the encoding doesn't depend on Go map iteration order
+func MarshalGolden(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	normalized, err := json.MarshalIndent(sortCollections(value), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(normalized, '\n'), nil
+}
+
+// AssertGolden compares v encoded by MarshalGolden with the golden file at path, and fails t with
+// a line diff if they differ. If UpdateGoldenEnv is set, the golden file is (re)written instead,
+// e.g. OVSDBTEST_UPDATE_GOLDEN=1 go test ./...
+func AssertGolden(t testing.TB, path string, v interface{}) {
+	t.Helper()
+	got, err := MarshalGolden(v)
+	if err != nil {
+		t.Fatalf("failed to encode %T: %v", v, err)
+	}
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory of golden file: %v", err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (set %s to create it): %v", UpdateGoldenEnv, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%T differs from golden file %s (- golden, + got):\n%s", v, path, diffLines(string(want), string(got)))
+	}
+}
+
+// sortCollections sorts the elements of ["set", [...]] and ["map", [...]] values in a decoded JSON value
+func sortCollections(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, member := range value {
+			value[key] = sortCollections(member)
+		}
+		return value
+	case []interface{}:
+		for i, element := range value {
+			value[i] = sortCollections(element)
+		}
+		if len(value) == 2 && (value[0] == "set" || value[0] == "map") {
+			if elements, ok := value[1].([]interface{}); ok {
+				sortByEncoding(elements)
+			}
+		}
+		return value
+	}
+	return value
+}
+
+// sortByEncoding sorts values by their JSON encoding
+func sortByEncoding(values []interface{}) {
+	keys := make([]string, len(values))
+	for i, value := range values {
+		key, _ := json.Marshal(value)
+		keys[i] = string(key)
+	}
+	sort.Sort(byKey{keys, values})
+}
+
+// byKey sorts values by keys
+type byKey struct {
+	keys   []string
+	values []interface{}
+}
+
+func (b byKey) Len() int           { return len(b.keys) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+}
+
+// diffContext is the number of unchanged lines shown around changes by diffLines
+const diffContext = 2
+
+// diffLines returns a line diff of a and b, removed lines are prefixed with "-", added ones with "+"
+// and unchanged ones around them with " "
+func diffLines(a, b string) string {
+	x, y := strings.Split(a, "\n"), strings.Split(b, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, " "+x[i])
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+x[i])
+			i++
+		default:
+			lines = append(lines, "+"+y[j])
+			j++
+		}
+	}
+
+	// show only changes and their context
+	show := make([]bool, len(lines))
+	for n, line := range lines {
+		if line[0] == ' ' {
+			continue
+		}
+		for k := n - diffContext; k <= n+diffContext; k++ {
+			if k >= 0 && k < len(lines) {
+				show[k] = true
+			}
+		}
+	}
+	var diff bytes.Buffer
+	for n, line := range lines {
+		if show[n] {
+			diff.WriteString(line + "\n")
+		} else if n == 0 || show[n-1] {
+			diff.WriteString("...\n")
+		}
+	}
+	return diff.String()
+}
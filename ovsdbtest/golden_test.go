@@ -0,0 +1,68 @@
+package ovsdbtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/liwei/go-ovsdb"
+)
+
+// recordingTB records the failures of a test instead of failing it
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func (tb *recordingTB) Fatalf(format string, args ...interface{}) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGolden(t *testing.T) {
+	externalIDs, _ := ovsdb.ConvertToValue(map[string]string{"owner": "test", "app": "golden", "zone": "a"})
+	ops := []ovsdb.Operation{
+		&ovsdb.InsertOperation{Table: "Bridge", UUIDName: "br0", Row: map[ovsdb.ID]interface{}{
+			"name":         "br0",
+			"external_ids": externalIDs,
+		}},
+		&ovsdb.MutateOperation{
+			Table:     "Open_vSwitch",
+			Where:     ovsdb.MatchAll(),
+			Mutations: []ovsdb.Mutation{{Column: "bridges", Mutator: ovsdb.MutatorInsert, Value: ovsdb.NamedUUID("br0")}},
+		},
+	}
+	// the pairs of external_ids are in random order, but always match
+	for i := 0; i < 5; i++ {
+		AssertGolden(t, "testdata/insert_bridge.json", ops)
+	}
+
+	tb := &recordingTB{TB: t}
+	ops[0].(*ovsdb.InsertOperation).Row.(map[ovsdb.ID]interface{})["name"] = "br1"
+	AssertGolden(tb, "testdata/insert_bridge.json", ops)
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], `-      "name": "br0"`) ||
+		!strings.Contains(tb.failures[0], `+      "name": "br1"`) {
+		t.Errorf("unexpected failures of a changed transaction: %q", tb.failures)
+	}
+
+	tb = &recordingTB{TB: t}
+	AssertGolden(tb, "testdata/missing.json", ops)
+	// Fatalf doesn't stop AssertGolden here, the first failure is the one that counts
+	if len(tb.failures) == 0 || !strings.Contains(tb.failures[0], UpdateGoldenEnv) {
+		t.Errorf("unexpected failures of a missing golden file: %q", tb.failures)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+	b := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n12\n13"
+	want := "...\n 3\n 4\n-5\n+five\n 6\n 7\n...\n 11\n 12\n+13\n"
+	if got := diffLines(a, b); got != want {
+		t.Errorf("diffLines returned\n%s\nwant\n%s", got, want)
+	}
+}
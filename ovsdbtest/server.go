@@ -0,0 +1,234 @@
+package ovsdbtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// HandlerFunc answers a request of the fake server, params are the elements of the request's "params" array.
+// A non-nil error is sent as the "error" of the response.
+type HandlerFunc func(params []json.RawMessage) (result interface{}, err error)
+
+// Server is a scripted fake OVSDB server serving a single JSON-RPC connection.
+// It doesn't implement a database: requests are answered by the handlers registered with Handle,
+// and tests program failures to validate error handling paths of the client and the code built on it.
+// By default "echo" is answered, "list_dbs" returns no database and "transact" succeeds with an empty
+// result for each operation.
+type Server struct {
+	conn    net.Conn
+	encoder *json.Encoder
+	// writeLock serializes messages written to conn
+	writeLock sync.Mutex
+
+	mu           sync.Mutex
+	handlers     map[string]HandlerFunc
+	delay        time.Duration
+	opErrors     []opError
+	disconnectOn map[string]bool
+}
+
+// opError is an operation error injected into the next transact response
+type opError struct {
+	index   int
+	err     string
+	details string
+}
+
+// request is a JSON-RPC request or notification
+type request struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	ID     interface{}       `json:"id"`
+}
+
+// NewServer creates a Server serving conn, e.g. one end of a net.Pipe whose other end is passed to ovsdb.NewClient
+func NewServer(conn net.Conn) *Server {
+	s := &Server{
+		conn:         conn,
+		encoder:      json.NewEncoder(conn),
+		handlers:     make(map[string]HandlerFunc),
+		disconnectOn: make(map[string]bool),
+	}
+	s.Handle("echo", func(params []json.RawMessage) (interface{}, error) {
+		return params, nil
+	})
+	s.Handle("list_dbs", func(params []json.RawMessage) (interface{}, error) {
+		return []string{}, nil
+	})
+	s.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		results := make([]interface{}, 0, len(params))
+		for range params[1:] {
+			results = append(results, struct{}{})
+		}
+		return results, nil
+	})
+	go s.serve()
+	return s
+}
+
+// Handle registers handler for requests of method, replacing any previous one
+func (s *Server) Handle(method string, handler HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = handler
+}
+
+// SetDelay delays every response by delay
+func (s *Server) SetDelay(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = delay
+}
+
+// FailOperation makes the operation at index of the next transaction fail with the OVSDB error err,
+// e.g. "constraint violation", following operations are reported as not attempted
+func (s *Server) FailOperation(index int, err, details string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opErrors = append(s.opErrors, opError{index, err, details})
+}
+
+// DisconnectOn makes the server close the connection when it receives the next request of method,
+// without responding, e.g. to drop the connection in the middle of a transaction
+func (s *Server) DisconnectOn(method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disconnectOn[method] = true
+}
+
+// Notify sends a notification of method with params to the client, e.g. an "update" of a monitor
+func (s *Server) Notify(method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	return s.write(struct {
+		Method string        `json:"method"`
+		Params []interface{} `json:"params"`
+		ID     interface{}   `json:"id"`
+	}{method, params, nil})
+}
+
+// SendRaw sends frame to the client as is, e.g. a malformed notification
+func (s *Server) SendRaw(frame []byte) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	_, err := s.conn.Write(frame)
+	return err
+}
+
+// Close closes the connection
+func (s *Server) Close() error {
+	return s.conn.Close()
+}
+
+// serve reads requests and answers them until the connection is closed
+func (s *Server) serve() {
+	decoder := json.NewDecoder(s.conn)
+	for {
+		var req request
+		if err := decoder.Decode(&req); err != nil {
+			if err != io.EOF {
+				s.conn.Close()
+			}
+			return
+		}
+		if req.Method == "" {
+			// a response to a request of the server, e.g. echo
+			continue
+		}
+
+		s.mu.Lock()
+		handler := s.handlers[req.Method]
+		delay := s.delay
+		disconnect := s.disconnectOn[req.Method]
+		delete(s.disconnectOn, req.Method)
+		s.mu.Unlock()
+
+		if disconnect {
+			s.conn.Close()
+			return
+		}
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+		if req.ID == nil {
+			// notifications are not answered
+			continue
+		}
+
+		var result interface{}
+		var err error
+		if handler == nil {
+			err = fmt.Errorf("unknown method %q", req.Method)
+		} else {
+			result, err = handler(req.Params)
+		}
+		if err == nil && req.Method == "transact" {
+			result, err = s.injectOpErrors(result)
+		}
+		if err := s.respond(req.ID, result, err); err != nil {
+			return
+		}
+	}
+}
+
+// injectOpErrors applies the pending operation errors to the result of a transaction
+func (s *Server) injectOpErrors(result interface{}) (interface{}, error) {
+	s.mu.Lock()
+	opErrors := s.opErrors
+	s.opErrors = nil
+	s.mu.Unlock()
+	if len(opErrors) == 0 {
+		return result, nil
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var results []interface{}
+	if err := json.Unmarshal(raw, &results); err != nil {
+		return nil, errors.New("transact handler didn't return an array")
+	}
+	for _, opErr := range opErrors {
+		if opErr.index < 0 || opErr.index >= len(results) {
+			continue
+		}
+		// like ovsdb-server, empty details are left out
+		failed := map[string]string{"error": opErr.err}
+		if opErr.details != "" {
+			failed["details"] = opErr.details
+		}
+		results[opErr.index] = failed
+		for i := opErr.index + 1; i < len(results); i++ {
+			results[i] = nil
+		}
+	}
+	return results, nil
+}
+
+// respond sends the response of request id
+func (s *Server) respond(id, result interface{}, err error) error {
+	var response = struct {
+		ID     interface{} `json:"id"`
+		Result interface{} `json:"result"`
+		Error  interface{} `json:"error"`
+	}{ID: id, Result: result}
+	if err != nil {
+		response.Result = nil
+		response.Error = err.Error()
+	}
+	return s.write(response)
+}
+
+// write sends a message to the client
+func (s *Server) write(message interface{}) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	return s.encoder.Encode(message)
+}
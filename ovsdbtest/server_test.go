@@ -0,0 +1,104 @@
+package ovsdbtest
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb"
+)
+
+func newTestClient() (*ovsdb.Client, *Server) {
+	client, server := net.Pipe()
+	return ovsdb.NewClient(client), NewServer(server)
+}
+
+func TestServerFailOperation(t *testing.T) {
+	client, server := newTestClient()
+	defer server.Close()
+
+	server.FailOperation(0, "constraint violation", "duplicate name")
+	insert := &ovsdb.InsertOperation{Table: "Bridge", Row: map[ovsdb.ID]ovsdb.Value{"name": "br0"}}
+	result, err := client.Transact("Open_vSwitch", insert, insert)
+	if err != nil {
+		t.Fatalf("Transact failed: %v", err)
+	}
+	if len(result.Errors) != 1 || ovsdb.ClassifyError(result.Errors) != ovsdb.ErrConstraintViolation {
+		t.Errorf("Transact errors = %v, want a constraint violation", result.Errors)
+	}
+	if len(result.Results) != 2 || result.Results[1] != nil {
+		t.Errorf("Transact results = %v, want the second operation not attempted", result.Results)
+	}
+
+	// errors are injected only once
+	result, err = client.Transact("Open_vSwitch", insert)
+	if err != nil || len(result.Errors) != 0 {
+		t.Errorf("Transact = %v, %v, want success", result, err)
+	}
+}
+
+func TestServerFailOperationWithoutDetails(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := NewServer(serverConn)
+	defer server.Close()
+
+	// like ovsdb-server, a failed wait is reported without details
+	server.FailOperation(0, "timed out", "")
+	go json.NewEncoder(conn).Encode(map[string]interface{}{
+		"method": "transact",
+		"params": []interface{}{"Open_vSwitch", map[string]interface{}{"op": "wait"}},
+		"id":     1,
+	})
+	var response struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		t.Fatalf("failed to read the response: %v", err)
+	}
+	if want := `[{"error":"timed out"}]`; string(response.Result) != want {
+		t.Errorf("transact result = %s, want %s", response.Result, want)
+	}
+}
+
+func TestServerHandle(t *testing.T) {
+	client, server := newTestClient()
+	defer server.Close()
+
+	server.Handle("list_dbs", func(params []json.RawMessage) (interface{}, error) {
+		return []string{"Open_vSwitch"}, nil
+	})
+	dbs, err := client.ListDbs()
+	if err != nil || len(dbs) != 1 || dbs[0] != "Open_vSwitch" {
+		t.Errorf("ListDbs = %v, %v", dbs, err)
+	}
+
+	server.Handle("list_dbs", func(params []json.RawMessage) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	if _, err := client.ListDbs(); err == nil {
+		t.Error("expect ListDbs failed, but got nil")
+	}
+}
+
+func TestServerDelayAndDisconnect(t *testing.T) {
+	client, server := newTestClient()
+	defer server.Close()
+
+	server.SetDelay(20 * time.Millisecond)
+	start := time.Now()
+	if _, err := client.ListDbs(); err != nil {
+		t.Fatalf("ListDbs failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("ListDbs took %v, want at least 20ms", elapsed)
+	}
+
+	server.SetDelay(0)
+	server.DisconnectOn("transact")
+	insert := &ovsdb.InsertOperation{Table: "Bridge", Row: map[ovsdb.ID]ovsdb.Value{"name": "br0"}}
+	if _, err := client.Transact("Open_vSwitch", insert); err == nil {
+		t.Error("expect Transact failed after disconnect, but got nil")
+	}
+}
@@ -0,0 +1,156 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// DefaultPageSize is the page size of SelectPages if PageOptions.Size is not set
+const DefaultPageSize = 1000
+
+// errStopPaging stops SelectPages without an error
+var errStopPaging = errors.New("stop paging")
+
+// StopPaging is returned by a PageFunc to stop SelectPages without an error
+func StopPaging() error {
+	return errStopPaging
+}
+
+// PageOptions controls the rows and the pages of SelectPages
+type PageOptions struct {
+	// Where are the conditions rows must match, all rows are selected if it's empty
+	Where []Condition
+	// Columns are the selected columns, all columns are selected if it's empty
+	Columns []ID
+	// Size is the maximum number of rows in a page, default to DefaultPageSize
+	Size int
+	// After resumes paging with the rows whose UUID sorts after it, e.g. the last UUID seen
+	// by a previous SelectPages
+	After UUID
+}
+
+// PageFunc is called by SelectPages with each page of rows, last is the UUID of the last row in the page.
+// Returning a non-nil error stops paging, StopPaging() stops it without making SelectPages fail.
+type PageFunc func(rows []json.RawMessage, last UUID) error
+
+// SelectPages selects rows of table in database db page by page, so very large tables can be read
+// without holding all rows in memory.
+// The OVSDB protocol can't compare UUIDs nor limit the number of selected rows, so paging is emulated:
+// the UUIDs of matching rows are selected first and sorted on the client side, then each page is
+// selected in its own transaction with one select per UUID.
+// Rows deleted or changed to not match Where between pages are skipped, rows inserted meanwhile are
+// not returned, so pages are not a consistent snapshot of the table.
+func (c *Client) SelectPages(db, table ID, options PageOptions, fn PageFunc) error {
+	where := options.Where
+	if len(where) == 0 {
+		where = MatchAll()
+	}
+	size := options.Size
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+
+	result, err := c.Transact(db, &SelectOperation{Table: table, Where: where, Columns: []ID{"_uuid"}})
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) != 0 {
+		return result.Errors
+	}
+	uuids, err := selectedUUIDs(result.Results[0])
+	if err != nil {
+		return err
+	}
+	uuids = uuidsAfter(uuids, options.After)
+
+	for len(uuids) > 0 {
+		n := size
+		if n > len(uuids) {
+			n = len(uuids)
+		}
+		page := uuids[:n]
+		uuids = uuids[n:]
+
+		result, err := c.Transact(db, pageOperations(table, page, where, options.Columns)...)
+		if err != nil {
+			return err
+		}
+		if len(result.Errors) != 0 {
+			return result.Errors
+		}
+		rows, err := pageRows(result.Results)
+		if err != nil {
+			return err
+		}
+		if err := fn(rows, page[n-1]); err != nil {
+			if err == errStopPaging {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// selectedUUIDs decodes the UUIDs of the rows in a select result and sorts them
+func selectedUUIDs(result interface{}) ([]UUID, error) {
+	raw, ok := result.(json.RawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected select result: %v", result)
+	}
+	var selected struct {
+		Rows []struct {
+			UUID UUID `json:"_uuid"`
+		} `json:"rows"`
+	}
+	if err := json.Unmarshal(raw, &selected); err != nil {
+		return nil, err
+	}
+	uuids := make([]UUID, 0, len(selected.Rows))
+	for _, row := range selected.Rows {
+		uuids = append(uuids, row.UUID)
+	}
+	sort.Slice(uuids, func(i, j int) bool { return uuids[i] < uuids[j] })
+	return uuids, nil
+}
+
+// uuidsAfter returns the UUIDs in sorted uuids greater than after
+func uuidsAfter(uuids []UUID, after UUID) []UUID {
+	if after == "" {
+		return uuids
+	}
+	i := sort.Search(len(uuids), func(i int) bool { return uuids[i] > after })
+	return uuids[i:]
+}
+
+// pageOperations returns one select operation for each row of a page
+func pageOperations(table ID, page []UUID, where []Condition, columns []ID) []Operation {
+	ops := make([]Operation, 0, len(page))
+	for _, uuid := range page {
+		conditions := append([]Condition{{"_uuid", FuncEq, uuid}}, where...)
+		ops = append(ops, &SelectOperation{Table: table, Where: conditions, Columns: columns})
+	}
+	return ops
+}
+
+// pageRows collects the rows selected by the operations of a page,
+// the empty result of a comment appended by the client has no rows
+func pageRows(results []interface{}) ([]json.RawMessage, error) {
+	var rows []json.RawMessage
+	for _, result := range results {
+		raw, ok := result.(json.RawMessage)
+		if !ok {
+			return nil, fmt.Errorf("unexpected select result: %v", result)
+		}
+		var selected struct {
+			Rows []json.RawMessage `json:"rows"`
+		}
+		if err := json.Unmarshal(raw, &selected); err != nil {
+			return nil, err
+		}
+		rows = append(rows, selected.Rows...)
+	}
+	return rows, nil
+}
@@ -0,0 +1,68 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSelectedUUIDs(t *testing.T) {
+	result := json.RawMessage(`{"rows":[` +
+		`{"_uuid":["uuid","c0000000-0000-0000-0000-000000000000"]},` +
+		`{"_uuid":["uuid","a0000000-0000-0000-0000-000000000000"]},` +
+		`{"_uuid":["uuid","b0000000-0000-0000-0000-000000000000"]}]}`)
+	uuids, err := selectedUUIDs(result)
+	if err != nil {
+		t.Fatalf("selectedUUIDs failed: %v", err)
+	}
+	want := []UUID{
+		"a0000000-0000-0000-0000-000000000000",
+		"b0000000-0000-0000-0000-000000000000",
+		"c0000000-0000-0000-0000-000000000000",
+	}
+	if !reflect.DeepEqual(uuids, want) {
+		t.Errorf("selectedUUIDs = %v, want %v", uuids, want)
+	}
+
+	afterTests := []struct {
+		after UUID
+		want  []UUID
+	}{
+		{"", want},
+		{"a0000000-0000-0000-0000-000000000000", want[1:]},
+		{"a5000000-0000-0000-0000-000000000000", want[1:]},
+		{"c0000000-0000-0000-0000-000000000000", want[3:]},
+	}
+	for _, test := range afterTests {
+		if got := uuidsAfter(uuids, test.after); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("uuidsAfter(%q) = %v, want %v", test.after, got, test.want)
+		}
+	}
+}
+
+func TestPageOperations(t *testing.T) {
+	page := []UUID{"a0000000-0000-0000-0000-000000000000", "b0000000-0000-0000-0000-000000000000"}
+	where := []Condition{{"name", FuncNe, ""}}
+	ops := pageOperations("Bridge", page, where, []ID{"name"})
+	bytes, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	want := `[{"op":"select","table":"Bridge","where":[["_uuid","==",["uuid","a0000000-0000-0000-0000-000000000000"]],["name","!=",""]],"columns":["name"]},` +
+		`{"op":"select","table":"Bridge","where":[["_uuid","==",["uuid","b0000000-0000-0000-0000-000000000000"]],["name","!=",""]],"columns":["name"]}]`
+	if string(bytes) != want {
+		t.Errorf("operations = %s, want %s", bytes, want)
+	}
+
+	rows, err := pageRows([]interface{}{
+		json.RawMessage(`{"rows":[{"name":"br0"}]}`),
+		json.RawMessage(`{"rows":[]}`),
+		json.RawMessage(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("pageRows failed: %v", err)
+	}
+	if len(rows) != 1 || string(rows[0]) != `{"name":"br0"}` {
+		t.Errorf("pageRows = %s", rows)
+	}
+}
@@ -0,0 +1,151 @@
+package ovsdb
+
+import (
+	"fmt"
+	"log"
+)
+
+// SchemaMismatchPolicy defines how the client behaves when the schema reported by the server
+// differs from the expected schema registered with ExpectSchema
+type SchemaMismatchPolicy int
+
+// Supported SchemaMismatchPolicies
+const (
+	// SchemaMismatchWarn logs the mismatch and returns the server schema as is
+	SchemaMismatchWarn SchemaMismatchPolicy = iota
+	// SchemaMismatchFail makes GetSchema fail with a *SchemaMismatchError
+	SchemaMismatchFail
+	// SchemaMismatchDegrade logs the mismatch and removes tables and columns unknown to
+	// the expected schema from the server schema, so callers only see what they know about
+	SchemaMismatchDegrade
+)
+
+// ExpectedSchema describes the schema a client is built against, e.g. the schema generated models come from
+type ExpectedSchema struct {
+	// Version is the expected schema version, empty means any version
+	Version Version
+	// Checksum is the expected schema checksum, empty means any checksum
+	Checksum string
+	// Tables optionally maps known table names to their known columns,
+	// it's used by SchemaMismatchDegrade to ignore unknown tables and columns
+	Tables map[ID][]ID
+}
+
+// SchemaMismatchError is returned by GetSchema when the server schema differs from
+// the expected one and the SchemaMismatchFail policy is in effect
+type SchemaMismatchError struct {
+	Database ID
+	Expected ExpectedSchema
+	Version  Version
+	Checksum string
+}
+
+// Error implements error interface
+func (e *SchemaMismatchError) Error() string {
+	return fmt.Sprintf("schema mismatch for database %s: expected version %q checksum %q, got version %q checksum %q",
+		e.Database, e.Expected.Version, e.Expected.Checksum, e.Version, e.Checksum)
+}
+
+// ExpectSchema registers the expected schema of database db.
+// Schemas returned by GetSchema are checked against it according to the SchemaMismatchPolicy.
+func (c *Client) ExpectSchema(db ID, expected ExpectedSchema) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.expectedSchemas == nil {
+		c.expectedSchemas = make(map[ID]ExpectedSchema)
+	}
+	c.expectedSchemas[db] = expected
+}
+
+// SetSchemaMismatchPolicy set policy as the behavior on schema mismatch, default to SchemaMismatchWarn
+func (c *Client) SetSchemaMismatchPolicy(policy SchemaMismatchPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mismatchPolicy = policy
+}
+
+// checkSchema checks dbSchema against the registered expected schema of db
+func (c *Client) checkSchema(db ID, dbSchema *DatabaseSchema) (*DatabaseSchema, error) {
+	c.mu.Lock()
+	expected, ok := c.expectedSchemas[db]
+	policy := c.mismatchPolicy
+	c.mu.Unlock()
+	if !ok {
+		return dbSchema, nil
+	}
+	return applySchemaMismatchPolicy(db, expected, policy, dbSchema)
+}
+
+// applySchemaMismatchPolicy compares dbSchema with expected and handles any difference according to policy
+func applySchemaMismatchPolicy(db ID, expected ExpectedSchema, policy SchemaMismatchPolicy, dbSchema *DatabaseSchema) (*DatabaseSchema, error) {
+	if expected.matches(dbSchema) {
+		return dbSchema, nil
+	}
+
+	mismatch := &SchemaMismatchError{
+		Database: db,
+		Expected: expected,
+		Version:  dbSchema.Version,
+		Checksum: dbSchema.Checksum,
+	}
+	switch policy {
+	case SchemaMismatchFail:
+		return nil, mismatch
+	case SchemaMismatchDegrade:
+		log.Printf("ovsdb: %v, ignoring unknown tables and columns", mismatch)
+		return expected.prune(dbSchema), nil
+	}
+	log.Printf("ovsdb: %v", mismatch)
+	return dbSchema, nil
+}
+
+// matches returns true if dbSchema has the expected version and checksum
+func (expected ExpectedSchema) matches(dbSchema *DatabaseSchema) bool {
+	if expected.Version != "" && expected.Version != dbSchema.Version {
+		return false
+	}
+	if expected.Checksum != "" && expected.Checksum != dbSchema.Checksum {
+		return false
+	}
+	return true
+}
+
+// prune returns a copy of dbSchema without the tables and columns unknown to expected.
+// dbSchema is returned as is if expected doesn't specify Tables.
+func (expected ExpectedSchema) prune(dbSchema *DatabaseSchema) *DatabaseSchema {
+	if expected.Tables == nil {
+		return dbSchema
+	}
+
+	pruned := *dbSchema
+	pruned.Tables = make(map[ID]*TableSchema)
+	for table, columns := range expected.Tables {
+		tableSchema, ok := dbSchema.Tables[table]
+		if !ok {
+			continue
+		}
+		prunedTable := *tableSchema
+		prunedTable.Columns = make(map[ID]*ColumnSchema)
+		for _, column := range columns {
+			if columnSchema, ok := tableSchema.Columns[column]; ok {
+				prunedTable.Columns[column] = columnSchema
+			}
+		}
+		// drop indexes over unknown columns
+		prunedTable.Indexes = nil
+		for _, index := range tableSchema.Indexes {
+			known := true
+			for _, column := range index {
+				if _, ok := prunedTable.Columns[ID(column)]; !ok {
+					known = false
+					break
+				}
+			}
+			if known {
+				prunedTable.Indexes = append(prunedTable.Indexes, index)
+			}
+		}
+		pruned.Tables[table] = &prunedTable
+	}
+	return &pruned
+}
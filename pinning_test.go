@@ -0,0 +1,83 @@
+package ovsdb
+
+import (
+	"testing"
+)
+
+func testDatabaseSchema() *DatabaseSchema {
+	return &DatabaseSchema{
+		Name:     "TestDB",
+		Version:  "1.2.0",
+		Checksum: "12345",
+		Tables: map[ID]*TableSchema{
+			"Known": &TableSchema{
+				Columns: map[ID]*ColumnSchema{
+					"name":  &ColumnSchema{},
+					"extra": &ColumnSchema{},
+				},
+				Indexes: []ColumnSet{{"name"}, {"extra"}},
+			},
+			"Unknown": &TableSchema{
+				Columns: map[ID]*ColumnSchema{"name": &ColumnSchema{}},
+			},
+		},
+	}
+}
+
+func TestApplySchemaMismatchPolicy(t *testing.T) {
+	tests := []struct {
+		expected ExpectedSchema
+		policy   SchemaMismatchPolicy
+		fail     bool
+		tables   int
+	}{
+		// matched schema
+		{ExpectedSchema{Version: "1.2.0", Checksum: "12345"}, SchemaMismatchFail, false, 2},
+		{ExpectedSchema{Version: "1.2.0"}, SchemaMismatchFail, false, 2},
+		{ExpectedSchema{}, SchemaMismatchFail, false, 2},
+		// mismatched schema
+		{ExpectedSchema{Version: "1.1.0"}, SchemaMismatchFail, true, 0},
+		{ExpectedSchema{Checksum: "54321"}, SchemaMismatchFail, true, 0},
+		{ExpectedSchema{Version: "1.1.0"}, SchemaMismatchWarn, false, 2},
+		{ExpectedSchema{Version: "1.1.0"}, SchemaMismatchDegrade, false, 2},
+		{ExpectedSchema{Version: "1.1.0", Tables: map[ID][]ID{"Known": {"name"}}}, SchemaMismatchDegrade, false, 1},
+	}
+
+	for _, test := range tests {
+		dbSchema, err := applySchemaMismatchPolicy("TestDB", test.expected, test.policy, testDatabaseSchema())
+		if test.fail {
+			if _, ok := err.(*SchemaMismatchError); !ok {
+				t.Errorf("expect *SchemaMismatchError for %+v, got %v", test.expected, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %+v: %v", test.expected, err)
+			continue
+		}
+		if len(dbSchema.Tables) != test.tables {
+			t.Errorf("got %d tables for %+v, want %d", len(dbSchema.Tables), test.expected, test.tables)
+		}
+	}
+}
+
+func TestExpectedSchemaPrune(t *testing.T) {
+	expected := ExpectedSchema{Tables: map[ID][]ID{"Known": {"name"}, "Missing": {"name"}}}
+	dbSchema := testDatabaseSchema()
+	pruned := expected.prune(dbSchema)
+
+	if len(pruned.Tables) != 1 {
+		t.Fatalf("got %d tables, want 1", len(pruned.Tables))
+	}
+	known := pruned.Tables["Known"]
+	if len(known.Columns) != 1 || known.Columns["name"] == nil {
+		t.Errorf("got columns %v, want only name", known.Columns)
+	}
+	if len(known.Indexes) != 1 || known.Indexes[0][0] != "name" {
+		t.Errorf("got indexes %v, want [[name]]", known.Indexes)
+	}
+	// the original schema is untouched
+	if len(dbSchema.Tables) != 2 || len(dbSchema.Tables["Known"].Columns) != 2 {
+		t.Error("prune modified the original schema")
+	}
+}
@@ -0,0 +1,160 @@
+package ovsdb
+
+import (
+	"fmt"
+)
+
+// OperationPolicy is invoked with each operation of a transaction before the transaction is submitted.
+// It vetoes the whole transaction by returning an error, e.g. to keep a component away from parts of
+// the database it must not touch.
+type OperationPolicy func(db ID, op Operation) error
+
+// PolicyError is returned by Transact when an OperationPolicy vetoes a transaction
+type PolicyError struct {
+	// Database of the transaction
+	Database ID
+	// Index of the vetoed operation in the transaction
+	Index int
+	// Op is the vetoed operation
+	Op Operation
+	// Err is the error returned by the policy
+	Err error
+}
+
+// Error implements error interface
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("operation %d (%s) on database %s denied by policy: %v", e.Index, e.Op.Op(), e.Database, e.Err)
+}
+
+// AddOperationPolicy registers policy to check operations of all transactions, policies are checked in the order added
+func (c *Client) AddOperationPolicy(policy OperationPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policies = append(c.policies, policy)
+}
+
+// checkPolicies checks ops against the registered policies
+func (c *Client) checkPolicies(db ID, ops []Operation) error {
+	c.mu.Lock()
+	policies := c.policies
+	c.mu.Unlock()
+	for i, op := range ops {
+		for _, policy := range policies {
+			if err := policy(db, op); err != nil {
+				return &PolicyError{Database: db, Index: i, Op: op, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// ReadOnly returns a policy which denies all operations modifying the database
+func ReadOnly() OperationPolicy {
+	return func(db ID, op Operation) error {
+		switch op.Op() {
+		case OpInsert, OpUpdate, OpMutate, OpDelete:
+			return fmt.Errorf("%s is not allowed in read-only mode", op.Op())
+		}
+		return nil
+	}
+}
+
+// DenyOps returns a policy which denies operations of types
+func DenyOps(types ...OperationType) OperationPolicy {
+	return func(db ID, op Operation) error {
+		for _, opType := range types {
+			if op.Op() == opType {
+				return fmt.Errorf("%s is denied", opType)
+			}
+		}
+		return nil
+	}
+}
+
+// DenyTables returns a policy which denies all operations on tables
+func DenyTables(tables ...ID) OperationPolicy {
+	return func(db ID, op Operation) error {
+		table := OperationTable(op)
+		for _, denied := range tables {
+			if table == denied {
+				return fmt.Errorf("table %s is denied", table)
+			}
+		}
+		return nil
+	}
+}
+
+// DenyColumns returns a policy which denies writes to columns of table
+func DenyColumns(table ID, columns ...ID) OperationPolicy {
+	return func(db ID, op Operation) error {
+		if OperationTable(op) != table {
+			return nil
+		}
+		written, err := WrittenColumns(op)
+		if err != nil {
+			return err
+		}
+		for _, column := range written {
+			for _, denied := range columns {
+				if column == denied {
+					return fmt.Errorf("writing column %s of table %s is denied", column, table)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// OperationTable returns the table op works on, empty if op is not on a table
+func OperationTable(op Operation) ID {
+	switch o := op.(type) {
+	case *InsertOperation:
+		return o.Table
+	case *SelectOperation:
+		return o.Table
+	case *UpdateOperation:
+		return o.Table
+	case *MutateOperation:
+		return o.Table
+	case *DeleteOperation:
+		return o.Table
+	case *WaitOperation:
+		return o.Table
+	case *boundOperation:
+		return OperationTable(o.template)
+	}
+	return ""
+}
+
+// WrittenColumns returns the columns op writes to, a DeleteOperation writes no column
+func WrittenColumns(op Operation) ([]ID, error) {
+	var columns []ID
+	switch o := op.(type) {
+	case *InsertOperation:
+		row, err := rowColumns(o.Row)
+		if err != nil {
+			return nil, err
+		}
+		for column := range row {
+			columns = append(columns, column)
+		}
+	case *UpdateOperation:
+		if len(o.Mask) != 0 {
+			return append(columns, o.Mask...), nil
+		}
+		row, err := rowColumns(o.Row)
+		if err != nil {
+			return nil, err
+		}
+		for column := range row {
+			columns = append(columns, column)
+		}
+	case *MutateOperation:
+		for _, mutation := range o.Mutations {
+			columns = append(columns, mutation.Column)
+		}
+	case *boundOperation:
+		return WrittenColumns(o.template)
+	}
+	return columns, nil
+}
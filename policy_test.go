@@ -0,0 +1,61 @@
+package ovsdb
+
+import (
+	"testing"
+)
+
+func TestOperationPolicies(t *testing.T) {
+	insert := &InsertOperation{Table: "Bridge", Row: map[ID]Value{"name": "br0"}}
+	sel := &SelectOperation{Table: "Bridge", Where: MatchAll()}
+	update := &UpdateOperation{Table: "Interface", Where: MatchAll(), Row: map[ID]Value{"admin_state": "up", "mtu": 1500}, Mask: Fields("mtu")}
+	mutate := &MutateOperation{Table: "Bridge", Where: MatchAll(), Mutations: []Mutation{{"ports", MutatorInsert, UUID(zeroUUID)}}}
+	del := &DeleteOperation{Table: "Port", Where: MatchAll()}
+
+	tests := []struct {
+		name   string
+		policy OperationPolicy
+		op     Operation
+		denied bool
+	}{
+		{"read-only select", ReadOnly(), sel, false},
+		{"read-only insert", ReadOnly(), insert, true},
+		{"read-only delete", ReadOnly(), del, true},
+		{"deny delete", DenyOps(OpDelete), del, true},
+		{"deny delete on mutate", DenyOps(OpDelete), mutate, false},
+		{"deny table", DenyTables("Port"), del, true},
+		{"deny other table", DenyTables("Port"), insert, false},
+		{"deny inserted column", DenyColumns("Bridge", "name"), insert, true},
+		{"deny mutated column", DenyColumns("Bridge", "ports"), mutate, true},
+		{"deny unmasked column", DenyColumns("Interface", "admin_state"), update, false},
+		{"deny masked column", DenyColumns("Interface", "mtu"), update, true},
+		{"deny column of other table", DenyColumns("Port", "name"), insert, false},
+	}
+
+	for _, test := range tests {
+		err := test.policy("Open_vSwitch", test.op)
+		if denied := err != nil; denied != test.denied {
+			t.Errorf("%s: got error %v, want denied %v", test.name, err, test.denied)
+		}
+	}
+}
+
+func TestCheckPolicies(t *testing.T) {
+	c := &Client{}
+	c.AddOperationPolicy(DenyTables("Port"))
+
+	ops := []Operation{
+		&SelectOperation{Table: "Bridge", Where: MatchAll()},
+		&DeleteOperation{Table: "Port", Where: MatchAll()},
+	}
+	err := c.checkPolicies("Open_vSwitch", ops)
+	policyErr, ok := err.(*PolicyError)
+	if !ok {
+		t.Fatalf("checkPolicies returned %v, want *PolicyError", err)
+	}
+	if policyErr.Index != 1 || policyErr.Op != ops[1] {
+		t.Errorf("vetoed operation %d (%v), want 1 (%v)", policyErr.Index, policyErr.Op, ops[1])
+	}
+	if err := c.checkPolicies("Open_vSwitch", ops[:1]); err != nil {
+		t.Errorf("checkPolicies returned %v, want nil", err)
+	}
+}
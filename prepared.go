@@ -0,0 +1,154 @@
+package ovsdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// paramMarker starts the JSON encoding of a Param in prepared operations, NUL is escaped by encoding/json
+// so the marker can't appear in the encoding of other strings
+const paramMarker = `"\u0000param:`
+
+// Param is a placeholder for a value in the operations of a PreparedTransaction,
+// e.g. Param("name") in a row or a condition, the value is bound when the transaction is executed
+type Param string
+
+// MarshalJSON implements json.Marshaler interface
+func (p Param) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("empty parameter name")
+	}
+	return json.Marshal("\x00param:" + string(p))
+}
+
+// PreparedTransaction is a parameterized sequence of operations validated and encoded once by Prepare,
+// then executed many times with different values bound to its parameters with TransactPrepared,
+// which saves validation and encoding in hot provisioning paths
+type PreparedTransaction struct {
+	ops    []preparedOperation
+	params []string
+}
+
+// preparedOperation is the encoding of an operation split around its parameters
+type preparedOperation struct {
+	template Operation
+	// literals are the encoded parts of the operation, params[i] is between literals[i] and literals[i+1]
+	literals [][]byte
+	params   []Param
+}
+
+// Prepare validates and encodes ops, whose values may contain Params
+func Prepare(ops ...Operation) (*PreparedTransaction, error) {
+	prepared := &PreparedTransaction{}
+	seen := make(map[string]bool)
+	for i, op := range ops {
+		encoded, err := json.Marshal(op)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operation %d (%s): %v", i, op.Op(), err)
+		}
+		preparedOp, err := splitParams(op, encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operation %d (%s): %v", i, op.Op(), err)
+		}
+		for _, param := range preparedOp.params {
+			if !seen[string(param)] {
+				seen[string(param)] = true
+				prepared.params = append(prepared.params, string(param))
+			}
+		}
+		prepared.ops = append(prepared.ops, preparedOp)
+	}
+	sort.Strings(prepared.params)
+	return prepared, nil
+}
+
+// splitParams splits the encoding of op around the encodings of Params
+func splitParams(op Operation, encoded []byte) (preparedOperation, error) {
+	preparedOp := preparedOperation{template: op}
+	marker := []byte(paramMarker)
+	for {
+		i := bytes.Index(encoded, marker)
+		if i < 0 {
+			break
+		}
+		end := bytes.IndexByte(encoded[i+len(marker):], '"')
+		if end < 0 {
+			return preparedOp, fmt.Errorf("malformed parameter in %s", encoded)
+		}
+		end += i + len(marker)
+		preparedOp.literals = append(preparedOp.literals, encoded[:i])
+		preparedOp.params = append(preparedOp.params, Param(encoded[i+len(marker):end]))
+		encoded = encoded[end+1:]
+	}
+	preparedOp.literals = append(preparedOp.literals, encoded)
+	return preparedOp, nil
+}
+
+// Params returns the names of the parameters of the transaction, sorted
+func (p *PreparedTransaction) Params() []string {
+	return p.params
+}
+
+// Bind returns the operations of the transaction with values bound to the parameters,
+// every parameter must be bound and every binding must be a parameter
+func (p *PreparedTransaction) Bind(bindings map[string]Value) ([]Operation, error) {
+	encodedValues := make(map[Param][]byte, len(bindings))
+	for name, value := range bindings {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of parameter %s: %v", name, err)
+		}
+		encodedValues[Param(name)] = encoded
+	}
+	for name := range bindings {
+		if i := sort.SearchStrings(p.params, name); i == len(p.params) || p.params[i] != name {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+	}
+
+	ops := make([]Operation, 0, len(p.ops))
+	for _, preparedOp := range p.ops {
+		var buf bytes.Buffer
+		for i, literal := range preparedOp.literals {
+			buf.Write(literal)
+			if i == len(preparedOp.params) {
+				break
+			}
+			encoded, ok := encodedValues[preparedOp.params[i]]
+			if !ok {
+				return nil, fmt.Errorf("parameter %s is not bound", preparedOp.params[i])
+			}
+			buf.Write(encoded)
+		}
+		ops = append(ops, &boundOperation{template: preparedOp.template, encoded: buf.Bytes()})
+	}
+	return ops, nil
+}
+
+// TransactPrepared executes the prepared transaction p on database db with bindings bound to its parameters
+func (c *Client) TransactPrepared(db ID, p *PreparedTransaction, bindings map[string]Value) (*TransactResult, error) {
+	ops, err := p.Bind(bindings)
+	if err != nil {
+		return &TransactResult{}, err
+	}
+	return c.Transact(db, ops...)
+}
+
+// boundOperation is an operation of a PreparedTransaction with values bound to its parameters,
+// it's encoded as is
+type boundOperation struct {
+	template Operation
+	encoded  json.RawMessage
+}
+
+// Op implements Operation interface
+func (b *boundOperation) Op() OperationType {
+	return b.template.Op()
+}
+
+// MarshalJSON implements json.Marshaler interface
+func (b boundOperation) MarshalJSON() ([]byte, error) {
+	return b.encoded, nil
+}
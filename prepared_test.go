@@ -0,0 +1,61 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPreparedTransaction(t *testing.T) {
+	prepared, err := Prepare(
+		&InsertOperation{
+			Table:    "Logical_Switch_Port",
+			Row:      map[ID]Value{"name": Param("port"), "addresses": Param("addresses")},
+			UUIDName: "lsp",
+		},
+		&MutateOperation{
+			Table:     "Logical_Switch",
+			Where:     []Condition{{"name", FuncEq, Param("switch")}},
+			Mutations: []Mutation{{"ports", MutatorInsert, NamedUUID("lsp")}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if params := prepared.Params(); !reflect.DeepEqual(params, []string{"addresses", "port", "switch"}) {
+		t.Errorf("Params = %v", params)
+	}
+
+	for _, port := range []string{"lsp1", "lsp2"} {
+		ops, err := prepared.Bind(map[string]Value{
+			"port":      port,
+			"addresses": StringSet{Values: []string{"0a:00:00:00:00:01", "dynamic"}},
+			"switch":    "ls1",
+		})
+		if err != nil {
+			t.Fatalf("Bind failed: %v", err)
+		}
+		bytes, err := json.Marshal(ops)
+		if err != nil {
+			t.Fatalf("json marshal failed: %v", err)
+		}
+		want := `[{"op":"insert","table":"Logical_Switch_Port","row":{"addresses":["set",["0a:00:00:00:00:01","dynamic"]],"name":"` + port + `"},"uuid-name":"lsp"},` +
+			`{"op":"mutate","table":"Logical_Switch","where":[["name","==","ls1"]],"mutations":[["ports","insert",["named-uuid","lsp"]]]}]`
+		if string(bytes) != want {
+			t.Errorf("bound operations = %s, want %s", bytes, want)
+		}
+		if table := OperationTable(ops[0]); table != "Logical_Switch_Port" {
+			t.Errorf("OperationTable = %s", table)
+		}
+	}
+
+	if _, err := prepared.Bind(map[string]Value{"port": "lsp1", "addresses": "dynamic"}); err == nil {
+		t.Error("expect Bind failed with a missing parameter, but got nil")
+	}
+	if _, err := prepared.Bind(map[string]Value{"port": "lsp1", "addresses": "dynamic", "switch": "ls1", "other": 1}); err == nil {
+		t.Error("expect Bind failed with an unknown parameter, but got nil")
+	}
+	if _, err := Prepare(&InsertOperation{Row: map[ID]Value{"name": Param("port")}}); err == nil {
+		t.Error("expect Prepare failed with an invalid operation, but got nil")
+	}
+}
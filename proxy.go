@@ -0,0 +1,220 @@
+package ovsdb
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WithProxy sets the proxy tcp connections are made through, a URL with scheme "socks5", "socks5h"
+// or "http" (HTTP CONNECT), user information in the URL is used to authenticate with the proxy.
+// By default the proxy is taken from the ALL_PROXY environment variable, except for hosts listed in
+// NO_PROXY. A nil proxyURL dials directly, ignoring the environment.
+func WithProxy(proxyURL *url.URL) DialOption {
+	return func(o *dialOptions) {
+		o.proxy = proxyURL
+		o.proxySet = true
+	}
+}
+
+// proxyFor returns the proxy for tcp connections to address, nil if it's dialed directly
+func (o *dialOptions) proxyFor(address string) (*url.URL, error) {
+	if o.proxySet {
+		return o.proxy, nil
+	}
+	return proxyFromEnvironment(address)
+}
+
+// proxyFromEnvironment returns the proxy in ALL_PROXY for address unless its host is in NO_PROXY
+func proxyFromEnvironment(address string) (*url.URL, error) {
+	value := getenvAny("ALL_PROXY", "all_proxy")
+	if value == "" {
+		return nil, nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range strings.Split(getenvAny("NO_PROXY", "no_proxy"), ",") {
+		pattern = strings.TrimPrefix(strings.TrimSpace(pattern), ".")
+		if pattern == "*" || pattern != "" && (host == pattern || strings.HasSuffix(host, "."+pattern)) {
+			return nil, nil
+		}
+	}
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ALL_PROXY %q: %v", value, err)
+	}
+	return proxyURL, nil
+}
+
+// getenvAny returns the value of the first set environment variable of names
+func getenvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// dialProxy connects to address through the proxy at proxyURL, which is dialed with dialer
+func dialProxy(ctx context.Context, dialer ContextDialer, proxyURL *url.URL, address string) (net.Conn, error) {
+	var connect func(conn net.Conn, address string, user *url.Userinfo) error
+	port := proxyURL.Port()
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		connect = socks5Connect
+		if port == "" {
+			port = "1080"
+		}
+	case "http":
+		connect = httpConnect
+		if port == "" {
+			port = "80"
+		}
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(proxyURL.Hostname(), port))
+	if err != nil {
+		return nil, err
+	}
+	if err := connect(conn, address, proxyURL.User); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %v", proxyURL.Host, err)
+	}
+	return conn, nil
+}
+
+// socks5Connect asks the SOCKS5 proxy on conn to connect to address, RFC 1928 and RFC 1929
+func socks5Connect(conn net.Conn, address string, user *url.Userinfo) error {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port %q", portString)
+	}
+
+	method := byte(0x00) // no authentication
+	if user != nil {
+		method = 0x02 // username/password
+	}
+	if _, err := conn.Write([]byte{0x05, 0x01, method}); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 0x05 || reply[1] != method {
+		return errors.New("socks5 authentication method not accepted")
+	}
+	if user != nil {
+		password, _ := user.Password()
+		if len(user.Username()) > 255 || len(password) > 255 {
+			return errors.New("socks5 username or password too long")
+		}
+		request := []byte{0x01, byte(len(user.Username()))}
+		request = append(request, user.Username()...)
+		request = append(request, byte(len(password)))
+		request = append(request, password...)
+		if _, err := conn.Write(request); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[1] != 0x00 {
+			return errors.New("socks5 authentication failed")
+		}
+	}
+
+	request := []byte{0x05, 0x01, 0x00}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return errors.New("socks5 host name too long")
+		}
+		request = append(request, 0x03, byte(len(host)))
+		request = append(request, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		request = append(request, 0x01)
+		request = append(request, ip4...)
+	} else {
+		request = append(request, 0x04)
+		request = append(request, ip...)
+	}
+	request = append(request, byte(port>>8), byte(port))
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[1] != 0x00 {
+		return fmt.Errorf("socks5 connect failed with code %d", header[1])
+	}
+	// skip the bound address and port
+	var skip int
+	switch header[3] {
+	case 0x01:
+		skip = net.IPv4len + 2
+	case 0x04:
+		skip = net.IPv6len + 2
+	case 0x03:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		skip = int(length[0]) + 2
+	default:
+		return fmt.Errorf("socks5 reply with unknown address type %d", header[3])
+	}
+	_, err = io.ReadFull(conn, make([]byte, skip))
+	return err
+}
+
+// httpConnect asks the HTTP proxy on conn to tunnel to address with the CONNECT method
+func httpConnect(conn net.Conn, address string, user *url.Userinfo) error {
+	request := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", address, address)
+	if user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		request += "Proxy-Authorization: Basic " + credentials + "\r\n"
+	}
+	if _, err := io.WriteString(conn, request+"\r\n"); err != nil {
+		return err
+	}
+
+	// read the response byte by byte, the tunnel starts right after it
+	var response []byte
+	b := make([]byte, 1)
+	for !strings.HasSuffix(string(response), "\r\n\r\n") {
+		if len(response) > 64*1024 {
+			return errors.New("http proxy response too long")
+		}
+		if _, err := conn.Read(b); err != nil {
+			return err
+		}
+		response = append(response, b[0])
+	}
+	status := strings.SplitN(strings.SplitN(string(response), "\r\n", 2)[0], " ", 3)
+	if len(status) < 2 || !strings.HasPrefix(status[0], "HTTP/") {
+		return errors.New("invalid http proxy response")
+	}
+	if status[1] != "200" {
+		return fmt.Errorf("http proxy CONNECT failed: %s", strings.Join(status[1:], " "))
+	}
+	return nil
+}
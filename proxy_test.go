@@ -0,0 +1,102 @@
+package ovsdb
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+// serveProxied serves OVSDB on conn once a proxy handshake succeeded, as if it was tunneled
+func serveProxied(conn net.Conn, servers chan<- *ovsdbtest.Server) {
+	servers <- ovsdbtest.NewServer(conn)
+}
+
+func TestDialSOCKS5Proxy(t *testing.T) {
+	servers := make(chan *ovsdbtest.Server, 1)
+	targets := make(chan string, 1)
+	address := listen(t, func(conn net.Conn) {
+		greeting := make([]byte, 3)
+		io.ReadFull(conn, greeting)
+		conn.Write([]byte{0x05, 0x02})
+		auth := make([]byte, 2)
+		io.ReadFull(conn, auth)
+		user := make([]byte, auth[1])
+		io.ReadFull(conn, user)
+		io.ReadFull(conn, auth[:1])
+		password := make([]byte, auth[0])
+		io.ReadFull(conn, password)
+		if string(user) != "admin" || string(password) != "secret" {
+			conn.Write([]byte{0x01, 0x01})
+			conn.Close()
+			return
+		}
+		conn.Write([]byte{0x01, 0x00})
+		header := make([]byte, 5)
+		io.ReadFull(conn, header)
+		host := make([]byte, header[4]+2)
+		io.ReadFull(conn, host)
+		targets <- string(host[:header[4]])
+		conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0, 0})
+		serveProxied(conn, servers)
+	})
+	proxyURL, _ := url.Parse("socks5://admin:secret@" + address[len("tcp:"):])
+
+	client, err := Dial("tcp:ovn-central.example:6641", WithProxy(proxyURL))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+	server := <-servers
+	defer server.Close()
+	if target := <-targets; target != "ovn-central.example" {
+		t.Errorf("proxy connected to %s", target)
+	}
+	if _, err := client.ListDbs(); err != nil {
+		t.Errorf("ListDbs failed: %v", err)
+	}
+}
+
+func TestDialHTTPProxyFromEnvironment(t *testing.T) {
+	servers := make(chan *ovsdbtest.Server, 1)
+	address := listen(t, func(conn net.Conn) {
+		request, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil || request.Method != "CONNECT" || request.Host != "10.0.0.1:6641" {
+			conn.Write([]byte("HTTP/1.1 403 Forbidden\r\n\r\n"))
+			conn.Close()
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		serveProxied(conn, servers)
+	})
+	os.Setenv("ALL_PROXY", "http://"+address[len("tcp:"):])
+	defer os.Unsetenv("ALL_PROXY")
+
+	client, err := Dial("tcp:10.0.0.1:6641")
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+	server := <-servers
+	defer server.Close()
+	if _, err := client.ListDbs(); err != nil {
+		t.Errorf("ListDbs failed: %v", err)
+	}
+
+	os.Setenv("NO_PROXY", "example.com, 10.0.0.1")
+	defer os.Unsetenv("NO_PROXY")
+	if proxy, err := proxyFromEnvironment("10.0.0.1:6641"); err != nil || proxy != nil {
+		t.Errorf("proxyFromEnvironment = %v, %v, want no proxy", proxy, err)
+	}
+	if proxy, err := proxyFromEnvironment("db.example.com:6641"); err != nil || proxy != nil {
+		t.Errorf("proxyFromEnvironment = %v, %v, want no proxy", proxy, err)
+	}
+	if proxy, err := proxyFromEnvironment("10.0.0.2:6641"); err != nil || proxy == nil {
+		t.Errorf("proxyFromEnvironment = %v, %v, want the proxy", proxy, err)
+	}
+}
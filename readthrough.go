@@ -0,0 +1,417 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRowNotFound is returned by ReadThroughCache.Get if the row doesn't exist
+var ErrRowNotFound = errors.New("row not found")
+
+// ReadThroughCache caches rows of a database selected on demand, a middle ground between raw selects
+// and replicating tables with monitors for deployments that can't afford the latter.
+// Get and List select rows from the server on a cache miss and cache them for a TTL.
+// Tables written by transactions of the client on the database are invalidated automatically,
+// changes made by other clients are only seen once cached rows expire or are invalidated with Invalidate.
+type ReadThroughCache struct {
+	client *Client
+	db     ID
+	ttl    time.Duration
+
+	mu          sync.Mutex
+	rows        map[ID]map[UUID]cachedRow
+	tables      map[ID]cachedTable
+	bytes       map[ID]int
+	clock       uint64
+	revision    uint64
+	maxBytes    int
+	limitPolicy LimitPolicy
+	columns     map[ID][]ID
+	stats       CacheStats
+	// epochs counts the invalidations of each table, rows selected while the table is invalidated
+	// are not cached since they may predate the change
+	epochs map[ID]uint64
+	// removeHook unregisters the transaction hook invalidating the cache
+	removeHook func()
+
+	// generation is incremented, atomically and with mu held, whenever cached rows change,
+	// snapshot holds the last *DBView built by Snapshot
+	generation uint64
+	snapshot   atomic.Value
+}
+
+// CacheStats are statistics of a ReadThroughCache, e.g. for exporting as metrics
+type CacheStats struct {
+	// Hits and Misses count the Get and List calls served from the cache or not
+	Hits   uint64
+	Misses uint64
+	// Rows is the number of cached rows by table, including expired ones
+	Rows map[ID]int
+	// Bytes is the size of the JSON encoding of the cached rows by table
+	Bytes map[ID]int
+	// Evictions counts the rows evicted by LimitEvictLRU,
+	// Denials counts the rows not cached because of LimitDeny
+	Evictions uint64
+	Denials   uint64
+	// LastUpdate is when rows were last selected from the server, zero if never
+	LastUpdate time.Time
+}
+
+// HitRatio returns the ratio of calls served from the cache, 0 if there was no call
+func (stats CacheStats) HitRatio() float64 {
+	if stats.Hits+stats.Misses == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(stats.Hits+stats.Misses)
+}
+
+// cachedRow is a cached row or the absence of a row
+type cachedRow struct {
+	row     json.RawMessage
+	expires time.Time
+	// used is the value of the clock of the cache when the row was last used
+	used uint64
+	// revision is the revision of the cache when the row was last seen changed
+	revision uint64
+}
+
+// cachedTable holds the UUIDs of all rows of a table
+type cachedTable struct {
+	uuids   []UUID
+	expires time.Time
+}
+
+// NewReadThroughCache creates a ReadThroughCache of database db caching rows for ttl
+func NewReadThroughCache(client *Client, db ID, ttl time.Duration) *ReadThroughCache {
+	cache := &ReadThroughCache{
+		client: client,
+		db:     db,
+		ttl:    ttl,
+		rows:   make(map[ID]map[UUID]cachedRow),
+		tables: make(map[ID]cachedTable),
+		bytes:  make(map[ID]int),
+		epochs: make(map[ID]uint64),
+	}
+	cache.removeHook = client.AddTransactHook(func(db ID, ops []Operation, result *TransactResult, duration time.Duration, err error) {
+		if db != cache.db {
+			return
+		}
+		for _, op := range ops {
+			if op.Op() != OpSelect && op.Op() != OpWait && op.Op() != OpComment {
+				cache.Invalidate(OperationTable(op))
+			}
+		}
+	})
+	return cache
+}
+
+// Close unregisters the cache from the client, which otherwise keeps it alive to invalidate it on writes.
+// The cache must not be used after Close, writes of the client are not seen anymore.
+func (cache *ReadThroughCache) Close() {
+	cache.removeHook()
+}
+
+// Get returns the row uuid of table, or ErrRowNotFound if it doesn't exist.
+// The row is a copy owned by the caller, cached rows are never modified in place, so concurrent
+// readers never see a row being updated.
+func (cache *ReadThroughCache) Get(table ID, uuid UUID) (json.RawMessage, error) {
+	row, err := cache.UnsafeGet(table, uuid)
+	if err != nil {
+		return nil, err
+	}
+	return copyRow(row), nil
+}
+
+// UnsafeGet is Get without copying the row, for hot paths. The row is shared with the cache and other
+// callers, it must not be modified.
+func (cache *ReadThroughCache) UnsafeGet(table ID, uuid UUID) (json.RawMessage, error) {
+	row, _, err := cache.get(table, uuid)
+	return row, err
+}
+
+// GetWithRevision is Get also returning the revision of the row, which increases every time the cache sees
+// the row changed, like the resourceVersion of Kubernetes objects, and when it's cached again after being
+// invalidated or evicted. Revisions are ordered across all rows of the cache, e.g. for optimistic concurrency
+// or ignoring stale data, but they are not persistent and start over with a new cache.
+func (cache *ReadThroughCache) GetWithRevision(table ID, uuid UUID) (json.RawMessage, uint64, error) {
+	row, revision, err := cache.get(table, uuid)
+	if err != nil {
+		return nil, 0, err
+	}
+	return copyRow(row), revision, nil
+}
+
+// get returns the row uuid of table and its revision, selecting it if it's not cached
+func (cache *ReadThroughCache) get(table ID, uuid UUID) (json.RawMessage, uint64, error) {
+	now := time.Now()
+	cache.mu.Lock()
+	cached, ok := cache.rows[table][uuid]
+	hit := ok && !now.After(cached.expires)
+	cache.count(hit)
+	if hit {
+		cache.touch(table, uuid)
+	}
+	epoch := cache.epochs[table]
+	cache.mu.Unlock()
+	if !hit {
+		rows, err := cache.selectRows(table, []Condition{{"_uuid", FuncEq, uuid}})
+		if err != nil {
+			return nil, 0, err
+		}
+		cached = cachedRow{expires: now.Add(cache.ttl)}
+		if len(rows) != 0 {
+			cached.row = rows[0]
+		}
+		cache.mu.Lock()
+		if cache.epochs[table] == epoch {
+			stored := map[UUID]cachedRow{uuid: cached}
+			cache.store(table, stored)
+			cached = stored[uuid]
+		} else {
+			// the table was invalidated during the select, the row is returned but not cached
+			cache.revision++
+			cached.revision = cache.revision
+		}
+		cache.stats.LastUpdate = now
+		cache.mu.Unlock()
+	}
+	if cached.row == nil {
+		return nil, 0, ErrRowNotFound
+	}
+	return cached.row, cached.revision, nil
+}
+
+// List returns all rows of table, the rows are copies owned by the caller like the row returned by Get
+func (cache *ReadThroughCache) List(table ID) ([]json.RawMessage, error) {
+	rows, err := cache.UnsafeList(table)
+	if err != nil {
+		return nil, err
+	}
+	copies := make([]json.RawMessage, len(rows))
+	for i, row := range rows {
+		copies[i] = copyRow(row)
+	}
+	return copies, nil
+}
+
+// UnsafeList is List without copying the rows, for hot paths. The rows are shared with the cache and
+// other callers, they must not be modified.
+func (cache *ReadThroughCache) UnsafeList(table ID) ([]json.RawMessage, error) {
+	now := time.Now()
+	cache.mu.Lock()
+	cached, ok := cache.tables[table]
+	var rows []json.RawMessage
+	if ok && now.Before(cached.expires) {
+		for _, uuid := range cached.uuids {
+			row, ok := cache.rows[table][uuid]
+			if !ok || row.row == nil || now.After(row.expires) {
+				// a row was invalidated or expired
+				rows = nil
+				break
+			}
+			rows = append(rows, row.row)
+		}
+		if len(rows) == len(cached.uuids) {
+			cache.count(true)
+			for _, uuid := range cached.uuids {
+				cache.touch(table, uuid)
+			}
+			cache.mu.Unlock()
+			return rows, nil
+		}
+	}
+	cache.count(false)
+	epoch := cache.epochs[table]
+	cache.mu.Unlock()
+
+	rows, err := cache.selectRows(table, MatchAll())
+	if err != nil {
+		return nil, err
+	}
+	cached = cachedTable{expires: now.Add(cache.ttl)}
+	tableRows := make(map[UUID]cachedRow, len(rows))
+	for _, row := range rows {
+		uuid, err := rowUUID(row)
+		if err != nil {
+			return nil, err
+		}
+		cached.uuids = append(cached.uuids, uuid)
+		tableRows[uuid] = cachedRow{row: row, expires: cached.expires}
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	// rows selected while the table was invalidated are not cached
+	if cache.epochs[table] == epoch && cache.store(table, tableRows) {
+		cache.tables[table] = cached
+	}
+	cache.stats.LastUpdate = now
+	return rows, nil
+}
+
+// Stats returns the statistics of the cache
+func (cache *ReadThroughCache) Stats() CacheStats {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	stats := cache.stats
+	stats.Rows = make(map[ID]int, len(cache.rows))
+	for table, rows := range cache.rows {
+		stats.Rows[table] = len(rows)
+	}
+	stats.Bytes = make(map[ID]int, len(cache.bytes))
+	for table, bytes := range cache.bytes {
+		stats.Bytes[table] = bytes
+	}
+	return stats
+}
+
+// count counts a hit or a miss, cache.mu must be held
+func (cache *ReadThroughCache) count(hit bool) {
+	if hit {
+		cache.stats.Hits++
+	} else {
+		cache.stats.Misses++
+	}
+}
+
+// Invalidate drops the cached rows uuids of table, or all cached rows of table if no uuid is given
+func (cache *ReadThroughCache) Invalidate(table ID, uuids ...UUID) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if len(uuids) == 0 {
+		cache.drop(table)
+		cache.changed()
+		return
+	}
+	cache.bumpEpoch(table)
+	for _, uuid := range uuids {
+		cache.remove(table, uuid)
+	}
+}
+
+// drop drops all cached rows of table, cache.mu must be held
+func (cache *ReadThroughCache) drop(table ID) {
+	cache.bumpEpoch(table)
+	delete(cache.rows, table)
+	delete(cache.tables, table)
+	delete(cache.bytes, table)
+}
+
+// bumpEpoch records an invalidation of table, so rows of table being selected are not cached,
+// cache.mu must be held
+func (cache *ReadThroughCache) bumpEpoch(table ID) {
+	if cache.epochs == nil {
+		cache.epochs = make(map[ID]uint64)
+	}
+	cache.epochs[table]++
+}
+
+// rowsOf returns the cached rows of table, cache.mu must be held
+func (cache *ReadThroughCache) rowsOf(table ID) map[UUID]cachedRow {
+	rows, ok := cache.rows[table]
+	if !ok {
+		rows = make(map[UUID]cachedRow)
+		cache.rows[table] = rows
+	}
+	return rows
+}
+
+// store caches rows of table, unless they are denied by the size limit of tables, cache.mu must be held.
+// The revisions of rows are set in rows, they are kept for rows cached with the same values.
+// It returns false if the rows are not cached.
+func (cache *ReadThroughCache) store(table ID, rows map[UUID]cachedRow) bool {
+	if cache.bytes == nil {
+		cache.bytes = make(map[ID]int)
+	}
+	for uuid, row := range rows {
+		cached, ok := cache.rows[table][uuid]
+		if ok && (cached.row == nil && row.row == nil || cached.row != nil && row.row != nil && sameRow(cached.row, row.row)) {
+			row.revision = cached.revision
+		} else {
+			cache.revision++
+			row.revision = cache.revision
+		}
+		rows[uuid] = row
+	}
+	if cache.maxBytes > 0 && cache.limitPolicy == LimitDeny {
+		bytes := cache.bytes[table]
+		for uuid, row := range rows {
+			bytes += len(row.row) - len(cache.rows[table][uuid].row)
+		}
+		if bytes > cache.maxBytes {
+			cache.stats.Denials += uint64(len(rows))
+			return false
+		}
+	}
+	tableRows := cache.rowsOf(table)
+	cache.changed()
+	for uuid, row := range rows {
+		cache.clock++
+		row.used = cache.clock
+		cache.bytes[table] += len(row.row) - len(tableRows[uuid].row)
+		tableRows[uuid] = row
+	}
+	if cache.maxBytes > 0 && cache.limitPolicy == LimitEvictLRU {
+		cache.evict(table)
+	}
+	return true
+}
+
+// remove drops the cached row uuid of table, cache.mu must be held
+func (cache *ReadThroughCache) remove(table ID, uuid UUID) {
+	row, ok := cache.rows[table][uuid]
+	if !ok {
+		return
+	}
+	cache.bytes[table] -= len(row.row)
+	delete(cache.rows[table], uuid)
+	cache.changed()
+}
+
+// changed records a change of the cached rows, cache.mu must be held
+func (cache *ReadThroughCache) changed() {
+	atomic.AddUint64(&cache.generation, 1)
+}
+
+// touch marks the cached row uuid of table as used, cache.mu must be held
+func (cache *ReadThroughCache) touch(table ID, uuid UUID) {
+	if row, ok := cache.rows[table][uuid]; ok {
+		cache.clock++
+		row.used = cache.clock
+		cache.rows[table][uuid] = row
+	}
+}
+
+// selectRows selects rows of table matching where
+func (cache *ReadThroughCache) selectRows(table ID, where []Condition) ([]json.RawMessage, error) {
+	cache.mu.Lock()
+	columns := cache.columns[table]
+	cache.mu.Unlock()
+	result, err := cache.client.Transact(cache.db, &SelectOperation{Table: table, Where: where, Columns: columns})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) != 0 {
+		return nil, result.Errors
+	}
+	return pageRows(result.Results)
+}
+
+// rowUUID returns the "_uuid" column of a selected row
+func rowUUID(row json.RawMessage) (UUID, error) {
+	var columns struct {
+		UUID UUID `json:"_uuid"`
+	}
+	if err := json.Unmarshal(row, &columns); err != nil {
+		return "", fmt.Errorf("failed to decode _uuid of row: %v", err)
+	}
+	return columns.UUID, nil
+}
+
+// copyRow returns a copy of a cached row
+func copyRow(row json.RawMessage) json.RawMessage {
+	return append(json.RawMessage(nil), row...)
+}
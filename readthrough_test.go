@@ -0,0 +1,246 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestReadThroughCache(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	var selects int32
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		var op struct {
+			Op string `json:"op"`
+		}
+		json.Unmarshal(params[1], &op)
+		if op.Op != "select" {
+			return []interface{}{map[string]interface{}{"count": 1}}, nil
+		}
+		atomic.AddInt32(&selects, 1)
+		row := map[string]interface{}{"_uuid": []string{"uuid", "a0000000-0000-0000-0000-000000000000"}, "name": "br0"}
+		return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}, nil
+	})
+
+	cache := NewReadThroughCache(client, "Open_vSwitch", 50*time.Millisecond)
+	rows, err := cache.List("Bridge")
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("List = %s, %v", rows, err)
+	}
+	// served from the cache
+	if _, err := cache.Get("Bridge", "a0000000-0000-0000-0000-000000000000"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, err := cache.List("Bridge"); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&selects); n != 1 {
+		t.Errorf("%d selects, want 1", n)
+	}
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Rows["Bridge"] != 1 || stats.LastUpdate.IsZero() {
+		t.Errorf("Stats = %+v", stats)
+	}
+	if ratio := stats.HitRatio(); ratio < 0.66 || ratio > 0.67 {
+		t.Errorf("HitRatio = %v, want 2/3", ratio)
+	}
+
+	// writes of the client invalidate the table
+	client.Transact("Open_vSwitch", &UpdateOperation{Table: "Bridge", Where: MatchAll(), Row: map[ID]Value{"name": "br1"}})
+	if _, err := cache.Get("Bridge", "a0000000-0000-0000-0000-000000000000"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&selects); n != 2 {
+		t.Errorf("%d selects after a write, want 2", n)
+	}
+
+	// rows expire
+	time.Sleep(60 * time.Millisecond)
+	if _, err := cache.Get("Bridge", "a0000000-0000-0000-0000-000000000000"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&selects); n != 3 {
+		t.Errorf("%d selects after expiration, want 3", n)
+	}
+}
+
+func TestReadThroughCacheCopiesRows(t *testing.T) {
+	cache := &ReadThroughCache{
+		ttl:    time.Hour,
+		rows:   map[ID]map[UUID]cachedRow{"Bridge": {"a0000000-0000-0000-0000-000000000000": {row: json.RawMessage(`{"name":"br0"}`), expires: time.Now().Add(time.Hour)}}},
+		tables: map[ID]cachedTable{"Bridge": {uuids: []UUID{"a0000000-0000-0000-0000-000000000000"}, expires: time.Now().Add(time.Hour)}},
+	}
+	row, err := cache.Get("Bridge", "a0000000-0000-0000-0000-000000000000")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	copy(row, `{"name":"XXX"}`)
+	rows, err := cache.List("Bridge")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	copy(rows[0], `{"name":"YYY"}`)
+	if row, _ := cache.UnsafeGet("Bridge", "a0000000-0000-0000-0000-000000000000"); string(row) != `{"name":"br0"}` {
+		t.Errorf("cached row modified by callers: %s", row)
+	}
+}
+
+// benchmarkReadThroughCacheGet gets a cached row of about 4KB with get
+func benchmarkReadThroughCacheGet(b *testing.B, get func(cache *ReadThroughCache) (json.RawMessage, error)) {
+	row, _ := json.Marshal(map[string]interface{}{"_uuid": []string{"uuid", "a0000000-0000-0000-0000-000000000000"}, "description": string(make([]byte, 4096))})
+	cache := &ReadThroughCache{
+		ttl:  time.Hour,
+		rows: map[ID]map[UUID]cachedRow{"Bridge": {"a0000000-0000-0000-0000-000000000000": {row: row, expires: time.Now().Add(time.Hour)}}},
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := get(cache); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkReadThroughCacheGet(b *testing.B) {
+	benchmarkReadThroughCacheGet(b, func(cache *ReadThroughCache) (json.RawMessage, error) {
+		return cache.Get("Bridge", "a0000000-0000-0000-0000-000000000000")
+	})
+}
+
+func BenchmarkReadThroughCacheUnsafeGet(b *testing.B) {
+	benchmarkReadThroughCacheGet(b, func(cache *ReadThroughCache) (json.RawMessage, error) {
+		return cache.UnsafeGet("Bridge", "a0000000-0000-0000-0000-000000000000")
+	})
+}
+
+func TestReadThroughCacheRevisions(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	var name atomic.Value
+	name.Store("br0")
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		row := map[string]interface{}{"_uuid": []string{"uuid", "a0000000-0000-0000-0000-000000000000"}, "name": name.Load()}
+		return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}, nil
+	})
+
+	cache := NewReadThroughCache(client, "Open_vSwitch", time.Millisecond)
+	_, first, err := cache.GetWithRevision("Bridge", "a0000000-0000-0000-0000-000000000000")
+	if err != nil || first == 0 {
+		t.Fatalf("GetWithRevision = %d, %v", first, err)
+	}
+	// the row is selected again after expiring, but it's unchanged
+	time.Sleep(2 * time.Millisecond)
+	if _, revision, _ := cache.GetWithRevision("Bridge", "a0000000-0000-0000-0000-000000000000"); revision != first {
+		t.Errorf("revision of an unchanged row = %d, want %d", revision, first)
+	}
+	name.Store("br1")
+	time.Sleep(2 * time.Millisecond)
+	if _, revision, _ := cache.GetWithRevision("Bridge", "a0000000-0000-0000-0000-000000000000"); revision <= first {
+		t.Errorf("revision of a changed row = %d, want greater than %d", revision, first)
+	}
+}
+
+func TestReadThroughCacheInvalidateDuringSelect(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	var selects int32
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		atomic.AddInt32(&selects, 1)
+		started <- struct{}{}
+		<-release
+		row := map[string]interface{}{"_uuid": []string{"uuid", "a0000000-0000-0000-0000-000000000000"}, "name": "br0"}
+		return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}, nil
+	})
+
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(interfaceSchema), &dbSchema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	cache := NewReadThroughCache(client, "Open_vSwitch", time.Minute)
+	reads := []func() error{
+		func() error {
+			_, err := cache.Get("Bridge", "a0000000-0000-0000-0000-000000000000")
+			return err
+		},
+		func() error {
+			_, err := cache.List("Bridge")
+			return err
+		},
+	}
+	invalidations := []func(){
+		// e.g. a write of the client committed while the row is being selected
+		func() { cache.Invalidate("Bridge") },
+		// the row is selected with the previous columns
+		func() { cache.ExcludeColumns(&dbSchema, ExcludeStatistics) },
+	}
+	for _, invalidate := range invalidations {
+		for _, read := range reads {
+			done := make(chan error, 1)
+			go func() { done <- read() }()
+			<-started
+			invalidate()
+			release <- struct{}{}
+			if err := <-done; err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if rows := cache.Stats().Rows["Bridge"]; rows != 0 {
+				t.Errorf("%d rows selected during the invalidation cached, want none", rows)
+			}
+		}
+	}
+	if n := atomic.LoadInt32(&selects); n != 4 {
+		t.Errorf("%d selects, want 4", n)
+	}
+}
+
+func TestReadThroughCacheClose(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	var selects int32
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		var op struct {
+			Op string `json:"op"`
+		}
+		json.Unmarshal(params[1], &op)
+		if op.Op != "select" {
+			return []interface{}{map[string]interface{}{"count": 1}}, nil
+		}
+		atomic.AddInt32(&selects, 1)
+		row := map[string]interface{}{"_uuid": []string{"uuid", "a0000000-0000-0000-0000-000000000000"}, "name": "br0"}
+		return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}, nil
+	})
+
+	cache := NewReadThroughCache(client, "Open_vSwitch", time.Hour)
+	if _, err := cache.Get("Bridge", "a0000000-0000-0000-0000-000000000000"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	cache.Close()
+	// the client doesn't invalidate the closed cache anymore
+	client.Transact("Open_vSwitch", &UpdateOperation{Table: "Bridge", Where: MatchAll(), Row: map[ID]Value{"name": "br1"}})
+	if rows := cache.Stats().Rows["Bridge"]; rows != 1 {
+		t.Errorf("%d rows cached after a write, want the closed cache left as is", rows)
+	}
+	if n := atomic.LoadInt32(&selects); n != 1 {
+		t.Errorf("%d selects, want 1", n)
+	}
+}
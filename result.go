@@ -0,0 +1,66 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNotAttempted is returned by the result accessors of TransactResult for an operation not attempted
+// because a prior operation failed
+var ErrNotAttempted = errors.New("operation not attempted")
+
+// DecodeAt decodes the result of the operation at index i into into, e.g. a *SelectResult or a custom struct.
+// It returns the *Error of a failed operation, ErrNotAttempted for an operation not attempted,
+// or an error if there's no result at i.
+func (tr *TransactResult) DecodeAt(i int, into interface{}) error {
+	if i < 0 || i >= len(tr.Results) {
+		return fmt.Errorf("no result of operation %d in %d results", i, len(tr.Results))
+	}
+	switch result := tr.Results[i].(type) {
+	case nil:
+		return ErrNotAttempted
+	case *Error:
+		return result
+	case json.RawMessage:
+		if err := json.Unmarshal(result, into); err != nil {
+			return fmt.Errorf("invalid result of operation %d: %v", i, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unexpected result of operation %d: %v", i, result)
+	}
+}
+
+// InsertAt returns the result of the insert operation at index i, see DecodeAt for the errors
+func (tr *TransactResult) InsertAt(i int) (*InsertResult, error) {
+	var result InsertResult
+	if err := tr.DecodeAt(i, &result); err != nil {
+		return nil, err
+	}
+	if result.UUID == "" {
+		return nil, fmt.Errorf("no uuid in the result of operation %d", i)
+	}
+	return &result, nil
+}
+
+// SelectAt returns the result of the select operation at index i, see DecodeAt for the errors
+func (tr *TransactResult) SelectAt(i int) (*SelectResult, error) {
+	var result SelectResult
+	if err := tr.DecodeAt(i, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CountAt returns the number of rows matched by the update, mutate or delete operation at index i,
+// see DecodeAt for the errors
+func (tr *TransactResult) CountAt(i int) (int, error) {
+	var result struct {
+		Count int `json:"count"`
+	}
+	if err := tr.DecodeAt(i, &result); err != nil {
+		return 0, err
+	}
+	return result.Count, nil
+}
@@ -0,0 +1,44 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTransactResultAccessors(t *testing.T) {
+	var result TransactResult
+	err := json.Unmarshal([]byte(`[
+		{"uuid": ["uuid", "`+ls1+`"]},
+		{"rows": [{"name": "sw0"}]},
+		{"count": 2},
+		{"error": "constraint violation", "details": "duplicate name"},
+		null
+	]`), &result)
+	if err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+
+	insert, err := result.InsertAt(0)
+	if err != nil || insert.UUID != ls1 {
+		t.Errorf("InsertAt(0) = %v, %v, want %s", insert, err, ls1)
+	}
+	selected, err := result.SelectAt(1)
+	if err != nil || len(selected.Rows) != 1 || string(*selected.Rows[0]) != `{"name": "sw0"}` {
+		t.Errorf("SelectAt(1) = %v, %v, want one row", selected, err)
+	}
+	if count, err := result.CountAt(2); err != nil || count != 2 {
+		t.Errorf("CountAt(2) = %d, %v, want 2", count, err)
+	}
+	if _, err := result.CountAt(3); err != result.Results[3] {
+		t.Errorf("CountAt(3) error = %v, want the operation error", err)
+	}
+	if _, err := result.CountAt(4); err != ErrNotAttempted {
+		t.Errorf("CountAt(4) error = %v, want %v", err, ErrNotAttempted)
+	}
+	if _, err := result.InsertAt(5); err == nil {
+		t.Error("InsertAt(5) succeeded without result")
+	}
+	if _, err := result.InsertAt(1); err == nil {
+		t.Error("InsertAt(1) succeeded on a select result")
+	}
+}
@@ -0,0 +1,80 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// String implements fmt.Stringer interface, it's Format without the operations
+func (tr *TransactResult) String() string {
+	return tr.Format(nil)
+}
+
+// Format returns a readable summary of the results, one line per operation for logs and command line output, e.g.
+//
+//	op 0 insert Bridge: uuid 9f3e1d2c-...
+//	op 1 update Interface: count 3
+//	op 2 failed: constraint violation (...)
+//
+// ops are the operations of the transaction, which name the operations in the summary, they may be nil.
+// Results of operations not attempted because a prior one failed, and the error of the commit reported
+// after the results of all operations, are included.
+func (tr *TransactResult) Format(ops []Operation) string {
+	var lines []string
+	for i, result := range tr.Results {
+		prefix := fmt.Sprintf("op %d", i)
+		if i < len(ops) {
+			prefix += " " + string(ops[i].Op())
+			if table := OperationTable(ops[i]); table != "" {
+				prefix += " " + string(table)
+			}
+		} else if len(ops) != 0 {
+			// the error of the transaction as a whole, e.g. a failed commit
+			prefix = "transaction"
+		}
+		switch result := result.(type) {
+		case nil:
+			lines = append(lines, prefix+": not attempted")
+		case *Error:
+			line := prefix + " failed: " + result.Err
+			if result.Details != "" {
+				line += " (" + result.Details + ")"
+			}
+			lines = append(lines, line)
+		case json.RawMessage:
+			if summary := summarizeResult(result); summary != "" {
+				lines = append(lines, prefix+": "+summary)
+			} else {
+				lines = append(lines, prefix+": ok")
+			}
+		default:
+			lines = append(lines, fmt.Sprintf("%s: %v", prefix, result))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// summarizeResult summarizes the members of the result of a successful operation,
+// it's empty for results without members
+func summarizeResult(raw json.RawMessage) string {
+	var result struct {
+		UUID  *UUID             `json:"uuid"`
+		Count *int              `json:"count"`
+		Rows  []json.RawMessage `json:"rows"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return string(raw)
+	}
+	var parts []string
+	if result.UUID != nil {
+		parts = append(parts, "uuid "+string(*result.UUID))
+	}
+	if result.Count != nil {
+		parts = append(parts, fmt.Sprintf("count %d", *result.Count))
+	}
+	if result.Rows != nil {
+		parts = append(parts, fmt.Sprintf("%d rows", len(result.Rows)))
+	}
+	return strings.Join(parts, ", ")
+}
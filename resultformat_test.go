@@ -0,0 +1,52 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTransactResultFormat(t *testing.T) {
+	var result TransactResult
+	err := json.Unmarshal([]byte(`[
+		{"uuid": ["uuid", "9f3e1d2c-0000-0000-0000-000000000001"]},
+		{"count": 3},
+		{"rows": [{"name": "br0"}, {"name": "br1"}]},
+		{},
+		{"error": "constraint violation", "details": "name must be unique"},
+		null,
+		{"error": "aborted", "details": ""}
+	]`), &result)
+	if err != nil {
+		t.Fatalf("invalid result: %v", err)
+	}
+	ops := []Operation{
+		&InsertOperation{Table: "Bridge", Row: map[ID]Value{"name": "br0"}},
+		&UpdateOperation{Table: "Interface", Where: MatchAll(), Row: map[ID]Value{"mtu_request": 1500}},
+		&SelectOperation{Table: "Bridge", Where: MatchAll()},
+		&CommentOperation{Comment: "test"},
+		&InsertOperation{Table: "Bridge", Row: map[ID]Value{"name": "br0"}},
+		&DeleteOperation{Table: "Port", Where: MatchAll()},
+	}
+
+	want := `op 0 insert Bridge: uuid 9f3e1d2c-0000-0000-0000-000000000001
+op 1 update Interface: count 3
+op 2 select Bridge: 2 rows
+op 3 comment: ok
+op 4 insert Bridge failed: constraint violation (name must be unique)
+op 5 delete Port: not attempted
+transaction failed: aborted`
+	if got := result.Format(ops); got != want {
+		t.Errorf("Format returned\n%s\nwant\n%s", got, want)
+	}
+
+	want = `op 0: uuid 9f3e1d2c-0000-0000-0000-000000000001
+op 1: count 3
+op 2: 2 rows
+op 3: ok
+op 4 failed: constraint violation (name must be unique)
+op 5: not attempted
+op 6 failed: aborted`
+	if got := result.String(); got != want {
+		t.Errorf("String returned\n%s\nwant\n%s", got, want)
+	}
+}
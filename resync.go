@@ -0,0 +1,97 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Resync selects all rows of table again and replaces the cached rows of the table with them, e.g. after
+// detecting the cache diverged from the server, without dropping the rest of the cache. It returns the
+// corrections of what the cache held as TableUpdates, which ChangeEvents turns into corrective events:
+// cached rows which changed or no longer exist, cached absences of rows which exist, and rows missing
+// from the cached listing of the table if List cached it.
+func (cache *ReadThroughCache) Resync(table ID) (TableUpdates, error) {
+	now := time.Now()
+	rows, err := cache.selectRows(table, MatchAll())
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[UUID]cachedRow, len(rows))
+	listing := cachedTable{expires: now.Add(cache.ttl)}
+	for _, row := range rows {
+		uuid, err := rowUUID(row)
+		if err != nil {
+			return nil, err
+		}
+		current[uuid] = cachedRow{row: row, expires: listing.expires}
+		listing.uuids = append(listing.uuids, uuid)
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	corrections := TableUpdate{}
+	for uuid, cached := range cache.rows[table] {
+		row, exists := current[uuid]
+		switch {
+		case cached.row == nil && exists:
+			corrections[uuid] = RowUpdate{New: rowUpdateRow(row.row)}
+		case cached.row != nil && !exists:
+			corrections[uuid] = RowUpdate{Old: rowUpdateRow(cached.row)}
+		case cached.row != nil && !sameRow(cached.row, row.row):
+			corrections[uuid] = RowUpdate{Old: rowUpdateRow(cached.row), New: rowUpdateRow(row.row)}
+		}
+	}
+	if cached, ok := cache.tables[table]; ok {
+		listed := make(map[UUID]bool, len(cached.uuids))
+		for _, uuid := range cached.uuids {
+			listed[uuid] = true
+		}
+		for uuid, row := range current {
+			if _, ok := cache.rows[table][uuid]; !ok && !listed[uuid] {
+				corrections[uuid] = RowUpdate{New: rowUpdateRow(row.row)}
+			}
+		}
+	}
+
+	// rows which still exist stay cached, so store keeps the revisions of the unchanged ones
+	for uuid := range cache.rows[table] {
+		if _, exists := current[uuid]; !exists {
+			cache.remove(table, uuid)
+		}
+	}
+	delete(cache.tables, table)
+	if cache.store(table, current) {
+		cache.tables[table] = listing
+	} else {
+		// the rows of the server are denied by the size limit, the stale ones are dropped anyway
+		for uuid := range cache.rows[table] {
+			cache.remove(table, uuid)
+		}
+	}
+	cache.stats.LastUpdate = now
+	if len(corrections) == 0 {
+		return TableUpdates{}, nil
+	}
+	return TableUpdates{table: corrections}, nil
+}
+
+// rowUpdateRow returns a pointer to a copy of row, for RowUpdates
+func rowUpdateRow(row json.RawMessage) *json.RawMessage {
+	raw := copyRow(row)
+	return &raw
+}
+
+// sameRow returns true if the rows a and b have the same columns with equal values
+func sameRow(a, b json.RawMessage) bool {
+	var columnsA, columnsB map[ID]json.RawMessage
+	if json.Unmarshal(a, &columnsA) != nil || json.Unmarshal(b, &columnsB) != nil || len(columnsA) != len(columnsB) {
+		return false
+	}
+	for column, value := range columnsA {
+		other, ok := columnsB[column]
+		if !ok || !ValueEqual(value, other) {
+			return false
+		}
+	}
+	return true
+}
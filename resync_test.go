@@ -0,0 +1,84 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestReadThroughCacheResync(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	const (
+		br0 = "a0000000-0000-0000-0000-000000000000"
+		br1 = "a0000000-0000-0000-0000-000000000001"
+		br2 = "a0000000-0000-0000-0000-000000000002"
+	)
+	var mu sync.Mutex
+	rows := map[string]string{br0: "br0", br1: "br1"}
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		var selected []interface{}
+		for _, uuid := range []string{br0, br1, br2} {
+			if name, ok := rows[uuid]; ok {
+				selected = append(selected, map[string]interface{}{"_uuid": []string{"uuid", uuid}, "name": name})
+			}
+		}
+		return []interface{}{map[string]interface{}{"rows": selected}}, nil
+	})
+
+	cache := NewReadThroughCache(client, "Open_vSwitch", time.Hour)
+	if _, err := cache.List("Bridge"); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	_, revision0, err := cache.GetWithRevision("Bridge", br0)
+	if err != nil {
+		t.Fatalf("GetWithRevision failed: %v", err)
+	}
+	// changes of another client, not seen by the cache
+	mu.Lock()
+	rows[br0] = "br-renamed"
+	delete(rows, br1)
+	rows[br2] = "br2"
+	mu.Unlock()
+
+	updates, err := cache.Resync("Bridge")
+	if err != nil {
+		t.Fatalf("Resync failed: %v", err)
+	}
+	var got []string
+	for _, event := range ChangeEvents("Open_vSwitch", updates) {
+		got = append(got, string(event.Op)+" "+event.UUID)
+	}
+	want := []string{"modify " + br0, "delete " + br1, "insert " + br2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("corrections = %v, want %v", got, want)
+	}
+	row, err := cache.Get("Bridge", br0)
+	if err != nil || !sameRow(row, json.RawMessage(`{"_uuid":["uuid","`+br0+`"],"name":"br-renamed"}`)) {
+		t.Errorf("Get = %s, %v after Resync", row, err)
+	}
+	if rows, err := cache.List("Bridge"); err != nil || len(rows) != 2 {
+		t.Errorf("List = %s, %v after Resync", rows, err)
+	}
+	_, revision1, _ := cache.GetWithRevision("Bridge", br0)
+	if revision1 <= revision0 {
+		t.Errorf("revision of a corrected row = %d, want greater than %d", revision1, revision0)
+	}
+	if updates, err := cache.Resync("Bridge"); err != nil || len(updates) != 0 {
+		t.Errorf("Resync = %v, %v without divergence", updates, err)
+	}
+	// unchanged rows keep their revisions
+	if _, revision, _ := cache.GetWithRevision("Bridge", br0); revision != revision1 {
+		t.Errorf("revision of an unchanged row = %d after Resync, want %d", revision, revision1)
+	}
+}
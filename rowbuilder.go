@@ -0,0 +1,186 @@
+package ovsdb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RowBuilder builds the row of an insert or update operation and validates column names and values
+// against the table schema as they are set, so mistakes are caught before the server rejects them.
+// Errors are collected and reported by Build, which allows chaining setters:
+//
+//	row, err := NewRowBuilder(schema, "Bridge").Set("name", "br0").SetSet("ports", uuids).Build()
+type RowBuilder struct {
+	table       ID
+	tableSchema *TableSchema
+	row         map[ID]Value
+	errs        []string
+}
+
+// NewRowBuilder creates a RowBuilder for table in dbSchema
+func NewRowBuilder(dbSchema *DatabaseSchema, table ID) *RowBuilder {
+	b := &RowBuilder{table: table, row: make(map[ID]Value)}
+	if dbSchema != nil {
+		b.tableSchema = dbSchema.Tables[table]
+	}
+	if b.tableSchema == nil {
+		b.errorf("table %s not found in schema", table)
+	}
+	return b
+}
+
+// Set sets column to value, which is an atom, or a Set or Map in any form accepted by CanonicalValue.
+// Values of types with a registered Converter are converted, see RegisterConverter.
+func (b *RowBuilder) Set(column ID, value Value) *RowBuilder {
+	b.set(column, value)
+	return b
+}
+
+// SetSet sets the set column to the elements of values, a Go slice or array
+func (b *RowBuilder) SetSet(column ID, values interface{}) *RowBuilder {
+	value, err := ConvertToValue(values)
+	if err != nil {
+		b.errorf("column %s: %v", column, err)
+		return b
+	}
+	if _, ok := value.(Set); !ok {
+		b.errorf("column %s: %T is not a slice or array", column, values)
+		return b
+	}
+	b.set(column, value)
+	return b
+}
+
+// SetMap sets the map column to the pairs of m, a Go map
+func (b *RowBuilder) SetMap(column ID, m interface{}) *RowBuilder {
+	value, err := ConvertToValue(m)
+	if err != nil {
+		b.errorf("column %s: %v", column, err)
+		return b
+	}
+	if _, ok := value.(Map); !ok {
+		b.errorf("column %s: %T is not a map", column, m)
+		return b
+	}
+	b.set(column, value)
+	return b
+}
+
+// Build returns the row, or an error listing every problem found while setting columns
+func (b *RowBuilder) Build() (map[ID]Value, error) {
+	if len(b.errs) != 0 {
+		return nil, fmt.Errorf("invalid row for table %s: %s", b.table, strings.Join(b.errs, "; "))
+	}
+	return b.row, nil
+}
+
+// set validates value against the schema of column and sets it
+func (b *RowBuilder) set(column ID, value Value) {
+	if b.tableSchema == nil {
+		return
+	}
+	columnSchema, ok := b.tableSchema.Columns[column]
+	if !ok {
+		b.errorf("column %s not found", column)
+		return
+	}
+	canonical, err := CanonicalValue(value)
+	if err != nil {
+		b.errorf("column %s: %v", column, err)
+		return
+	}
+	if err := validateColumnValue(columnSchema, canonical); err != nil {
+		b.errorf("column %s: %v", column, err)
+		return
+	}
+	b.row[column] = canonical
+}
+
+func (b *RowBuilder) errorf(format string, args ...interface{}) {
+	b.errs = append(b.errs, fmt.Sprintf(format, args...))
+}
+
+// validateColumnValue checks a canonical value against the type of a column
+func validateColumnValue(columnSchema *ColumnSchema, value Value) error {
+	var n int
+	if columnSchema.IsMap() {
+		m, ok := value.(Map)
+		if !ok {
+			return fmt.Errorf("%v is not a map", value)
+		}
+		for _, pair := range m.Values {
+			if err := validateAtom(columnSchema.Type.JSON.Key, pair[0]); err != nil {
+				return err
+			}
+			if err := validateAtom(columnSchema.Type.JSON.Value, pair[1]); err != nil {
+				return err
+			}
+		}
+		n = len(m.Values)
+	} else {
+		key := columnSchema.Type.JSON.Key
+		if columnSchema.Type.IsAtomic {
+			key = AtomicOrJSONBaseType{IsAtomic: true, Atomic: columnSchema.Type.Atomic}
+		}
+		switch v := value.(type) {
+		case Map:
+			return fmt.Errorf("%v is not a set or an atom", value)
+		case Set:
+			for _, atom := range v.Values {
+				if err := validateAtom(key, atom); err != nil {
+					return err
+				}
+			}
+			n = len(v.Values)
+		default:
+			if err := validateAtom(key, v); err != nil {
+				return err
+			}
+			n = 1
+		}
+	}
+
+	if n < columnSchema.MinElements() {
+		return fmt.Errorf("%d elements, at least %d required", n, columnSchema.MinElements())
+	}
+	if max := columnSchema.MaxElements(); max >= 0 && n > max {
+		return fmt.Errorf("%d elements, at most %d allowed", n, max)
+	}
+	return nil
+}
+
+// validateAtom checks a canonical atom against a base type
+func validateAtom(baseType AtomicOrJSONBaseType, atom Atomic) error {
+	var ok bool
+	switch baseType.Type() {
+	case TypeInteger:
+		_, ok = atom.(int64)
+	case TypeReal:
+		switch atom.(type) {
+		case int64, float64:
+			ok = true
+		}
+	case TypeBoolean:
+		_, ok = atom.(bool)
+	case TypeString:
+		_, ok = atom.(string)
+	case TypeUUID:
+		switch atom.(type) {
+		case UUID, NamedUUID:
+			ok = true
+		}
+	}
+	if !ok {
+		return fmt.Errorf("%#v is not of type %s", atom, baseType.Type())
+	}
+
+	if !baseType.IsAtomic && len(baseType.JSON.Enum.Values) != 0 {
+		for _, allowed := range baseType.JSON.Enum.Values {
+			if ValueEqual(allowed, atom) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%#v is not one of %v", atom, baseType.JSON.Enum.Values)
+	}
+	return nil
+}
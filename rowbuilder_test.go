@@ -0,0 +1,77 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+)
+
+const rowBuilderSchema = `{
+	"name": "Open_vSwitch",
+	"version": "7.15.1",
+	"tables": {
+		"Bridge": {
+			"columns": {
+				"name": {"type": "string", "mutable": false},
+				"ports": {"type": {"key": {"type": "uuid", "refTable": "Port"}, "min": 0, "max": "unlimited"}},
+				"fail_mode": {"type": {"key": {"type": "string", "enum": ["set", ["standalone", "secure"]]}, "min": 0, "max": 1}},
+				"stp_enable": {"type": "boolean"},
+				"external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+			}
+		}
+	}
+}`
+
+func TestRowBuilder(t *testing.T) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(rowBuilderSchema), &dbSchema); err != nil {
+		t.Fatalf("json unmarshal failed: %v", err)
+	}
+	if columnSchema := dbSchema.Tables["Bridge"].Columns["name"]; columnSchema.IsSet() || columnSchema.IsMap() {
+		t.Error("Bridge.name is a set or a map, want an atom")
+	}
+	if columnSchema := dbSchema.Tables["Bridge"].Columns["ports"]; !columnSchema.IsSet() || columnSchema.MaxElements() != -1 {
+		t.Error("Bridge.ports is not an unlimited set")
+	}
+
+	uuids := []UUID{"a0000000-0000-0000-0000-000000000000", "b0000000-0000-0000-0000-000000000000"}
+	row, err := NewRowBuilder(&dbSchema, "Bridge").
+		Set("name", "br0").
+		Set("fail_mode", "secure").
+		SetSet("ports", uuids).
+		SetMap("external_ids", map[string]net.IP{"gw": net.ParseIP("10.0.0.1")}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	bytes, err := json.Marshal(row)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	want := `{"external_ids":["map",[["gw","10.0.0.1"]]],"fail_mode":"secure","name":"br0",` +
+		`"ports":["set",[["uuid","a0000000-0000-0000-0000-000000000000"],["uuid","b0000000-0000-0000-0000-000000000000"]]]}`
+	if string(bytes) != want {
+		t.Errorf("row = %s, want %s", bytes, want)
+	}
+
+	_, err = NewRowBuilder(&dbSchema, "Bridge").
+		Set("nmae", "br0").
+		Set("stp_enable", "yes").
+		Set("fail_mode", "open").
+		SetSet("name", []string{"a", "b"}).
+		SetMap("ports", map[string]string{}).
+		Build()
+	if err == nil {
+		t.Fatal("expect Build failed, but got nil")
+	}
+	for _, column := range []string{"nmae", "stp_enable", "fail_mode", "name", "ports"} {
+		if !strings.Contains(err.Error(), "column "+column) {
+			t.Errorf("Build error %q doesn't report column %s", err, column)
+		}
+	}
+
+	if _, err := NewRowBuilder(&dbSchema, "Port").Set("name", "p0").Build(); err == nil {
+		t.Error("expect Build failed for unknown table, but got nil")
+	}
+}
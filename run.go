@@ -0,0 +1,31 @@
+package ovsdb
+
+import "context"
+
+// Run blocks until the connection terminates and returns the cause, e.g. a *MessageSizeError or the error
+// of a lost connection, nil if the client was closed with Close. If ctx is done first, the client is closed
+// and ctx.Err() is returned. It's designed to be run in an errgroup alongside cache sync and reconcile loops,
+// so a lost connection cancels them and the group returns its cause:
+//
+//	g, ctx := errgroup.WithContext(ctx)
+//	g.Go(func() error { return client.Run(ctx) })
+//	g.Go(func() error { return reconcile(ctx, client) })
+//	err := g.Wait()
+//
+// The connection is served whether Run is called or not, Run only ties its lifetime to ctx.
+func (c *Client) Run(ctx context.Context) error {
+	select {
+	case <-c.closed:
+	case <-ctx.Done():
+		c.Close()
+		return ctx.Err()
+	}
+	return c.closeError()
+}
+
+// closeError returns the cause of the change to StateClosed
+func (c *Client) closeError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closeErr
+}
@@ -0,0 +1,55 @@
+package ovsdb
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestRun(t *testing.T) {
+	newClient := func() (*Client, *ovsdbtest.Server) {
+		conn, serverConn := net.Pipe()
+		return NewClient(conn), ovsdbtest.NewServer(serverConn)
+	}
+	run := func(ctx context.Context, client *Client) <-chan error {
+		errs := make(chan error, 1)
+		go func() { errs <- client.Run(ctx) }()
+		return errs
+	}
+	wait := func(errs <-chan error, want error, cause string) {
+		select {
+		case err := <-errs:
+			if err != want {
+				t.Errorf("Run returned %v after %s, want %v", err, cause, want)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("Run not returned after %s", cause)
+		}
+	}
+
+	client, server := newClient()
+	errs := run(context.Background(), client)
+	server.Close()
+	wait(errs, errDisconnected, "a lost connection")
+
+	client, server = newClient()
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	errs = run(ctx, client)
+	cancel()
+	wait(errs, context.Canceled, "canceling ctx")
+	if state := client.State(); state != StateClosed {
+		t.Errorf("State = %v after canceling Run, want %v", state, StateClosed)
+	}
+
+	client, server = newClient()
+	defer server.Close()
+	errs = run(context.Background(), client)
+	client.Close()
+	wait(errs, nil, "Close")
+	// Run returns at once on a closed client
+	wait(run(context.Background(), client), nil, "Close")
+}
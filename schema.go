@@ -18,6 +18,39 @@ type DatabaseSchema struct {
 	Tables map[ID]*TableSchema `json:"tables"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler
+// It validates the version of the schema, and the names of the database, its tables and columns (see ValidateIDs).
+func (dbSchema *DatabaseSchema) UnmarshalJSON(value []byte) error {
+	type aliasDatabaseSchema DatabaseSchema
+	var alias aliasDatabaseSchema
+	if err := json.Unmarshal(value, &alias); err != nil {
+		return err
+	}
+	if len(alias.Version) != 0 {
+		if _, err := alias.Version.Parse(); err != nil {
+			return err
+		}
+	}
+	if err := validateID("database name", alias.Name); err != nil {
+		return err
+	}
+	for table, tableSchema := range alias.Tables {
+		if err := validateID("table name", table); err != nil {
+			return err
+		}
+		if tableSchema == nil {
+			continue
+		}
+		for column := range tableSchema.Columns {
+			if err := validateID(fmt.Sprintf("column name of table %s", table), column); err != nil {
+				return err
+			}
+		}
+	}
+	*dbSchema = DatabaseSchema(alias)
+	return nil
+}
+
 // ColumnSet is an array of one or more strings,each of which names a column.
 // Each Columnset is a set of columns whose values, taken together within any given row, must be
 // unique within the table
@@ -57,6 +90,51 @@ func (cs *ColumnSchema) UnmarshalJSON(value []byte) error {
 	return nil
 }
 
+// KeyType returns the atomic type of the column's values, or of the keys of a map column
+func (cs *ColumnSchema) KeyType() AtomicType {
+	if cs.Type.IsAtomic {
+		return cs.Type.Atomic
+	}
+	return cs.Type.JSON.Key.Type()
+}
+
+// ValueType returns the atomic type of the values of a map column, it's empty for other columns
+func (cs *ColumnSchema) ValueType() AtomicType {
+	if cs.Type.IsAtomic {
+		return ""
+	}
+	return cs.Type.JSON.Value.Type()
+}
+
+// IsMap returns true if the column is a map
+func (cs *ColumnSchema) IsMap() bool {
+	return cs.ValueType() != ""
+}
+
+// IsSet returns true if the column is a set, i.e. not a map nor a column holding exactly one atom
+func (cs *ColumnSchema) IsSet() bool {
+	return !cs.IsMap() && (cs.MinElements() != 1 || cs.MaxElements() != 1)
+}
+
+// MinElements returns the minimum number of elements of the column's value
+func (cs *ColumnSchema) MinElements() int {
+	if cs.Type.IsAtomic {
+		return 1
+	}
+	return cs.Type.JSON.Min
+}
+
+// MaxElements returns the maximum number of elements of the column's value, -1 means unlimited
+func (cs *ColumnSchema) MaxElements() int {
+	if cs.Type.IsAtomic {
+		return 1
+	}
+	if !cs.Type.JSON.Max.IsInt {
+		return -1
+	}
+	return cs.Type.JSON.Max.Int
+}
+
 // AtomicOrJSONColumnType is the type of a database column.  Either an <atomic-type> or a JSON
 // object that describes the type of a database column
 type AtomicOrJSONColumnType struct {
@@ -78,6 +156,15 @@ func (atomjson *AtomicOrJSONColumnType) UnmarshalJSON(value []byte) error {
 // AtomicType is one of the strings "integer", "real", "boolean", "string", or "uuid", representing the specified scalar type.
 type AtomicType string
 
+// Supported AtomicTypes
+const (
+	TypeInteger AtomicType = "integer"
+	TypeReal    AtomicType = "real"
+	TypeBoolean AtomicType = "boolean"
+	TypeString  AtomicType = "string"
+	TypeUUID    AtomicType = "uuid"
+)
+
 // JSONColumnType is a JSON object that describes the type of a database column
 type JSONColumnType struct {
 	Key   AtomicOrJSONBaseType `json:"key"`
@@ -86,6 +173,21 @@ type JSONColumnType struct {
 	Max   IntOrString          `json:"max,omitempty"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler
+// Both min and max default to 1 if not specified
+func (ct *JSONColumnType) UnmarshalJSON(value []byte) error {
+	type aliasJSONColumnType JSONColumnType
+	alias := aliasJSONColumnType{
+		Min: 1,
+		Max: IntOrString{IsInt: true, Int: 1},
+	}
+	if err := json.Unmarshal(value, &alias); err != nil {
+		return err
+	}
+	*ct = JSONColumnType(alias)
+	return nil
+}
+
 // IntOrString is a type that can hold an int or a string.  When used in
 // JSON or YAML marshalling and unmarshalling, it produces or consumes the
 // inner type.  This allows you to have, for example, a JSON field that can
@@ -124,6 +226,14 @@ func (atomjson *AtomicOrJSONBaseType) UnmarshalJSON(value []byte) error {
 	return json.Unmarshal(value, &atomjson.JSON)
 }
 
+// Type returns the atomic type of the key or value
+func (atomjson AtomicOrJSONBaseType) Type() AtomicType {
+	if atomjson.IsAtomic {
+		return atomjson.Atomic
+	}
+	return atomjson.JSON.Type
+}
+
 // JSONBaseType is a JSON object that describes the type of key or value
 type JSONBaseType struct {
 	Type       AtomicType `json:"type"`
@@ -0,0 +1,39 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDatabaseSchemaUnmarshal(t *testing.T) {
+	tests := []struct {
+		jsonStr string
+		ok      bool
+	}{
+		{`{"name":"TestDB","version":"1.0.0","tables":{"TestTable":{"columns":{"name":{"type":"string"}}}}}`, true},
+		{`{"name":"Test-DB","version":"1.0.0","tables":{}}`, false},
+		{`{"name":"TestDB","version":"1.0.0","tables":{"Test Table":{"columns":{}}}}`, false},
+		{`{"name":"TestDB","version":"1.0.0","tables":{"TestTable":{"columns":{"1name":{"type":"string"}}}}}`, false},
+		{`{"name":"TestDB","version":"1.0","tables":{}}`, false},
+		{`{"name":"TestDB","version":"v1.0.0","tables":{}}`, false},
+	}
+
+	for _, test := range tests {
+		var dbSchema DatabaseSchema
+		err := json.Unmarshal([]byte(test.jsonStr), &dbSchema)
+		if test.ok && err != nil {
+			t.Errorf("Error during unmarshal: %v", err)
+		}
+		if !test.ok && err == nil {
+			t.Errorf("Expect error for %s, got nil", test.jsonStr)
+		}
+	}
+
+	// escape hatch
+	ValidateIDs = false
+	defer func() { ValidateIDs = true }()
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(`{"name":"Test-DB","version":"1.0.0","tables":{}}`), &dbSchema); err != nil {
+		t.Errorf("Error during unmarshal with ValidateIDs off: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// SelectSpec describes the select of one table in MultiSelect
+type SelectSpec struct {
+	// Where are the conditions rows must match, all rows are selected if it's empty
+	Where []Condition
+	// Columns are the selected columns, all columns are selected if it's empty
+	Columns []ID
+	// Rows must be a pointer to a slice the selected rows are decoded into with encoding/json,
+	// e.g. *[]map[ID]interface{} or a pointer to a slice of a struct type of the table
+	Rows interface{}
+}
+
+// MultiSelect selects rows of several tables of database db in a single transaction and decodes
+// each table's rows into the Rows of its SelectSpec, so related tables are read in one round trip
+// and from the same database snapshot.
+func (c *Client) MultiSelect(db ID, specs map[ID]SelectSpec) error {
+	tables, ops, err := selectOperations(specs)
+	if err != nil {
+		return err
+	}
+	result, err := c.Transact(db, ops...)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) != 0 {
+		return result.Errors
+	}
+	return decodeSelectResults(tables, specs, result.Results)
+}
+
+// selectOperations returns the select operations of specs and the table of each operation, sorted by table name
+func selectOperations(specs map[ID]SelectSpec) ([]ID, []Operation, error) {
+	var tables []ID
+	for table, spec := range specs {
+		if spec.Rows == nil {
+			return nil, nil, fmt.Errorf("no Rows to decode table %s into", table)
+		}
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i] < tables[j] })
+
+	var ops []Operation
+	for _, table := range tables {
+		spec := specs[table]
+		where := spec.Where
+		if len(where) == 0 {
+			where = MatchAll()
+		}
+		ops = append(ops, &SelectOperation{Table: table, Where: where, Columns: spec.Columns})
+	}
+	return tables, ops, nil
+}
+
+// decodeSelectResults decodes the rows of each select result into the Rows of the spec of its table
+func decodeSelectResults(tables []ID, specs map[ID]SelectSpec, results []interface{}) error {
+	if len(results) < len(tables) {
+		return fmt.Errorf("got %d results for %d select operations", len(results), len(tables))
+	}
+	for i, table := range tables {
+		raw, ok := results[i].(json.RawMessage)
+		if !ok {
+			return fmt.Errorf("unexpected select result of table %s: %v", table, results[i])
+		}
+		var selected struct {
+			Rows json.RawMessage `json:"rows"`
+		}
+		if err := json.Unmarshal(raw, &selected); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(selected.Rows, specs[table].Rows); err != nil {
+			return fmt.Errorf("failed to decode rows of table %s: %v", table, err)
+		}
+	}
+	return nil
+}
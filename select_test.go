@@ -0,0 +1,53 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMultiSelectDecode(t *testing.T) {
+	var bridges []struct {
+		Name string `json:"name"`
+	}
+	var ports []map[ID]interface{}
+	specs := map[ID]SelectSpec{
+		"Port":   {Columns: []ID{"name"}, Rows: &ports},
+		"Bridge": {Where: []Condition{{"name", FuncEq, "br0"}}, Rows: &bridges},
+	}
+
+	tables, ops, err := selectOperations(specs)
+	if err != nil {
+		t.Fatalf("selectOperations failed: %v", err)
+	}
+	if want := []ID{"Bridge", "Port"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("tables = %v, want %v", tables, want)
+	}
+	bytes, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	want := `[{"op":"select","table":"Bridge","where":[["name","==","br0"]]},` +
+		`{"op":"select","table":"Port","where":[["_uuid","!=",["uuid","00000000-0000-0000-0000-000000000000"]]],"columns":["name"]}]`
+	if string(bytes) != want {
+		t.Errorf("operations = %s, want %s", bytes, want)
+	}
+
+	results := []interface{}{
+		json.RawMessage(`{"rows":[{"name":"br0"}]}`),
+		json.RawMessage(`{"rows":[{"name":"p0"},{"name":"p1"}]}`),
+	}
+	if err := decodeSelectResults(tables, specs, results); err != nil {
+		t.Fatalf("decodeSelectResults failed: %v", err)
+	}
+	if len(bridges) != 1 || bridges[0].Name != "br0" {
+		t.Errorf("bridges = %v", bridges)
+	}
+	if len(ports) != 2 || ports[1]["name"] != "p1" {
+		t.Errorf("ports = %v", ports)
+	}
+
+	if _, _, err := selectOperations(map[ID]SelectSpec{"Bridge": {}}); err == nil {
+		t.Error("expect selectOperations failed without Rows, but got nil")
+	}
+}
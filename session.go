@@ -0,0 +1,148 @@
+package ovsdb
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ClientSession is a lightweight view of a Client bound to one database, for programs talking to several
+// databases of a server, e.g. OVN_Northbound and OVN_Southbound, over a shared connection.
+// A session caches the schema of its database and has its own notification handler, which receives the
+// updates of the monitors created with the session, instead of the handler of the Client.
+// Lock notifications are not related to databases, they still go to the handler of the Client.
+type ClientSession struct {
+	client *Client
+	db     ID
+
+	mu       sync.Mutex
+	schema   *DatabaseSchema
+	handler  NotificationHandler
+	monitors map[string]string
+}
+
+// Database creates a ClientSession of database db, sessions are independent of each other
+func (c *Client) Database(db ID) *ClientSession {
+	return &ClientSession{
+		client:   c,
+		db:       db,
+		handler:  &defaultNotificationHandler,
+		monitors: make(map[string]string),
+	}
+}
+
+// Name returns the name of the database of the session
+func (s *ClientSession) Name() ID {
+	return s.db
+}
+
+// Client returns the Client of the session
+func (s *ClientSession) Client() *Client {
+	return s.client
+}
+
+// Schema returns the schema of the database, it's only got from the server on the first call
+// or the first call after InvalidateSchema
+func (s *ClientSession) Schema() (*DatabaseSchema, error) {
+	s.mu.Lock()
+	schema := s.schema
+	s.mu.Unlock()
+	if schema != nil {
+		return schema, nil
+	}
+	schema, err := s.client.GetSchema(s.db)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.schema = schema
+	s.mu.Unlock()
+	return schema, nil
+}
+
+// InvalidateSchema drops the cached schema, e.g. after the database was converted
+func (s *ClientSession) InvalidateSchema() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schema = nil
+}
+
+// Transact do operations as a transaction on the database
+func (s *ClientSession) Transact(ops ...Operation) (*TransactResult, error) {
+	return s.client.Transact(s.db, ops...)
+}
+
+// SetNotificationHandler sets handler as the notification handler of the session,
+// its Update method receives the updates of the monitors of the session, and its MonitorCanceled method,
+// if it implements MonitorCanceledHandler, the cancellation of them. Locked and Stolen are never called.
+func (s *ClientSession) SetNotificationHandler(handler NotificationHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = handler
+}
+
+// notificationHandler returns the notification handler of the session
+func (s *ClientSession) notificationHandler() NotificationHandler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handler
+}
+
+// Monitor monitors tables of the database, like Client.Monitor, but updates are sent to the notification
+// handler of the session with jsonValue, which must be unique among the monitors of the session
+func (s *ClientSession) Monitor(jsonValue Value, requests MonitorRequests) (TableUpdates, error) {
+	key, err := monitorKey(jsonValue)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if _, ok := s.monitors[key]; ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("duplicate monitor %s of database %s", key, s.db)
+	}
+	monitorID := s.client.watchMonitor(func(updates TableUpdates) {
+		s.notificationHandler().Update(jsonValue, updates)
+	})
+	s.monitors[key] = monitorID
+	s.mu.Unlock()
+
+	s.client.watchMonitorCanceled(monitorID, func() {
+		s.forget(key)
+		if handler, ok := s.notificationHandler().(MonitorCanceledHandler); ok {
+			handler.MonitorCanceled(jsonValue)
+		}
+	})
+	updates, err := s.client.Monitor(s.db, monitorID, requests)
+	if err != nil {
+		s.forget(key)
+		return nil, err
+	}
+	return updates, nil
+}
+
+// MonitorCancel cancels a monitor created by Monitor of the session
+func (s *ClientSession) MonitorCancel(jsonValue Value) error {
+	key, err := monitorKey(jsonValue)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	monitorID, ok := s.monitors[key]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown monitor %s of database %s", key, s.db)
+	}
+	err = s.client.MonitorCancel(monitorID)
+	s.forget(key)
+	return err
+}
+
+// forget stops routing the updates of monitor key to the session
+func (s *ClientSession) forget(key string) {
+	s.mu.Lock()
+	monitorID, ok := s.monitors[key]
+	delete(s.monitors, key)
+	s.mu.Unlock()
+	if ok {
+		s.client.unwatchMonitor(monitorID)
+	}
+}
@@ -0,0 +1,112 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestClientSession(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	schemaRequests := make(map[string]int)
+	server.Handle("get_schema", func(params []json.RawMessage) (interface{}, error) {
+		var db string
+		json.Unmarshal(params[0], &db)
+		schemaRequests[db]++
+		return map[string]interface{}{"name": db, "version": "1.0.0", "tables": map[string]interface{}{}}, nil
+	})
+	monitors := make(map[string]string)
+	server.Handle("monitor", func(params []json.RawMessage) (interface{}, error) {
+		var db, monitorID string
+		json.Unmarshal(params[0], &db)
+		json.Unmarshal(params[1], &monitorID)
+		monitors[db] = monitorID
+		return map[string]interface{}{}, nil
+	})
+	server.Handle("monitor_cancel", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+
+	type update struct {
+		jsonValue Value
+		tables    []ID
+	}
+	sessionHandler := func(updates chan update) *NotificationHandlerFuncs {
+		return &NotificationHandlerFuncs{UpdateFunc: func(jsonValue Value, tableUpdates TableUpdates) error {
+			var tables []ID
+			for table := range tableUpdates {
+				tables = append(tables, table)
+			}
+			updates <- update{jsonValue, tables}
+			return nil
+		}}
+	}
+	nbUpdates, sbUpdates := make(chan update, 1), make(chan update, 1)
+	nb, sb := client.Database("OVN_Northbound"), client.Database("OVN_Southbound")
+	nb.SetNotificationHandler(sessionHandler(nbUpdates))
+	sb.SetNotificationHandler(sessionHandler(sbUpdates))
+	client.SetNotificationHandler(&NotificationHandlerFuncs{UpdateFunc: func(jsonValue Value, updates TableUpdates) error {
+		t.Errorf("client handler got updates of %v", jsonValue)
+		return nil
+	}})
+
+	for i := 0; i < 2; i++ {
+		for _, session := range []*ClientSession{nb, sb} {
+			schema, err := session.Schema()
+			if err != nil {
+				t.Fatalf("Schema failed: %v", err)
+			}
+			if schema.Name != session.Name() {
+				t.Errorf("got schema of %s for session of %s", schema.Name, session.Name())
+			}
+		}
+	}
+	if schemaRequests["OVN_Northbound"] != 1 || schemaRequests["OVN_Southbound"] != 1 {
+		t.Errorf("schemas requested %v times, want once per database", schemaRequests)
+	}
+
+	// both sessions use the same <json-value>
+	if _, err := nb.Monitor("controller", MonitorRequests{"Logical_Switch": {}}); err != nil {
+		t.Fatalf("Monitor failed: %v", err)
+	}
+	if _, err := sb.Monitor("controller", MonitorRequests{"Chassis": {}}); err != nil {
+		t.Fatalf("Monitor failed: %v", err)
+	}
+	if _, err := nb.Monitor("controller", MonitorRequests{"Logical_Switch": {}}); err == nil {
+		t.Error("duplicate monitor of a session should fail")
+	}
+	row := map[string]interface{}{"new": map[string]interface{}{"name": "x"}}
+	server.Notify("update", monitors["OVN_Northbound"], map[string]interface{}{
+		"Logical_Switch": map[string]interface{}{"a0000000-0000-0000-0000-000000000000": row},
+	})
+	server.Notify("update", monitors["OVN_Southbound"], map[string]interface{}{
+		"Chassis": map[string]interface{}{"c0000000-0000-0000-0000-000000000000": row},
+	})
+	for _, expected := range []struct {
+		updates chan update
+		table   ID
+	}{{nbUpdates, "Logical_Switch"}, {sbUpdates, "Chassis"}} {
+		select {
+		case got := <-expected.updates:
+			if got.jsonValue != "controller" || len(got.tables) != 1 || got.tables[0] != expected.table {
+				t.Errorf("got update %v, want one of %s for controller", got, expected.table)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no update of %s", expected.table)
+		}
+	}
+
+	if err := nb.MonitorCancel("controller"); err != nil {
+		t.Errorf("MonitorCancel failed: %v", err)
+	}
+	if err := nb.MonitorCancel("controller"); err == nil {
+		t.Error("canceling an unknown monitor should fail")
+	}
+}
@@ -0,0 +1,143 @@
+package ovsdb
+
+import "sort"
+
+// SessionResetReason is the reason of a SessionReset
+type SessionResetReason int
+
+// Supported SessionResetReasons
+const (
+	// ResetDisconnected is the loss of the connection, e.g. when ovsdb-server restarts,
+	// all monitors and locks of the client are gone
+	ResetDisconnected SessionResetReason = iota
+	// ResetMonitorCanceled is a monitor canceled by the server, e.g. when its database is removed or converted
+	ResetMonitorCanceled
+	// ResetLockStolen is a lock held by the client stolen by another client
+	ResetLockStolen
+)
+
+// String implements fmt.Stringer interface
+func (r SessionResetReason) String() string {
+	switch r {
+	case ResetDisconnected:
+		return "Disconnected"
+	case ResetMonitorCanceled:
+		return "MonitorCanceled"
+	case ResetLockStolen:
+		return "LockStolen"
+	}
+	return "Unknown"
+}
+
+// SessionReset is the event of monitors or locks of the client being lost on the server side.
+// Caches seeded by the lost monitors don't receive updates anymore and must be re-seeded,
+// e.g. with a new monitor on a new connection, rather than silently serving stale data.
+type SessionReset struct {
+	Reason SessionResetReason
+	// Monitors are the <json-value>s of the lost monitors, Locks the names of the lost locks
+	Monitors []Value
+	Locks    []ID
+	// Err is the cause of a lost connection
+	Err error
+}
+
+// SessionResetFunc is invoked on a SessionReset
+type SessionResetFunc func(reset SessionReset)
+
+// OnSessionReset registers fn to be invoked when monitors or locks of the client are lost.
+// The client tracks the monitors created with Monitor and MonitorCond until they are canceled with
+// MonitorCancel, and the locks acquired with Lock or Steal until they are released with Unlock.
+// A lost connection resets the session only if monitors or locks were active, not after Close.
+func (c *Client) OnSessionReset(fn SessionResetFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessionResetFuncs = append(c.sessionResetFuncs, fn)
+}
+
+// trackMonitor records the monitor jsonValue as active, or not if active is false
+func (c *Client) trackMonitor(jsonValue Value, active bool) {
+	// a monitor id which can't be encoded fails the monitor request
+	key, _ := monitorKey(jsonValue)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !active {
+		delete(c.sessionMonitors, key)
+		return
+	}
+	if c.sessionMonitors == nil {
+		c.sessionMonitors = make(map[string]Value)
+	}
+	c.sessionMonitors[key] = jsonValue
+}
+
+// trackLock records lock as held, or not if held is false
+func (c *Client) trackLock(lock ID, held bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !held {
+		delete(c.sessionLocks, lock)
+		return
+	}
+	if c.sessionLocks == nil {
+		c.sessionLocks = make(map[ID]bool)
+	}
+	c.sessionLocks[lock] = true
+}
+
+// monitorCanceled resets the session after the server canceled the monitor jsonValue
+func (c *Client) monitorCanceled(jsonValue Value) {
+	key, _ := monitorKey(jsonValue)
+	c.mu.Lock()
+	_, active := c.sessionMonitors[key]
+	delete(c.sessionMonitors, key)
+	c.mu.Unlock()
+	if active {
+		c.resetSession(SessionReset{Reason: ResetMonitorCanceled, Monitors: []Value{jsonValue}})
+	}
+}
+
+// lockStolen resets the session after lock was stolen
+func (c *Client) lockStolen(lock ID) {
+	c.mu.Lock()
+	held := c.sessionLocks[lock]
+	delete(c.sessionLocks, lock)
+	c.mu.Unlock()
+	if held {
+		c.resetSession(SessionReset{Reason: ResetLockStolen, Locks: []ID{lock}})
+	}
+}
+
+// disconnected resets the session after the connection was lost with err
+func (c *Client) disconnected(err error) {
+	reset := SessionReset{Reason: ResetDisconnected, Err: err}
+	var keys []string
+	c.mu.Lock()
+	for key := range c.sessionMonitors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		reset.Monitors = append(reset.Monitors, c.sessionMonitors[key])
+	}
+	for lock := range c.sessionLocks {
+		reset.Locks = append(reset.Locks, lock)
+	}
+	c.sessionMonitors = nil
+	c.sessionLocks = nil
+	c.mu.Unlock()
+	if len(reset.Monitors) == 0 && len(reset.Locks) == 0 {
+		return
+	}
+	sort.Slice(reset.Locks, func(i, j int) bool { return reset.Locks[i] < reset.Locks[j] })
+	c.resetSession(reset)
+}
+
+// resetSession invokes the functions registered with OnSessionReset
+func (c *Client) resetSession(reset SessionReset) {
+	c.mu.Lock()
+	fns := c.sessionResetFuncs
+	c.mu.Unlock()
+	for _, fn := range fns {
+		fn(reset)
+	}
+}
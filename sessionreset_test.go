@@ -0,0 +1,93 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestSessionReset(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	server.Handle("monitor", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+	server.Handle("lock", func(params []json.RawMessage) (interface{}, error) {
+		return LockResult{Locked: true}, nil
+	})
+	for _, method := range []string{"monitor_cancel", "unlock"} {
+		server.Handle(method, func(params []json.RawMessage) (interface{}, error) {
+			return map[string]interface{}{}, nil
+		})
+	}
+	client := NewClient(conn)
+	resets := make(chan SessionReset, 4)
+	client.OnSessionReset(func(reset SessionReset) {
+		resets <- reset
+	})
+	expect := func(want SessionReset) {
+		t.Helper()
+		select {
+		case got := <-resets:
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("session reset = %+v, want %+v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("session reset %v not received", want.Reason)
+		}
+	}
+
+	for _, id := range []string{"m1", "m2", "m3"} {
+		if _, err := client.Monitor("Open_vSwitch", id, MonitorRequests{}); err != nil {
+			t.Fatalf("Monitor failed: %v", err)
+		}
+	}
+	if locked, err := client.Lock("l1"); err != nil || !locked {
+		t.Fatalf("Lock returned %v, %v", locked, err)
+	}
+	if locked, err := client.Lock("l2"); err != nil || !locked {
+		t.Fatalf("Lock returned %v, %v", locked, err)
+	}
+
+	server.Notify("monitor_canceled", "m1")
+	expect(SessionReset{Reason: ResetMonitorCanceled, Monitors: []Value{"m1"}})
+	server.Notify("stolen", "l1")
+	expect(SessionReset{Reason: ResetLockStolen, Locks: []ID{"l1"}})
+	// canceled monitors and released locks are not lost
+	if err := client.MonitorCancel("m3"); err != nil {
+		t.Fatalf("MonitorCancel failed: %v", err)
+	}
+	if err := client.Unlock("l2"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if _, err := client.Lock("l3"); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	server.Close()
+	expect(SessionReset{Reason: ResetDisconnected, Monitors: []Value{"m2"}, Locks: []ID{"l3"}, Err: errDisconnected})
+
+	// closing the client doesn't reset the session
+	conn, serverConn = net.Pipe()
+	server = ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	server.Handle("monitor", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+	client = NewClient(conn)
+	client.OnSessionReset(func(reset SessionReset) {
+		resets <- reset
+	})
+	if _, err := client.Monitor("Open_vSwitch", "m1", MonitorRequests{}); err != nil {
+		t.Fatalf("Monitor failed: %v", err)
+	}
+	client.Close()
+	select {
+	case reset := <-resets:
+		t.Errorf("session reset %+v after Close", reset)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
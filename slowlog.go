@@ -0,0 +1,49 @@
+package ovsdb
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// CallTimer returns an Interceptor which reports the wall time of every RPC to observe
+func CallTimer(observe func(method string, duration time.Duration, err error)) Interceptor {
+	return func(next CallFunc) CallFunc {
+		return func(ctx context.Context, method string, args interface{}, reply interface{}) error {
+			start := time.Now()
+			err := next(ctx, method, args, reply)
+			observe(method, time.Since(start), err)
+			return err
+		}
+	}
+}
+
+// SlowTransactionLog returns a TransactHook which logs transactions taking longer than threshold,
+// with the types and tables of their operations but not the values.
+// Logs are written with logf, or log.Printf if logf is nil.
+func SlowTransactionLog(threshold time.Duration, logf func(format string, args ...interface{})) TransactHook {
+	if logf == nil {
+		logf = log.Printf
+	}
+	return func(db ID, ops []Operation, result *TransactResult, duration time.Duration, err error) {
+		if duration <= threshold {
+			return
+		}
+		logf("ovsdb: slow transaction on %s took %v (threshold %v): %s", db, duration, threshold, SummarizeOperations(ops))
+	}
+}
+
+// SummarizeOperations describes ops by their types and tables, e.g. "insert Bridge, mutate Open_vSwitch".
+// Values are left out, so the summary is safe to log.
+func SummarizeOperations(ops []Operation) string {
+	summaries := make([]string, 0, len(ops))
+	for _, op := range ops {
+		summary := string(op.Op())
+		if table := OperationTable(op); table != "" {
+			summary += " " + string(table)
+		}
+		summaries = append(summaries, summary)
+	}
+	return strings.Join(summaries, ", ")
+}
@@ -0,0 +1,48 @@
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSlowTransactionLog(t *testing.T) {
+	var logs []string
+	logf := func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	}
+	hook := SlowTransactionLog(time.Second, logf)
+	ops := []Operation{
+		&InsertOperation{Table: "Bridge", Row: map[ID]Value{"name": "secret"}},
+		&MutateOperation{Table: "Open_vSwitch", Where: MatchAll(), Mutations: []Mutation{{"bridges", MutatorInsert, NamedUUID("br")}}},
+	}
+
+	hook("Open_vSwitch", ops, &TransactResult{}, time.Millisecond, nil)
+	if len(logs) != 0 {
+		t.Fatalf("fast transaction logged: %v", logs)
+	}
+	hook("Open_vSwitch", ops, &TransactResult{}, 2*time.Second, nil)
+	want := "ovsdb: slow transaction on Open_vSwitch took 2s (threshold 1s): insert Bridge, mutate Open_vSwitch"
+	if len(logs) != 1 || logs[0] != want {
+		t.Errorf("logs = %q, want [%q]", logs, want)
+	}
+}
+
+func TestCallTimer(t *testing.T) {
+	errCall := errors.New("call failed")
+	var observed []string
+	timer := CallTimer(func(method string, duration time.Duration, err error) {
+		observed = append(observed, fmt.Sprintf("%s %v", method, err))
+	})
+	call := timer(func(ctx context.Context, method string, args interface{}, reply interface{}) error {
+		return errCall
+	})
+	if err := call(context.Background(), "transact", nil, nil); err != errCall {
+		t.Errorf("call returned %v, want %v", err, errCall)
+	}
+	if len(observed) != 1 || observed[0] != "transact call failed" {
+		t.Errorf("observed %v, want [transact call failed]", observed)
+	}
+}
@@ -0,0 +1,79 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"sort"
+	"sync/atomic"
+)
+
+// DBView is an immutable snapshot of the rows of a ReadThroughCache, keyed by their "_uuid",
+// see ReadThroughCache.Snapshot. It's safe for concurrent use without locking.
+type DBView struct {
+	generation uint64
+	rows       map[ID]map[UUID]json.RawMessage
+}
+
+// Snapshot returns a view of the rows cached now, for read-heavy request handlers serving many lookups
+// without taking the lock of the cache for every read. The view is built on the first call after the cached
+// rows change and atomically swapped, other calls are wait-free and return the same view.
+// Rows are in the view regardless of their TTL, and the view never selects rows from the server:
+// use Get or List to read rows which are not cached. Like with UnsafeGet, the rows are shared,
+// they must not be modified.
+func (cache *ReadThroughCache) Snapshot() *DBView {
+	if view, ok := cache.snapshot.Load().(*DBView); ok && view.generation == atomic.LoadUint64(&cache.generation) {
+		return view
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	generation := atomic.LoadUint64(&cache.generation)
+	if view, ok := cache.snapshot.Load().(*DBView); ok && view.generation == generation {
+		return view
+	}
+	view := &DBView{generation: generation, rows: make(map[ID]map[UUID]json.RawMessage, len(cache.rows))}
+	for table, tableRows := range cache.rows {
+		rows := make(map[UUID]json.RawMessage, len(tableRows))
+		for uuid, cached := range tableRows {
+			// rows cached as absent are left out
+			if cached.row != nil {
+				rows[uuid] = cached.row
+			}
+		}
+		if len(rows) != 0 {
+			view.rows[table] = rows
+		}
+	}
+	cache.snapshot.Store(view)
+	return view
+}
+
+// Get returns the row uuid of table, false if it's not in the view
+func (view *DBView) Get(table ID, uuid UUID) (json.RawMessage, bool) {
+	row, ok := view.rows[table][uuid]
+	return row, ok
+}
+
+// List returns the rows of table in the view, ordered by UUID. Unlike ReadThroughCache.List, they
+// may not be all rows of the table, only the cached ones.
+func (view *DBView) List(table ID) []json.RawMessage {
+	uuids := view.UUIDs(table)
+	rows := make([]json.RawMessage, len(uuids))
+	for i, uuid := range uuids {
+		rows[i] = view.rows[table][uuid]
+	}
+	return rows
+}
+
+// UUIDs returns the UUIDs of the rows of table in the view, in order
+func (view *DBView) UUIDs(table ID) []UUID {
+	uuids := make([]UUID, 0, len(view.rows[table]))
+	for uuid := range view.rows[table] {
+		uuids = append(uuids, uuid)
+	}
+	sort.Slice(uuids, func(i, j int) bool { return uuids[i] < uuids[j] })
+	return uuids
+}
+
+// Len returns the number of rows of table in the view
+func (view *DBView) Len(table ID) int {
+	return len(view.rows[table])
+}
@@ -0,0 +1,87 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadThroughCacheSnapshot(t *testing.T) {
+	cache := &ReadThroughCache{
+		ttl:    time.Hour,
+		rows:   make(map[ID]map[UUID]cachedRow),
+		tables: make(map[ID]cachedTable),
+	}
+	expires := time.Now().Add(time.Hour)
+	cache.store("Bridge", map[UUID]cachedRow{
+		ls1: {row: json.RawMessage(`{"name":"br0"}`), expires: expires},
+		ls2: {row: json.RawMessage(`{"name":"br1"}`), expires: expires},
+		// a row cached as absent
+		lsp1: {expires: expires},
+	})
+
+	view := cache.Snapshot()
+	if row, ok := view.Get("Bridge", ls1); !ok || string(row) != `{"name":"br0"}` {
+		t.Errorf("Get = %s, %v, want br0", row, ok)
+	}
+	if _, ok := view.Get("Bridge", lsp1); ok {
+		t.Error("Get returned a row cached as absent")
+	}
+	if got := view.UUIDs("Bridge"); !reflect.DeepEqual(got, []UUID{ls1, ls2}) {
+		t.Errorf("UUIDs = %v, want %v", got, []UUID{ls1, ls2})
+	}
+	if view.Len("Port") != 0 || len(view.List("Port")) != 0 {
+		t.Error("view has rows of a table not cached")
+	}
+	if again := cache.Snapshot(); again != view {
+		t.Error("Snapshot built a new view without changes")
+	}
+
+	// a change makes a new view, the old one is unchanged
+	cache.Invalidate("Bridge", ls1)
+	changed := cache.Snapshot()
+	if changed == view {
+		t.Fatal("Snapshot returned the old view after a change")
+	}
+	if _, ok := changed.Get("Bridge", ls1); ok {
+		t.Error("new view has an invalidated row")
+	}
+	if rows := view.List("Bridge"); len(rows) != 2 {
+		t.Errorf("old view has %d rows after a change, want 2", len(rows))
+	}
+}
+
+func benchmarkCacheReads(b *testing.B, read func(cache *ReadThroughCache, uuid UUID)) {
+	cache := &ReadThroughCache{
+		ttl:    time.Hour,
+		rows:   make(map[ID]map[UUID]cachedRow),
+		tables: make(map[ID]cachedTable),
+	}
+	rows := make(map[UUID]cachedRow)
+	uuids := make([]UUID, 1000)
+	for i := range uuids {
+		uuids[i] = UUID(fmt.Sprintf("a0000000-0000-0000-0000-%012d", i))
+		rows[uuids[i]] = cachedRow{row: json.RawMessage(`{"name":"br0"}`), expires: time.Now().Add(time.Hour)}
+	}
+	cache.store("Bridge", rows)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			read(cache, uuids[i%len(uuids)])
+		}
+	})
+}
+
+func BenchmarkReadThroughCacheParallelUnsafeGet(b *testing.B) {
+	benchmarkCacheReads(b, func(cache *ReadThroughCache, uuid UUID) {
+		cache.UnsafeGet("Bridge", uuid)
+	})
+}
+
+func BenchmarkReadThroughCacheParallelSnapshotGet(b *testing.B) {
+	benchmarkCacheReads(b, func(cache *ReadThroughCache, uuid UUID) {
+		cache.Snapshot().Get("Bridge", uuid)
+	})
+}
@@ -0,0 +1,61 @@
+package ovsdb
+
+import (
+	"net"
+	"time"
+)
+
+// WithKeepAlive makes tcp connections send keepalive probes after period of idleness and every period after that,
+// so dead peers are detected much faster than with the kernel defaults. A negative period disables keepalive.
+func WithKeepAlive(period time.Duration) DialOption {
+	return func(o *dialOptions) {
+		o.keepAlive = period
+	}
+}
+
+// WithUserTimeout sets the TCP_USER_TIMEOUT of tcp connections, the maximum time transmitted data may remain
+// unacknowledged before the connection is closed. It's only supported on Linux, elsewhere Dial fails with it.
+func WithUserTimeout(timeout time.Duration) DialOption {
+	return func(o *dialOptions) {
+		o.userTimeout = timeout
+	}
+}
+
+// WithNoDelay sets TCP_NODELAY on tcp connections, it's set by default
+func WithNoDelay(noDelay bool) DialOption {
+	return func(o *dialOptions) {
+		o.noDelay = &noDelay
+	}
+}
+
+// setSockopts applies the socket options to conn if it's a tcp connection
+func (o *dialOptions) setSockopts(conn net.Conn) error {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	if o.keepAlive < 0 {
+		if err := tcpConn.SetKeepAlive(false); err != nil {
+			return err
+		}
+	} else if o.keepAlive > 0 {
+		if err := tcpConn.SetKeepAlive(true); err != nil {
+			return err
+		}
+		if err := tcpConn.SetKeepAlivePeriod(o.keepAlive); err != nil {
+			return err
+		}
+		if err := setKeepAliveInterval(tcpConn, o.keepAlive); err != nil {
+			return err
+		}
+	}
+	if o.noDelay != nil {
+		if err := tcpConn.SetNoDelay(*o.noDelay); err != nil {
+			return err
+		}
+	}
+	if o.userTimeout > 0 {
+		return setUserTimeout(tcpConn, o.userTimeout)
+	}
+	return nil
+}
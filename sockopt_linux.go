@@ -0,0 +1,40 @@
+package ovsdb
+
+import (
+	"net"
+	"syscall"
+	"time"
+)
+
+// tcpUserTimeout is TCP_USER_TIMEOUT from linux/tcp.h, which package syscall doesn't define
+const tcpUserTimeout = 0x12
+
+// setUserTimeout sets the TCP_USER_TIMEOUT of conn
+func setUserTimeout(conn *net.TCPConn, timeout time.Duration) error {
+	return setsockoptInt(conn, tcpUserTimeout, int(timeout/time.Millisecond))
+}
+
+// setKeepAliveInterval sets the TCP_KEEPINTVL of conn, which SetKeepAlivePeriod leaves alone in recent Go releases
+func setKeepAliveInterval(conn *net.TCPConn, interval time.Duration) error {
+	seconds := int(interval / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return setsockoptInt(conn, syscall.TCP_KEEPINTVL, seconds)
+}
+
+// setsockoptInt sets a IPPROTO_TCP level option of conn
+func setsockoptInt(conn *net.TCPConn, opt, value int) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, opt, value)
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
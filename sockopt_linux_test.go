@@ -0,0 +1,52 @@
+package ovsdb
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestDialSockopts(t *testing.T) {
+	dialed := make(chan net.Conn, 1)
+	address := listen(t, func(conn net.Conn) {
+		ovsdbtest.NewServer(conn)
+	})
+	client, err := Dial(address, WithProxy(nil), WithKeepAlive(5*time.Second), WithUserTimeout(10*time.Second),
+		WithNetDialer(dialerFunc(func(network, address string) (net.Conn, error) {
+			conn, err := net.Dial(network, address)
+			if err == nil {
+				dialed <- conn
+			}
+			return conn, err
+		})))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+
+	raw, err := (<-dialed).(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw.Control(func(fd uintptr) {
+		for _, opt := range []struct {
+			name       string
+			level, opt int
+			value      int
+		}{
+			{"SO_KEEPALIVE", syscall.SOL_SOCKET, syscall.SO_KEEPALIVE, 1},
+			{"TCP_KEEPINTVL", syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL, 5},
+			{"TCP_USER_TIMEOUT", syscall.IPPROTO_TCP, tcpUserTimeout, 10000},
+		} {
+			value, err := syscall.GetsockoptInt(int(fd), opt.level, opt.opt)
+			if err != nil {
+				t.Errorf("failed to get %s: %v", opt.name, err)
+			} else if value != opt.value {
+				t.Errorf("%s is %d, want %d", opt.name, value, opt.value)
+			}
+		}
+	})
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package ovsdb
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// setUserTimeout fails, TCP_USER_TIMEOUT is specific to Linux
+func setUserTimeout(conn *net.TCPConn, timeout time.Duration) error {
+	return errors.New("TCP_USER_TIMEOUT is not supported on this platform")
+}
+
+// setKeepAliveInterval does nothing, SetKeepAlivePeriod sets the interval where it's supported
+func setKeepAliveInterval(conn *net.TCPConn, interval time.Duration) error {
+	return nil
+}
@@ -0,0 +1,411 @@
+package ovsdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SQLDialect is the flavor of SQL spoken by the database of a SQLMirror
+type SQLDialect struct {
+	Name string
+	// Types are the SQL types of columns holding one atom, by atomic type
+	Types map[AtomicType]string
+	// Collection is the SQL type of set and map columns, which hold the JSON encoding of their value:
+	// an array of the elements of a set, an object for a map with string keys, or an array of key-value
+	// pairs for other maps
+	Collection string
+	// Placeholder returns the placeholder of the nth parameter of a statement, counted from 1
+	Placeholder func(n int) string
+}
+
+// Supported SQLDialects, PostgreSQL 9.5+ and SQLite 3.24+ which support upserts with "ON CONFLICT"
+var (
+	PostgreSQL = SQLDialect{
+		Name: "postgres",
+		Types: map[AtomicType]string{
+			TypeInteger: "BIGINT",
+			TypeReal:    "DOUBLE PRECISION",
+			TypeBoolean: "BOOLEAN",
+			TypeString:  "TEXT",
+			TypeUUID:    "UUID",
+		},
+		Collection:  "JSONB",
+		Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+	}
+	SQLite = SQLDialect{
+		Name: "sqlite",
+		Types: map[AtomicType]string{
+			TypeInteger: "INTEGER",
+			TypeReal:    "REAL",
+			TypeBoolean: "BOOLEAN",
+			TypeString:  "TEXT",
+			TypeUUID:    "TEXT",
+		},
+		Collection:  "TEXT",
+		Placeholder: func(n int) string { return "?" },
+	}
+)
+
+// sqlUUIDColumn is the primary key column of mirrored tables
+const sqlUUIDColumn = "_uuid"
+
+// SQLMirror mirrors tables of an OVSDB database into a SQL database, e.g. for ad-hoc queries and
+// historical analysis of OVN state. Each table is mirrored into a SQL table of the same name, with the
+// UUIDs of rows in the "_uuid" primary key column and a column per OVSDB column, see SQLDialect.
+// The SQL database is only written by the mirror, the driver for it must be imported by the program.
+type SQLMirror struct {
+	db       *sql.DB
+	dialect  SQLDialect
+	dbSchema *DatabaseSchema
+	tables   []ID
+
+	mu      sync.Mutex
+	onError func(err error)
+	workers int
+}
+
+// NewSQLMirror creates a SQLMirror of tables of the database with schema dbSchema into db,
+// all tables are mirrored if none is given
+func NewSQLMirror(db *sql.DB, dialect SQLDialect, dbSchema *DatabaseSchema, tables ...ID) *SQLMirror {
+	if len(tables) == 0 {
+		for table := range dbSchema.Tables {
+			tables = append(tables, table)
+		}
+	}
+	tables = append([]ID{}, tables...)
+	sort.Slice(tables, func(i, j int) bool { return tables[i] < tables[j] })
+	return &SQLMirror{db: db, dialect: dialect, dbSchema: dbSchema, tables: tables, workers: runtime.GOMAXPROCS(0)}
+}
+
+// SetWorkers sets the number of goroutines decoding the rows of large batches of updates, e.g. the initial
+// contents of a monitor, into SQL statements, which are still executed in order. It's GOMAXPROCS by default,
+// 1 decodes rows serially.
+func (m *SQLMirror) SetWorkers(workers int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers = workers
+}
+
+// OnError registers fn to be called with the errors of applying updates streamed by MirrorSQL
+func (m *SQLMirror) OnError(fn func(err error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onError = fn
+}
+
+// SchemaSQL returns the statements creating the SQL tables of the mirror, if they don't exist
+func (m *SQLMirror) SchemaSQL() []string {
+	var statements []string
+	for _, table := range m.tables {
+		tableSchema := m.dbSchema.Tables[table]
+		if tableSchema == nil {
+			continue
+		}
+		definitions := []string{fmt.Sprintf("%s %s PRIMARY KEY", quoteSQL(sqlUUIDColumn), m.dialect.Types[TypeUUID])}
+		for _, column := range sortedColumnNames(tableSchema) {
+			definitions = append(definitions, quoteSQL(string(column))+" "+m.sqlType(tableSchema.Columns[column]))
+		}
+		statements = append(statements, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)",
+			quoteSQL(string(table)), strings.Join(definitions, ", ")))
+	}
+	return statements
+}
+
+// CreateTables creates the SQL tables of the mirror if they don't exist
+func (m *SQLMirror) CreateTables() error {
+	for _, statement := range m.SchemaSQL() {
+		if _, err := m.db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create table: %v", err)
+		}
+	}
+	return nil
+}
+
+// Apply applies updates, e.g. of a monitor, to the SQL tables in one SQL transaction,
+// in the order of ApplyOrder so foreign keys added to the tables are respected
+func (m *SQLMirror) Apply(updates TableUpdates) error {
+	return m.apply(updates, false)
+}
+
+// Replace replaces the content of the SQL tables with the rows of updates, e.g. the initial
+// contents of a monitor, in one SQL transaction
+func (m *SQLMirror) Replace(updates TableUpdates) error {
+	return m.apply(updates, true)
+}
+
+// apply applies updates in a SQL transaction, after deleting all mirrored rows if replace
+func (m *SQLMirror) apply(updates TableUpdates, replace bool) error {
+	ordered, err := ApplyOrder(m.dbSchema, updates)
+	if err != nil {
+		return err
+	}
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	if replace {
+		for _, table := range m.tables {
+			if _, err := tx.Exec("DELETE FROM " + quoteSQL(string(table))); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to clear table %s: %v", table, err)
+			}
+		}
+	}
+	m.mu.Lock()
+	workers := m.workers
+	m.mu.Unlock()
+	statements, err := m.statements(ordered, workers)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement.query, statement.args...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply update of row %s in table %s: %v", statement.update.UUID, statement.update.Table, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// parallelStatements is the number of updates from which statements are built by several workers,
+// smaller batches are not worth the goroutines
+const parallelStatements = 1000
+
+// sqlStatement is the SQL statement applying an update
+type sqlStatement struct {
+	update OrderedUpdate
+	query  string
+	args   []interface{}
+}
+
+// statements returns the statements applying the updates of mirrored tables, in order. Statements of
+// large batches are built by workers, each of them decoding a contiguous share of the updates.
+func (m *SQLMirror) statements(ordered []OrderedUpdate, workers int) ([]sqlStatement, error) {
+	mirrored := make(map[ID]bool, len(m.tables))
+	for _, table := range m.tables {
+		mirrored[table] = true
+	}
+	var updates []OrderedUpdate
+	for _, update := range ordered {
+		if mirrored[update.Table] {
+			updates = append(updates, update)
+		}
+	}
+	if len(updates) < parallelStatements || workers < 1 {
+		workers = 1
+	}
+
+	statements := make([]sqlStatement, len(updates))
+	errs := make([]error, workers)
+	share := (len(updates) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		start, end := worker*share, (worker+1)*share
+		if end > len(updates) {
+			end = len(updates)
+		}
+		wg.Add(1)
+		go func(worker, start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				query, args, err := m.statement(updates[i])
+				if err != nil {
+					errs[worker] = err
+					return
+				}
+				statements[i] = sqlStatement{update: updates[i], query: query, args: args}
+			}
+		}(worker, start, end)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return statements, nil
+}
+
+// statement returns the SQL statement applying a row update and its arguments
+func (m *SQLMirror) statement(update OrderedUpdate) (string, []interface{}, error) {
+	table := quoteSQL(string(update.Table))
+	if update.Update.New == nil {
+		return fmt.Sprintf("DELETE FROM %s WHERE %s = %s", table, quoteSQL(sqlUUIDColumn), m.dialect.Placeholder(1)),
+			[]interface{}{string(update.UUID)}, nil
+	}
+
+	var row map[ID]json.RawMessage
+	if err := json.Unmarshal(*update.Update.New, &row); err != nil {
+		return "", nil, fmt.Errorf("invalid row %s in table %s: %v", update.UUID, update.Table, err)
+	}
+	tableSchema := m.dbSchema.Tables[update.Table]
+	columns := []string{quoteSQL(sqlUUIDColumn)}
+	placeholders := []string{m.dialect.Placeholder(1)}
+	args := []interface{}{string(update.UUID)}
+	var assignments []string
+	for _, column := range sortedColumnNames(tableSchema) {
+		raw, ok := row[column]
+		if !ok {
+			continue
+		}
+		arg, err := sqlValue(tableSchema.Columns[column], raw)
+		if err != nil {
+			return "", nil, fmt.Errorf("column %s of row %s in table %s: %v", column, update.UUID, update.Table, err)
+		}
+		name := quoteSQL(string(column))
+		columns = append(columns, name)
+		args = append(args, arg)
+		placeholders = append(placeholders, m.dialect.Placeholder(len(args)))
+		assignments = append(assignments, fmt.Sprintf("%s = excluded.%s", name, name))
+	}
+	conflict := "DO NOTHING"
+	if len(assignments) != 0 {
+		conflict = "DO UPDATE SET " + strings.Join(assignments, ", ")
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) %s", table, strings.Join(columns, ", "),
+		strings.Join(placeholders, ", "), quoteSQL(sqlUUIDColumn), conflict), args, nil
+}
+
+// sqlType returns the SQL type of a column
+func (m *SQLMirror) sqlType(columnSchema *ColumnSchema) string {
+	if columnSchema.IsMap() || columnSchema.MaxElements() != 1 {
+		return m.dialect.Collection
+	}
+	return m.dialect.Types[columnSchema.KeyType()]
+}
+
+// sqlValue converts the JSON encoding of the value of a column into the argument of a SQL statement
+func sqlValue(columnSchema *ColumnSchema, raw json.RawMessage) (interface{}, error) {
+	value, err := CanonicalValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !columnSchema.IsMap() && columnSchema.MaxElements() == 1 {
+		if set, ok := value.(Set); ok {
+			if len(set.Values) != 0 {
+				return nil, fmt.Errorf("%d elements in a column of one", len(set.Values))
+			}
+			return nil, nil
+		}
+		return sqlAtom(columnSchema.KeyType(), value), nil
+	}
+
+	var collection interface{}
+	switch value := value.(type) {
+	case Map:
+		if columnSchema.KeyType() == TypeString {
+			object := make(map[string]interface{}, len(value.Values))
+			for _, pair := range value.Values {
+				key, _ := pair[0].(string)
+				object[key] = sqlAtom(columnSchema.ValueType(), pair[1])
+			}
+			collection = object
+		} else {
+			pairs := make([][2]interface{}, 0, len(value.Values))
+			for _, pair := range value.Values {
+				pairs = append(pairs, [2]interface{}{
+					sqlAtom(columnSchema.KeyType(), pair[0]),
+					sqlAtom(columnSchema.ValueType(), pair[1]),
+				})
+			}
+			collection = pairs
+		}
+	case Set:
+		elements := make([]interface{}, 0, len(value.Values))
+		for _, element := range value.Values {
+			elements = append(elements, sqlAtom(columnSchema.KeyType(), element))
+		}
+		collection = elements
+	default:
+		// a set of one element
+		collection = []interface{}{sqlAtom(columnSchema.KeyType(), value)}
+	}
+	bytes, err := json.Marshal(collection)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
+}
+
+// sqlAtom converts a canonical atom of atomicType into a SQL value
+func sqlAtom(atomicType AtomicType, atom Value) interface{} {
+	switch atom := atom.(type) {
+	case UUID:
+		return string(atom)
+	case float64:
+		if atomicType == TypeInteger {
+			return int64(atom)
+		}
+	}
+	return atom
+}
+
+// quoteSQL quotes an identifier of SQL
+func quoteSQL(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// sortedColumnNames returns the names of the columns of a table in order
+func sortedColumnNames(tableSchema *TableSchema) []ID {
+	columns := make([]ID, 0, len(tableSchema.Columns))
+	for column := range tableSchema.Columns {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i] < columns[j] })
+	return columns
+}
+
+// MirrorSQL monitors the tables of mirror in database db and streams their content into the SQL database:
+// the SQL tables are created if they don't exist, their content is replaced with the current content of
+// the OVSDB tables, and updates are applied in the order the server sent them. Errors applying updates are
+// reported to the function registered with OnError. The returned stop function cancels the monitor.
+func (c *Client) MirrorSQL(db ID, mirror *SQLMirror) (stop func() error, err error) {
+	if err := mirror.CreateTables(); err != nil {
+		return nil, err
+	}
+	requests := make(MonitorRequests, len(mirror.tables))
+	for _, table := range mirror.tables {
+		requests[table] = MonitorRequest{}
+	}
+
+	// updates are delivered in order, the first ones wait until the initial contents are written,
+	// replaced is set before initialized is closed
+	initialized := make(chan struct{})
+	var replaced bool
+	monitorID := c.watchMonitor(func(updates TableUpdates) {
+		<-initialized
+		if !replaced {
+			return
+		}
+		if err := mirror.Apply(updates); err != nil {
+			mirror.mu.Lock()
+			onError := mirror.onError
+			mirror.mu.Unlock()
+			if onError != nil {
+				onError(err)
+			}
+		}
+	})
+	defer close(initialized)
+	initial, err := c.Monitor(db, monitorID, requests)
+	if err != nil {
+		c.unwatchMonitor(monitorID)
+		return nil, err
+	}
+	stop = func() error {
+		c.unwatchMonitor(monitorID)
+		return c.MonitorCancel(monitorID)
+	}
+	if err := mirror.Replace(initial); err != nil {
+		stop()
+		return nil, err
+	}
+	replaced = true
+	return stop, nil
+}
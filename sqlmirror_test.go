@@ -0,0 +1,270 @@
+package ovsdb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+// recordingDriver is a database/sql driver recording executed statements, by DSN
+type recordingDriver struct {
+	mu   sync.Mutex
+	logs map[string][]string
+}
+
+func (d *recordingDriver) Open(dsn string) (driver.Conn, error) {
+	return &recordingConn{d, dsn}, nil
+}
+
+func (d *recordingDriver) log(dsn string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.logs[dsn]
+}
+
+// reset clears the log of dsn, so a test opening it sees only its own statements when it runs repeatedly
+func (d *recordingDriver) reset(dsn string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.logs, dsn)
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+	dsn    string
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{c, query}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { c.record("BEGIN"); return c, nil }
+func (c *recordingConn) Commit() error             { c.record("COMMIT"); return nil }
+func (c *recordingConn) Rollback() error           { c.record("ROLLBACK"); return nil }
+
+func (c *recordingConn) record(statement string) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.logs[c.dsn] = append(c.driver.logs[c.dsn], statement)
+}
+
+type recordingStmt struct {
+	conn  *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	statement := s.query
+	if len(args) != 0 {
+		statement += fmt.Sprintf(" %v", args)
+	}
+	s.conn.record(statement)
+	return driver.RowsAffected(1), nil
+}
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+var sqlDriver = &recordingDriver{logs: make(map[string][]string)}
+
+func init() {
+	sql.Register("ovsdb-recording", sqlDriver)
+}
+
+func TestSQLMirrorSchemaSQL(t *testing.T) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(cascadeSchema), &dbSchema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	mirror := NewSQLMirror(nil, PostgreSQL, &dbSchema, "Switch_Group", "Logical_Switch")
+	want := []string{
+		`CREATE TABLE IF NOT EXISTS "Logical_Switch" ("_uuid" UUID PRIMARY KEY, "acls" JSONB, "load_balancer" JSONB, "name" TEXT, "ports" JSONB)`,
+		`CREATE TABLE IF NOT EXISTS "Switch_Group" ("_uuid" UUID PRIMARY KEY, "primary" UUID, "switches" JSONB)`,
+	}
+	if got := mirror.SchemaSQL(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SchemaSQL returned\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSQLMirrorApply(t *testing.T) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(cascadeSchema), &dbSchema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	sqlDriver.reset(t.Name())
+	db, err := sql.Open("ovsdb-recording", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	raw := func(s string) *json.RawMessage {
+		r := json.RawMessage(s)
+		return &r
+	}
+
+	mirror := NewSQLMirror(db, SQLite, &dbSchema, "Logical_Switch", "Logical_Switch_Port", "Switch_Group")
+	err = mirror.Apply(TableUpdates{
+		"Logical_Switch": {
+			ls1: {New: raw(`{"name":"ls1","ports":["set",[["uuid","` + lsp1 + `"],["uuid","` + lsp2 + `"]]]}`)},
+		},
+		"Logical_Switch_Port": {
+			lsp1: {New: raw(`{"name":"lsp1"}`)},
+			lsp2: {Old: raw(`{"name":"lsp2"}`)},
+		},
+		"Switch_Group": {
+			sg1: {New: raw(`{"primary":["set",[]],"switches":["map",[["a",["uuid","` + ls1 + `"]]]]}`)},
+		},
+		"ACL": {
+			acl1: {New: raw(`{"priority":1}`)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	// parents first: Switch_Group refers to Logical_Switch which refers to Logical_Switch_Port
+	want := []string{
+		"BEGIN",
+		`DELETE FROM "Logical_Switch_Port" WHERE "_uuid" = ? [` + lsp2 + `]`,
+		`INSERT INTO "Switch_Group" ("_uuid", "primary", "switches") VALUES (?, ?, ?) ON CONFLICT ("_uuid") DO UPDATE SET "primary" = excluded."primary", "switches" = excluded."switches" [` +
+			sg1 + ` <nil> {"a":"` + ls1 + `"}]`,
+		`INSERT INTO "Logical_Switch" ("_uuid", "name", "ports") VALUES (?, ?, ?) ON CONFLICT ("_uuid") DO UPDATE SET "name" = excluded."name", "ports" = excluded."ports" [` +
+			ls1 + ` ls1 ["` + lsp1 + `","` + lsp2 + `"]]`,
+		`INSERT INTO "Logical_Switch_Port" ("_uuid", "name") VALUES (?, ?) ON CONFLICT ("_uuid") DO UPDATE SET "name" = excluded."name" [` + lsp1 + ` lsp1]`,
+		"COMMIT",
+	}
+	if got := sqlDriver.log(t.Name()); !reflect.DeepEqual(got, want) {
+		t.Errorf("executed\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// largeSnapshot returns the ordered initial contents of n logical switches with a port each
+func largeSnapshot(t testing.TB, dbSchema *DatabaseSchema, n int) []OrderedUpdate {
+	updates := TableUpdates{"Logical_Switch": {}, "Logical_Switch_Port": {}}
+	for i := 0; i < n; i++ {
+		ls := UUID(fmt.Sprintf("a0000000-0000-0000-0000-%012d", i))
+		lsp := UUID(fmt.Sprintf("b0000000-0000-0000-0000-%012d", i))
+		switchRow := json.RawMessage(fmt.Sprintf(`{"name":"ls%d","ports":["uuid","%s"]}`, i, lsp))
+		portRow := json.RawMessage(fmt.Sprintf(`{"name":"lsp%d"}`, i))
+		updates["Logical_Switch"][ls] = RowUpdate{New: &switchRow}
+		updates["Logical_Switch_Port"][lsp] = RowUpdate{New: &portRow}
+	}
+	ordered, err := ApplyOrder(dbSchema, updates)
+	if err != nil {
+		t.Fatalf("ApplyOrder failed: %v", err)
+	}
+	return ordered
+}
+
+func TestSQLMirrorParallelStatements(t *testing.T) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(cascadeSchema), &dbSchema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	mirror := NewSQLMirror(nil, PostgreSQL, &dbSchema, "Logical_Switch", "Logical_Switch_Port")
+	ordered := largeSnapshot(t, &dbSchema, parallelStatements)
+	serial, err := mirror.statements(ordered, 1)
+	if err != nil {
+		t.Fatalf("serial statements failed: %v", err)
+	}
+	parallel, err := mirror.statements(ordered, 3)
+	if err != nil {
+		t.Fatalf("parallel statements failed: %v", err)
+	}
+	if len(serial) != 2*parallelStatements || !reflect.DeepEqual(serial, parallel) {
+		t.Error("statements built by several workers differ from the serial ones")
+	}
+}
+
+func benchmarkSQLMirrorStatements(b *testing.B, workers int) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(cascadeSchema), &dbSchema); err != nil {
+		b.Fatalf("invalid schema: %v", err)
+	}
+	mirror := NewSQLMirror(nil, PostgreSQL, &dbSchema, "Logical_Switch", "Logical_Switch_Port")
+	ordered := largeSnapshot(b, &dbSchema, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := mirror.statements(ordered, workers); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSQLMirrorStatementsSerial(b *testing.B) {
+	benchmarkSQLMirrorStatements(b, 1)
+}
+
+func BenchmarkSQLMirrorStatementsParallel(b *testing.B) {
+	benchmarkSQLMirrorStatements(b, runtime.GOMAXPROCS(0))
+}
+
+func TestMirrorSQL(t *testing.T) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(cascadeSchema), &dbSchema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	sqlDriver.reset(t.Name())
+	db, err := sql.Open("ovsdb-recording", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	var monitorID json.RawMessage
+	server.Handle("monitor", func(params []json.RawMessage) (interface{}, error) {
+		monitorID = params[1]
+		return map[string]interface{}{"Logical_Switch_Port": map[string]interface{}{
+			lsp1: map[string]interface{}{"new": map[string]interface{}{"name": "lsp1"}},
+		}}, nil
+	})
+	client := NewClient(conn)
+	defer client.Close()
+
+	mirror := NewSQLMirror(db, SQLite, &dbSchema, "Logical_Switch_Port")
+	stop, err := client.MirrorSQL("OVN_Northbound", mirror)
+	if err != nil {
+		t.Fatalf("MirrorSQL failed: %v", err)
+	}
+	defer stop()
+	// a modification then a deletion, which must not be undone by the modification
+	server.Notify("update", monitorID, map[string]interface{}{"Logical_Switch_Port": map[string]interface{}{
+		lsp1: map[string]interface{}{"old": map[string]interface{}{"name": "lsp1"}, "new": map[string]interface{}{"name": "renamed"}},
+	}})
+	server.Notify("update", monitorID, map[string]interface{}{"Logical_Switch_Port": map[string]interface{}{
+		lsp1: map[string]interface{}{"old": map[string]interface{}{"name": "renamed"}},
+	}})
+
+	want := []string{
+		"BEGIN",
+		`INSERT INTO "Logical_Switch_Port" ("_uuid", "name") VALUES (?, ?) ON CONFLICT ("_uuid") DO UPDATE SET "name" = excluded."name" [` + lsp1 + ` renamed]`,
+		"COMMIT",
+		"BEGIN",
+		`DELETE FROM "Logical_Switch_Port" WHERE "_uuid" = ? [` + lsp1 + `]`,
+		"COMMIT",
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		executed := sqlDriver.log(t.Name())
+		if len(executed) >= len(want) && reflect.DeepEqual(executed[len(executed)-len(want):], want) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("executed\n%s\nwant to end with\n%s", strings.Join(executed, "\n"), strings.Join(want, "\n"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
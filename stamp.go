@@ -0,0 +1,140 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// StampFunc returns the keys added to external_ids of the rows inserted by a transaction on db
+type StampFunc func(db ID) map[string]string
+
+// SetStampFunc makes the client add the keys returned by fn to the external_ids column of every row inserted
+// by its transactions, giving operators of shared databases the provenance of rows. Keys already in the
+// external_ids of an inserted row are kept. Tables without an external_ids column are left alone, the
+// schema of a database is got from the server on its first transaction for that.
+// A nil fn turns it off.
+func (c *Client) SetStampFunc(fn StampFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stampFunc = fn
+}
+
+// Provenance returns a StampFunc stamping the id and version of a controller, and the time of the
+// transaction in RFC 3339 format, as the keys "controller", "version" and "timestamp" of ns
+func Provenance(ns KeyNamespace, controller, version string) StampFunc {
+	return func(db ID) map[string]string {
+		return map[string]string{
+			ns.Key("controller"): controller,
+			ns.Key("version"):    version,
+			ns.Key("timestamp"):  time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+}
+
+// stampInserts returns ops with the keys of the StampFunc added to the rows of insert operations
+func (c *Client) stampInserts(db ID, ops []Operation) ([]Operation, error) {
+	c.mu.Lock()
+	fn := c.stampFunc
+	c.mu.Unlock()
+	if fn == nil {
+		return ops, nil
+	}
+	var stamped []Operation
+	var stamp map[string]string
+	for i, op := range ops {
+		insert, ok := op.(*InsertOperation)
+		if !ok {
+			continue
+		}
+		dbSchema, err := c.cachedSchema(db)
+		if err != nil {
+			return nil, err
+		}
+		tableSchema, ok := dbSchema.Tables[insert.Table]
+		if !ok {
+			continue
+		}
+		if _, ok := tableSchema.Columns[ExternalIDs]; !ok {
+			continue
+		}
+		if stamp == nil {
+			if stamp = fn(db); len(stamp) == 0 {
+				return ops, nil
+			}
+		}
+		row, err := stampRow(insert.Row, stamp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stamp row of operation %d: %v", i, err)
+		}
+		if stamped == nil {
+			stamped = append([]Operation{}, ops...)
+		}
+		stamped[i] = &InsertOperation{Table: insert.Table, Row: row, UUIDName: insert.UUIDName}
+	}
+	if stamped == nil {
+		return ops, nil
+	}
+	return stamped, nil
+}
+
+// cachedSchema returns the schema of db, it's got from the server only once and shared by helpers of the client
+func (c *Client) cachedSchema(db ID) (*DatabaseSchema, error) {
+	c.mu.Lock()
+	dbSchema, ok := c.schemas[string(db)]
+	c.mu.Unlock()
+	if ok {
+		return dbSchema, nil
+	}
+	dbSchema, err := c.GetSchema(db)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.schemas[string(db)] = dbSchema
+	c.mu.Unlock()
+	return dbSchema, nil
+}
+
+// stampRow returns row with the keys of stamp missing in its external_ids added
+func stampRow(row Row, stamp map[string]string) (map[ID]interface{}, error) {
+	bytes, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	var columns map[ID]json.RawMessage
+	if err := json.Unmarshal(bytes, &columns); err != nil {
+		return nil, err
+	}
+	externalIDs := make(map[string]string)
+	if raw, ok := columns[ExternalIDs]; ok {
+		var m Map
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", ExternalIDs, err)
+		}
+		for _, pair := range m.Values {
+			key, ok1 := pair[0].(string)
+			value, ok2 := pair[1].(string)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("invalid %s: not a string map", ExternalIDs)
+			}
+			externalIDs[key] = value
+		}
+	}
+	for key, value := range stamp {
+		if _, ok := externalIDs[key]; !ok {
+			externalIDs[key] = value
+		}
+	}
+
+	value, err := ConvertToValue(externalIDs)
+	if err != nil {
+		return nil, err
+	}
+	stamped := make(map[ID]interface{}, len(columns)+1)
+	for column, raw := range columns {
+		stamped[column] = raw
+	}
+	stamped[ExternalIDs] = value
+	return stamped, nil
+}
@@ -0,0 +1,79 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStampInserts(t *testing.T) {
+	var dbSchema DatabaseSchema
+	err := json.Unmarshal([]byte(`{"name": "OVN_Northbound", "version": "5.0.0", "tables": {
+		"Logical_Switch": {"columns": {
+			"name": {"type": "string"},
+			"external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}}},
+		"NB_Global": {"columns": {"nb_cfg": {"type": "integer"}}}}}`), &dbSchema)
+	if err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	c := &Client{schemas: map[string]*DatabaseSchema{"OVN_Northbound": &dbSchema}}
+
+	ops := []Operation{
+		&InsertOperation{Table: "Logical_Switch", Row: map[ID]Value{
+			"name":         "ls1",
+			"external_ids": Map{Values: []MapPair{{"app:controller", "other"}, {"owner", "me"}}},
+		}},
+		&InsertOperation{Table: "NB_Global", Row: map[ID]Value{"nb_cfg": 1}},
+		&InsertOperation{Table: "Logical_Switch", Row: map[ID]Value{"name": "ls2"}, UUIDName: "ls2"},
+	}
+	if got, _ := c.stampInserts("OVN_Northbound", ops); len(got) != 3 || got[0] != ops[0] {
+		t.Errorf("operations changed without StampFunc")
+	}
+
+	c.SetStampFunc(func(db ID) map[string]string {
+		return map[string]string{"app:controller": "ctl-1", "app:version": "1.2.3"}
+	})
+	got, err := c.stampInserts("OVN_Northbound", ops)
+	if err != nil {
+		t.Fatalf("stampInserts failed: %v", err)
+	}
+	if got[1] != ops[1] {
+		t.Error("insert into a table without external_ids should be untouched")
+	}
+	if insert := got[2].(*InsertOperation); insert.UUIDName != "ls2" {
+		t.Errorf("uuid-name lost: %q", insert.UUIDName)
+	}
+	want := []map[string]string{
+		{"app:controller": "other", "app:version": "1.2.3", "owner": "me"},
+		{"app:controller": "ctl-1", "app:version": "1.2.3"},
+	}
+	for i, index := range []int{0, 2} {
+		bytes, _ := json.Marshal(got[index].(*InsertOperation).Row)
+		var row struct {
+			Name        string `json:"name"`
+			ExternalIDs Map    `json:"external_ids"`
+		}
+		if err := json.Unmarshal(bytes, &row); err != nil {
+			t.Fatalf("invalid stamped row %s: %v", bytes, err)
+		}
+		externalIDs := make(map[string]string)
+		for _, pair := range row.ExternalIDs.Values {
+			externalIDs[pair[0].(string)] = pair[1].(string)
+		}
+		if len(externalIDs) != len(want[i]) {
+			t.Errorf("row %s: got external_ids %v, want %v", row.Name, externalIDs, want[i])
+		}
+		for key, value := range want[i] {
+			if externalIDs[key] != value {
+				t.Errorf("row %s: got external_ids %v, want %v", row.Name, externalIDs, want[i])
+				break
+			}
+		}
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	stamp := Provenance("app", "ctl-1", "1.2.3")("OVN_Northbound")
+	if stamp["app:controller"] != "ctl-1" || stamp["app:version"] != "1.2.3" || stamp["app:timestamp"] == "" {
+		t.Errorf("Provenance stamped %v", stamp)
+	}
+}
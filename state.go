@@ -0,0 +1,140 @@
+package ovsdb
+
+// ConnectionState is the state of the connection of a Client
+type ConnectionState int
+
+// Supported ConnectionStates
+const (
+	// StateConnecting is the state before the connection is established
+	StateConnecting ConnectionState = iota
+	// StateActive is the state of a working connection
+	StateActive
+	// StateDegraded is entered when a RPC times out or is canceled before the server responds,
+	// the connection is Active again once the server responds to a RPC
+	StateDegraded
+	// StateClosed is the final state, after the connection is closed or lost
+	StateClosed
+)
+
+// String implements fmt.Stringer interface
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnecting:
+		return "Connecting"
+	case StateActive:
+		return "Active"
+	case StateDegraded:
+		return "Degraded"
+	case StateClosed:
+		return "Closed"
+	}
+	return "Unknown"
+}
+
+// StateChange is a transition of the ConnectionState of a Client
+type StateChange struct {
+	From ConnectionState
+	To   ConnectionState
+	// Err is the cause of the transition, if any
+	Err error
+}
+
+// stateChangesBuffer is the number of state changes buffered for a subscriber
+const stateChangesBuffer = 16
+
+// State returns the current state of the connection
+func (c *Client) State() ConnectionState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// StateChanges subscribes to the state changes of the connection, cancel ends the subscription and closes
+// the channel. Changes are buffered, a subscriber which doesn't keep up misses changes, State returns
+// the current state anyway. The channel is closed after the change to StateClosed.
+func (c *Client) StateChanges() (changes <-chan StateChange, cancel func()) {
+	ch := make(chan StateChange, stateChangesBuffer)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == StateClosed {
+		close(ch)
+		return ch, func() {}
+	}
+	if c.stateSubscribers == nil {
+		c.stateSubscribers = make(map[chan StateChange]bool)
+	}
+	c.stateSubscribers[ch] = true
+	return ch, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.stateSubscribers[ch] {
+			delete(c.stateSubscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// setState changes the state of the connection to state, StateClosed is final
+func (c *Client) setState(state ConnectionState, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == state || c.state == StateClosed {
+		return
+	}
+	change := StateChange{From: c.state, To: state, Err: err}
+	c.state = state
+	for ch := range c.stateSubscribers {
+		select {
+		case ch <- change:
+		default:
+		}
+		if state == StateClosed {
+			close(ch)
+		}
+	}
+	if state == StateClosed {
+		c.stateSubscribers = nil
+		c.closeErr = err
+		if c.closed != nil {
+			close(c.closed)
+		}
+	}
+}
+
+// trackDisconnect changes the state to StateClosed when the connection is closed
+func (c *Client) trackDisconnect() {
+	<-c.rpc.DisconnectNotify()
+	c.notifications.close()
+	c.mu.Lock()
+	closing := c.closing
+	c.mu.Unlock()
+	if closing {
+		c.setState(StateClosed, nil)
+		return
+	}
+	err := c.codec.err()
+	if err == nil {
+		err = c.notifications.backlogError()
+	}
+	if err == nil {
+		err = errDisconnected
+	}
+	c.setState(StateClosed, err)
+	c.disconnected(err)
+}
+
+// Close closes the connection to the server
+func (c *Client) Close() error {
+	c.mu.Lock()
+	c.closing = true
+	c.mu.Unlock()
+	c.connectMu.Lock()
+	rpc := c.rpc
+	c.connectMu.Unlock()
+	var err error
+	if rpc != nil {
+		err = rpc.Close()
+	}
+	c.setState(StateClosed, nil)
+	return err
+}
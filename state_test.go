@@ -0,0 +1,54 @@
+package ovsdb
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestConnectionState(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	client := NewClient(conn)
+	if state := client.State(); state != StateActive {
+		t.Fatalf("State = %v, want %v", state, StateActive)
+	}
+	changes, cancel := client.StateChanges()
+	defer cancel()
+
+	// a RPC timing out degrades the connection
+	server.SetDelay(50 * time.Millisecond)
+	ctx, cancelCall := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelCall()
+	if err := client.Call(ctx, "list_dbs", nil, nil); err != context.DeadlineExceeded {
+		t.Fatalf("Call error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	// a response makes it active again
+	server.SetDelay(0)
+	if _, err := client.ListDbs(); err != nil {
+		t.Fatalf("ListDbs failed: %v", err)
+	}
+	server.Close()
+
+	want := []StateChange{
+		{StateActive, StateDegraded, context.DeadlineExceeded},
+		{StateDegraded, StateActive, nil},
+		{StateActive, StateClosed, errDisconnected},
+	}
+	for _, change := range want {
+		select {
+		case got := <-changes:
+			if got != change {
+				t.Errorf("state change = %v, want %v", got, change)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("state change %v not received", change)
+		}
+	}
+	if _, ok := <-changes; ok {
+		t.Error("state changes not closed after StateClosed")
+	}
+}
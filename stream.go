@@ -0,0 +1,152 @@
+package ovsdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// RowDecoder decodes one row of a select result from decoder, it must consume exactly one JSON value,
+// e.g. with decoder.Decode(&row) into a buffer reused across rows
+type RowDecoder func(decoder *json.Decoder) error
+
+// SelectStream executes the select operation on database db and calls decodeRow for each selected row
+// while the result is decoded, so huge tables are processed without materializing all rows.
+// Decoding stops at the first error returned by decodeRow.
+func (c *Client) SelectStream(db ID, op *SelectOperation, decodeRow RowDecoder) error {
+	stream := &rowStream{decodeRow: decodeRow}
+	if err := c.transact(db, []Operation{op}, stream, &stream.result); err != nil {
+		return err
+	}
+	if stream.err != nil {
+		return stream.err
+	}
+	if len(stream.result.Errors) != 0 {
+		return stream.result.Errors
+	}
+	return nil
+}
+
+// rowStream decodes the response of a transaction made of a select operation, optionally followed by
+// a comment, streaming the selected rows to decodeRow
+type rowStream struct {
+	decodeRow RowDecoder
+	// result holds the operation errors, successful results are not kept
+	result TransactResult
+	// err is the error returned by decodeRow
+	err error
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface
+func (s *rowStream) UnmarshalJSON(value []byte) error {
+	err := s.decode(value)
+	if s.err != nil {
+		// decoding stopped at the error of decodeRow, which is reported by SelectStream
+		return nil
+	}
+	return err
+}
+
+// decode decodes the transaction result
+func (s *rowStream) decode(value []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	if err := expectDelim(decoder, '['); err != nil {
+		return err
+	}
+	for i := 0; decoder.More(); i++ {
+		if i == 0 {
+			if err := s.decodeSelectResult(decoder); err != nil {
+				return err
+			}
+			continue
+		}
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+		if err := s.result.UnmarshalJSON(append(append([]byte{'['}, raw...), ']')); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, ']')
+}
+
+// decodeSelectResult decodes the result of the select operation
+func (s *rowStream) decodeSelectResult(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		// not attempted
+		s.result.Results = append(s.result.Results, nil)
+		return nil
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("unexpected select result: %v", token)
+	}
+
+	var opError *Error
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case "rows":
+			if err := s.decodeRows(decoder); err != nil {
+				return err
+			}
+		case "error", "details":
+			var message string
+			if err := decoder.Decode(&message); err != nil {
+				return err
+			}
+			if opError == nil {
+				opError = &Error{}
+			}
+			if token == "error" {
+				opError.Err = message
+			} else {
+				opError.Details = message
+			}
+		default:
+			var ignored json.RawMessage
+			if err := decoder.Decode(&ignored); err != nil {
+				return err
+			}
+		}
+	}
+	if opError != nil {
+		s.result.Errors = append(s.result.Errors, opError)
+		s.result.Results = append(s.result.Results, opError)
+	} else {
+		s.result.Results = append(s.result.Results, json.RawMessage(`{}`))
+	}
+	return expectDelim(decoder, '}')
+}
+
+// decodeRows calls decodeRow for each row of the "rows" array
+func (s *rowStream) decodeRows(decoder *json.Decoder) error {
+	if err := expectDelim(decoder, '['); err != nil {
+		return err
+	}
+	for decoder.More() {
+		if s.err = s.decodeRow(decoder); s.err != nil {
+			return s.err
+		}
+	}
+	return expectDelim(decoder, ']')
+}
+
+// expectDelim reads the next token from decoder and checks it is delim
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("unexpected token %v, want %v", token, delim)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestRowStream(t *testing.T) {
+	var names []string
+	stream := &rowStream{decodeRow: func(decoder *json.Decoder) error {
+		var row struct {
+			Name string `json:"name"`
+		}
+		if err := decoder.Decode(&row); err != nil {
+			return err
+		}
+		names = append(names, row.Name)
+		return nil
+	}}
+	err := json.Unmarshal([]byte(`[{"rows":[{"name":"br0"},{"name":"br1","other":[1,{"a":2}]}]},{}]`), stream)
+	if err != nil {
+		t.Fatalf("json unmarshal failed: %v", err)
+	}
+	if len(names) != 2 || names[1] != "br1" {
+		t.Errorf("decoded rows %v", names)
+	}
+	if len(stream.result.Results) != 2 || len(stream.result.Errors) != 0 {
+		t.Errorf("result = %v", stream.result)
+	}
+
+	// operation errors
+	stream = &rowStream{decodeRow: func(decoder *json.Decoder) error { return nil }}
+	err = json.Unmarshal([]byte(`[{"error":"syntax error","details":"bad table"}]`), stream)
+	if err != nil {
+		t.Fatalf("json unmarshal failed: %v", err)
+	}
+	if len(stream.result.Errors) != 1 || stream.result.Errors[0].Details != "bad table" {
+		t.Errorf("result errors = %v", stream.result.Errors)
+	}
+
+	// decodeRow errors stop decoding
+	errStop := errors.New("stop")
+	calls := 0
+	stream = &rowStream{decodeRow: func(decoder *json.Decoder) error {
+		calls++
+		return errStop
+	}}
+	if err := json.Unmarshal([]byte(`[{"rows":[{"name":"br0"},{"name":"br1"}]}]`), stream); err != nil {
+		t.Fatalf("json unmarshal failed: %v", err)
+	}
+	if stream.err != errStop || calls != 1 {
+		t.Errorf("stream error = %v after %d calls, want %v after 1", stream.err, calls, errStop)
+	}
+}
@@ -0,0 +1,556 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// DesiredRow is a row of the desired state synced by a Reconciler
+type DesiredRow struct {
+	Table ID
+	// Name, if not empty, lets other desired rows refer to the row with NamedUUID(Name),
+	// which is replaced with the UUID of the row if it already exists
+	Name ID
+	// Row holds the columns managed by the reconciler, other columns of existing rows are left untouched
+	Row map[ID]Value
+}
+
+// SyncAction is the kind of change made to a row by a SyncPlan
+type SyncAction string
+
+// SyncActions of RowChanges
+const (
+	SyncCreate SyncAction = "create"
+	SyncModify SyncAction = "modify"
+	SyncDelete SyncAction = "delete"
+)
+
+// FieldChange is the change of the value of a column, values are in the form returned by CanonicalValue
+type FieldChange struct {
+	Column ID    `json:"column"`
+	Old    Value `json:"old,omitempty"`
+	New    Value `json:"new,omitempty"`
+}
+
+// RowChange is the change of a row made by a SyncPlan
+type RowChange struct {
+	Action SyncAction `json:"action"`
+	Table  ID         `json:"table"`
+	// UUID is the UUID of the existing row, it's empty for created rows
+	UUID UUID `json:"uuid,omitempty"`
+	// Name is the name of the desired row, if any
+	Name   ID            `json:"name,omitempty"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// SyncPlan is the result of reconciling the observed content of tables with a desired state
+type SyncPlan struct {
+	Changes []RowChange
+	// Operations make the changes in one transaction
+	Operations []Operation
+}
+
+// Reconciler computes the operations turning the observed content of tables into a desired state.
+// Desired rows are matched with existing rows by the values of the key columns of their table,
+// the tables with key columns are managed by the reconciler: existing rows matching no desired row
+// are deleted, matched rows are modified where their columns differ, and other desired rows are created.
+// Changed sets and maps with more than one element allowed are mutated, other columns are updated.
+// A row is replaced with a new one if an immutable column differs, references to the row from other
+// desired rows follow the new row.
+// By default the reconciler owns all rows of the managed tables, see SetOwner to share them with others.
+type Reconciler struct {
+	dbSchema *DatabaseSchema
+	keys     map[ID][]ID
+	// owner is the tag marking owned rows, if any
+	owner *MapPair
+	// ownerColumn is the map column holding the tag
+	ownerColumn ID
+}
+
+// NewReconciler creates a Reconciler managing the tables of keys, which maps them to their key columns.
+// If the key columns of a table are empty, the first index of the table in dbSchema is used.
+func NewReconciler(dbSchema *DatabaseSchema, keys map[ID][]ID) (*Reconciler, error) {
+	r := &Reconciler{dbSchema: dbSchema, keys: make(map[ID][]ID, len(keys))}
+	for table, columns := range keys {
+		tableSchema, ok := dbSchema.Tables[table]
+		if !ok {
+			return nil, fmt.Errorf("table %s not found in schema", table)
+		}
+		if len(columns) == 0 {
+			if len(tableSchema.Indexes) == 0 {
+				return nil, fmt.Errorf("no key columns for table %s, which has no index", table)
+			}
+			for _, column := range tableSchema.Indexes[0] {
+				columns = append(columns, ID(column))
+			}
+		}
+		for _, column := range columns {
+			if _, ok := tableSchema.Columns[column]; !ok {
+				return nil, fmt.Errorf("key column %s not found in table %s", column, table)
+			}
+		}
+		r.keys[table] = columns
+	}
+	return r, nil
+}
+
+// SetOwner limits the rows owned by the reconciler to the rows it creates, which are tagged with
+// the pair key:value in map column, e.g. external_ids. Unmatched rows are only deleted if they have
+// the tag, so rows created by humans or other controllers are never pruned. Matched rows are modified
+// whether they are owned or not, rows owned keep their tag and others aren't adopted.
+// All managed tables must have column as a map of strings.
+func (r *Reconciler) SetOwner(column ID, key, value string) error {
+	for table := range r.keys {
+		columnSchema, ok := r.dbSchema.Tables[table].Columns[column]
+		if !ok || !columnSchema.IsMap() || columnSchema.KeyType() != TypeString || columnSchema.ValueType() != TypeString {
+			return fmt.Errorf("table %s has no string map column %s", table, column)
+		}
+	}
+	r.ownerColumn = column
+	r.owner = &MapPair{key, value}
+	return nil
+}
+
+// owns returns true if the observed row is owned by the reconciler
+func (r *Reconciler) owns(row map[ID]interface{}) bool {
+	if r.owner == nil {
+		return true
+	}
+	value, err := CanonicalValue(row[r.ownerColumn])
+	if err != nil {
+		return false
+	}
+	m, _ := value.(Map)
+	for _, pair := range m.Values {
+		if pair[0] == r.owner[0] && pair[1] == r.owner[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// tagged returns true if a desired row gets the owner tag: if it's created,
+// or if it's owned and the desired row holds the column of the tag, which would drop the tag otherwise
+func (r *Reconciler) tagged(row *syncRow, columns map[ID]interface{}, observed map[ID]map[UUID]map[ID]interface{}) bool {
+	if row.uuid == "" || row.replace {
+		return true
+	}
+	_, ok := columns[r.ownerColumn]
+	return ok && r.owns(observed[row.Table][row.uuid])
+}
+
+// tag adds the owner tag to the column values of a desired row encoded in JSON
+func (r *Reconciler) tag(columns map[ID]interface{}) {
+	pairs := []interface{}{}
+	if m, ok := columns[r.ownerColumn].([]interface{}); ok && len(m) == 2 && m[0] == mapMagic {
+		existing, _ := m[1].([]interface{})
+		for _, pair := range existing {
+			if p, ok := pair.([]interface{}); ok && len(p) == 2 && p[0] == r.owner[0] {
+				continue
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	pairs = append(pairs, []interface{}{r.owner[0], r.owner[1]})
+	columns[r.ownerColumn] = []interface{}{mapMagic, pairs}
+}
+
+// Sync reconciles the tables managed by r in database db with desired and applies the plan in one transaction.
+// The returned plan holds the changes made, it's empty if the tables are already in sync.
+// The rows are observed and the plan applied in separate transactions, the latter starts with waits for the
+// managed tables to still hold the observed rows, so it fails with a "timed out" error and changes nothing
+// if another client changed them in between, Sync can be retried then.
+func (c *Client) Sync(db ID, r *Reconciler, desired []DesiredRow) (*SyncPlan, error) {
+	observed, err := c.observe(db, r)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := r.Plan(desired, observed)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Operations) == 0 {
+		return plan, nil
+	}
+	ops := append(r.observedWaits(observed), plan.Operations...)
+	result, err := c.Transact(db, ops...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) != 0 {
+		return nil, result.Errors
+	}
+	return plan, nil
+}
+
+// PlanSync returns the plan Sync would apply without applying it, e.g. to preview the changes of a controller
+func (c *Client) PlanSync(db ID, r *Reconciler, desired []DesiredRow) (*SyncPlan, error) {
+	observed, err := c.observe(db, r)
+	if err != nil {
+		return nil, err
+	}
+	return r.Plan(desired, observed)
+}
+
+// observe selects the rows of the tables managed by r in database db
+func (c *Client) observe(db ID, r *Reconciler) (map[ID]map[UUID]map[ID]interface{}, error) {
+	managed := &DatabaseSchema{Name: r.dbSchema.Name, Tables: make(map[ID]*TableSchema, len(r.keys))}
+	for table := range r.keys {
+		managed.Tables[table] = r.dbSchema.Tables[table]
+	}
+	return c.selectAllRows(db, managed)
+}
+
+// observedWaits returns wait operations failing immediately unless the managed tables hold the observed rows
+func (r *Reconciler) observedWaits(observed map[ID]map[UUID]map[ID]interface{}) []Operation {
+	tables := make([]ID, 0, len(r.keys))
+	for table := range r.keys {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i] < tables[j] })
+	timeout := 0
+	ops := make([]Operation, 0, len(tables))
+	for _, table := range tables {
+		var columns []ID
+		for column := range r.dbSchema.Tables[table].Columns {
+			columns = append(columns, column)
+		}
+		sort.Slice(columns, func(i, j int) bool { return columns[i] < columns[j] })
+		rows := make([]Row, 0, len(observed[table]))
+		for uuid, row := range observed[table] {
+			// the server may have columns unknown to the schema, only the ones of the schema are compared
+			waited := map[ID]interface{}{"_uuid": uuid}
+			for _, column := range columns {
+				if value, ok := row[column]; ok {
+					waited[column] = value
+				}
+			}
+			rows = append(rows, waited)
+		}
+		columns = append(columns, "_uuid")
+		ops = append(ops, &WaitOperation{
+			Timeout: &timeout,
+			Table:   table,
+			Where:   MatchAll(),
+			Columns: columns,
+			Until:   WaitEqual,
+			Rows:    rows,
+		})
+	}
+	return ops
+}
+
+// syncRow is a desired row matched with the observed rows
+type syncRow struct {
+	DesiredRow
+	key  string
+	uuid UUID
+	// replace is true if the matched row is replaced by a new one
+	replace bool
+}
+
+// Plan computes the changes turning observed, the rows of the managed tables keyed by table and UUID
+// (e.g. a snapshot selected from the database), into desired
+func (r *Reconciler) Plan(desired []DesiredRow, observed map[ID]map[UUID]map[ID]interface{}) (*SyncPlan, error) {
+	// index observed rows by key
+	existing := make(map[ID]map[string]UUID, len(r.keys))
+	for table, columns := range r.keys {
+		existing[table] = make(map[string]UUID)
+		for uuid, row := range observed[table] {
+			key, err := rowKeyString(columns, row)
+			if err != nil {
+				return nil, fmt.Errorf("invalid row %s of table %s: %v", uuid, table, err)
+			}
+			existing[table][key] = uuid
+		}
+	}
+
+	// match desired rows
+	rows := make([]*syncRow, 0, len(desired))
+	names := make(map[ID]bool)
+	keys := make(map[ID]map[string]bool)
+	for _, d := range desired {
+		columns, ok := r.keys[d.Table]
+		if !ok {
+			return nil, fmt.Errorf("table %s is not managed", d.Table)
+		}
+		key, err := rowKeyString(columns, d.Row)
+		if err != nil {
+			return nil, fmt.Errorf("invalid desired row of table %s: %v", d.Table, err)
+		}
+		if keys[d.Table] == nil {
+			keys[d.Table] = make(map[string]bool)
+		}
+		if keys[d.Table][key] {
+			return nil, fmt.Errorf("duplicate desired row %s of table %s", key, d.Table)
+		}
+		keys[d.Table][key] = true
+		if d.Name != "" {
+			if names[d.Name] {
+				return nil, fmt.Errorf("duplicate row name %s", d.Name)
+			}
+			names[d.Name] = true
+		}
+		rows = append(rows, &syncRow{DesiredRow: d, key: key, uuid: existing[d.Table][key]})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Table != rows[j].Table {
+			return rows[i].Table < rows[j].Table
+		}
+		return rows[i].key < rows[j].key
+	})
+
+	// replace rows with changed immutable columns, until references to replaced rows change no more
+	var resolved []map[ID]interface{}
+	for {
+		uuids := make(map[ID]UUID)
+		for _, row := range rows {
+			if row.Name != "" && row.uuid != "" && !row.replace {
+				uuids[row.Name] = row.uuid
+			}
+		}
+		resolved = resolved[:0]
+		replaced := false
+		for _, row := range rows {
+			columns, refs, err := resolveColumns(row.Row, uuids)
+			if err != nil {
+				return nil, fmt.Errorf("invalid desired row %s of table %s: %v", row.key, row.Table, err)
+			}
+			for _, name := range refs {
+				if !names[name] {
+					return nil, fmt.Errorf("desired row %s of table %s refers to unknown row %s", row.key, row.Table, name)
+				}
+			}
+			if r.owner != nil && r.tagged(row, columns, observed) {
+				r.tag(columns)
+			}
+			resolved = append(resolved, columns)
+			if row.uuid == "" || row.replace {
+				continue
+			}
+			tableSchema := r.dbSchema.Tables[row.Table]
+			for column, value := range columns {
+				columnSchema, ok := tableSchema.Columns[column]
+				if ok && !columnSchema.Mutable && !ValueEqual(value, observed[row.Table][row.uuid][column]) {
+					row.replace = true
+					replaced = true
+					break
+				}
+			}
+		}
+		if !replaced {
+			break
+		}
+	}
+
+	plan := &SyncPlan{}
+	var inserts, updates, deletes []Operation
+	matched := make(map[UUID]bool)
+	replaced := make(map[UUID]bool)
+	for i, row := range rows {
+		if row.replace {
+			replaced[row.uuid] = true
+		}
+		if row.uuid != "" && !row.replace {
+			matched[row.uuid] = true
+			change, ops, err := r.modify(row, resolved[i], observed[row.Table][row.uuid])
+			if err != nil {
+				return nil, err
+			}
+			if change != nil {
+				plan.Changes = append(plan.Changes, *change)
+				updates = append(updates, ops...)
+			}
+			continue
+		}
+		change := RowChange{Action: SyncCreate, Table: row.Table, Name: row.Name}
+		for _, column := range sortedColumns(resolved[i]) {
+			value, err := CanonicalValue(resolved[i][column])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of column %s of desired row %s of table %s: %v", column, row.key, row.Table, err)
+			}
+			change.Fields = append(change.Fields, FieldChange{Column: column, New: value})
+		}
+		plan.Changes = append(plan.Changes, change)
+		inserts = append(inserts, &InsertOperation{Table: row.Table, Row: resolved[i], UUIDName: row.Name})
+	}
+
+	var tables []ID
+	for table := range r.keys {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i] < tables[j] })
+	for _, table := range tables {
+		var uuids []UUID
+		for uuid, row := range observed[table] {
+			// replaced rows are deleted no matter they're owned or not, so they don't conflict with the new rows
+			if !matched[uuid] && (replaced[uuid] || r.owns(row)) {
+				uuids = append(uuids, uuid)
+			}
+		}
+		sort.Slice(uuids, func(i, j int) bool { return uuids[i] < uuids[j] })
+		for _, uuid := range uuids {
+			plan.Changes = append(plan.Changes, RowChange{Action: SyncDelete, Table: table, UUID: uuid})
+			deletes = append(deletes, &DeleteOperation{Table: table, Where: []Condition{{"_uuid", FuncEq, uuid}}})
+		}
+	}
+
+	plan.Operations = append(plan.Operations, inserts...)
+	plan.Operations = append(plan.Operations, updates...)
+	plan.Operations = append(plan.Operations, deletes...)
+	return plan, nil
+}
+
+// modify returns the change and operations modifying the existing row observed into desired, which holds
+// JSON values with references resolved, the change is nil if the row is unchanged
+func (r *Reconciler) modify(row *syncRow, desired map[ID]interface{}, observed map[ID]interface{}) (*RowChange, []Operation, error) {
+	tableSchema := r.dbSchema.Tables[row.Table]
+	change := RowChange{Action: SyncModify, Table: row.Table, UUID: row.uuid, Name: row.Name}
+	updated := make(map[ID]interface{})
+	var mutations []Mutation
+	for _, column := range sortedColumns(desired) {
+		newValue, err := CanonicalValue(desired[column])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid value of column %s of desired row %s of table %s: %v", column, row.key, row.Table, err)
+		}
+		oldValue, err := CanonicalValue(observed[column])
+		if err != nil {
+			oldValue = nil
+		}
+		if ValueEqual(newValue, oldValue) {
+			continue
+		}
+		change.Fields = append(change.Fields, FieldChange{Column: column, Old: oldValue, New: newValue})
+
+		columnSchema, ok := tableSchema.Columns[column]
+		if ok && oldValue != nil && columnSchema.MaxElements() != 1 && (columnSchema.IsSet() || columnSchema.IsMap()) {
+			mutations = append(mutations, collectionMutations(column, columnSchema.IsMap(), oldValue, newValue)...)
+		} else {
+			updated[column] = desired[column]
+		}
+	}
+	if len(change.Fields) == 0 {
+		return nil, nil, nil
+	}
+
+	where := []Condition{{"_uuid", FuncEq, row.uuid}}
+	var ops []Operation
+	if len(updated) != 0 {
+		ops = append(ops, &UpdateOperation{Table: row.Table, Where: where, Row: updated})
+	}
+	if len(mutations) != 0 {
+		ops = append(ops, &MutateOperation{Table: row.Table, Where: where, Mutations: mutations})
+	}
+	return &change, ops, nil
+}
+
+// collectionMutations returns the mutations changing a set or map column from the canonical value oldValue to newValue
+func collectionMutations(column ID, isMap bool, oldValue, newValue Value) []Mutation {
+	var mutations []Mutation
+	if isMap {
+		oldPairs, newPairs := mapPairs(oldValue), mapPairs(newValue)
+		var deletes []Value
+		var inserts []MapPair
+		for key, oldPair := range oldPairs {
+			if newPair, ok := newPairs[key]; !ok || !ValueEqual(oldPair[1], newPair[1]) {
+				deletes = append(deletes, oldPair[0])
+			}
+		}
+		for key, newPair := range newPairs {
+			if oldPair, ok := oldPairs[key]; !ok || !ValueEqual(oldPair[1], newPair[1]) {
+				inserts = append(inserts, newPair)
+			}
+		}
+		sort.Slice(deletes, func(i, j int) bool { return compareAtoms(deletes[i], deletes[j]) < 0 })
+		sort.Slice(inserts, func(i, j int) bool { return compareAtoms(inserts[i][0], inserts[j][0]) < 0 })
+		if len(deletes) != 0 {
+			mutations = append(mutations, Mutation{column, MutatorDelete, Set{Values: deletes}})
+		}
+		if len(inserts) != 0 {
+			mutations = append(mutations, Mutation{column, MutatorInsert, Map{Values: inserts}})
+		}
+		return mutations
+	}
+
+	oldElements, newElements := setElements(oldValue), setElements(newValue)
+	var deletes, inserts []Value
+	for _, element := range oldElements {
+		if !containsAtom(newElements, element) {
+			deletes = append(deletes, element)
+		}
+	}
+	for _, element := range newElements {
+		if !containsAtom(oldElements, element) {
+			inserts = append(inserts, element)
+		}
+	}
+	if len(deletes) != 0 {
+		mutations = append(mutations, Mutation{column, MutatorDelete, Set{Values: deletes}})
+	}
+	if len(inserts) != 0 {
+		mutations = append(mutations, Mutation{column, MutatorInsert, Set{Values: inserts}})
+	}
+	return mutations
+}
+
+// setElements returns the elements of a canonical set, which is a single atom if it has one element
+func setElements(v Value) []Value {
+	if set, ok := v.(Set); ok {
+		return set.Values
+	}
+	return []Value{v}
+}
+
+// mapPairs returns the pairs of a canonical map keyed by the JSON encoding of their keys
+func mapPairs(v Value) map[string]MapPair {
+	m, _ := v.(Map)
+	pairs := make(map[string]MapPair, len(m.Values))
+	for _, pair := range m.Values {
+		key, _ := json.Marshal(pair[0])
+		pairs[string(key)] = pair
+	}
+	return pairs
+}
+
+// containsAtom returns true if the canonical atom is one of values
+func containsAtom(values []Value, atom Atomic) bool {
+	for _, value := range values {
+		if compareAtoms(value, atom) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rowKeyString returns the JSON encoding of the canonical values of columns of row, which identifies the row
+func rowKeyString(columns []ID, row interface{}) (string, error) {
+	values, err := rowColumns(row)
+	if err != nil {
+		return "", err
+	}
+	var key []Value
+	for _, column := range columns {
+		raw, ok := values[column]
+		if !ok {
+			return "", fmt.Errorf("key column %s is missing", column)
+		}
+		value, err := CanonicalValue(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid value of key column %s: %v", column, err)
+		}
+		key = append(key, value)
+	}
+	bytes, err := json.Marshal(key)
+	return string(bytes), err
+}
+
+// sortedColumns returns the columns of row in order
+func sortedColumns(row map[ID]interface{}) []ID {
+	columns := make([]ID, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i] < columns[j] })
+	return columns
+}
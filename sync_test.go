@@ -0,0 +1,276 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+const syncSchema = `{
+	"name": "OVN_Northbound",
+	"version": "5.10.0",
+	"tables": {
+		"Logical_Switch": {
+			"columns": {
+				"name": {"type": "string"},
+				"ports": {"type": {"key": {"type": "uuid", "refTable": "Logical_Switch_Port"}, "min": 0, "max": "unlimited"}},
+				"other_config": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+			},
+			"isRoot": true,
+			"indexes": [["name"]]
+		},
+		"Logical_Switch_Port": {
+			"columns": {
+				"name": {"type": "string"},
+				"type": {"type": "string", "mutable": false}
+			},
+			"indexes": [["name"]]
+		}
+	}
+}`
+
+func syncFixture(t *testing.T) (*Reconciler, map[ID]map[UUID]map[ID]interface{}) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(syncSchema), &dbSchema); err != nil {
+		t.Fatalf("failed to decode schema: %v", err)
+	}
+	var observed map[ID]map[UUID]map[ID]interface{}
+	err := json.Unmarshal([]byte(`{
+		"Logical_Switch": {
+			"`+ls1+`": {"name": "sw0", "ports": ["uuid", "`+lsp1+`"], "other_config": ["map", [["a", "1"], ["b", "2"]]]},
+			"`+ls2+`": {"name": "sw1", "ports": ["set", []], "other_config": ["map", []]}
+		},
+		"Logical_Switch_Port": {
+			"`+lsp1+`": {"name": "p0", "type": ""},
+			"`+lsp2+`": {"name": "p1", "type": "router"}
+		}
+	}`), &observed)
+	if err != nil {
+		t.Fatalf("failed to decode rows: %v", err)
+	}
+	r, err := NewReconciler(&dbSchema, map[ID][]ID{"Logical_Switch": nil, "Logical_Switch_Port": {"name"}})
+	if err != nil {
+		t.Fatalf("NewReconciler failed: %v", err)
+	}
+	return r, observed
+}
+
+func TestReconcilerPlan(t *testing.T) {
+	r, observed := syncFixture(t)
+	desired := []DesiredRow{
+		{Table: "Logical_Switch", Row: map[ID]Value{
+			"name":         "sw0",
+			"ports":        Set{Values: []Value{NamedUUID("p0"), NamedUUID("p1"), NamedUUID("p2")}},
+			"other_config": Map{Values: []MapPair{{"a", "1"}, {"b", "3"}, {"c", "4"}}},
+		}},
+		{Table: "Logical_Switch_Port", Name: "p0", Row: map[ID]Value{"name": "p0", "type": ""}},
+		{Table: "Logical_Switch_Port", Name: "p1", Row: map[ID]Value{"name": "p1", "type": ""}},
+		{Table: "Logical_Switch_Port", Name: "p2", Row: map[ID]Value{"name": "p2", "type": "localnet"}},
+	}
+	plan, err := r.Plan(desired, observed)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	bytes, err := json.Marshal(plan.Operations)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	want := `[{"op":"insert","table":"Logical_Switch_Port","row":{"name":"p1","type":""},"uuid-name":"p1"},` +
+		`{"op":"insert","table":"Logical_Switch_Port","row":{"name":"p2","type":"localnet"},"uuid-name":"p2"},` +
+		`{"op":"mutate","table":"Logical_Switch","where":[["_uuid","==",["uuid","` + ls1 + `"]]],"mutations":[` +
+		`["other_config","delete","b"],["other_config","insert",["map",[["b","3"],["c","4"]]]],` +
+		`["ports","insert",["set",[["named-uuid","p1"],["named-uuid","p2"]]]]]},` +
+		`{"op":"delete","table":"Logical_Switch","where":[["_uuid","==",["uuid","` + ls2 + `"]]]},` +
+		`{"op":"delete","table":"Logical_Switch_Port","where":[["_uuid","==",["uuid","` + lsp2 + `"]]]}]`
+	if string(bytes) != want {
+		t.Errorf("operations = %s, want %s", bytes, want)
+	}
+
+	var actions []string
+	for _, change := range plan.Changes {
+		actions = append(actions, string(change.Action)+" "+string(change.Table))
+	}
+	wantActions := []string{
+		"modify Logical_Switch", "create Logical_Switch_Port", "create Logical_Switch_Port",
+		"delete Logical_Switch", "delete Logical_Switch_Port",
+	}
+	if len(actions) != len(wantActions) {
+		t.Fatalf("changes = %v, want %v", actions, wantActions)
+	}
+	for i := range wantActions {
+		if actions[i] != wantActions[i] {
+			t.Errorf("change %d = %s, want %s", i, actions[i], wantActions[i])
+		}
+	}
+	if fields := plan.Changes[0].Fields; len(fields) != 2 || fields[0].Column != "other_config" || fields[1].Column != "ports" {
+		t.Errorf("fields of modified switch = %+v", fields)
+	}
+}
+
+func TestReconcilerPlanInSync(t *testing.T) {
+	r, observed := syncFixture(t)
+	desired := []DesiredRow{
+		{Table: "Logical_Switch", Row: map[ID]Value{"name": "sw0", "ports": NamedUUID("p0")}},
+		{Table: "Logical_Switch", Row: map[ID]Value{"name": "sw1"}},
+		{Table: "Logical_Switch_Port", Name: "p0", Row: map[ID]Value{"name": "p0"}},
+		{Table: "Logical_Switch_Port", Row: map[ID]Value{"name": "p1", "type": "router"}},
+	}
+	plan, err := r.Plan(desired, observed)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(plan.Changes) != 0 || len(plan.Operations) != 0 {
+		t.Errorf("plan = %+v, want no change", plan)
+	}
+
+	if _, err := r.Plan([]DesiredRow{{Table: "ACL", Row: map[ID]Value{"name": "x"}}}, observed); err == nil {
+		t.Error("expect error for unmanaged table, but got nil")
+	}
+	if _, err := r.Plan([]DesiredRow{{Table: "Logical_Switch", Row: map[ID]Value{"name": "sw0", "ports": NamedUUID("missing")}}}, observed); err == nil {
+		t.Error("expect error for reference to unknown row, but got nil")
+	}
+}
+
+func TestSyncPlanDiff(t *testing.T) {
+	r, observed := syncFixture(t)
+	desired := []DesiredRow{
+		{Table: "Logical_Switch", Row: map[ID]Value{"name": "sw0", "other_config": Map{Values: []MapPair{{"a", "1"}}}}},
+		{Table: "Logical_Switch", Row: map[ID]Value{"name": "sw1"}},
+		{Table: "Logical_Switch_Port", Row: map[ID]Value{"name": "p0"}},
+		{Table: "Logical_Switch_Port", Name: "p2", Row: map[ID]Value{"name": "p2"}},
+	}
+	plan, err := r.Plan(desired, observed)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	want := `~ Logical_Switch ` + ls1 + `
+    other_config: ["map",[["a","1"],["b","2"]]] -> ["map",[["a","1"]]]
++ Logical_Switch_Port p2
+    name: "p2"
+- Logical_Switch_Port ` + lsp2 + `
+
+1 to create, 1 to modify, 1 to delete
+`
+	if plan.String() != want {
+		t.Errorf("String() = %s, want %s", plan, want)
+	}
+
+	bytes, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	wantJSON := `{"changes":[{"action":"modify","table":"Logical_Switch","uuid":["uuid","` + ls1 + `"],"fields":[{"column":"other_config","old":["map",[["a","1"],["b","2"]]],"new":["map",[["a","1"]]]}]},` +
+		`{"action":"create","table":"Logical_Switch_Port","name":"p2","fields":[{"column":"name","new":"p2"}]},` +
+		`{"action":"delete","table":"Logical_Switch_Port","uuid":["uuid","` + lsp2 + `"]}],` +
+		`"summary":{"create":1,"modify":1,"delete":1}}`
+	if string(bytes) != wantJSON {
+		t.Errorf("json = %s, want %s", bytes, wantJSON)
+	}
+
+	if s := (&SyncPlan{}).String(); s != "No changes\n" {
+		t.Errorf("String() of empty plan = %q", s)
+	}
+}
+
+func TestReconcilerOwner(t *testing.T) {
+	r, observed := syncFixture(t)
+	observed["Logical_Switch"][ls1]["other_config"] = []interface{}{"map", []interface{}{[]interface{}{"owner", "ctl"}}}
+	if err := r.SetOwner("name", "owner", "ctl"); err == nil {
+		t.Error("expect error for owner column which is not a map, but got nil")
+	}
+	r.keys = map[ID][]ID{"Logical_Switch": {"name"}}
+	if err := r.SetOwner("other_config", "owner", "ctl"); err != nil {
+		t.Fatalf("SetOwner failed: %v", err)
+	}
+
+	plan, err := r.Plan([]DesiredRow{{Table: "Logical_Switch", Row: map[ID]Value{"name": "sw2"}}}, observed)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	bytes, err := json.Marshal(plan.Operations)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	// sw1 is not owned and is left alone
+	want := `[{"op":"insert","table":"Logical_Switch","row":{"name":"sw2","other_config":["map",[["owner","ctl"]]]}},` +
+		`{"op":"delete","table":"Logical_Switch","where":[["_uuid","==",["uuid","` + ls1 + `"]]]}]`
+	if string(bytes) != want {
+		t.Errorf("operations = %s, want %s", bytes, want)
+	}
+
+	// owned rows keep their tag, others aren't adopted
+	plan, err = r.Plan([]DesiredRow{
+		{Table: "Logical_Switch", Row: map[ID]Value{"name": "sw0", "other_config": Map{Values: []MapPair{{"a", "1"}}}}},
+		{Table: "Logical_Switch", Row: map[ID]Value{"name": "sw1", "other_config": Map{Values: []MapPair{{"a", "1"}}}}},
+	}, observed)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if bytes, err = json.Marshal(plan.Operations); err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	want = `[{"op":"mutate","table":"Logical_Switch","where":[["_uuid","==",["uuid","` + ls1 + `"]]],"mutations":[["other_config","insert",["map",[["a","1"]]]]]},` +
+		`{"op":"mutate","table":"Logical_Switch","where":[["_uuid","==",["uuid","` + ls2 + `"]]],"mutations":[["other_config","insert",["map",[["a","1"]]]]]}]`
+	if string(bytes) != want {
+		t.Errorf("operations = %s, want %s", bytes, want)
+	}
+}
+
+func TestSyncWaitsForObservedRows(t *testing.T) {
+	r, _ := syncFixture(t)
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	applied := make(chan []json.RawMessage, 1)
+	server.Handle("transact", func(params []json.RawMessage) (interface{}, error) {
+		var op struct {
+			Op string `json:"op"`
+		}
+		json.Unmarshal(params[1], &op)
+		if op.Op == "select" {
+			port := map[string]interface{}{"_uuid": []string{"uuid", lsp1}, "_version": []string{"uuid", lsp2}, "name": "p0", "type": ""}
+			return []interface{}{
+				map[string]interface{}{"rows": []interface{}{}},
+				map[string]interface{}{"rows": []interface{}{port}},
+			}, nil
+		}
+		applied <- params[1:]
+		results := make([]interface{}, len(params)-1)
+		for i := range results {
+			results[i] = map[string]interface{}{}
+		}
+		return results, nil
+	})
+
+	plan, err := client.Sync("OVN_Northbound", r, []DesiredRow{
+		{Table: "Logical_Switch", Row: map[ID]Value{"name": "sw0"}},
+		{Table: "Logical_Switch_Port", Row: map[ID]Value{"name": "p0"}},
+	})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	ops := <-applied
+	if len(ops) != len(plan.Operations)+2 {
+		t.Fatalf("%d operations applied, want the %d of the plan and 2 waits", len(ops), len(plan.Operations))
+	}
+	for i, want := range []string{
+		`{"op":"wait","timeout":0,"table":"Logical_Switch","where":[["_uuid","!=",["uuid","00000000-0000-0000-0000-000000000000"]]],` +
+			`"columns":["name","other_config","ports","_uuid"],"until":"==","rows":[]}`,
+		`{"op":"wait","timeout":0,"table":"Logical_Switch_Port","where":[["_uuid","!=",["uuid","00000000-0000-0000-0000-000000000000"]]],` +
+			`"columns":["name","type","_uuid"],"until":"==","rows":[{"_uuid":["uuid","` + lsp1 + `"],"name":"p0","type":""}]}`,
+	} {
+		var got, expected interface{}
+		json.Unmarshal(ops[i], &got)
+		json.Unmarshal([]byte(want), &expected)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("operation %d = %s, want %s", i, ops[i], want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ovsdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SyncSummary counts the rows changed by a SyncPlan by action
+type SyncSummary struct {
+	Create int `json:"create"`
+	Modify int `json:"modify"`
+	Delete int `json:"delete"`
+}
+
+// Summary counts the changes of the plan
+func (plan *SyncPlan) Summary() SyncSummary {
+	var summary SyncSummary
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case SyncCreate:
+			summary.Create++
+		case SyncModify:
+			summary.Modify++
+		case SyncDelete:
+			summary.Delete++
+		}
+	}
+	return summary
+}
+
+// String renders the plan as a human readable diff: each changed row is a line starting with
+// "+", "~" or "-" for created, modified and deleted rows followed by its table and UUID (or name if it's
+// created), then an indented line per changed column with its old and new values, and a summary line.
+func (plan *SyncPlan) String() string {
+	var b bytes.Buffer
+	for _, change := range plan.Changes {
+		id := string(change.UUID)
+		if id == "" {
+			id = string(change.Name)
+		}
+		switch change.Action {
+		case SyncCreate:
+			fmt.Fprintf(&b, "+ %s %s\n", change.Table, id)
+		case SyncModify:
+			fmt.Fprintf(&b, "~ %s %s\n", change.Table, id)
+		case SyncDelete:
+			fmt.Fprintf(&b, "- %s %s\n", change.Table, id)
+		}
+		for _, field := range change.Fields {
+			if change.Action == SyncCreate {
+				fmt.Fprintf(&b, "    %s: %s\n", field.Column, formatDiffValue(field.New))
+			} else {
+				fmt.Fprintf(&b, "    %s: %s -> %s\n", field.Column, formatDiffValue(field.Old), formatDiffValue(field.New))
+			}
+		}
+	}
+	if len(plan.Changes) == 0 {
+		b.WriteString("No changes\n")
+		return b.String()
+	}
+	summary := plan.Summary()
+	fmt.Fprintf(&b, "\n%d to create, %d to modify, %d to delete\n", summary.Create, summary.Modify, summary.Delete)
+	return b.String()
+}
+
+// MarshalJSON implements json.Marshaler, the plan is encoded as its changes and summary:
+// {"changes": [<row-change>*], "summary": {"create": <n>, "modify": <n>, "delete": <n>}}
+func (plan SyncPlan) MarshalJSON() ([]byte, error) {
+	changes := plan.Changes
+	if changes == nil {
+		changes = []RowChange{}
+	}
+	return json.Marshal(struct {
+		Changes []RowChange `json:"changes"`
+		Summary SyncSummary `json:"summary"`
+	}{changes, plan.Summary()})
+}
+
+// formatDiffValue formats a canonical value as its JSON encoding
+func formatDiffValue(v Value) string {
+	if v == nil {
+		return "(none)"
+	}
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(bytes)
+}
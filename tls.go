@@ -0,0 +1,131 @@
+package ovsdb
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+// TLSFiles are the PEM files of the TLS configuration of ssl connections, like the
+// --private-key, --certificate and --ca-cert options of ovsdb-client
+type TLSFiles struct {
+	// PrivateKey and Certificate are the key and certificate presented to the server
+	PrivateKey  string
+	Certificate string
+	// CACert is the bundle of CA certificates the certificate of the server is verified with,
+	// it's required unless InsecureSkipVerify is set
+	CACert string
+	// InsecureSkipVerify accepts any certificate of the server, it's only meant for testing
+	InsecureSkipVerify bool
+}
+
+// Config loads the files into a tls.Config. Like ovsdb-client, the certificate of the server is
+// verified with the CA bundle but not its host name, since certificates made by ovs-pki name
+// the component and not the host.
+func (files TLSFiles) Config() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(files.Certificate, files.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the private key and certificate: %v", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		// verification is done by VerifyPeerCertificate, without the host name
+		InsecureSkipVerify: true,
+	}
+	if files.InsecureSkipVerify {
+		return config, nil
+	}
+	if files.CACert == "" {
+		return nil, errors.New("no CA certificate to verify the server with")
+	}
+	pem, err := ioutil.ReadFile(files.CACert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate in %s", files.CACert)
+	}
+	config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		return verifyChain(rawCerts, roots)
+	}
+	return config, nil
+}
+
+// verifyChain verifies the certificate chain presented by the server with roots, ignoring its name
+func verifyChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("bad certificate: %v", err)
+		}
+		certs[i] = cert
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// WithTLS sets the TLS configuration of "ssl:<host>:<port>" connections. Unless config sets
+// ServerName or InsecureSkipVerify, the certificate of the server must be valid for <host>.
+// Without it, ssl connections verify the server with the system roots and present no certificate.
+func WithTLS(config *tls.Config) DialOption {
+	return func(o *dialOptions) {
+		o.tlsConfig = config
+		o.tlsErr = nil
+	}
+}
+
+// WithTLSFiles sets the TLS configuration of "ssl:<host>:<port>" connections to the one loaded from files,
+// see TLSFiles.Config. The files are loaded once, if that fails Dial returns the error.
+func WithTLSFiles(files TLSFiles) DialOption {
+	return func(o *dialOptions) {
+		o.tlsConfig, o.tlsErr = files.Config()
+	}
+}
+
+// handshake establishes a TLS session over conn to the server at address, "<host>:<port>"
+func (o *dialOptions) handshake(ctx context.Context, conn net.Conn, address string) (net.Conn, error) {
+	config := o.tlsConfig
+	if config == nil {
+		config = &tls.Config{}
+	}
+	if config.ServerName == "" && !config.InsecureSkipVerify {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+	// the handshake is bounded by the deadline of ctx, cancellation isn't observed
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
@@ -0,0 +1,155 @@
+package ovsdb
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+// testCert is a certificate and its key made by newTestCert
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert makes a certificate named like by ovs-pki, without host names, signed by parent or self-signed
+func newTestCert(t *testing.T, name string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{usage}
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert, key}
+}
+
+// tlsCertificate returns c as a tls.Certificate
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+// write writes the PEM files of c in dir, returning the paths of the key and certificate
+func (c *testCert) write(t *testing.T, dir, name string) (string, string) {
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath, certPath := filepath.Join(dir, name+"-privkey.pem"), filepath.Join(dir, name+"-cert.pem")
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return keyPath, certPath
+}
+
+func TestDialTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "switchca", nil, 0)
+	serverCert := newTestCert(t, "ovsdb-server", ca, x509.ExtKeyUsageServerAuth)
+	clientCert := newTestCert(t, "ovn-controller", ca, x509.ExtKeyUsageClientAuth)
+	_, caPath := ca.write(t, dir, "cacert")
+	keyPath, certPath := clientCert.write(t, dir, "client")
+	otherCA := newTestCert(t, "otherca", nil, 0)
+	_, otherCAPath := otherCA.write(t, dir, "othercacert")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	serverConfig := &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate()},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	// listenTLS accepts one ssl connection, its server is sent to servers once the handshake succeeds
+	servers := make(chan *ovsdbtest.Server, 1)
+	listenTLS := func() string {
+		address := listen(t, func(conn net.Conn) {
+			tlsConn := tls.Server(conn, serverConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				conn.Close()
+				return
+			}
+			servers <- ovsdbtest.NewServer(tlsConn)
+		})
+		return "ssl:" + strings.TrimPrefix(address, "tcp:")
+	}
+
+	client, err := Dial(listenTLS(), WithTLSFiles(TLSFiles{PrivateKey: keyPath, Certificate: certPath, CACert: caPath}))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	server := <-servers
+	defer server.Close()
+	if _, err := client.ListDbs(); err != nil {
+		t.Errorf("ListDbs failed: %v", err)
+	}
+	client.Close()
+
+	// the server isn't verified with the wrong CA
+	if _, err := Dial(listenTLS(), WithTLSFiles(TLSFiles{PrivateKey: keyPath, Certificate: certPath, CACert: otherCAPath})); err == nil {
+		t.Error("Dial verified the server with the wrong CA")
+	}
+	// unless verification is skipped
+	client, err = Dial(listenTLS(), WithTLSFiles(TLSFiles{PrivateKey: keyPath, Certificate: certPath, InsecureSkipVerify: true}))
+	if err != nil {
+		t.Fatalf("Dial skipping verification failed: %v", err)
+	}
+	server = <-servers
+	defer server.Close()
+	client.Close()
+
+	// a tls.Config verifies the host name by default, which the certificate of the server doesn't have
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	_, err = Dial(listenTLS(), WithTLS(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{clientCert.tlsCertificate()}}))
+	if err == nil || !strings.Contains(err.Error(), "127.0.0.1") {
+		t.Errorf("Dial = %v, want host name verification error", err)
+	}
+
+	// bad files fail Dial before connecting
+	_, err = Dial("ssl:127.0.0.1:1", WithTLSFiles(TLSFiles{PrivateKey: keyPath, Certificate: certPath}))
+	if err == nil || !strings.Contains(err.Error(), "bad TLS configuration") {
+		t.Errorf("Dial = %v, want configuration error", err)
+	}
+}
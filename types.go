@@ -22,6 +22,39 @@ var (
 // the user.
 type ID string
 
+// ValidateIDs controls whether IDs are validated when building operations and loading schemas.
+// It's an escape hatch for servers using non-standard identifiers, don't turn it off otherwise.
+var ValidateIDs = true
+
+// Valid returns true if id matches [a-zA-Z_][a-zA-Z0-9_]*
+func (id ID) Valid() bool {
+	if len(id) == 0 {
+		return false
+	}
+	for i, c := range id {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Reserved returns true if id begins with _, which is reserved to the implementation, e.g. "_uuid"
+func (id ID) Reserved() bool {
+	return len(id) > 0 && id[0] == '_'
+}
+
+// validateID returns an error describing what if id is not a valid ID, unless ValidateIDs is off
+func validateID(what string, id ID) error {
+	if ValidateIDs && !id.Valid() {
+		return fmt.Errorf("Invalid %s %q: must match [a-zA-Z_][a-zA-Z0-9_]*", what, id)
+	}
+	return nil
+}
+
 // Version is a JSON string that contains a version number that matches [0-9]+
 // \.[0-9]+\.[0-9]+
 type Version string
@@ -100,3 +100,28 @@ func TestNamedUUIDUnmarshal(t *testing.T) {
 	}
 
 }
+
+func TestIDValid(t *testing.T) {
+	tests := []struct {
+		id       ID
+		valid    bool
+		reserved bool
+	}{
+		{"Bridge", true, false},
+		{"external_ids", true, false},
+		{"_uuid", true, true},
+		{"column2", true, false},
+		{"", false, false},
+		{"2column", false, false},
+		{"with-dash", false, false},
+		{"with space", false, false},
+	}
+	for _, test := range tests {
+		if valid := test.id.Valid(); valid != test.valid {
+			t.Errorf("ID(%q).Valid() = %v, want %v", test.id, valid, test.valid)
+		}
+		if reserved := test.id.Reserved(); reserved != test.reserved {
+			t.Errorf("ID(%q).Reserved() = %v, want %v", test.id, reserved, test.reserved)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package ovsdb
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// UnindexedQueryError describes conditions which can't be served by any index of their table,
+// such queries make ovsdb-server scan the whole table, a common scalability trap on large tables
+type UnindexedQueryError struct {
+	Database ID
+	Table    ID
+	// Op is the type of the operation, or "monitor_cond" for the conditions of a monitor
+	Op    OperationType
+	Where []Condition
+}
+
+// Error implements error interface
+func (err *UnindexedQueryError) Error() string {
+	columns := make([]string, 0, len(err.Where))
+	for _, cond := range err.Where {
+		columns = append(columns, string(cond.Column))
+	}
+	return fmt.Sprintf("unindexed %s on %s.%s: conditions on %s cause a full table scan",
+		err.Op, err.Database, err.Table, strings.Join(columns, ", "))
+}
+
+// opMonitorCond is the Op of UnindexedQueryErrors of monitor conditions
+const opMonitorCond OperationType = "monitor_cond"
+
+// QueryIndex returns the index of tableSchema serving where, and false if there's none and the query
+// scans the table. Like ovsdb-server, an index serves where if it has a "==" condition on every column
+// of the index; "_uuid" is always indexed. Conditions matched by all rows, e.g. MatchAll, read the whole
+// table on purpose and are reported as served, with a nil index.
+func QueryIndex(tableSchema *TableSchema, where []Condition) (ColumnSet, bool) {
+	if matchesAll(where) {
+		return nil, true
+	}
+	equal := make(map[string]bool)
+	for _, cond := range where {
+		if cond.Function == FuncEq {
+			equal[string(cond.Column)] = true
+		}
+	}
+	if equal["_uuid"] {
+		return ColumnSet{"_uuid"}, true
+	}
+	for _, index := range tableSchema.Indexes {
+		served := len(index) != 0
+		for _, column := range index {
+			served = served && equal[column]
+		}
+		if served {
+			return index, true
+		}
+	}
+	return nil, false
+}
+
+// matchesAll returns true if where is matched by all rows, i.e. it's empty or MatchAll
+func matchesAll(where []Condition) bool {
+	for _, cond := range where {
+		if cond.Column != "_uuid" || cond.Function != FuncNe || cond.Value != UUID(zeroUUID) {
+			return false
+		}
+	}
+	return true
+}
+
+// UnindexedQueries returns a policy which calls report for operations on tables of dbSchema whose conditions
+// can't be served by an index, see QueryIndex. The operation is allowed if report returns nil, vetoed
+// otherwise, e.g. report can count queries as a metric, or return its argument to forbid unindexed queries
+// in tests. If report is nil, unindexed queries are logged with log.Printf and allowed.
+func UnindexedQueries(dbSchema *DatabaseSchema, report func(err *UnindexedQueryError) error) OperationPolicy {
+	if report == nil {
+		report = func(err *UnindexedQueryError) error {
+			log.Printf("ovsdb: %v", err)
+			return nil
+		}
+	}
+	return func(db ID, op Operation) error {
+		if db != dbSchema.Name {
+			return nil
+		}
+		where, ok := operationWhere(op)
+		if !ok {
+			return nil
+		}
+		table := OperationTable(op)
+		tableSchema, ok := dbSchema.Tables[table]
+		if !ok {
+			return nil
+		}
+		if _, ok := QueryIndex(tableSchema, where); ok {
+			return nil
+		}
+		return report(&UnindexedQueryError{Database: db, Table: table, Op: op.Op(), Where: where})
+	}
+}
+
+// UnindexedMonitorConds returns the conditions of requests, a monitor_cond request on database dbSchema,
+// which can't be served by an index. A row is monitored if it matches any condition, so every condition
+// is checked on its own.
+func UnindexedMonitorConds(dbSchema *DatabaseSchema, requests MonitorCondRequests) []*UnindexedQueryError {
+	tables := make([]ID, 0, len(requests))
+	for table := range requests {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i] < tables[j] })
+	var unindexed []*UnindexedQueryError
+	for _, table := range tables {
+		tableSchema, ok := dbSchema.Tables[table]
+		if !ok {
+			continue
+		}
+		var where []Condition
+		for _, cond := range requests[table].Where {
+			if _, ok := QueryIndex(tableSchema, []Condition{cond}); !ok {
+				where = append(where, cond)
+			}
+		}
+		if len(where) != 0 {
+			unindexed = append(unindexed, &UnindexedQueryError{
+				Database: dbSchema.Name,
+				Table:    table,
+				Op:       opMonitorCond,
+				Where:    where,
+			})
+		}
+	}
+	return unindexed
+}
+
+// operationWhere returns the conditions of op, false if op has none
+func operationWhere(op Operation) ([]Condition, bool) {
+	switch o := op.(type) {
+	case *SelectOperation:
+		return o.Where, true
+	case *UpdateOperation:
+		return o.Where, true
+	case *MutateOperation:
+		return o.Where, true
+	case *DeleteOperation:
+		return o.Where, true
+	case *WaitOperation:
+		return o.Where, true
+	case *boundOperation:
+		return operationWhere(o.template)
+	}
+	return nil, false
+}
@@ -0,0 +1,70 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnindexedQueries(t *testing.T) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(syncSchema), &dbSchema); err != nil {
+		t.Fatalf("failed to decode schema: %v", err)
+	}
+	var reported []string
+	policy := UnindexedQueries(&dbSchema, func(err *UnindexedQueryError) error {
+		reported = append(reported, err.Error())
+		if err.Table == "Logical_Switch_Port" {
+			return err
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name   string
+		db     ID
+		op     Operation
+		denied bool
+	}{
+		{"indexed select", "OVN_Northbound", &SelectOperation{Table: "Logical_Switch", Where: []Condition{{"name", FuncEq, "sw0"}}}, false},
+		{"uuid select", "OVN_Northbound", &DeleteOperation{Table: "Logical_Switch_Port", Where: []Condition{{"_uuid", FuncEq, UUID(lsp1)}, {"type", FuncEq, ""}}}, false},
+		{"select all", "OVN_Northbound", &SelectOperation{Table: "Logical_Switch_Port", Where: MatchAll()}, false},
+		{"insert", "OVN_Northbound", &InsertOperation{Table: "Logical_Switch_Port", Row: map[ID]Value{"name": "p0"}}, false},
+		{"other database", "OVN_Southbound", &SelectOperation{Table: "Logical_Switch_Port", Where: []Condition{{"type", FuncEq, ""}}}, false},
+		{"not equal", "OVN_Northbound", &SelectOperation{Table: "Logical_Switch", Where: []Condition{{"name", FuncNe, "sw0"}}}, false},
+		{"unindexed update", "OVN_Northbound", &UpdateOperation{Table: "Logical_Switch_Port", Where: []Condition{{"type", FuncEq, "router"}}, Row: map[ID]Value{"name": "p1"}}, true},
+	}
+	for _, test := range tests {
+		err := policy(test.db, test.op)
+		if denied := err != nil; denied != test.denied {
+			t.Errorf("%s: policy returned %v, want denied %v", test.name, err, test.denied)
+		}
+	}
+	want := []string{
+		"unindexed select on OVN_Northbound.Logical_Switch: conditions on name cause a full table scan",
+		"unindexed update on OVN_Northbound.Logical_Switch_Port: conditions on type cause a full table scan",
+	}
+	if !reflect.DeepEqual(reported, want) {
+		t.Errorf("reported %q, want %q", reported, want)
+	}
+}
+
+func TestUnindexedMonitorConds(t *testing.T) {
+	var dbSchema DatabaseSchema
+	if err := json.Unmarshal([]byte(syncSchema), &dbSchema); err != nil {
+		t.Fatalf("failed to decode schema: %v", err)
+	}
+	unindexed := UnindexedMonitorConds(&dbSchema, MonitorCondRequests{
+		"Logical_Switch":      {Where: []Condition{{"name", FuncEq, "sw0"}, {"name", FuncEq, "sw1"}}},
+		"Logical_Switch_Port": {Where: []Condition{{"name", FuncEq, "p0"}, {"type", FuncEq, "router"}}},
+	})
+	want := []*UnindexedQueryError{{
+		Database: "OVN_Northbound",
+		Table:    "Logical_Switch_Port",
+		Op:       "monitor_cond",
+		Where:    []Condition{{"type", FuncEq, "router"}},
+	}}
+	if !reflect.DeepEqual(unindexed, want) {
+		t.Errorf("UnindexedMonitorConds returned %v, want %v", unindexed, want)
+	}
+}
@@ -0,0 +1,117 @@
+package ovsdb
+
+import (
+	"encoding/json"
+)
+
+// UpdateFilter decides whether the update of row uuid of table is delivered to the notification handler,
+// it returns false to drop the update
+type UpdateFilter func(table ID, uuid UUID, update RowUpdate) bool
+
+// AddUpdateFilter registers filter to run on "update" notifications before the notification handler,
+// so high-volume updates the handler is not interested in, e.g. of statistics columns, are dropped cheaply.
+// Updates are delivered only if all filters keep them, a notification left without updates is not delivered.
+// Updates of monitors created by helpers of this package, e.g. sessions and WatchDatabases, are not filtered.
+// Neither are "update2" notifications of monitors created with MonitorCond: their modifications hold the
+// differences of the columns rather than rows, which filters don't expect.
+func (c *Client) AddUpdateFilter(filter UpdateFilter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.updateFilters = append(c.updateFilters, filter)
+}
+
+// filterUpdates returns the updates kept by the filters registered with AddUpdateFilter,
+// ok is false if the filters dropped all of them
+func (c *Client) filterUpdates(updates TableUpdates) (filtered TableUpdates, ok bool) {
+	c.mu.Lock()
+	filters := c.updateFilters
+	c.mu.Unlock()
+	if len(filters) == 0 || len(updates) == 0 {
+		return updates, true
+	}
+	filtered = make(TableUpdates, len(updates))
+	for table, tableUpdate := range updates {
+		kept := make(TableUpdate, len(tableUpdate))
+		for uuid, rowUpdate := range tableUpdate {
+			if keepUpdate(filters, table, uuid, rowUpdate) {
+				kept[uuid] = rowUpdate
+			}
+		}
+		if len(kept) != 0 {
+			filtered[table] = kept
+		}
+	}
+	return filtered, len(filtered) != 0
+}
+
+// keepUpdate returns true if all filters keep a row update
+func keepUpdate(filters []UpdateFilter, table ID, uuid UUID, update RowUpdate) bool {
+	for _, filter := range filters {
+		if !filter(table, uuid, update) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterTables keeps the updates of tables only
+func FilterTables(tables ...ID) UpdateFilter {
+	kept := make(map[ID]bool, len(tables))
+	for _, table := range tables {
+		kept[table] = true
+	}
+	return func(table ID, uuid UUID, update RowUpdate) bool {
+		return kept[table]
+	}
+}
+
+// IgnoreColumnChanges drops modifications of rows of table which only change columns, e.g. the statistics
+// column of Interface, insertions and deletions of rows are kept
+func IgnoreColumnChanges(table ID, columns ...ID) UpdateFilter {
+	ignored := make(map[ID]bool, len(columns))
+	for _, column := range columns {
+		ignored[column] = true
+	}
+	return func(updateTable ID, uuid UUID, update RowUpdate) bool {
+		if updateTable != table || update.Old == nil || update.New == nil {
+			return true
+		}
+		// "old" holds the modified columns only
+		var old map[ID]json.RawMessage
+		if err := json.Unmarshal(*update.Old, &old); err != nil {
+			return true
+		}
+		for column := range old {
+			if !ignored[column] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FilterExternalID keeps the updates of rows whose external_ids has key set to value, e.g. rows labeled as
+// owned by a controller, updates of tables without external_ids are dropped. For modified rows the new
+// external_ids is checked, for deleted rows the old one.
+func FilterExternalID(key, value string) UpdateFilter {
+	return func(table ID, uuid UUID, update RowUpdate) bool {
+		row := update.New
+		if row == nil {
+			row = update.Old
+		}
+		var columns map[ID]json.RawMessage
+		if row == nil || json.Unmarshal(*row, &columns) != nil {
+			return false
+		}
+		raw, ok := columns[ExternalIDs]
+		if !ok {
+			return false
+		}
+		var externalIDs map[string]string
+		if err := ConvertFromValue(raw, &externalIDs); err != nil {
+			return false
+		}
+		actual, ok := externalIDs[key]
+		return ok && actual == value
+	}
+}
@@ -0,0 +1,91 @@
+package ovsdb
+
+import (
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestUpdateFilters(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	delivered := make(chan []string, 2)
+	client.SetNotificationHandler(&NotificationHandlerFuncs{UpdateFunc: func(jsonValue Value, updates TableUpdates) error {
+		var rows []string
+		for table, tableUpdate := range updates {
+			for uuid := range tableUpdate {
+				rows = append(rows, string(table)+" "+string(uuid))
+			}
+		}
+		sort.Strings(rows)
+		delivered <- rows
+		return nil
+	}})
+	client.AddUpdateFilter(FilterTables("Interface", "Port"))
+	client.AddUpdateFilter(IgnoreColumnChanges("Interface", "statistics", "link_resets"))
+
+	server.Notify("update", "monitor", map[string]interface{}{
+		"Interface": map[string]interface{}{
+			// statistics only
+			"i1": map[string]interface{}{"old": map[string]interface{}{"statistics": []interface{}{"map", []interface{}{}}}, "new": map[string]interface{}{"name": "eth0"}},
+			// configuration change
+			"i2": map[string]interface{}{"old": map[string]interface{}{"mtu": 1500, "statistics": []interface{}{"map", []interface{}{}}}, "new": map[string]interface{}{"name": "eth1"}},
+			"i3": map[string]interface{}{"new": map[string]interface{}{"name": "eth2"}},
+		},
+		"Bridge": map[string]interface{}{"b1": map[string]interface{}{"new": map[string]interface{}{"name": "br0"}}},
+	})
+	// dropped entirely
+	server.Notify("update", "monitor", map[string]interface{}{
+		"Interface": map[string]interface{}{
+			"i1": map[string]interface{}{"old": map[string]interface{}{"link_resets": 1}, "new": map[string]interface{}{"name": "eth0"}},
+		},
+	})
+	server.Notify("update", "monitor", map[string]interface{}{
+		"Port": map[string]interface{}{"p1": map[string]interface{}{"old": map[string]interface{}{"name": "p1"}}},
+	})
+
+	// notifications are delivered in order
+	var got []string
+	for i := 0; i < 2; i++ {
+		select {
+		case rows := <-delivered:
+			got = append(got, strings.Join(rows, ", "))
+		case <-time.After(time.Second):
+			t.Fatalf("only %v delivered", got)
+		}
+	}
+	if want := []string{"Interface i2, Interface i3", "Port p1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %q, want %q", got, want)
+	}
+	select {
+	case rows := <-delivered:
+		t.Errorf("filtered out updates delivered: %v", rows)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestFilterExternalID(t *testing.T) {
+	filter := FilterExternalID("app:owner", "ctl-1")
+	tests := []struct {
+		update RowUpdate
+		keep   bool
+	}{
+		{RowUpdate{New: rawRow(`{"external_ids":["map",[["app:owner","ctl-1"]]]}`)}, true},
+		{RowUpdate{New: rawRow(`{"external_ids":["map",[["app:owner","ctl-2"]]]}`)}, false},
+		{RowUpdate{Old: rawRow(`{"external_ids":["map",[["app:owner","ctl-1"],["x","y"]]]}`)}, true},
+		{RowUpdate{New: rawRow(`{"name":"sw0"}`)}, false},
+	}
+	for _, test := range tests {
+		if keep := filter("Logical_Switch", "a", test.update); keep != test.keep {
+			t.Errorf("filter(%+v) = %v, want %v", test.update, keep, test.keep)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValueEqual reports whether a and b represent the same OVSDB value.
+// Representation quirks of the wire format are ignored:
+// a one-element set equals its single atom, sets and maps are compared regardless of
+// element or pair order, numbers are compared by value no matter they are Go integers or
+// float64 decoded from JSON, and UUIDs equal their ["uuid", ...] JSON form.
+// Values that can not be interpreted as an OVSDB value are never equal.
+func ValueEqual(a, b Value) bool {
+	ca, err := CanonicalValue(a)
+	if err != nil {
+		return false
+	}
+	cb, err := CanonicalValue(b)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(ca, cb)
+}
+
+// CanonicalValue converts v into the canonical representation of an OVSDB value:
+// - integers (and integral float64 decoded from JSON) become int64, other reals stay float64
+// - ["uuid", ...] and ["named-uuid", ...] JSON arrays become UUID and NamedUUID
+// - a set with exactly one element becomes that element, other sets become a Set with sorted values
+// - maps become a Map with pairs sorted by key
+// - values of types with a registered Converter become the atom returned by the converter
+// v may be a Go value built by users, a value decoded by encoding/json into interface{},
+// or a json.RawMessage holding the JSON encoding of a value.
+func CanonicalValue(v Value) (Value, error) {
+	switch value := v.(type) {
+	case json.RawMessage:
+		return canonicalRawValue(value)
+	case *json.RawMessage:
+		if value == nil {
+			return nil, errNotValue(v)
+		}
+		return canonicalRawValue(*value)
+	case Set:
+		return canonicalSet(value.Values)
+	case *Set:
+		return canonicalSet(value.Values)
+	case StringSet:
+		return canonicalStringSet(value.Values)
+	case *StringSet:
+		return canonicalStringSet(value.Values)
+	case Map:
+		return canonicalMap(value.Values)
+	case *Map:
+		return canonicalMap(value.Values)
+	case []interface{}:
+		return canonicalArray(value)
+	}
+	return canonicalAtom(v)
+}
+
+// canonicalRawValue decodes a JSON encoded value and canonicalizes it
+func canonicalRawValue(raw json.RawMessage) (Value, error) {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return CanonicalValue(v)
+}
+
+// canonicalArray canonicalizes a 2-element JSON array, which is one of <set>, <map>, <uuid> or <named-uuid>
+func canonicalArray(array []interface{}) (Value, error) {
+	if len(array) != 2 {
+		return nil, errNotValue(array)
+	}
+	magic, ok := array[0].(string)
+	if !ok {
+		return nil, errNotValue(array)
+	}
+	switch magic {
+	case setMagic:
+		values, ok := array[1].([]interface{})
+		if !ok {
+			return nil, errNotSet
+		}
+		var set []Value
+		for _, value := range values {
+			set = append(set, value)
+		}
+		return canonicalSet(set)
+	case mapMagic:
+		values, ok := array[1].([]interface{})
+		if !ok {
+			return nil, errNotMap
+		}
+		var pairs []MapPair
+		for _, value := range values {
+			pair, ok := value.([]interface{})
+			if !ok || len(pair) != 2 {
+				return nil, errNotMap
+			}
+			pairs = append(pairs, MapPair{pair[0], pair[1]})
+		}
+		return canonicalMap(pairs)
+	case uuidMagic, namedUUIDMagic:
+		return canonicalAtom(array)
+	}
+	return nil, errNotValue(array)
+}
+
+// canonicalSet canonicalizes the elements of a set and sorts them
+func canonicalSet(values []Value) (Value, error) {
+	var atoms []Atomic
+	for _, value := range values {
+		atom, err := canonicalAtom(value)
+		if err != nil {
+			return nil, err
+		}
+		atoms = append(atoms, atom)
+	}
+	// a set with exactly one element is equal to the element itself
+	if len(atoms) == 1 {
+		return atoms[0], nil
+	}
+	sort.Slice(atoms, func(i, j int) bool {
+		return compareAtoms(atoms[i], atoms[j]) < 0
+	})
+	set := Set{Values: make([]Value, 0, len(atoms))}
+	for _, atom := range atoms {
+		set.Values = append(set.Values, atom)
+	}
+	return set, nil
+}
+
+// canonicalStringSet converts a StringSet into canonical form
+func canonicalStringSet(values []string) (Value, error) {
+	var set []Value
+	for _, value := range values {
+		set = append(set, value)
+	}
+	return canonicalSet(set)
+}
+
+// canonicalMap canonicalizes keys and values of a map and sorts pairs by key
+func canonicalMap(pairs []MapPair) (Value, error) {
+	m := Map{Values: make([]MapPair, 0, len(pairs))}
+	for _, pair := range pairs {
+		key, err := canonicalAtom(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		value, err := canonicalAtom(pair[1])
+		if err != nil {
+			return nil, err
+		}
+		m.Values = append(m.Values, MapPair{key, value})
+	}
+	sort.Slice(m.Values, func(i, j int) bool {
+		return compareAtoms(m.Values[i][0], m.Values[j][0]) < 0
+	})
+	return m, nil
+}
+
+// canonicalAtom converts an <atom> into canonical form
+func canonicalAtom(v interface{}) (Atomic, error) {
+	switch atom := v.(type) {
+	case string, bool, UUID, NamedUUID:
+		return atom, nil
+	case *UUID:
+		if atom != nil {
+			return *atom, nil
+		}
+	case *NamedUUID:
+		if atom != nil {
+			return *atom, nil
+		}
+	case json.Number:
+		if i, err := atom.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := atom.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return canonicalReal(f), nil
+	case []interface{}:
+		// <uuid> or <named-uuid> decoded from JSON
+		if len(atom) != 2 {
+			break
+		}
+		magic, _ := atom[0].(string)
+		id, ok := atom[1].(string)
+		if !ok {
+			break
+		}
+		switch magic {
+		case uuidMagic:
+			return UUID(id), nil
+		case namedUUIDMagic:
+			return NamedUUID(id), nil
+		}
+	default:
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() {
+			break
+		}
+		if conv, ok := lookupConverter(rv.Type()); ok {
+			converted, err := conv.ToAtom(v)
+			if err != nil {
+				return nil, err
+			}
+			return canonicalAtom(converted)
+		}
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return rv.Int(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() <= math.MaxInt64 {
+				return int64(rv.Uint()), nil
+			}
+			return float64(rv.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			return canonicalReal(rv.Float()), nil
+		case reflect.String:
+			return rv.String(), nil
+		case reflect.Bool:
+			return rv.Bool(), nil
+		}
+	}
+	return nil, errNotValue(v)
+}
+
+// canonicalReal converts an integral real number into int64, since JSON doesn't distinguish them
+func canonicalReal(f float64) Atomic {
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+		return int64(f)
+	}
+	return f
+}
+
+// atomRank orders atoms of different types
+func atomRank(atom Atomic) int {
+	switch atom.(type) {
+	case bool:
+		return 0
+	case int64, float64:
+		return 1
+	case string:
+		return 2
+	case UUID:
+		return 3
+	case NamedUUID:
+		return 4
+	}
+	return 5
+}
+
+// compareAtoms compares two canonical atoms, returns -1, 0 or 1
+func compareAtoms(a, b Atomic) int {
+	if ra, rb := atomRank(a), atomRank(b); ra != rb {
+		return compareInts(ra, rb)
+	}
+	switch x := a.(type) {
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case int64, float64:
+		fa, fb := atomFloat(a), atomFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(x, b.(string))
+	case UUID:
+		return strings.Compare(string(x), string(b.(UUID)))
+	case NamedUUID:
+		return strings.Compare(string(x), string(b.(NamedUUID)))
+	}
+	return 0
+}
+
+func atomFloat(atom Atomic) float64 {
+	if i, ok := atom.(int64); ok {
+		return float64(i)
+	}
+	return atom.(float64)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func errNotValue(v interface{}) error {
+	return fmt.Errorf("Not an OVSDB value: %#v", v)
+}
@@ -0,0 +1,92 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValueEqual(t *testing.T) {
+	tests := []struct {
+		a, b  Value
+		equal bool
+	}{
+		// atoms
+		{"value", "value", true},
+		{"value", "other", false},
+		{true, true, true},
+		{true, false, false},
+		{1, float64(1), true},
+		{int32(1), int64(1), true},
+		{1.5, float64(1.5), true},
+		{1, 1.5, false},
+		{1, "1", false},
+		{UUID("550e8400-e29b-41d4-a716-446655440000"), []interface{}{"uuid", "550e8400-e29b-41d4-a716-446655440000"}, true},
+		{UUID("550e8400-e29b-41d4-a716-446655440000"), NamedUUID("550e8400-e29b-41d4-a716-446655440000"), false},
+		{NamedUUID("row"), []interface{}{"named-uuid", "row"}, true},
+		// sets
+		{Set{Values: []Value{"value"}}, "value", true},
+		{StringSet{Values: []string{"value"}}, "value", true},
+		{Set{Values: []Value{"a", "b"}}, Set{Values: []Value{"b", "a"}}, true},
+		{Set{Values: []Value{"a", "b"}}, StringSet{Values: []string{"b", "a"}}, true},
+		{Set{Values: []Value{"a", "b"}}, Set{Values: []Value{"a"}}, false},
+		{Set{Values: []Value{}}, []interface{}{"set", []interface{}{}}, true},
+		{Set{Values: []Value{1, 2}}, []interface{}{"set", []interface{}{float64(2), float64(1)}}, true},
+		// maps
+		{
+			Map{Values: []MapPair{{"key1", "value1"}, {"key2", "value2"}}},
+			Map{Values: []MapPair{{"key2", "value2"}, {"key1", "value1"}}},
+			true,
+		},
+		{
+			Map{Values: []MapPair{{"key1", "value1"}}},
+			Map{Values: []MapPair{{"key1", "value2"}}},
+			false,
+		},
+		{
+			Map{Values: []MapPair{{"key", 1}}},
+			[]interface{}{"map", []interface{}{[]interface{}{"key", float64(1)}}},
+			true,
+		},
+		{Map{Values: []MapPair{{"key", "value"}}}, Set{Values: []Value{"key", "value"}}, false},
+		// raw json
+		{json.RawMessage(`["set",["b","a"]]`), Set{Values: []Value{"a", "b"}}, true},
+		{json.RawMessage(`["uuid","550e8400-e29b-41d4-a716-446655440000"]`), UUID("550e8400-e29b-41d4-a716-446655440000"), true},
+		// invalid values
+		{struct{}{}, struct{}{}, false},
+		{[]interface{}{"notset", []interface{}{}}, []interface{}{"notset", []interface{}{}}, false},
+	}
+
+	for _, test := range tests {
+		if equal := ValueEqual(test.a, test.b); equal != test.equal {
+			t.Errorf("ValueEqual(%#v, %#v) = %v, want %v", test.a, test.b, equal, test.equal)
+		}
+	}
+}
+
+func TestCanonicalValue(t *testing.T) {
+	tests := []struct {
+		value   Value
+		jsonStr string
+	}{
+		{float64(1), `1`},
+		{1.5, `1.5`},
+		{Set{Values: []Value{"value"}}, `"value"`},
+		{Set{Values: []Value{"b", 2, true}}, `["set",[true,2,"b"]]`},
+		{[]interface{}{"map", []interface{}{[]interface{}{"b", "2"}, []interface{}{"a", "1"}}}, `["map",[["a","1"],["b","2"]]]`},
+	}
+
+	for _, test := range tests {
+		value, err := CanonicalValue(test.value)
+		if err != nil {
+			t.Errorf("CanonicalValue(%#v) failed: %v", test.value, err)
+			continue
+		}
+		bytes, err := json.Marshal(value)
+		if err != nil {
+			t.Errorf("Error during marshal: %v", err)
+		}
+		if string(bytes) != test.jsonStr {
+			t.Errorf("CanonicalValue(%#v) = %s, want %s", test.value, bytes, test.jsonStr)
+		}
+	}
+}
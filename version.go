@@ -0,0 +1,111 @@
+package ovsdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VersionNumber is a Version parsed into its major, minor and patch numbers
+type VersionNumber struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// String returns the Version of n
+func (n VersionNumber) String() string {
+	return fmt.Sprintf("%d.%d.%d", n.Major, n.Minor, n.Patch)
+}
+
+// Compare compares n with other, it returns -1 if n is older than other, 1 if newer, and 0 if equal
+func (n VersionNumber) Compare(other VersionNumber) int {
+	switch {
+	case n.Major != other.Major:
+		return compareInts(n.Major, other.Major)
+	case n.Minor != other.Minor:
+		return compareInts(n.Minor, other.Minor)
+	}
+	return compareInts(n.Patch, other.Patch)
+}
+
+// Less returns true if n is older than other
+func (n VersionNumber) Less(other VersionNumber) bool {
+	return n.Compare(other) < 0
+}
+
+// AtLeast returns true if n is other or newer
+func (n VersionNumber) AtLeast(other VersionNumber) bool {
+	return n.Compare(other) >= 0
+}
+
+// Parse parses v, which must match [0-9]+\.[0-9]+\.[0-9]+
+func (v Version) Parse() (VersionNumber, error) {
+	var numbers [3]int
+	parts := strings.Split(string(v), ".")
+	if len(parts) != 3 {
+		return VersionNumber{}, fmt.Errorf("invalid version %q", v)
+	}
+	for i, part := range parts {
+		if len(part) == 0 || strings.TrimLeft(part, "0123456789") != "" {
+			return VersionNumber{}, fmt.Errorf("invalid version %q", v)
+		}
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return VersionNumber{}, fmt.Errorf("invalid version %q: %v", v, err)
+		}
+		numbers[i] = number
+	}
+	return VersionNumber{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// Valid returns true if v matches [0-9]+\.[0-9]+\.[0-9]+
+func (v Version) Valid() bool {
+	_, err := v.Parse()
+	return err == nil
+}
+
+// Compare compares v with other by major, minor and patch numbers.
+// It returns -1 if v is older than other, 1 if newer, and 0 if they're the same version.
+func (v Version) Compare(other Version) (int, error) {
+	a, err := v.Parse()
+	if err != nil {
+		return 0, err
+	}
+	b, err := other.Parse()
+	if err != nil {
+		return 0, err
+	}
+	return a.Compare(b), nil
+}
+
+// CompareVersions evaluates "a op b" like "ovsdb-tool compare-versions",
+// op is one of "<", "<=", "==", ">=", ">" and "!=".
+func CompareVersions(a Version, op string, b Version) (bool, error) {
+	result, err := a.Compare(b)
+	if err != nil {
+		return false, err
+	}
+	switch op {
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case "==":
+		return result == 0, nil
+	case ">=":
+		return result >= 0, nil
+	case ">":
+		return result > 0, nil
+	case "!=":
+		return result != 0, nil
+	}
+	return false, fmt.Errorf("invalid comparison operator %q", op)
+}
+
+// VersionAtLeast returns true if the schema version is min or newer, e.g. to gate features
+// on a minimum OVN_Northbound schema version. It returns false if either version is invalid.
+func (dbSchema *DatabaseSchema) VersionAtLeast(min Version) bool {
+	atLeast, err := CompareVersions(dbSchema.Version, ">=", min)
+	return err == nil && atLeast
+}
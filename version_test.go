@@ -0,0 +1,94 @@
+package ovsdb
+
+import (
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a      Version
+		op     string
+		b      Version
+		result bool
+		ok     bool
+	}{
+		{"7.3.0", "==", "7.3.0", true, true},
+		{"7.3.0", "<", "7.10.0", true, true},
+		{"7.10.0", ">", "7.3.0", true, true},
+		{"5.0.1", ">=", "5.0.0", true, true},
+		{"5.0.1", "<=", "5.0.0", false, true},
+		{"1.2.3", "!=", "1.2.3", false, true},
+		{"1.2.3", "=", "1.2.3", false, false},
+		{"1.2", "==", "1.2.0", false, false},
+		{"1.2.x", "==", "1.2.0", false, false},
+		{"1.2.-1", "<", "1.2.0", false, false},
+		{"1.2.+1", ">", "1.2.0", false, false},
+	}
+	for _, test := range tests {
+		result, err := CompareVersions(test.a, test.op, test.b)
+		if test.ok != (err == nil) {
+			t.Errorf("CompareVersions(%s %s %s) error = %v, want ok %v", test.a, test.op, test.b, err, test.ok)
+			continue
+		}
+		if result != test.result {
+			t.Errorf("CompareVersions(%s %s %s) = %v, want %v", test.a, test.op, test.b, result, test.result)
+		}
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	dbSchema := &DatabaseSchema{Version: "7.3.0"}
+	tests := []struct {
+		min     Version
+		atLeast bool
+	}{
+		{"7.2.9", true},
+		{"7.3.0", true},
+		{"7.3.1", false},
+		{"invalid", false},
+	}
+	for _, test := range tests {
+		if atLeast := dbSchema.VersionAtLeast(test.min); atLeast != test.atLeast {
+			t.Errorf("VersionAtLeast(%s) = %v, want %v", test.min, atLeast, test.atLeast)
+		}
+	}
+}
+
+func TestVersionParse(t *testing.T) {
+	tests := []struct {
+		version Version
+		number  VersionNumber
+		ok      bool
+	}{
+		{"7.3.0", VersionNumber{7, 3, 0}, true},
+		{"5.10.12", VersionNumber{5, 10, 12}, true},
+		{"", VersionNumber{}, false},
+		{"1.2", VersionNumber{}, false},
+		{"1.2.3.4", VersionNumber{}, false},
+		{"1..3", VersionNumber{}, false},
+		{"1.2.3a", VersionNumber{}, false},
+	}
+	for _, test := range tests {
+		number, err := test.version.Parse()
+		if test.ok != (err == nil) || test.ok != test.version.Valid() {
+			t.Errorf("Version(%q).Parse() error = %v, want ok %v", test.version, err, test.ok)
+			continue
+		}
+		if number != test.number {
+			t.Errorf("Version(%q).Parse() = %+v, want %+v", test.version, number, test.number)
+		}
+		if test.ok && Version(number.String()) != test.version {
+			t.Errorf("VersionNumber.String() = %s, want %s", number, test.version)
+		}
+	}
+}
+
+func TestVersionNumberCompare(t *testing.T) {
+	older, newer := VersionNumber{5, 9, 1}, VersionNumber{5, 10, 0}
+	if !older.Less(newer) || newer.Less(older) {
+		t.Errorf("%s should be older than %s", older, newer)
+	}
+	if !newer.AtLeast(older) || !newer.AtLeast(newer) || older.AtLeast(newer) {
+		t.Errorf("AtLeast of %s and %s is wrong", older, newer)
+	}
+}
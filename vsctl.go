@@ -0,0 +1,127 @@
+package ovsdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseValue parses text in the syntax printed by FormatValue and accepted by ovs-vsctl and ovn-nbctl
+// into a value of the column, e.g. "{key=value, key2=value2}" for a map or "[uuid1, uuid2]" for a set.
+// Brackets and braces may be omitted, in which case elements are separated by commas.
+// Atoms are parsed according to the column's types: strings are bare or double-quoted, and
+// UUIDs are bare or "@name" for the row named name inserted in the same transaction.
+// Map columns yield a Map, set columns a Set and other columns an atom.
+func ParseValue(columnSchema *ColumnSchema, text string) (Value, error) {
+	text = strings.TrimSpace(text)
+	switch {
+	case columnSchema.IsMap():
+		elements, err := splitElements(text, "{", "}")
+		if err != nil {
+			return nil, err
+		}
+		m := Map{Values: []MapPair{}}
+		for _, element := range elements {
+			i := indexUnquoted(element, '=')
+			if i < 0 {
+				return nil, fmt.Errorf("invalid map pair %q: must be <key>=<value>", element)
+			}
+			key, err := parseAtom(columnSchema.KeyType(), element[:i])
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseAtom(columnSchema.ValueType(), element[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			m.Values = append(m.Values, MapPair{key, value})
+		}
+		return m, nil
+	case columnSchema.IsSet():
+		elements, err := splitElements(text, "[", "]")
+		if err != nil {
+			return nil, err
+		}
+		set := Set{Values: []Value{}}
+		for _, element := range elements {
+			atom, err := parseAtom(columnSchema.KeyType(), element)
+			if err != nil {
+				return nil, err
+			}
+			set.Values = append(set.Values, atom)
+		}
+		return set, nil
+	}
+	return parseAtom(columnSchema.KeyType(), text)
+}
+
+// splitElements strips the optional open and close delimiters of text and splits it on unquoted commas
+func splitElements(text, open, close string) ([]string, error) {
+	if strings.HasPrefix(text, open) {
+		if !strings.HasSuffix(text, close) {
+			return nil, fmt.Errorf("invalid value %q: missing %s", text, close)
+		}
+		text = strings.TrimSpace(text[len(open) : len(text)-len(close)])
+	}
+	if text == "" {
+		return nil, nil
+	}
+	var elements []string
+	for {
+		i := indexUnquoted(text, ',')
+		if i < 0 {
+			return append(elements, strings.TrimSpace(text)), nil
+		}
+		elements = append(elements, strings.TrimSpace(text[:i]))
+		text = text[i+1:]
+	}
+}
+
+// indexUnquoted returns the index of the first c in s outside of double quotes, or -1
+func indexUnquoted(s string, c byte) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && s[i] == c:
+			return i
+		}
+	}
+	return -1
+}
+
+// parseAtom parses an atom of type atomicType
+func parseAtom(atomicType AtomicType, text string) (Atomic, error) {
+	text = strings.TrimSpace(text)
+	switch atomicType {
+	case TypeString:
+		if strings.HasPrefix(text, `"`) {
+			return strconv.Unquote(text)
+		}
+		return text, nil
+	case TypeInteger:
+		return strconv.ParseInt(text, 10, 64)
+	case TypeReal:
+		return strconv.ParseFloat(text, 64)
+	case TypeBoolean:
+		switch text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean %q", text)
+	case TypeUUID:
+		if strings.HasPrefix(text, "@") {
+			return NamedUUID(text[1:]), nil
+		}
+		if !isUUIDString(text) {
+			return nil, fmt.Errorf("invalid UUID %q", text)
+		}
+		return UUID(text), nil
+	}
+	return nil, fmt.Errorf("unknown atomic type %q", atomicType)
+}
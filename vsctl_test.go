@@ -0,0 +1,69 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseValue(t *testing.T) {
+	var tableSchema TableSchema
+	err := json.Unmarshal([]byte(`{"columns": {
+		"name": {"type": "string"},
+		"tag": {"type": {"key": "integer", "min": 0, "max": 1}},
+		"enabled": {"type": "boolean"},
+		"ports": {"type": {"key": {"type": "uuid", "refTable": "Logical_Switch_Port"}, "min": 0, "max": "unlimited"}},
+		"addresses": {"type": {"key": "string", "min": 0, "max": "unlimited"}},
+		"external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+	}}`), &tableSchema)
+	if err != nil {
+		t.Fatalf("failed to decode schema: %v", err)
+	}
+
+	tests := []struct {
+		column ID
+		text   string
+		want   string
+	}{
+		{"name", "sw0", `"sw0"`},
+		{"name", `"sw 0, \"a\""`, `"sw 0, \"a\""`},
+		{"tag", "[]", `["set",[]]`},
+		{"tag", "42", `42`},
+		{"enabled", "true", `true`},
+		{"ports", "[" + lsp1 + ", @new]", `["set",[["uuid","` + lsp1 + `"],["named-uuid","new"]]]`},
+		{"addresses", `"00:00:00:00:00:01 10.0.0.1", unknown`, `["set",["00:00:00:00:00:01 10.0.0.1","unknown"]]`},
+		{"external_ids", "{}", `["map",[]]`},
+		{"external_ids", `{a=1, "b=c"="x,y"}`, `["map",[["a","1"],["b=c","x,y"]]]`},
+		{"external_ids", `a=1`, `["map",[["a","1"]]]`},
+	}
+	for _, test := range tests {
+		value, err := ParseValue(tableSchema.Columns[test.column], test.text)
+		if err != nil {
+			t.Errorf("ParseValue(%s, %s) failed: %v", test.column, test.text, err)
+			continue
+		}
+		bytes, _ := json.Marshal(value)
+		if string(bytes) != test.want {
+			t.Errorf("ParseValue(%s, %s) = %s, want %s", test.column, test.text, bytes, test.want)
+		}
+		// FormatValue and ParseValue round trip
+		again, err := ParseValue(tableSchema.Columns[test.column], FormatValue(value))
+		if err != nil || !ValueEqual(value, again) {
+			t.Errorf("ParseValue(FormatValue(%s)) = %v, %v", test.want, again, err)
+		}
+	}
+
+	for _, test := range []struct {
+		column ID
+		text   string
+	}{
+		{"tag", "x"},
+		{"enabled", "yes"},
+		{"ports", "[sw0]"},
+		{"external_ids", "{a}"},
+		{"external_ids", "{a=1"},
+	} {
+		if _, err := ParseValue(tableSchema.Columns[test.column], test.text); err == nil {
+			t.Errorf("expect error for ParseValue(%s, %s), but got nil", test.column, test.text)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// RowPredicate reports whether a row matches, row holds the monitored columns of the row
+type RowPredicate func(uuid UUID, row json.RawMessage) bool
+
+// WaitFor blocks until a row of table in database db matching predicate exists, e.g. until
+// Port_Binding.chassis of a port is set, and returns the UUID of the row.
+// The table is monitored for the duration of the call, so existing rows are checked first and then
+// rows are checked as they are inserted or modified, columns selects the monitored columns,
+// all columns are monitored if it's empty.
+// It returns ctx.Err() if ctx is done first, even if the server doesn't respond, or an error if the
+// connection is lost.
+func (c *Client) WaitFor(ctx context.Context, db, table ID, columns []ID, predicate RowPredicate) (UUID, error) {
+	return c.waitForRow(ctx, db, table, columns, func(uuid UUID, row json.RawMessage) (bool, error) {
+		return predicate(uuid, row), nil
+	})
+}
+
+// waitForRow monitors columns of table until match returns true or an error for a row
+func (c *Client) waitForRow(ctx context.Context, db, table ID, columns []ID, match func(UUID, json.RawMessage) (bool, error)) (UUID, error) {
+	updates := make(chan TableUpdates)
+	done := make(chan struct{})
+	monitorID := c.watchMonitor(func(tableUpdates TableUpdates) {
+		select {
+		case updates <- tableUpdates:
+		case <-done:
+		}
+	})
+
+	initial, err := c.monitor(ctx, db, monitorID, MonitorRequests{
+		table: MonitorRequest{Columns: columns},
+	})
+	// the server may create the monitor after ctx is done
+	created := err == nil || ctx.Err() != nil
+	defer func() {
+		close(done)
+		c.stopMonitor(ctx, monitorID, created)
+	}()
+	if err != nil {
+		return "", err
+	}
+
+	tableUpdates := initial
+	for {
+		for uuid, rowUpdate := range tableUpdates[table] {
+			if rowUpdate.New == nil {
+				continue
+			}
+			ok, err := match(uuid, *rowUpdate.New)
+			if err != nil {
+				return "", err
+			}
+			if ok {
+				return uuid, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-c.closed:
+			return "", errDisconnected
+		case tableUpdates = <-updates:
+		}
+	}
+}
+
+// stopMonitor cancels the monitor monitorID of waitForRow if it may have been created, and removes its
+// watcher once it's canceled, so its updates never reach the notification handler. The cancellation
+// waits for the server within ctx, it goes on in the background once ctx is done.
+func (c *Client) stopMonitor(ctx context.Context, monitorID string, created bool) {
+	if created && ctx.Err() == nil {
+		c.monitorCancel(ctx, monitorID)
+	}
+	if !created || ctx.Err() == nil {
+		c.unwatchMonitor(monitorID)
+		return
+	}
+	go func() {
+		c.monitorCancel(context.Background(), monitorID)
+		c.unwatchMonitor(monitorID)
+	}()
+}
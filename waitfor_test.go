@@ -0,0 +1,118 @@
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestWaitFor(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	server.Handle("monitor", func(params []json.RawMessage) (interface{}, error) {
+		var monitorID string
+		json.Unmarshal(params[1], &monitorID)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			server.Notify("update", monitorID, map[string]interface{}{
+				"Port_Binding": map[string]interface{}{
+					"b0000000-0000-0000-0000-000000000000": map[string]interface{}{
+						"new": map[string]interface{}{"logical_port": "lsp1", "chassis": []interface{}{"uuid", "c0000000-0000-0000-0000-000000000000"}},
+					},
+				},
+			})
+		}()
+		return map[string]interface{}{
+			"Port_Binding": map[string]interface{}{
+				"b0000000-0000-0000-0000-000000000000": map[string]interface{}{
+					"new": map[string]interface{}{"logical_port": "lsp1", "chassis": []interface{}{"set", []interface{}{}}},
+				},
+			},
+		}, nil
+	})
+	server.Handle("monitor_cancel", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+
+	bound := func(uuid UUID, row json.RawMessage) bool {
+		var columns map[ID]interface{}
+		json.Unmarshal(row, &columns)
+		chassis, err := CanonicalValue(columns["chassis"])
+		_, ok := chassis.(UUID)
+		return err == nil && ok
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	uuid, err := client.WaitFor(ctx, "OVN_Southbound", "Port_Binding", []ID{"logical_port", "chassis"}, bound)
+	if err != nil {
+		t.Fatalf("WaitFor failed: %v", err)
+	}
+	if uuid != "b0000000-0000-0000-0000-000000000000" {
+		t.Errorf("WaitFor returned %s", uuid)
+	}
+
+	// never matches
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	never := func(uuid UUID, row json.RawMessage) bool { return false }
+	if _, err := client.WaitFor(ctx, "OVN_Southbound", "Port_Binding", nil, never); err != context.DeadlineExceeded {
+		t.Errorf("WaitFor error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWaitForHungServer(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	release := make(chan struct{})
+	monitored := make(chan string, 1)
+	server.Handle("monitor", func(params []json.RawMessage) (interface{}, error) {
+		monitored <- string(params[1])
+		<-release
+		return map[string]interface{}{}, nil
+	})
+	canceled := make(chan string, 1)
+	server.Handle("monitor_cancel", func(params []json.RawMessage) (interface{}, error) {
+		canceled <- string(params[0])
+		return map[string]interface{}{}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		_, err := client.WaitFor(ctx, "OVN_Southbound", "Port_Binding", nil, func(uuid UUID, row json.RawMessage) bool {
+			return true
+		})
+		errs <- err
+	}()
+	select {
+	case err := <-errs:
+		if err != context.DeadlineExceeded {
+			t.Errorf("WaitFor returned %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitFor blocked past its deadline on a hung server")
+	}
+
+	// the monitor created once the server recovers is canceled
+	close(release)
+	monitorID := <-monitored
+	select {
+	case id := <-canceled:
+		if id != monitorID {
+			t.Errorf("canceled monitor %s, want %s", id, monitorID)
+		}
+	case <-time.After(time.Second):
+		t.Error("monitor not canceled after the deadline")
+	}
+}
@@ -0,0 +1,159 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// ColumnChange is a change of a column watched with WatchColumns, Old and New are canonical values
+// (see CanonicalValue). Old is nil for the first value seen of the column, New is nil if the row is deleted.
+type ColumnChange struct {
+	Column ID
+	Old    Value
+	New    Value
+}
+
+// ColumnsChangedFunc is called with the changes of the watched columns of a row
+type ColumnsChangedFunc func(changes []ColumnChange)
+
+// WatchColumns watches the columns of the row uuid of table, e.g. a single option of NB_Global, with a
+// conditional monitor (see MonitorCond) so the server only sends the watched columns of that row.
+// changed is called with the current values of the columns before WatchColumns returns, if the row exists,
+// then with the columns whose values changed on every update of the row. Calls of changed are serialized.
+// The returned stop function cancels the monitor.
+func (c *Client) WatchColumns(db, table ID, uuid UUID, changed ColumnsChangedFunc, columns ...ID) (stop func() error, err error) {
+	dbSchema, err := c.cachedSchema(db)
+	if err != nil {
+		return nil, err
+	}
+	tableSchema, ok := dbSchema.Tables[table]
+	if !ok {
+		return nil, fmt.Errorf("unknown table %s in database %s", table, db)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no column of table %s to watch", table)
+	}
+	watcher := &columnsWatcher{
+		changed: changed,
+		columns: make(map[ID]*ColumnSchema, len(columns)),
+	}
+	for _, column := range columns {
+		columnSchema, ok := tableSchema.Columns[column]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %s of table %s", column, table)
+		}
+		watcher.columns[column] = columnSchema
+	}
+	watcher.order = columns
+
+	// updates are held back until the initial contents are applied
+	watcher.mu.Lock()
+	monitorID := c.watchMonitor2(func(updates TableUpdates2) {
+		watcher.mu.Lock()
+		defer watcher.mu.Unlock()
+		watcher.update(updates[table][uuid])
+	})
+	c.watchMonitorCanceled(monitorID, func() {
+		c.unwatchMonitor(monitorID)
+	})
+	initial, err := c.MonitorCond(db, monitorID, MonitorCondRequests{
+		table: MonitorCondRequest{
+			Columns: columns,
+			Where:   []Condition{{"_uuid", FuncEq, uuid}},
+		},
+	})
+	if err != nil {
+		watcher.mu.Unlock()
+		c.unwatchMonitor(monitorID)
+		return nil, err
+	}
+	watcher.update(initial[table][uuid])
+	watcher.mu.Unlock()
+
+	stop = func() error {
+		c.unwatchMonitor(monitorID)
+		return c.MonitorCancel(monitorID)
+	}
+	return stop, nil
+}
+
+// WatchColumns watches columns of a row of the database, like Client.WatchColumns
+func (s *ClientSession) WatchColumns(table ID, uuid UUID, changed ColumnsChangedFunc, columns ...ID) (stop func() error, err error) {
+	return s.client.WatchColumns(s.db, table, uuid, changed, columns...)
+}
+
+// columnsWatcher tracks the watched columns of a row from its <row-update2>s
+type columnsWatcher struct {
+	changed ColumnsChangedFunc
+	columns map[ID]*ColumnSchema
+	order   []ID
+
+	// updates are delivered in order, mu holds them back until the initial values are applied
+	mu     sync.Mutex
+	values map[ID]Value
+}
+
+// update applies update to the values of the columns, and invokes changed if any of them changed
+func (w *columnsWatcher) update(update RowUpdate2) {
+	var row map[ID]json.RawMessage
+	var raw *json.RawMessage
+	switch {
+	case update.Initial != nil:
+		raw = update.Initial
+	case update.Insert != nil:
+		raw = update.Insert
+	case update.Modify != nil:
+		raw = update.Modify
+	case update.Delete:
+		w.set(nil)
+		return
+	default:
+		return
+	}
+	if err := json.Unmarshal(*raw, &row); err != nil {
+		return
+	}
+
+	values := make(map[ID]Value, len(w.columns))
+	for column, columnSchema := range w.columns {
+		value, ok := w.values[column]
+		if update.Modify == nil || !ok {
+			// columns with default values are omitted
+			value = defaultValue(columnSchema)
+		}
+		if raw, ok := row[column]; ok {
+			decoded, err := CanonicalValue(raw)
+			if err != nil {
+				return
+			}
+			if update.Modify != nil {
+				decoded, err = applyDiff(columnSchema, value, decoded)
+				if err != nil {
+					return
+				}
+			}
+			value = decoded
+		}
+		values[column] = value
+	}
+	w.set(values)
+}
+
+// set replaces the values of the columns with values, nil for a deleted row, and invokes changed
+// with the columns whose values changed
+func (w *columnsWatcher) set(values map[ID]Value) {
+	var changes []ColumnChange
+	for _, column := range w.order {
+		old, hadOld := w.values[column]
+		value, hasNew := values[column]
+		if hadOld && hasNew && ValueEqual(old, value) || !hadOld && !hasNew {
+			continue
+		}
+		changes = append(changes, ColumnChange{Column: column, Old: old, New: value})
+	}
+	w.values = values
+	if len(changes) != 0 {
+		w.changed(changes)
+	}
+}
@@ -0,0 +1,135 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/liwei/go-ovsdb/ovsdbtest"
+)
+
+func TestWatchColumns(t *testing.T) {
+	conn, serverConn := net.Pipe()
+	server := ovsdbtest.NewServer(serverConn)
+	defer server.Close()
+	client := NewClient(conn)
+
+	const nbGlobal = "a0000000-0000-0000-0000-000000000001"
+	server.Handle("get_schema", func(params []json.RawMessage) (interface{}, error) {
+		return json.RawMessage(`{"name": "OVN_Northbound", "version": "5.0.0", "tables": {
+			"NB_Global": {"columns": {
+				"nb_cfg": {"type": "integer"},
+				"options": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}},
+				"connections": {"type": {"key": "uuid", "min": 0, "max": "unlimited"}}}}}}`), nil
+	})
+	var monitorID string
+	var request json.RawMessage
+	server.Handle("monitor_cond", func(params []json.RawMessage) (interface{}, error) {
+		json.Unmarshal(params[1], &monitorID)
+		request = params[2]
+		return map[string]interface{}{
+			"NB_Global": map[string]interface{}{
+				nbGlobal: map[string]interface{}{"initial": map[string]interface{}{
+					"nb_cfg":  3,
+					"options": []interface{}{"map", []interface{}{[]interface{}{"a", "1"}}},
+				}},
+			},
+		}, nil
+	})
+	server.Handle("monitor_cancel", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+
+	changes := make(chan []ColumnChange, 3)
+	stop, err := client.WatchColumns("OVN_Northbound", "NB_Global", nbGlobal, func(c []ColumnChange) {
+		changes <- c
+	}, "options", "connections", "nb_cfg")
+	if err != nil {
+		t.Fatalf("WatchColumns failed: %v", err)
+	}
+	want := `{"NB_Global":{"columns":["options","connections","nb_cfg"],"where":[["_uuid","==",["uuid","` + nbGlobal + `"]]]}}`
+	if string(request) != want {
+		t.Errorf("monitor_cond request = %s, want %s", request, want)
+	}
+	expect := func(want []ColumnChange) {
+		t.Helper()
+		select {
+		case got := <-changes:
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("changes = %#v, want %#v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no changes")
+		}
+	}
+	expect([]ColumnChange{
+		{Column: "options", New: Map{Values: []MapPair{{"a", "1"}}}},
+		{Column: "connections", New: Set{Values: []Value{}}},
+		{Column: "nb_cfg", New: int64(3)},
+	})
+
+	server.Notify("update2", monitorID, map[string]interface{}{
+		"NB_Global": map[string]interface{}{
+			nbGlobal: map[string]interface{}{"modify": map[string]interface{}{
+				"options": []interface{}{"map", []interface{}{[]interface{}{"a", "1"}, []interface{}{"b", "2"}}},
+			}},
+		},
+	})
+	expect([]ColumnChange{
+		{Column: "options", Old: Map{Values: []MapPair{{"a", "1"}}}, New: Map{Values: []MapPair{{"b", "2"}}}},
+	})
+
+	server.Notify("update2", monitorID, map[string]interface{}{
+		"NB_Global": map[string]interface{}{nbGlobal: map[string]interface{}{"delete": nil}},
+	})
+	expect([]ColumnChange{
+		{Column: "options", Old: Map{Values: []MapPair{{"b", "2"}}}},
+		{Column: "connections", Old: Set{Values: []Value{}}},
+		{Column: "nb_cfg", Old: int64(3)},
+	})
+
+	if err := stop(); err != nil {
+		t.Errorf("stop failed: %v", err)
+	}
+	if _, err := client.WatchColumns("OVN_Northbound", "NB_Global", nbGlobal, nil, "external_ids"); err == nil {
+		t.Error("watching an unknown column should fail")
+	}
+}
+
+func TestApplyDiff(t *testing.T) {
+	var dbSchema DatabaseSchema
+	err := json.Unmarshal([]byte(`{"name": "test", "version": "1.0.0", "tables": {"T": {"columns": {
+		"i": {"type": "integer"},
+		"s": {"type": {"key": "string", "min": 0, "max": "unlimited"}},
+		"m": {"type": {"key": "string", "value": "integer", "min": 0, "max": "unlimited"}}}}}}`), &dbSchema)
+	if err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	columns := dbSchema.Tables["T"].Columns
+
+	tests := []struct {
+		column    ID
+		old, diff Value
+		want      Value
+	}{
+		{"i", int64(1), int64(2), int64(2)},
+		{"s", Set{Values: []Value{"a", "b"}}, Set{Values: []Value{"b", "c"}}, Set{Values: []Value{"a", "c"}}},
+		{"s", "a", "a", Set{Values: []Value{}}},
+		{"s", Set{Values: []Value{}}, "a", "a"},
+		{"m", Map{Values: []MapPair{{"a", int64(1)}, {"b", int64(2)}}},
+			Map{Values: []MapPair{{"a", int64(1)}, {"b", int64(3)}, {"c", int64(4)}}},
+			Map{Values: []MapPair{{"b", int64(3)}, {"c", int64(4)}}}},
+	}
+	for _, test := range tests {
+		got, err := applyDiff(columns[test.column], test.old, test.diff)
+		if err != nil {
+			t.Errorf("applyDiff(%s, %v, %v) failed: %v", test.column, test.old, test.diff, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("applyDiff(%s, %v, %v) = %#v, want %#v", test.column, test.old, test.diff, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package ovsdb
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebhookSignatureHeader is the header holding the HMAC-SHA256 signature of the body of webhook requests
+// with the secret of the webhook, as "sha256=<hex digest>"
+const WebhookSignatureHeader = "X-OVSDB-Signature"
+
+// Default retry policy of a WebhookSink
+const (
+	DefaultWebhookRetries = 3
+	DefaultWebhookBackoff = time.Second
+)
+
+// Webhook is an HTTP endpoint receiving change events from a WebhookSink
+type Webhook struct {
+	URL string
+	// Secret, if not empty, is the key signing requests, see WebhookSignatureHeader
+	Secret string
+	// Filters select the events sent to the endpoint, events matching any filter are sent,
+	// all events are sent if there's no filter
+	Filters []*EventFilter
+}
+
+// WebhookSink is a ChangeSink POSTing change events as a JSON array to webhooks, so systems not speaking
+// JSON-RPC can subscribe to changes of a database, e.g. with an ExportingHandler.
+// Requests failing with a network error or a 429 or 5xx status are retried with exponential backoff.
+type WebhookSink struct {
+	hooks []Webhook
+
+	mu      sync.Mutex
+	client  *http.Client
+	retries int
+	backoff time.Duration
+}
+
+// NewWebhookSink creates a WebhookSink sending events to hooks
+func NewWebhookSink(hooks ...Webhook) *WebhookSink {
+	return &WebhookSink{
+		hooks:   hooks,
+		client:  http.DefaultClient,
+		retries: DefaultWebhookRetries,
+		backoff: DefaultWebhookBackoff,
+	}
+}
+
+// SetHTTPClient sets the HTTP client sending requests, e.g. with a timeout or client certificates
+func (s *WebhookSink) SetHTTPClient(client *http.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = client
+}
+
+// SetRetries sets the number of retries of a failed request and the delay before the first retry,
+// which doubles on each retry
+func (s *WebhookSink) SetRetries(retries int, backoff time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries = retries
+	s.backoff = backoff
+}
+
+// Publish implements ChangeSink interface, it fails if any webhook failed to receive its events
+func (s *WebhookSink) Publish(events []ChangeEvent) error {
+	var failures []string
+	for _, hook := range s.hooks {
+		selected := hook.selectEvents(events)
+		if len(selected) == 0 {
+			continue
+		}
+		if err := s.post(hook, selected); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", hook.URL, err))
+		}
+	}
+	if len(failures) != 0 {
+		return fmt.Errorf("failed to deliver events to webhooks: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// selectEvents returns the events matching the filters of the webhook
+func (hook Webhook) selectEvents(events []ChangeEvent) []ChangeEvent {
+	if len(hook.Filters) == 0 {
+		return events
+	}
+	var selected []ChangeEvent
+	for _, event := range events {
+		for _, filter := range hook.Filters {
+			if filter.Match(event) {
+				selected = append(selected, event)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// post sends events to a webhook, retrying on transient failures
+func (s *WebhookSink) post(hook Webhook, events []ChangeEvent) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	client, retries, backoff := s.client, s.retries, s.backoff
+	s.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		retry, err := postWebhook(client, hook, body)
+		if err == nil || !retry || attempt >= retries {
+			return err
+		}
+		time.Sleep(backoff << uint(attempt))
+	}
+}
+
+// postWebhook sends a request to a webhook, it returns whether a failure is worth retrying
+func postWebhook(client *http.Client, hook Webhook, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if hook.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(hook.Secret, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	// drain the body so the connection is reused
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return false, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+// SignWebhook returns the value of WebhookSignatureHeader for body signed with secret,
+// receivers verify requests by comparing it with the header using hmac.Equal
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,67 @@
+package ovsdb
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookSink(t *testing.T) {
+	var mu sync.Mutex
+	var received [][]ChangeEvent
+	failures := 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		if !hmac.Equal([]byte(r.Header.Get(WebhookSignatureHeader)), []byte(SignWebhook("secret", body))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var events []ChangeEvent
+		json.Unmarshal(body, &events)
+		received = append(received, events)
+	}))
+	defer server.Close()
+
+	filter, err := ParseEventFilter("Logical_Switch_Port:.op==insert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := NewWebhookSink(Webhook{URL: server.URL, Secret: "secret", Filters: []*EventFilter{filter}})
+	sink.SetRetries(2, time.Millisecond)
+	events := []ChangeEvent{
+		{Database: "OVN_Northbound", Table: "Logical_Switch", UUID: ls1, Op: ChangeInsert, New: json.RawMessage(`{}`)},
+		{Database: "OVN_Northbound", Table: "Logical_Switch_Port", UUID: lsp1, Op: ChangeInsert, New: json.RawMessage(`{}`)},
+		{Database: "OVN_Northbound", Table: "Logical_Switch_Port", UUID: lsp2, Op: ChangeDelete, Old: json.RawMessage(`{}`)},
+	}
+	if err := sink.Publish(events); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if len(received) != 1 || len(received[0]) != 1 || received[0][0].UUID != lsp1 {
+		t.Errorf("webhook received %+v, want the insertion of lsp1", received)
+	}
+
+	// retries are exhausted
+	failures = 3
+	if err := sink.Publish(events); err == nil {
+		t.Error("Publish should fail after retries")
+	}
+
+	// a wrong signature is not retried
+	failures = 0
+	sink = NewWebhookSink(Webhook{URL: server.URL, Secret: "wrong"})
+	if err := sink.Publish(events); err == nil {
+		t.Error("Publish with a wrong secret should fail")
+	}
+}